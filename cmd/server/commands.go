@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/wire"
+)
+
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage: server <command> [flags]
+
+Commands:
+  serve            启动 HTTP 服务（默认）
+  migrate          数据库迁移：status | up | down [n] | to <version>
+  create-admin     创建管理员账号
+  reset-password   重置用户密码
+  seed             写入演示数据
+
+运行 "server <command> -h" 查看命令参数。
+`)
+}
+
+func serve() error {
+	app, cleanup, err := wire.InitializeApp()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	zap.L().Info("Server starting on :8080")
+
+	return app.Run()
+}
+
+// migrate 子命令缺省为 up；down 缺省回滚 1 个版本
+func migrate(args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	app, cleanup, err := wire.InitializeCLI()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var count int
+	switch action {
+	case "status":
+		statuses, err := app.MigrateStatus()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	case "up":
+		count, err = app.MigrateUp()
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid steps: %s", args[0])
+			}
+		}
+		count, err = app.MigrateDown(steps)
+	case "to":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: server migrate to <version>")
+		}
+		version, parseErr := strconv.ParseInt(args[0], 10, 64)
+		if parseErr != nil || version < 0 {
+			return fmt.Errorf("invalid version: %s", args[0])
+		}
+		count, err = app.MigrateTo(version)
+	default:
+		return fmt.Errorf("unknown migrate action: %s", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("Database migrated", zap.String("action", action), zap.Int("count", count))
+	return nil
+}
+
+// createAdmin 用户名与密码可通过参数或环境变量 ADMIN_USERNAME / ADMIN_PASSWORD 提供
+func createAdmin(args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	username := fs.String("username", os.Getenv("ADMIN_USERNAME"), "管理员用户名 (env ADMIN_USERNAME)")
+	password := fs.String("password", os.Getenv("ADMIN_PASSWORD"), "管理员密码 (env ADMIN_PASSWORD)")
+	nickname := fs.String("nickname", "管理员", "管理员昵称")
+	fs.Parse(args)
+
+	app, cleanup, err := wire.InitializeCLI()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := app.CreateAdmin(*username, *password, *nickname); err != nil {
+		return err
+	}
+
+	zap.L().Info("Admin created", zap.String("username", *username))
+	return nil
+}
+
+// resetPassword 密码可通过参数或环境变量 NEW_PASSWORD 提供
+func resetPassword(args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", os.Getenv("NEW_PASSWORD"), "新密码 (env NEW_PASSWORD)")
+	fs.Parse(args)
+
+	app, cleanup, err := wire.InitializeCLI()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := app.ResetPassword(*username, *password); err != nil {
+		return err
+	}
+
+	zap.L().Info("Password reset", zap.String("username", *username))
+	return nil
+}
+
+func seed() error {
+	app, cleanup, err := wire.InitializeCLI()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := app.Seed(); err != nil {
+		return err
+	}
+
+	zap.L().Info("Demo data seeded")
+	return nil
+}
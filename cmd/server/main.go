@@ -7,7 +7,6 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	_ "yuxialuozi_graduation_design_backend/docs"
-	"yuxialuozi_graduation_design_backend/internal/wire"
 )
 
 // @title 租户信息管理系统 API
@@ -33,17 +32,34 @@ func main() {
 	// 初始化日志
 	initLogger()
 
-	// 初始化应用
-	router, cleanup, err := wire.InitializeApp()
-	if err != nil {
-		zap.L().Fatal("Failed to initialize app", zap.Error(err))
+	command := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
 	}
-	defer cleanup()
 
-	zap.L().Info("Server starting on :8080")
+	var err error
+	switch command {
+	case "serve":
+		err = serve()
+	case "migrate":
+		err = migrate(args)
+	case "create-admin":
+		err = createAdmin(args)
+	case "reset-password":
+		err = resetPassword(args)
+	case "seed":
+		err = seed()
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		usage()
+		os.Exit(2)
+	}
 
-	if err := router.Run(); err != nil {
-		zap.L().Fatal("Failed to start server", zap.Error(err))
+	if err != nil {
+		zap.L().Fatal("Command failed", zap.String("command", command), zap.Error(err))
 	}
 }
 
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/wire"
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/database"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+)
+
+var ProviderSet = wire.NewSet(NewCLI)
+
+// CLI 运维命令，与 HTTP 服务共用同一套依赖注入图
+type CLI struct {
+	db            *gorm.DB
+	userService   *service.UserService
+	tenantService *service.TenantService
+}
+
+func NewCLI(db *gorm.DB, userService *service.UserService, tenantService *service.TenantService) *CLI {
+	return &CLI{
+		db:            db,
+		userService:   userService,
+		tenantService: tenantService,
+	}
+}
+
+func (c *CLI) MigrateStatus() ([]database.MigrationStatus, error) {
+	migrator, err := database.NewMigrator(c.db)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Status()
+}
+
+func (c *CLI) MigrateUp() (int, error) {
+	migrator, err := database.NewMigrator(c.db)
+	if err != nil {
+		return 0, err
+	}
+	return migrator.Up()
+}
+
+// MigrateDown 回滚最近执行的 steps 个迁移
+func (c *CLI) MigrateDown(steps int) (int, error) {
+	migrator, err := database.NewMigrator(c.db)
+	if err != nil {
+		return 0, err
+	}
+	return migrator.Down(steps)
+}
+
+// MigrateTo 升级或回滚到指定版本，version 为 0 表示回滚全部
+func (c *CLI) MigrateTo(version int64) (int, error) {
+	migrator, err := database.NewMigrator(c.db)
+	if err != nil {
+		return 0, err
+	}
+	return migrator.To(version)
+}
+
+// CreateAdmin 创建拥有全部权限的管理员账号
+func (c *CLI) CreateAdmin(username, password, nickname string) error {
+	if username == "" || password == "" {
+		return fmt.Errorf("username and password are required")
+	}
+	if len(password) < 6 {
+		return fmt.Errorf("password must be at least 6 characters")
+	}
+
+	admin := &model.User{
+		Username:    username,
+		Nickname:    nickname,
+		Role:        model.RoleAdmin,
+		Permissions: []string{"*"},
+	}
+	return c.userService.Create(admin, password)
+}
+
+func (c *CLI) ResetPassword(username, password string) error {
+	if username == "" || password == "" {
+		return fmt.Errorf("username and password are required")
+	}
+	if len(password) < 6 {
+		return fmt.Errorf("password must be at least 6 characters")
+	}
+	return c.userService.ResetPassword(username, password)
+}
+
+// Seed 写入演示数据，已有租户数据时跳过
+func (c *CLI) Seed() error {
+	tenants, err := c.tenantService.GetAll()
+	if err != nil {
+		return err
+	}
+	if len(tenants) > 0 {
+		return fmt.Errorf("database already contains tenants, skip seeding")
+	}
+
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		seedTenants := []model.Tenant{
+			{Name: "星辰科技有限公司", ContactPerson: "张伟", Phone: "13800000001", Email: "zhangwei@example.com", Status: "active"},
+			{Name: "云帆贸易有限公司", ContactPerson: "李娜", Phone: "13800000002", Email: "lina@example.com", Status: "active"},
+			{Name: "青禾设计工作室", ContactPerson: "王强", Phone: "13800000003", Email: "wangqiang@example.com", Status: "active"},
+		}
+		if err := tx.Create(&seedTenants).Error; err != nil {
+			return err
+		}
+
+		seedRooms := []model.Room{
+			{RoomNo: "A101", Building: "A栋", Floor: 1, Area: 80, MonthlyRent: 6000, Status: "occupied", TenantID: &seedTenants[0].ID},
+			{RoomNo: "A102", Building: "A栋", Floor: 1, Area: 60, MonthlyRent: 4500, Status: "occupied", TenantID: &seedTenants[1].ID},
+			{RoomNo: "A201", Building: "A栋", Floor: 2, Area: 120, MonthlyRent: 9000, Status: "vacant"},
+			{RoomNo: "B101", Building: "B栋", Floor: 1, Area: 50, MonthlyRent: 3800, Status: "occupied", TenantID: &seedTenants[2].ID},
+			{RoomNo: "B102", Building: "B栋", Floor: 1, Area: 50, MonthlyRent: 3800, Status: "vacant"},
+		}
+		if err := tx.Create(&seedRooms).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		end := start.AddDate(1, 0, -1)
+		seedContracts := []model.Contract{
+			{TenantID: seedTenants[0].ID, ContractNo: "HT-SEED-0001", StartDate: start, EndDate: end, Amount: 72000, Status: "active",
+				Rooms: []model.ContractRoom{{RoomID: seedRooms[0].ID, MonthlyRent: 6000}}},
+			{TenantID: seedTenants[1].ID, ContractNo: "HT-SEED-0002", StartDate: start, EndDate: end, Amount: 54000, Status: "active",
+				Rooms: []model.ContractRoom{{RoomID: seedRooms[1].ID, MonthlyRent: 4500}}},
+			{TenantID: seedTenants[2].ID, ContractNo: "HT-SEED-0003", StartDate: start, EndDate: end, Amount: 45600, Status: "active",
+				Rooms: []model.ContractRoom{{RoomID: seedRooms[3].ID, MonthlyRent: 3800}}},
+		}
+		// 租赁房间随合同一并写入 contract_rooms
+		return tx.Create(&seedContracts).Error
+	})
+}
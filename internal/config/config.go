@@ -9,29 +9,133 @@ import (
 var ProviderSet = wire.NewSet(NewConfig)
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Login     LoginConfig     `mapstructure:"login"`
+	MFA       MFAConfig       `mapstructure:"mfa"`
+	Contract  ContractConfig  `mapstructure:"contract"`
+	Billing   BillingConfig   `mapstructure:"billing"`
+	Overdue   OverdueConfig   `mapstructure:"overdue"`
+	Company   CompanyConfig   `mapstructure:"company"`
+	Invoice   InvoiceConfig   `mapstructure:"invoice"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Log       LogConfig       `mapstructure:"log"`
 }
 
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+	// RequireIfMatch 为 true 时更新业务数据的 PUT 请求必须携带 If-Match
+	RequireIfMatch bool `mapstructure:"require_if_match"`
 }
 
 type DatabaseConfig struct {
+	// Driver 存储实现：postgres（默认）或 memory，memory 仅用于测试与本地演示，数据不持久化
+	Driver   string `mapstructure:"driver"`
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	User     string `mapstructure:"user"`
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
 	SSLMode  string `mapstructure:"sslmode"`
+	// AutoMigrate 启动时自动执行未完成的迁移；未配置时仅 debug 模式开启
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type JWTConfig struct {
-	Secret string `mapstructure:"secret"`
-	Expire string `mapstructure:"expire"`
+	Algorithm        string `mapstructure:"algorithm"`
+	Secret           string `mapstructure:"secret"`
+	Expire           string `mapstructure:"expire"`
+	RefreshExpire    string `mapstructure:"refresh_expire"`
+	RotationInterval string `mapstructure:"rotation_interval"`
+	RotationOverlap  string `mapstructure:"rotation_overlap"`
+}
+
+type LoginConfig struct {
+	MaxAttempts     int    `mapstructure:"max_attempts"`
+	LockoutDuration string `mapstructure:"lockout_duration"`
+	BackoffBase     string `mapstructure:"backoff_base"`
+	IPMaxAttempts   int    `mapstructure:"ip_max_attempts"`
+	IPWindow        string `mapstructure:"ip_window"`
+}
+
+type MFAConfig struct {
+	Issuer        string   `mapstructure:"issuer"`
+	PendingExpire string   `mapstructure:"pending_expire"`
+	RequiredRoles []string `mapstructure:"required_roles"`
+}
+
+// IsRequired 判断该角色是否被策略要求启用两步验证
+func (c MFAConfig) IsRequired(role string) bool {
+	for _, r := range c.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type ContractConfig struct {
+	// ExpiringDays 距到期日不足该天数的生效合同标记为 expiring
+	ExpiringDays int `mapstructure:"expiring_days"`
+	// ReminderDays 距到期日多少天时生成续租提醒，如 [90, 60, 30]
+	ReminderDays []int `mapstructure:"reminder_days"`
+}
+
+type BillingConfig struct {
+	// AutoRun 为 true 时后台任务自动生成当月租金
+	AutoRun bool `mapstructure:"auto_run"`
+	// LeadDays 提前生成账单的天数，距下月不足该天数时后台任务同时生成下月租金
+	LeadDays int `mapstructure:"lead_days"`
+}
+
+type OverdueConfig struct {
+	// GraceDays 宽限天数，超过到期日该天数仍未结清的费用标记为 overdue
+	GraceDays int `mapstructure:"grace_days"`
+	// LateFees 按费用类型配置的滞纳金规则，未配置的费用类型逾期不收滞纳金
+	LateFees map[string]LateFeeRule `mapstructure:"late_fees"`
+}
+
+// LateFeeRule 滞纳金规则：FlatAmount 为逾期即收的固定金额，DailyRate 为超过宽限期后每天按未收金额收取的比例；
+// MaxAmount、MaxRate 分别按金额和原费用金额的比例封顶，为 0 时不限
+type LateFeeRule struct {
+	DailyRate  float64 `mapstructure:"daily_rate"`
+	FlatAmount float64 `mapstructure:"flat_amount"`
+	MaxAmount  float64 `mapstructure:"max_amount"`
+	MaxRate    float64 `mapstructure:"max_rate"`
+}
+
+// CompanyConfig 打印在发票、收据等单据抬头的出租方信息
+type CompanyConfig struct {
+	Name        string `mapstructure:"name"`
+	TaxID       string `mapstructure:"tax_id"`
+	Address     string `mapstructure:"address"`
+	Phone       string `mapstructure:"phone"`
+	BankName    string `mapstructure:"bank_name"`
+	BankAccount string `mapstructure:"bank_account"`
+}
+
+type InvoiceConfig struct {
+	// TaxRates 按费用类型配置的税率，费用金额视为含税金额，开票时拆分出不含税金额与税额
+	TaxRates map[string]float64 `mapstructure:"tax_rates"`
+	// DefaultTaxRate 未在 TaxRates 中配置的费用类型使用的税率
+	DefaultTaxRate float64 `mapstructure:"default_tax_rate"`
+}
+
+// TaxRate 返回费用类型适用的税率
+func (c InvoiceConfig) TaxRate(feeType string) float64 {
+	if rate, ok := c.TaxRates[feeType]; ok {
+		return rate
+	}
+	return c.DefaultTaxRate
+}
+
+type SchedulerConfig struct {
+	// Enabled 为 false 时不启动后台任务，多副本部署时可只在部分实例开启
+	Enabled bool `mapstructure:"enabled"`
+	// Interval 后台任务的执行间隔
+	Interval string `mapstructure:"interval"`
 }
 
 type LogConfig struct {
@@ -48,10 +152,33 @@ func NewConfig() (*Config, error) {
 	// 设置默认值
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.require_if_match", false)
+	viper.SetDefault("database.driver", "postgres")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.sslmode", "disable")
-	viper.SetDefault("jwt.expire", "24h")
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.expire", "15m")
+	viper.SetDefault("jwt.refresh_expire", "168h")
+	viper.SetDefault("jwt.rotation_interval", "720h")
+	viper.SetDefault("jwt.rotation_overlap", "24h")
+	viper.SetDefault("login.max_attempts", 5)
+	viper.SetDefault("login.lockout_duration", "15m")
+	viper.SetDefault("login.backoff_base", "1s")
+	viper.SetDefault("login.ip_max_attempts", 20)
+	viper.SetDefault("login.ip_window", "15m")
+	viper.SetDefault("mfa.issuer", "租户信息管理系统")
+	viper.SetDefault("mfa.pending_expire", "5m")
+	viper.SetDefault("mfa.required_roles", []string{})
+	viper.SetDefault("contract.expiring_days", 30)
+	viper.SetDefault("contract.reminder_days", []int{90, 60, 30})
+	viper.SetDefault("billing.auto_run", true)
+	viper.SetDefault("billing.lead_days", 7)
+	viper.SetDefault("overdue.grace_days", 5)
+	viper.SetDefault("company.name", "租户信息管理系统")
+	viper.SetDefault("invoice.default_tax_rate", 0)
+	viper.SetDefault("scheduler.enabled", true)
+	viper.SetDefault("scheduler.interval", "1h")
 	viper.SetDefault("log.level", "debug")
 	viper.SetDefault("log.format", "json")
 
@@ -70,5 +197,9 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if !viper.IsSet("database.auto_migrate") {
+		config.Database.AutoMigrate = config.Server.Mode != "release"
+	}
+
 	return &config, nil
 }
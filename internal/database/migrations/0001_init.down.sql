@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS maintenances;
+DROP TABLE IF EXISTS fees;
+DROP TABLE IF EXISTS rooms;
+DROP TABLE IF EXISTS contracts;
+DROP TABLE IF EXISTS tenants;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS signing_keys;
+DROP TABLE IF EXISTS login_histories;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,212 @@
+-- 初始表结构，与此前 AutoMigrate 生成的结构一致；
+-- 使用 IF NOT EXISTS 以便已有数据库直接纳入版本管理
+
+CREATE TABLE IF NOT EXISTS users (
+    id                 bigserial PRIMARY KEY,
+    username           varchar(50)  NOT NULL,
+    password           varchar(255) NOT NULL,
+    nickname           varchar(50),
+    avatar             varchar(255),
+    role               varchar(20)  DEFAULT 'user',
+    permissions        text[],
+    status             varchar(20)  DEFAULT 'active',
+    tenant_id          bigint,
+    failed_attempts    bigint       DEFAULT 0,
+    locked_until       timestamptz,
+    mfa_enabled        boolean      DEFAULT false,
+    mfa_secret         varchar(64),
+    mfa_pending_secret varchar(64),
+    mfa_recovery_codes text[],
+    mfa_last_step      bigint,
+    created_at         timestamptz,
+    updated_at         timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id                bigserial PRIMARY KEY,
+    user_id           bigint      NOT NULL,
+    token_hash        varchar(64) NOT NULL,
+    family_id         varchar(32) NOT NULL,
+    access_jti        varchar(32),
+    access_expires_at timestamptz,
+    expires_at        timestamptz,
+    used_at           timestamptz,
+    revoked_at        timestamptz,
+    ip                varchar(64),
+    user_agent        varchar(255),
+    created_at        timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_access_jti ON refresh_tokens (access_jti);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id         bigserial PRIMARY KEY,
+    jti        varchar(32) NOT NULL,
+    user_id    bigint,
+    expires_at timestamptz,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revoked_tokens_jti ON revoked_tokens (jti);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS login_histories (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint,
+    username   varchar(50),
+    ip         varchar(64),
+    user_agent varchar(255),
+    success    boolean,
+    reason     varchar(100),
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_login_histories_user_id ON login_histories (user_id);
+CREATE INDEX IF NOT EXISTS idx_login_histories_username ON login_histories (username);
+CREATE INDEX IF NOT EXISTS idx_login_histories_ip ON login_histories (ip);
+CREATE INDEX IF NOT EXISTS idx_login_histories_created_at ON login_histories (created_at);
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id          bigserial PRIMARY KEY,
+    kid         varchar(32) NOT NULL,
+    algorithm   varchar(10) NOT NULL,
+    private_key text        NOT NULL,
+    public_key  text        NOT NULL,
+    retired_at  timestamptz,
+    expires_at  timestamptz,
+    created_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_kid ON signing_keys (kid);
+CREATE INDEX IF NOT EXISTS idx_signing_keys_expires_at ON signing_keys (expires_at);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           bigserial PRIMARY KEY,
+    user_id      bigint       NOT NULL,
+    name         varchar(100) NOT NULL,
+    prefix       varchar(16)  NOT NULL,
+    key_hash     varchar(64)  NOT NULL,
+    permissions  text[],
+    allowed_ips  text[],
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    last_used_ip varchar(64),
+    revoked_at   timestamptz,
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          bigserial PRIMARY KEY,
+    actor_id    bigint,
+    actor_name  varchar(50),
+    api_key_id  bigint,
+    entity_type varchar(30) NOT NULL,
+    entity_id   bigint      NOT NULL,
+    action      varchar(20) NOT NULL,
+    before      jsonb,
+    after       jsonb,
+    ip          varchar(64),
+    request_id  varchar(64),
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs (request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id             bigserial PRIMARY KEY,
+    name           varchar(100) NOT NULL,
+    contact_person varchar(50),
+    phone          varchar(20),
+    email          varchar(100),
+    status         varchar(20) DEFAULT 'active',
+    version        bigint      NOT NULL DEFAULT 1,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants (deleted_at);
+
+CREATE TABLE IF NOT EXISTS contracts (
+    id          bigserial PRIMARY KEY,
+    tenant_id   bigint      NOT NULL,
+    contract_no varchar(50) NOT NULL,
+    start_date  timestamptz,
+    end_date    timestamptz,
+    amount      decimal(10,2),
+    status      varchar(20) DEFAULT 'draft',
+    version     bigint      NOT NULL DEFAULT 1,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    CONSTRAINT fk_contracts_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_contracts_tenant_id ON contracts (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contracts_contract_no ON contracts (contract_no);
+CREATE INDEX IF NOT EXISTS idx_contracts_deleted_at ON contracts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id           bigserial PRIMARY KEY,
+    room_no      varchar(20) NOT NULL,
+    building     varchar(50),
+    floor        bigint,
+    area         decimal(10,2),
+    monthly_rent decimal(10,2),
+    status       varchar(20) DEFAULT 'vacant',
+    tenant_id    bigint,
+    version      bigint      NOT NULL DEFAULT 1,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    CONSTRAINT fk_rooms_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rooms_room_no ON rooms (room_no);
+CREATE INDEX IF NOT EXISTS idx_rooms_tenant_id ON rooms (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_rooms_deleted_at ON rooms (deleted_at);
+
+CREATE TABLE IF NOT EXISTS fees (
+    id         bigserial PRIMARY KEY,
+    tenant_id  bigint      NOT NULL,
+    room_no    varchar(20),
+    fee_type   varchar(20) NOT NULL,
+    amount     decimal(10,2),
+    period     varchar(20),
+    due_date   timestamptz,
+    paid_date  timestamptz,
+    status     varchar(20) DEFAULT 'unpaid',
+    version    bigint      NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_fees_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_fees_tenant_id ON fees (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_fees_deleted_at ON fees (deleted_at);
+
+CREATE TABLE IF NOT EXISTS maintenances (
+    id           bigserial PRIMARY KEY,
+    ticket_no    varchar(50) NOT NULL,
+    tenant_id    bigint      NOT NULL,
+    room_no      varchar(20),
+    type         varchar(20),
+    description  text,
+    priority     varchar(20) DEFAULT 'medium',
+    status       varchar(20) DEFAULT 'pending',
+    assignee     varchar(50),
+    version      bigint      NOT NULL DEFAULT 1,
+    created_at   timestamptz,
+    completed_at timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    CONSTRAINT fk_maintenances_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_maintenances_ticket_no ON maintenances (ticket_no);
+CREATE INDEX IF NOT EXISTS idx_maintenances_tenant_id ON maintenances (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_maintenances_deleted_at ON maintenances (deleted_at);
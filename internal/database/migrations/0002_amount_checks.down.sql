@@ -0,0 +1,4 @@
+ALTER TABLE fees DROP CONSTRAINT IF EXISTS chk_fees_amount;
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS chk_rooms_monthly_rent;
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS chk_rooms_area;
+ALTER TABLE contracts DROP CONSTRAINT IF EXISTS chk_contracts_amount;
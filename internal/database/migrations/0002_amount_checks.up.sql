@@ -0,0 +1,5 @@
+-- 金额与面积不允许为负，防止绕过接口校验写入脏数据
+ALTER TABLE contracts ADD CONSTRAINT chk_contracts_amount CHECK (amount >= 0);
+ALTER TABLE rooms ADD CONSTRAINT chk_rooms_area CHECK (area >= 0);
+ALTER TABLE rooms ADD CONSTRAINT chk_rooms_monthly_rent CHECK (monthly_rent >= 0);
+ALTER TABLE fees ADD CONSTRAINT chk_fees_amount CHECK (amount >= 0);
@@ -0,0 +1,13 @@
+-- 合同与房间的租赁关系，每间房间单独约定月租金
+CREATE TABLE IF NOT EXISTS contract_rooms (
+    id           bigserial PRIMARY KEY,
+    contract_id  bigint        NOT NULL,
+    room_id      bigint        NOT NULL,
+    monthly_rent decimal(10,2) NOT NULL DEFAULT 0,
+    created_at   timestamptz,
+    CONSTRAINT fk_contract_rooms_contract FOREIGN KEY (contract_id) REFERENCES contracts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_rooms_room FOREIGN KEY (room_id) REFERENCES rooms (id),
+    CONSTRAINT chk_contract_rooms_monthly_rent CHECK (monthly_rent >= 0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contract_rooms_contract_room ON contract_rooms (contract_id, room_id);
+CREATE INDEX IF NOT EXISTS idx_contract_rooms_room_id ON contract_rooms (room_id);
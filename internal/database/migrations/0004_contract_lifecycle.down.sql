@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contract_transitions;
+ALTER TABLE contracts DROP CONSTRAINT IF EXISTS chk_contracts_status;
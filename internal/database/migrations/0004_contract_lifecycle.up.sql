@@ -0,0 +1,20 @@
+-- 合同状态此前可任意填写，不在生命周期内的历史状态统一归为草稿后再加约束
+UPDATE contracts SET status = 'draft'
+WHERE status IS NULL
+   OR status NOT IN ('draft', 'pending_approval', 'active', 'expiring', 'expired', 'terminated', 'renewed');
+
+ALTER TABLE contracts ADD CONSTRAINT chk_contracts_status
+    CHECK (status IN ('draft', 'pending_approval', 'active', 'expiring', 'expired', 'terminated', 'renewed'));
+
+CREATE TABLE IF NOT EXISTS contract_transitions (
+    id          bigserial PRIMARY KEY,
+    contract_id bigint      NOT NULL,
+    from_status varchar(20) NOT NULL DEFAULT '',
+    to_status   varchar(20) NOT NULL,
+    reason      varchar(500),
+    actor_id    bigint,
+    actor_name  varchar(50),
+    created_at  timestamptz,
+    CONSTRAINT fk_contract_transitions_contract FOREIGN KEY (contract_id) REFERENCES contracts (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_contract_transitions_contract_id ON contract_transitions (contract_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_contracts_status_end_date;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id          bigserial PRIMARY KEY,
+    type        varchar(30)  NOT NULL,
+    dedup_key   varchar(100) NOT NULL,
+    tenant_id   bigint,
+    contract_id bigint,
+    title       varchar(200) NOT NULL,
+    content     varchar(1000),
+    read_at     timestamptz,
+    created_at  timestamptz,
+    CONSTRAINT fk_notifications_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_contract FOREIGN KEY (contract_id) REFERENCES contracts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_dedup_key ON notifications (dedup_key);
+CREATE INDEX IF NOT EXISTS idx_notifications_type ON notifications (type);
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_id ON notifications (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_contract_id ON notifications (contract_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications (created_at);
+
+-- 到期扫描按状态与到期日查询合同
+CREATE INDEX IF NOT EXISTS idx_contracts_status_end_date ON contracts (status, end_date);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS cpi_indices;
+DROP TABLE IF EXISTS contract_rent_adjustments;
+
+DROP INDEX IF EXISTS idx_contracts_predecessor_id;
+ALTER TABLE contracts
+    DROP CONSTRAINT IF EXISTS chk_contracts_escalation_timing,
+    DROP CONSTRAINT IF EXISTS chk_contracts_escalation_type,
+    DROP CONSTRAINT IF EXISTS fk_contracts_predecessor,
+    DROP COLUMN IF EXISTS escalation_applied_years,
+    DROP COLUMN IF EXISTS escalation_timing,
+    DROP COLUMN IF EXISTS escalation_value,
+    DROP COLUMN IF EXISTS escalation_type,
+    DROP COLUMN IF EXISTS predecessor_id;
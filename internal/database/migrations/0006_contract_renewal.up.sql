@@ -0,0 +1,36 @@
+-- 续签关联、租金递增条款、租金调整记录与本地维护的 CPI 年度涨幅
+ALTER TABLE contracts
+    ADD COLUMN IF NOT EXISTS predecessor_id            bigint,
+    ADD COLUMN IF NOT EXISTS escalation_type           varchar(20)   NOT NULL DEFAULT 'none',
+    ADD COLUMN IF NOT EXISTS escalation_value          decimal(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS escalation_timing         varchar(20)   NOT NULL DEFAULT 'renewal',
+    ADD COLUMN IF NOT EXISTS escalation_applied_years  integer       NOT NULL DEFAULT 0;
+
+ALTER TABLE contracts
+    ADD CONSTRAINT fk_contracts_predecessor FOREIGN KEY (predecessor_id) REFERENCES contracts (id) ON DELETE SET NULL,
+    ADD CONSTRAINT chk_contracts_escalation_type CHECK (escalation_type IN ('none', 'percent', 'amount', 'cpi')),
+    ADD CONSTRAINT chk_contracts_escalation_timing CHECK (escalation_timing IN ('renewal', 'anniversary'));
+CREATE INDEX IF NOT EXISTS idx_contracts_predecessor_id ON contracts (predecessor_id);
+
+CREATE TABLE IF NOT EXISTS contract_rent_adjustments (
+    id             bigserial PRIMARY KEY,
+    contract_id    bigint NOT NULL,
+    room_id        bigint NOT NULL,
+    effective_date timestamptz,
+    old_rent       decimal(10,2),
+    new_rent       decimal(10,2),
+    reason         varchar(200),
+    created_at     timestamptz,
+    CONSTRAINT fk_contract_rent_adjustments_contract FOREIGN KEY (contract_id) REFERENCES contracts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_contract_rent_adjustments_room FOREIGN KEY (room_id) REFERENCES rooms (id)
+);
+CREATE INDEX IF NOT EXISTS idx_contract_rent_adjustments_contract_id ON contract_rent_adjustments (contract_id);
+
+CREATE TABLE IF NOT EXISTS cpi_indices (
+    id         bigserial PRIMARY KEY,
+    year       integer      NOT NULL,
+    rate       decimal(6,2) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cpi_indices_year ON cpi_indices (year);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_fees_contract_rent_period;
+DROP INDEX IF EXISTS idx_fees_contract_id;
+ALTER TABLE fees
+    DROP CONSTRAINT IF EXISTS fk_fees_contract,
+    DROP COLUMN IF EXISTS contract_id;
+
+ALTER TABLE contracts
+    DROP CONSTRAINT IF EXISTS chk_contracts_billing_mode,
+    DROP CONSTRAINT IF EXISTS chk_contracts_billing_due_day,
+    DROP CONSTRAINT IF EXISTS chk_contracts_billing_cycle,
+    DROP COLUMN IF EXISTS billing_mode,
+    DROP COLUMN IF EXISTS billing_due_day,
+    DROP COLUMN IF EXISTS billing_cycle;
@@ -0,0 +1,17 @@
+-- 合同计费条款；账单生成的租金关联合同，同一合同、房间、计费周期只生成一笔（含回收站中的费用）
+ALTER TABLE contracts
+    ADD COLUMN IF NOT EXISTS billing_cycle   varchar(20) NOT NULL DEFAULT 'monthly',
+    ADD COLUMN IF NOT EXISTS billing_due_day integer     NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS billing_mode    varchar(20) NOT NULL DEFAULT 'advance';
+
+ALTER TABLE contracts
+    ADD CONSTRAINT chk_contracts_billing_cycle CHECK (billing_cycle IN ('monthly', 'quarterly')),
+    ADD CONSTRAINT chk_contracts_billing_due_day CHECK (billing_due_day BETWEEN 1 AND 28),
+    ADD CONSTRAINT chk_contracts_billing_mode CHECK (billing_mode IN ('advance', 'arrears'));
+
+ALTER TABLE fees ADD COLUMN IF NOT EXISTS contract_id bigint;
+ALTER TABLE fees
+    ADD CONSTRAINT fk_fees_contract FOREIGN KEY (contract_id) REFERENCES contracts (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_fees_contract_id ON fees (contract_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fees_contract_rent_period ON fees (contract_id, room_no, period)
+    WHERE contract_id IS NOT NULL AND fee_type = 'rent';
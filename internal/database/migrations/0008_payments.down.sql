@@ -0,0 +1,7 @@
+UPDATE fees SET status = 'unpaid' WHERE status = 'partially_paid';
+ALTER TABLE fees
+    DROP CONSTRAINT IF EXISTS chk_fees_paid_amount,
+    DROP COLUMN IF EXISTS paid_amount;
+
+DROP TABLE IF EXISTS payment_allocations;
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,60 @@
+-- 收款记录及其在费用间的分配；费用的已收金额由未冲正的收款分配汇总而来
+CREATE TABLE IF NOT EXISTS payments (
+    id              bigserial PRIMARY KEY,
+    tenant_id       bigint        NOT NULL,
+    amount          decimal(10,2) NOT NULL,
+    method          varchar(20)   NOT NULL,
+    reference       varchar(100),
+    received_at     timestamptz   NOT NULL,
+    operator_id     bigint,
+    operator_name   varchar(50),
+    status          varchar(20)   NOT NULL DEFAULT 'completed',
+    remark          varchar(500),
+    reversed_at     timestamptz,
+    reversed_by     varchar(50),
+    reversal_reason varchar(500),
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    CONSTRAINT fk_payments_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT chk_payments_amount CHECK (amount > 0),
+    CONSTRAINT chk_payments_method CHECK (method IN ('cash', 'bank_transfer', 'wechat', 'alipay', 'other')),
+    CONSTRAINT chk_payments_status CHECK (status IN ('completed', 'reversed'))
+);
+CREATE INDEX IF NOT EXISTS idx_payments_tenant_id ON payments (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_payments_received_at ON payments (received_at);
+
+CREATE TABLE IF NOT EXISTS payment_allocations (
+    id         bigserial PRIMARY KEY,
+    payment_id bigint        NOT NULL,
+    fee_id     bigint        NOT NULL,
+    amount     decimal(10,2) NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_payment_allocations_payment FOREIGN KEY (payment_id) REFERENCES payments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_payment_allocations_fee FOREIGN KEY (fee_id) REFERENCES fees (id),
+    CONSTRAINT chk_payment_allocations_amount CHECK (amount > 0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_allocations_payment_fee ON payment_allocations (payment_id, fee_id);
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_fee_id ON payment_allocations (fee_id);
+
+ALTER TABLE fees ADD COLUMN IF NOT EXISTS paid_amount decimal(10,2) NOT NULL DEFAULT 0;
+
+-- 已缴纳的历史费用各补一笔收款，收入统计改为按收款汇总后金额保持不变
+WITH legacy AS (
+    SELECT id, tenant_id, amount, COALESCE(paid_date, updated_at, created_at, now()) AS received_at
+    FROM fees
+    WHERE status = 'paid' AND amount > 0
+), inserted AS (
+    INSERT INTO payments (tenant_id, amount, method, reference, received_at, operator_name, status, remark, created_at, updated_at)
+    SELECT tenant_id, amount, 'other', 'legacy-fee-' || id, received_at, 'system', 'completed', '历史缴费迁移', now(), now()
+    FROM legacy
+    RETURNING id, reference
+)
+INSERT INTO payment_allocations (payment_id, fee_id, amount, created_at)
+SELECT inserted.id, legacy.id, legacy.amount, now()
+FROM inserted
+JOIN legacy ON inserted.reference = 'legacy-fee-' || legacy.id;
+
+UPDATE fees SET paid_amount = amount WHERE status = 'paid' AND amount > 0;
+
+ALTER TABLE fees
+    ADD CONSTRAINT chk_fees_paid_amount CHECK (paid_amount >= 0 AND paid_amount <= amount);
@@ -0,0 +1,10 @@
+UPDATE fees SET status = 'unpaid' WHERE status = 'waived';
+
+DROP INDEX IF EXISTS idx_fees_status_due_date;
+DROP INDEX IF EXISTS idx_fees_penalty_of_id;
+ALTER TABLE fees
+    DROP CONSTRAINT IF EXISTS fk_fees_penalty_of,
+    DROP COLUMN IF EXISTS waive_reason,
+    DROP COLUMN IF EXISTS waived_by,
+    DROP COLUMN IF EXISTS waived_at,
+    DROP COLUMN IF EXISTS penalty_of_id;
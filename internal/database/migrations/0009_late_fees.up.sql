@@ -0,0 +1,13 @@
+-- 滞纳金关联所针对的逾期费用，每笔逾期费用至多一笔（含回收站中的滞纳金）；减免后记录操作人与原因
+ALTER TABLE fees
+    ADD COLUMN IF NOT EXISTS penalty_of_id bigint,
+    ADD COLUMN IF NOT EXISTS waived_at     timestamptz,
+    ADD COLUMN IF NOT EXISTS waived_by     varchar(50),
+    ADD COLUMN IF NOT EXISTS waive_reason  varchar(500);
+
+ALTER TABLE fees
+    ADD CONSTRAINT fk_fees_penalty_of FOREIGN KEY (penalty_of_id) REFERENCES fees (id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fees_penalty_of_id ON fees (penalty_of_id) WHERE penalty_of_id IS NOT NULL;
+
+-- 逾期检查按状态与到期日查找费用
+CREATE INDEX IF NOT EXISTS idx_fees_status_due_date ON fees (status, due_date) WHERE deleted_at IS NULL;
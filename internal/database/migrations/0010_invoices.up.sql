@@ -0,0 +1,60 @@
+-- 发票与红字发票；编号按系列、年度连续分配，草稿没有编号
+CREATE TABLE IF NOT EXISTS invoices (
+    id              bigserial PRIMARY KEY,
+    invoice_no      varchar(30),
+    kind            varchar(20)   NOT NULL DEFAULT 'invoice',
+    tenant_id       bigint        NOT NULL,
+    period          varchar(20),
+    original_id     bigint,
+    status          varchar(20)   NOT NULL DEFAULT 'draft',
+    subtotal        decimal(12,2) NOT NULL,
+    tax_amount      decimal(12,2) NOT NULL,
+    total           decimal(12,2) NOT NULL,
+    credited_amount decimal(12,2) NOT NULL DEFAULT 0,
+    remark          varchar(500),
+    issued_at       timestamptz,
+    issued_by       varchar(50),
+    voided_at       timestamptz,
+    voided_by       varchar(50),
+    void_reason     varchar(500),
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    CONSTRAINT fk_invoices_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_invoices_original FOREIGN KEY (original_id) REFERENCES invoices (id),
+    CONSTRAINT chk_invoices_kind CHECK (kind IN ('invoice', 'credit_note')),
+    CONSTRAINT chk_invoices_status CHECK (status IN ('draft', 'issued', 'void')),
+    CONSTRAINT chk_invoices_number CHECK (status = 'draft' OR invoice_no IS NOT NULL)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_invoice_no ON invoices (invoice_no);
+CREATE INDEX IF NOT EXISTS idx_invoices_tenant_id ON invoices (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_original_id ON invoices (original_id);
+
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    id               bigserial PRIMARY KEY,
+    invoice_id       bigint        NOT NULL,
+    fee_id           bigint,
+    fee_type         varchar(20),
+    room_no          varchar(20),
+    period           varchar(20),
+    original_line_id bigint,
+    description      varchar(200),
+    amount           decimal(12,2) NOT NULL,
+    tax_rate         decimal(5,4)  NOT NULL,
+    tax_amount       decimal(12,2) NOT NULL,
+    total            decimal(12,2) NOT NULL,
+    created_at       timestamptz,
+    CONSTRAINT fk_invoice_lines_invoice FOREIGN KEY (invoice_id) REFERENCES invoices (id) ON DELETE CASCADE,
+    CONSTRAINT fk_invoice_lines_fee FOREIGN KEY (fee_id) REFERENCES fees (id),
+    CONSTRAINT fk_invoice_lines_original FOREIGN KEY (original_line_id) REFERENCES invoice_lines (id),
+    CONSTRAINT chk_invoice_lines_tax_rate CHECK (tax_rate >= 0 AND tax_rate < 1)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_lines_invoice_id ON invoice_lines (invoice_id);
+CREATE INDEX IF NOT EXISTS idx_invoice_lines_fee_id ON invoice_lines (fee_id);
+CREATE INDEX IF NOT EXISTS idx_invoice_lines_original_line_id ON invoice_lines (original_line_id);
+
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    series  varchar(10) NOT NULL,
+    year    integer     NOT NULL,
+    last_no integer     NOT NULL,
+    PRIMARY KEY (series, year)
+);
@@ -0,0 +1,2 @@
+DELETE FROM invoice_sequences WHERE series = 'RC';
+DROP TABLE IF EXISTS receipts;
@@ -0,0 +1,44 @@
+-- 收款收据，每笔收款一张；编号与发票共用 invoice_sequences，系列为 RC
+CREATE TABLE IF NOT EXISTS receipts (
+    id              bigserial PRIMARY KEY,
+    receipt_no      varchar(30)   NOT NULL,
+    payment_id      bigint        NOT NULL,
+    tenant_id       bigint        NOT NULL,
+    amount          decimal(10,2) NOT NULL,
+    status          varchar(20)   NOT NULL DEFAULT 'valid',
+    issued_at       timestamptz   NOT NULL,
+    issued_by       varchar(50),
+    print_count     integer       NOT NULL DEFAULT 0,
+    last_printed_at timestamptz,
+    voided_at       timestamptz,
+    voided_by       varchar(50),
+    void_reason     varchar(500),
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    CONSTRAINT fk_receipts_payment FOREIGN KEY (payment_id) REFERENCES payments (id),
+    CONSTRAINT fk_receipts_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT chk_receipts_status CHECK (status IN ('valid', 'void'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receipts_receipt_no ON receipts (receipt_no);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receipts_payment_id ON receipts (payment_id);
+CREATE INDEX IF NOT EXISTS idx_receipts_tenant_id ON receipts (tenant_id);
+
+-- 已登记的收款按到账年度补开收据，已冲正的收款对应的收据为作废状态
+WITH numbered AS (
+    SELECT p.*,
+           extract(year FROM p.received_at)::int AS year,
+           row_number() OVER (PARTITION BY extract(year FROM p.received_at) ORDER BY p.received_at, p.id) AS seq
+    FROM payments p
+)
+INSERT INTO receipts (receipt_no, payment_id, tenant_id, amount, status, issued_at, issued_by,
+                      voided_at, voided_by, void_reason, created_at, updated_at)
+SELECT 'RC-' || year || '-' || lpad(seq::text, 6, '0'), id, tenant_id, amount,
+       CASE WHEN status = 'reversed' THEN 'void' ELSE 'valid' END,
+       received_at, operator_name, reversed_at, reversed_by, reversal_reason, now(), now()
+FROM numbered;
+
+INSERT INTO invoice_sequences (series, year, last_no)
+SELECT 'RC', extract(year FROM issued_at)::int, count(*)
+FROM receipts
+GROUP BY extract(year FROM issued_at)::int
+ON CONFLICT (series, year) DO UPDATE SET last_no = GREATEST(invoice_sequences.last_no, EXCLUDED.last_no);
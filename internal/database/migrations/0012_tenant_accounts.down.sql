@@ -0,0 +1,16 @@
+-- 退回账户余额抵扣计入的已收金额
+UPDATE fees f
+SET paid_amount = f.paid_amount - a.amount,
+    status = CASE
+        WHEN f.status = 'overdue' THEN 'overdue'
+        WHEN f.paid_amount - a.amount > 0 THEN 'partially_paid'
+        ELSE 'unpaid'
+    END,
+    paid_date = NULL
+FROM (SELECT fee_id, sum(amount) AS amount FROM credit_applications GROUP BY fee_id) a
+WHERE f.id = a.fee_id;
+
+DELETE FROM invoice_sequences WHERE series IN ('CR', 'RF');
+DROP TABLE IF EXISTS credit_applications;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS credit_notes;
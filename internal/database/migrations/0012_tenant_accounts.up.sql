@@ -0,0 +1,57 @@
+-- 租户账户：贷项通知单与退款，编号与发票共用 invoice_sequences，系列分别为 CR、RF
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id         bigserial PRIMARY KEY,
+    credit_no  varchar(30)   NOT NULL,
+    tenant_id  bigint        NOT NULL,
+    fee_id     bigint,
+    amount     decimal(10,2) NOT NULL,
+    reason     varchar(500)  NOT NULL,
+    issued_at  timestamptz   NOT NULL,
+    issued_by  varchar(50),
+    created_at timestamptz,
+    CONSTRAINT fk_credit_notes_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_credit_notes_fee FOREIGN KEY (fee_id) REFERENCES fees (id),
+    CONSTRAINT chk_credit_notes_amount CHECK (amount > 0)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_credit_notes_credit_no ON credit_notes (credit_no);
+CREATE INDEX IF NOT EXISTS idx_credit_notes_tenant_id ON credit_notes (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_credit_notes_fee_id ON credit_notes (fee_id);
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id            bigserial PRIMARY KEY,
+    refund_no     varchar(30)   NOT NULL,
+    tenant_id     bigint        NOT NULL,
+    payment_id    bigint,
+    amount        decimal(10,2) NOT NULL,
+    method        varchar(20)   NOT NULL,
+    reference     varchar(100),
+    reason        varchar(500)  NOT NULL,
+    refunded_at   timestamptz   NOT NULL,
+    operator_id   bigint,
+    operator_name varchar(50),
+    created_at    timestamptz,
+    CONSTRAINT fk_refunds_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_refunds_payment FOREIGN KEY (payment_id) REFERENCES payments (id),
+    CONSTRAINT chk_refunds_amount CHECK (amount > 0),
+    CONSTRAINT chk_refunds_method CHECK (method IN ('cash', 'bank_transfer', 'wechat', 'alipay', 'other'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_refund_no ON refunds (refund_no);
+CREATE INDEX IF NOT EXISTS idx_refunds_tenant_id ON refunds (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_payment_id ON refunds (payment_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_refunded_at ON refunds (refunded_at);
+
+-- 账户余额抵扣费用的记录，抵扣金额计入 fees.paid_amount
+CREATE TABLE IF NOT EXISTS credit_applications (
+    id         bigserial PRIMARY KEY,
+    tenant_id  bigint        NOT NULL,
+    fee_id     bigint        NOT NULL,
+    amount     decimal(10,2) NOT NULL,
+    applied_at timestamptz   NOT NULL,
+    applied_by varchar(50),
+    created_at timestamptz,
+    CONSTRAINT fk_credit_applications_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_credit_applications_fee FOREIGN KEY (fee_id) REFERENCES fees (id),
+    CONSTRAINT chk_credit_applications_amount CHECK (amount > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_credit_applications_tenant_id ON credit_applications (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_credit_applications_fee_id ON credit_applications (fee_id);
@@ -0,0 +1,11 @@
+-- 合同提前终止的时间，已终止的生效合同按状态变更记录回填
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS terminated_at timestamptz;
+
+UPDATE contracts c
+SET terminated_at = t.created_at
+FROM contract_transitions t
+WHERE t.contract_id = c.id
+  AND t.to_status = 'terminated'
+  AND t.from_status IN ('active', 'expiring')
+  AND c.status = 'terminated'
+  AND c.terminated_at IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS chk_payments_credited;
+ALTER TABLE payments DROP COLUMN IF EXISTS credited;
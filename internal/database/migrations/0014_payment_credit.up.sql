@@ -0,0 +1,3 @@
+-- 收款中未分配到费用的部分转入租户账户余额
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS credited decimal(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD CONSTRAINT chk_payments_credited CHECK (credited >= 0 AND credited <= amount);
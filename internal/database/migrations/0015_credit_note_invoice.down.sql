@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_credit_notes_invoice_id;
+ALTER TABLE credit_notes DROP CONSTRAINT IF EXISTS fk_credit_notes_invoice;
+ALTER TABLE credit_notes DROP COLUMN IF EXISTS voided_by;
+ALTER TABLE credit_notes DROP COLUMN IF EXISTS voided_at;
+ALTER TABLE credit_notes DROP COLUMN IF EXISTS invoice_id;
@@ -0,0 +1,6 @@
+-- 贷项与红字发票互相关联，红字发票作废时其贷项一并作废
+ALTER TABLE credit_notes ADD COLUMN IF NOT EXISTS invoice_id bigint;
+ALTER TABLE credit_notes ADD COLUMN IF NOT EXISTS voided_at timestamptz;
+ALTER TABLE credit_notes ADD COLUMN IF NOT EXISTS voided_by varchar(50);
+ALTER TABLE credit_notes ADD CONSTRAINT fk_credit_notes_invoice FOREIGN KEY (invoice_id) REFERENCES invoices (id);
+CREATE INDEX IF NOT EXISTS idx_credit_notes_invoice_id ON credit_notes (invoice_id);
@@ -10,12 +10,39 @@ import (
 	"gorm.io/gorm/logger"
 
 	"yuxialuozi_graduation_design_backend/internal/config"
-	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
 var ProviderSet = wire.NewSet(NewDatabase)
 
+// ConnectionSet 仅建立连接，不执行自动迁移，供 CLI 使用
+var ConnectionSet = wire.NewSet(Open)
+
+// NewDatabase 建立连接，并在开启 database.auto_migrate 时执行未完成的迁移
 func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Database.AutoMigrate {
+		if _, err := migrator.Up(); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	} else if pending, err := migrator.Pending(); err != nil {
+		return nil, fmt.Errorf("failed to check migrations: %w", err)
+	} else if pending > 0 {
+		zap.L().Warn("database has pending migrations, run `server migrate up`", zap.Int("pending", pending))
+	}
+
+	return db, nil
+}
+
+func Open(cfg *config.Config) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Database.Host,
@@ -40,22 +67,6 @@ func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
 
-	// 自动迁移
-	if err := autoMigrate(db); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
-	}
-
 	zap.L().Info("database connected successfully")
 	return db, nil
 }
-
-func autoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&model.User{},
-		&model.Tenant{},
-		&model.Contract{},
-		&model.Room{},
-		&model.Fee{},
-		&model.Maintenance{},
-	)
-}
@@ -8,6 +8,89 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type LoginHistoryListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=10"`
+	UserID   uint   `form:"userId"`
+	Username string `form:"username"`
+	IP       string `form:"ip"`
+	Success  *bool  `form:"success"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFADisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=6"`
+}
+
+// User
+type CreateUserRequest struct {
+	Username    string   `json:"username" binding:"required"`
+	Password    string   `json:"password" binding:"required,min=6"`
+	Nickname    string   `json:"nickname"`
+	Avatar      string   `json:"avatar"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	TenantID    *uint    `json:"tenantId"`
+}
+
+type UpdateUserRequest struct {
+	Nickname    string   `json:"nickname"`
+	Avatar      string   `json:"avatar"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	TenantID    *uint    `json:"tenantId"`
+}
+
+type UserListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=10"`
+	Keyword  string `form:"keyword"`
+	Role     string `form:"role"`
+	Status   string `form:"status"`
+}
+
+type UpdateUserStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active disabled"`
+}
+
+// API Key
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Permissions []string   `json:"permissions" binding:"required"`
+	AllowedIPs  []string   `json:"allowedIps"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+type APIKeyListRequest struct {
+	Page     int  `form:"page,default=1"`
+	PageSize int  `form:"pageSize,default=10"`
+	UserID   uint `form:"userId"`
+}
+
+// Trash
+type TrashListRequest struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"pageSize,default=10"`
+}
+
 // Tenant
 type CreateTenantRequest struct {
 	Name          string `json:"name" binding:"required"`
@@ -33,13 +116,37 @@ type TenantListRequest struct {
 }
 
 // Contract
+type ContractRoomRequest struct {
+	RoomID uint `json:"roomId" binding:"required"`
+	// MonthlyRent 该房间的月租金，为 0 时取房间当前月租金
+	MonthlyRent float64 `json:"monthlyRent" binding:"min=0"`
+}
+
+// ContractEscalationRequest 租金递增条款：percent 按百分比、amount 按固定金额、cpi 按上一年 CPI 涨幅加 value 个百分点
+type ContractEscalationRequest struct {
+	Type   string  `json:"type" binding:"omitempty,oneof=none percent amount cpi"`
+	Value  float64 `json:"value" binding:"min=0"`
+	Timing string  `json:"timing" binding:"omitempty,oneof=renewal anniversary"`
+}
+
+// ContractBillingRequest 计费条款：cycle 为 monthly 或 quarterly，dueDay 为缴费日（1-28），
+// mode 为 advance（预付）或 arrears（后付）
+type ContractBillingRequest struct {
+	Cycle  string `json:"cycle" binding:"omitempty,oneof=monthly quarterly"`
+	DueDay int    `json:"dueDay" binding:"min=0,max=28"`
+	Mode   string `json:"mode" binding:"omitempty,oneof=advance arrears"`
+}
+
 type CreateContractRequest struct {
-	TenantID   uint      `json:"tenantId" binding:"required"`
-	ContractNo string    `json:"contractNo"`
-	StartDate  time.Time `json:"startDate" binding:"required"`
-	EndDate    time.Time `json:"endDate" binding:"required"`
-	Amount     float64   `json:"amount"`
-	Status     string    `json:"status"`
+	TenantID   uint                       `json:"tenantId" binding:"required"`
+	ContractNo string                     `json:"contractNo"`
+	StartDate  time.Time                  `json:"startDate" binding:"required"`
+	EndDate    time.Time                  `json:"endDate" binding:"required"`
+	Amount     float64                    `json:"amount"`
+	Status     string                     `json:"status"`
+	Rooms      []ContractRoomRequest      `json:"rooms" binding:"dive"`
+	Escalation *ContractEscalationRequest `json:"escalation"`
+	Billing    *ContractBillingRequest    `json:"billing"`
 }
 
 type UpdateContractRequest struct {
@@ -49,6 +156,45 @@ type UpdateContractRequest struct {
 	EndDate    time.Time `json:"endDate"`
 	Amount     float64   `json:"amount"`
 	Status     string    `json:"status"`
+	// Rooms 不传时保持原有租赁房间，传空数组表示清空
+	Rooms []ContractRoomRequest `json:"rooms" binding:"dive"`
+	// Escalation 不传时保持原有递增条款
+	Escalation *ContractEscalationRequest `json:"escalation"`
+	// Billing 不传时保持原有计费条款
+	Billing *ContractBillingRequest `json:"billing"`
+}
+
+// RenewContractRequest 续签参数，均可省略：开始日期默认为原合同到期日，租期默认与原合同相同，
+// 总额默认按递增后的月租金计算，递增条款默认沿用原合同
+type RenewContractRequest struct {
+	StartDate  *time.Time                 `json:"startDate"`
+	EndDate    *time.Time                 `json:"endDate"`
+	Amount     *float64                   `json:"amount" binding:"omitempty,min=0"`
+	Escalation *ContractEscalationRequest `json:"escalation"`
+	// Status 续签合同的初始状态，draft 或 pending_approval，默认 draft
+	Status string `json:"status" binding:"omitempty,oneof=draft pending_approval"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type SaveCPIIndexRequest struct {
+	// Rate 当年 CPI 涨幅（百分比），如 2.5 表示上涨 2.5%，可为负数
+	Rate *float64 `json:"rate" binding:"required"`
+}
+
+// Billing
+type BillingRunRequest struct {
+	// Period 账期，格式 YYYY-MM
+	Period string `form:"period" binding:"required"`
+	// DryRun 为 true 时只预览将要生成的租金
+	DryRun bool `form:"dryRun"`
+}
+
+type ContractTransitionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type TerminateContractRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
 }
 
 type ContractListRequest struct {
@@ -60,6 +206,11 @@ type ContractListRequest struct {
 	StartDateTo   string `form:"startDateTo"`
 }
 
+type ExpiringContractRequest struct {
+	// Within 查询范围，格式为天数加 d 后缀（如 60d），也可只写数字
+	Within string `form:"within,default=30d"`
+}
+
 // Room
 type CreateRoomRequest struct {
 	RoomNo      string  `json:"roomNo" binding:"required"`
@@ -122,8 +273,129 @@ type FeeListRequest struct {
 	Period   string `form:"period"`
 }
 
+type OverdueFeeListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=10"`
+	TenantID uint   `form:"tenantId"`
+	FeeType  string `form:"feeType"`
+}
+
+type WaiveFeeRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// PayFeeRequest 为单笔费用登记收款，Amount 省略时收取全部未收金额，Method 省略时按现金登记
 type PayFeeRequest struct {
-	PaidDate *time.Time `json:"paidDate"`
+	PaidDate  *time.Time `json:"paidDate"`
+	Amount    float64    `json:"amount" binding:"min=0"`
+	Method    string     `json:"method" binding:"omitempty,oneof=cash bank_transfer wechat alipay other"`
+	Reference string     `json:"reference" binding:"max=100"`
+	Remark    string     `json:"remark" binding:"max=500"`
+}
+
+// Payment
+type PaymentAllocationRequest struct {
+	FeeID  uint    `json:"feeId" binding:"required"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CreatePaymentRequest 登记收款，Allocations 省略时按到期日从早到晚自动分配到该租户未结清的费用
+type CreatePaymentRequest struct {
+	TenantID    uint                       `json:"tenantId" binding:"required"`
+	Amount      float64                    `json:"amount" binding:"required,gt=0"`
+	Method      string                     `json:"method" binding:"required,oneof=cash bank_transfer wechat alipay other"`
+	Reference   string                     `json:"reference" binding:"max=100"`
+	ReceivedAt  *time.Time                 `json:"receivedAt"`
+	Remark      string                     `json:"remark" binding:"max=500"`
+	Allocations []PaymentAllocationRequest `json:"allocations" binding:"dive"`
+}
+
+type PaymentListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=10"`
+	TenantID uint   `form:"tenantId"`
+	Method   string `form:"method"`
+	Status   string `form:"status"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+}
+
+type ReversePaymentRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// Receipt
+type ReceiptListRequest struct {
+	Page      int    `form:"page,default=1"`
+	PageSize  int    `form:"pageSize,default=10"`
+	TenantID  uint   `form:"tenantId"`
+	Status    string `form:"status"`
+	ReceiptNo string `form:"receiptNo"`
+}
+
+// Account
+// StatementRequest 对账单查询区间，日期格式为 YYYY-MM-DD，To 含当天，均可省略
+type StatementRequest struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+type AccountListRequest struct {
+	Page     int  `form:"page,default=1"`
+	PageSize int  `form:"pageSize,default=10"`
+	TenantID uint `form:"tenantId"`
+}
+
+// CreateAccountCreditRequest 开具贷项，FeeID 为所针对的费用，可省略
+type CreateAccountCreditRequest struct {
+	TenantID uint    `json:"tenantId" binding:"required"`
+	FeeID    *uint   `json:"feeId"`
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+	Reason   string  `json:"reason" binding:"required,max=500"`
+}
+
+// CreateRefundRequest 从账户余额退款，PaymentID 为原路退回的收款，可省略
+type CreateRefundRequest struct {
+	TenantID   uint       `json:"tenantId" binding:"required"`
+	PaymentID  *uint      `json:"paymentId"`
+	Amount     float64    `json:"amount" binding:"required,gt=0"`
+	Method     string     `json:"method" binding:"required,oneof=cash bank_transfer wechat alipay other"`
+	Reference  string     `json:"reference" binding:"max=100"`
+	Reason     string     `json:"reason" binding:"required,max=500"`
+	RefundedAt *time.Time `json:"refundedAt"`
+}
+
+// Invoice
+// CreateInvoiceRequest 创建草稿发票，FeeIDs 省略时汇总该租户在 Period 账期内尚未开票的全部费用
+type CreateInvoiceRequest struct {
+	TenantID uint   `json:"tenantId" binding:"required"`
+	Period   string `json:"period" binding:"max=20"`
+	FeeIDs   []uint `json:"feeIds"`
+	Remark   string `json:"remark" binding:"max=500"`
+}
+
+type InvoiceListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=10"`
+	TenantID uint   `form:"tenantId"`
+	Kind     string `form:"kind"`
+	Status   string `form:"status"`
+	Period   string `form:"period"`
+}
+
+type VoidInvoiceRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type CreditNoteLineRequest struct {
+	LineID uint    `json:"lineId" binding:"required"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CreateCreditNoteRequest 开具红字发票，Lines 省略时冲减原发票的全部剩余金额
+type CreateCreditNoteRequest struct {
+	Reason string                  `json:"reason" binding:"required,max=500"`
+	Lines  []CreditNoteLineRequest `json:"lines" binding:"dive"`
 }
 
 // Maintenance
@@ -148,6 +420,7 @@ type UpdateMaintenanceRequest struct {
 type MaintenanceListRequest struct {
 	Page     int    `form:"page,default=1"`
 	PageSize int    `form:"pageSize,default=10"`
+	TenantID uint   `form:"tenantId"`
 	Keyword  string `form:"keyword"`
 	Type     string `form:"type"`
 	Status   string `form:"status"`
@@ -162,6 +435,14 @@ type CompleteMaintenanceRequest struct {
 	CompletedAt *time.Time `json:"completedAt"`
 }
 
+// Portal
+type PortalMaintenanceRequest struct {
+	RoomNo      string `json:"roomNo"`
+	Type        string `json:"type" binding:"required"`
+	Description string `json:"description" binding:"required"`
+	Priority    string `json:"priority"`
+}
+
 // Report
 type ReportQueryRequest struct {
 	Start   string `form:"start"`
@@ -169,3 +450,24 @@ type ReportQueryRequest struct {
 	GroupBy string `form:"groupBy"`
 	Limit   int    `form:"limit,default=10"`
 }
+
+// Audit Log
+type AuditLogListRequest struct {
+	Page       int    `form:"page,default=1"`
+	PageSize   int    `form:"pageSize,default=10"`
+	EntityType string `form:"entityType"`
+	EntityID   uint   `form:"entityId"`
+	ActorID    uint   `form:"actorId"`
+	Action     string `form:"action"`
+	RequestID  string `form:"requestId"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+}
+
+// Notification
+type NotificationListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=10"`
+	Type     string `form:"type"`
+	Unread   bool   `form:"unread"`
+}
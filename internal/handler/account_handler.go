@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+// AccountHandler 租户账户：贷项、退款与对账单
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// Statement godoc
+// @Summary 获取租户对账单
+// @Description 按日期列出租户的费用、收款、冲正、贷项与退款及逐笔余额，余额为正表示租户尚欠的金额。
+// @Description 省略 from 时从第一笔记录开始，省略 to 时截至当前
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "租户 ID"
+// @Param from query string false "开始日期 (YYYY-MM-DD)"
+// @Param to query string false "截止日期 (YYYY-MM-DD，含当天)"
+// @Success 200 {object} response.Response{data=service.Statement} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID 或日期"
+// @Failure 404 {object} response.Response "租户不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /tenants/{id}/statement [get]
+func (h *AccountHandler) Statement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+	respondStatement(c, h.accountService, uint(id))
+}
+
+// ListCreditNotes godoc
+// @Summary 获取贷项列表
+// @Description 分页获取给予租户的贷项，按开具顺序倒序
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /credit-notes [get]
+func (h *AccountHandler) ListCreditNotes(c *gin.Context) {
+	var req dto.AccountListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	notes, total, err := h.accountService.ListCreditNotes(req.Page, req.PageSize, req.TenantID)
+	if err != nil {
+		response.InternalError(c, "获取贷项列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(notes, total, req.Page, req.PageSize))
+}
+
+// GetCreditNote godoc
+// @Summary 获取贷项详情
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "贷项 ID"
+// @Success 200 {object} response.Response{data=model.CreditNote} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "贷项不存在"
+// @Router /credit-notes/{id} [get]
+func (h *AccountHandler) GetCreditNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	note, err := h.accountService.GetCreditNote(uint(id))
+	if err != nil {
+		response.NotFound(c, "贷项不存在")
+		return
+	}
+
+	response.Success(c, note)
+}
+
+// CreateCreditNote godoc
+// @Summary 开具贷项
+// @Description 给予租户一笔贷项并计入账户余额，随即抵扣未结清的费用：先抵扣 feeId 指定的费用，再按到期日从早到晚抵扣其他费用，
+// @Description 抵扣不完的部分留在账户中，自动抵扣之后新生成的费用或办理退款。同一费用的贷项合计不超过费用金额。
+// @Description feeId 指定的费用已开具发票时同时开具冲减该费用的红字发票，invoiceId 指向红字发票
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAccountCreditRequest true "开具贷项请求"
+// @Success 200 {object} response.Response{data=model.CreditNote} "开具成功"
+// @Failure 400 {object} response.Response "请求参数错误或租户、费用无效"
+// @Failure 409 {object} response.Response "贷项金额超过费用金额或发票中该费用可冲减的金额"
+// @Failure 500 {object} response.Response "开具失败"
+// @Router /credit-notes [post]
+func (h *AccountHandler) CreateCreditNote(c *gin.Context) {
+	var req dto.CreateAccountCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	note := &model.CreditNote{
+		TenantID: req.TenantID,
+		FeeID:    req.FeeID,
+		Amount:   req.Amount,
+		Reason:   req.Reason,
+	}
+	if err := h.accountService.CreateCreditNote(note, currentActor(c)); err != nil {
+		if !respondAccountError(c, err) {
+			response.InternalError(c, "开具贷项失败")
+		}
+		return
+	}
+
+	if created, err := h.accountService.GetCreditNote(note.ID); err == nil {
+		note = created
+	}
+
+	response.Success(c, note)
+}
+
+// ListRefunds godoc
+// @Summary 获取退款列表
+// @Description 分页获取退还给租户的款项，按退款时间倒序
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /refunds [get]
+func (h *AccountHandler) ListRefunds(c *gin.Context) {
+	var req dto.AccountListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	refunds, total, err := h.accountService.ListRefunds(req.Page, req.PageSize, req.TenantID)
+	if err != nil {
+		response.InternalError(c, "获取退款列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(refunds, total, req.Page, req.PageSize))
+}
+
+// GetRefund godoc
+// @Summary 获取退款详情
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "退款 ID"
+// @Success 200 {object} response.Response{data=model.Refund} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "退款记录不存在"
+// @Router /refunds/{id} [get]
+func (h *AccountHandler) GetRefund(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	refund, err := h.accountService.GetRefund(uint(id))
+	if err != nil {
+		response.NotFound(c, "退款记录不存在")
+		return
+	}
+
+	response.Success(c, refund)
+}
+
+// CreateRefund godoc
+// @Summary 登记退款
+// @Description 从租户账户余额中退款，金额不超过账户余额。指定 paymentId 时为原路退回该收款，
+// @Description 同一收款的退款合计不超过收款金额，已冲正的收款不能退款，有退款的收款不能再冲正
+// @Tags 租户账户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateRefundRequest true "登记退款请求"
+// @Success 200 {object} response.Response{data=model.Refund} "登记成功"
+// @Failure 400 {object} response.Response "请求参数错误或租户、收款无效"
+// @Failure 409 {object} response.Response "退款金额超过账户余额或收款金额，或收款已冲正"
+// @Failure 500 {object} response.Response "登记失败"
+// @Router /refunds [post]
+func (h *AccountHandler) CreateRefund(c *gin.Context) {
+	var req dto.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	refund := &model.Refund{
+		TenantID:  req.TenantID,
+		PaymentID: req.PaymentID,
+		Amount:    req.Amount,
+		Method:    req.Method,
+		Reference: req.Reference,
+		Reason:    req.Reason,
+	}
+	if req.RefundedAt != nil {
+		refund.RefundedAt = *req.RefundedAt
+	}
+	if err := h.accountService.CreateRefund(refund, currentActor(c)); err != nil {
+		if !respondAccountError(c, err) {
+			response.InternalError(c, "登记退款失败")
+		}
+		return
+	}
+
+	if created, err := h.accountService.GetRefund(refund.ID); err == nil {
+		refund = created
+	}
+
+	response.Success(c, refund)
+}
+
+// respondStatement 解析查询区间并返回租户对账单，供管理端与租户门户共用
+func respondStatement(c *gin.Context, accountService *service.AccountService, tenantID uint) {
+	var req dto.StatementRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	var from, to *time.Time
+	if req.From != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err != nil {
+			response.BadRequest(c, "开始日期格式错误，应为 YYYY-MM-DD")
+			return
+		}
+		from = &t
+	}
+	if req.To != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err != nil {
+			response.BadRequest(c, "截止日期格式错误，应为 YYYY-MM-DD")
+			return
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		response.BadRequest(c, "开始日期不能晚于截止日期")
+		return
+	}
+
+	statement, err := accountService.Statement(tenantID, from, to)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.NotFound(c, "租户不存在")
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "获取对账单失败")
+		return
+	}
+
+	response.Success(c, statement)
+}
+
+// respondAccountError 将贷项与退款的业务错误转换为响应，未识别的错误返回 false
+func respondAccountError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidCreditNote), errors.Is(err, service.ErrInvalidRefund):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrCreditExceedsFee), errors.Is(err, service.ErrRefundExceedsCredit),
+		errors.Is(err, service.ErrRefundExceedsPayment), errors.Is(err, service.ErrPaymentReversed),
+		errors.Is(err, service.ErrCreditExceedsAmount):
+		response.Conflict(c, err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// List godoc
+// @Summary 获取 API 密钥列表
+// @Description 获取当前用户的 API 密钥；拥有 user:manage 权限时可查看所有用户的密钥
+// @Tags API 密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param userId query int false "用户 ID（需 user:manage 权限）"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	var req dto.APIKeyListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	if !utils.HasPermission(middleware.GetPermissions(c), model.PermUserManage) {
+		req.UserID = middleware.GetUserID(c)
+	}
+
+	keys, total, err := h.apiKeyService.List(req.Page, req.PageSize, req.UserID)
+	if err != nil {
+		response.InternalError(c, "获取 API 密钥列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(keys, total, req.Page, req.PageSize))
+}
+
+// Create godoc
+// @Summary 创建 API 密钥
+// @Description 为当前用户创建 API 密钥，权限范围不能超出用户自身权限。明文密钥只在本次响应中返回，请求时通过 X-API-Key 请求头携带
+// @Tags API 密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateAPIKeyRequest true "创建 API 密钥请求"
+// @Success 200 {object} response.Response{data=service.CreateAPIKeyResponse} "创建成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	result, err := h.apiKeyService.Create(middleware.GetUserID(c), req.Name, req.Permissions, req.AllowedIPs, req.ExpiresAt)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Revoke godoc
+// @Summary 吊销 API 密钥
+// @Description 吊销指定 API 密钥，立即失效；只能吊销自己的密钥，拥有 user:manage 权限时可吊销任意密钥
+// @Tags API 密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "密钥 ID"
+// @Success 200 {object} response.Response "吊销成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "API 密钥不存在"
+// @Failure 500 {object} response.Response "吊销失败"
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	key, err := h.apiKeyService.GetByID(uint(id))
+	if err != nil || (key.UserID != middleware.GetUserID(c) && !utils.HasPermission(middleware.GetPermissions(c), model.PermUserManage)) {
+		response.NotFound(c, "API 密钥不存在")
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(key.ID); err != nil {
+		response.InternalError(c, "吊销 API 密钥失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// List godoc
+// @Summary 获取审计日志
+// @Description 分页查询数据变更审计日志，支持按实体、操作人、操作类型、请求 ID 与时间范围筛选
+// @Tags 审计日志
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param entityType query string false "实体类型" Enums(tenant, contract, room, fee, payment, maintenance)
+// @Param entityId query int false "实体 ID"
+// @Param actorId query int false "操作人 ID"
+// @Param action query string false "操作类型" Enums(create, update, delete, assign, pay, complete, restore, purge, transition, reverse, waive, issue, void, credit, overdue, accrue)
+// @Param requestId query string false "请求 ID"
+// @Param from query string false "开始日期 (YYYY-MM-DD)"
+// @Param to query string false "结束日期 (YYYY-MM-DD)"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /audit-logs [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	var req dto.AuditLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	var from, to *time.Time
+	if req.From != "" {
+		t, err := time.Parse("2006-01-02", req.From)
+		if err == nil {
+			from = &t
+		}
+	}
+	if req.To != "" {
+		t, err := time.Parse("2006-01-02", req.To)
+		if err == nil {
+			t = t.AddDate(0, 0, 1)
+			to = &t
+		}
+	}
+
+	logs, total, err := h.auditService.List(req.Page, req.PageSize, req.EntityType, req.EntityID, req.ActorID, req.Action, req.RequestID, from, to)
+	if err != nil {
+		response.InternalError(c, "获取审计日志失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(logs, total, req.Page, req.PageSize))
+}
+
+// History godoc
+// @Summary 获取实体变更历史
+// @Description 按时间顺序返回单个实体的全部变更记录
+// @Tags 审计日志
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entityType path string true "实体类型" Enums(tenant, contract, room, fee, payment, maintenance)
+// @Param entityId path int true "实体 ID"
+// @Success 200 {object} response.Response{data=[]model.AuditLog} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /audit-logs/{entityType}/{entityId} [get]
+func (h *AuditHandler) History(c *gin.Context) {
+	entityID, err := strconv.ParseUint(c.Param("entityId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	logs, err := h.auditService.History(c.Param("entityType"), uint(entityID))
+	if err != nil {
+		response.InternalError(c, "获取变更历史失败")
+		return
+	}
+
+	response.Success(c, logs)
+}
+
+// currentActor 当前请求的操作人、IP、请求 ID 与 API 密钥，传给服务层在事务中写入审计日志
+func currentActor(c *gin.Context) service.Actor {
+	actor := service.Actor{
+		ID:        middleware.GetUserID(c),
+		Name:      middleware.GetUsername(c),
+		IP:        c.ClientIP(),
+		RequestID: middleware.GetRequestID(c),
+	}
+	if apiKeyID := middleware.GetAPIKeyID(c); apiKeyID > 0 {
+		actor.APIKeyID = &apiKeyID
+	}
+	return actor
+}
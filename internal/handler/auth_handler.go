@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	"yuxialuozi_graduation_design_backend/internal/dto"
 	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/model"
 	"yuxialuozi_graduation_design_backend/internal/service"
 	"yuxialuozi_graduation_design_backend/pkg/response"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
 )
 
 type AuthHandler struct {
@@ -27,6 +31,7 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // @Success 200 {object} response.Response{data=service.LoginResponse} "登录成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 401 {object} response.Response "用户名或密码错误"
+// @Failure 429 {object} response.Response "登录失败次数过多"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
@@ -36,9 +41,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	result, err := h.authService.Login(&service.LoginRequest{
-		Username: req.Username,
-		Password: req.Password,
+		Username:  req.Username,
+		Password:  req.Password,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
 	})
+	if errors.Is(err, service.ErrLoginLocked) {
+		response.TooManyRequests(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.Error(c, 401, err.Error())
 		return
@@ -47,6 +58,68 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	response.Success(c, result)
 }
 
+// VerifyMFA godoc
+// @Summary 两步验证登录
+// @Description 登录返回 mfaRequired 时，提交 mfaToken 与身份验证器验证码（或恢复码）完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAVerifyRequest true "两步验证请求"
+// @Success 200 {object} response.Response{data=service.LoginResponse} "登录成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "验证码错误或已过期"
+// @Failure 429 {object} response.Response "登录失败次数过多"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dto.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	result, err := h.authService.VerifyMFA(req.MFAToken, req.Code, &service.LoginRequest{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if errors.Is(err, service.ErrLoginLocked) {
+		response.TooManyRequests(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Refresh godoc
+// @Summary 刷新令牌
+// @Description 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换，旧刷新令牌立即失效
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "刷新令牌请求"
+// @Success 200 {object} response.Response{data=service.LoginResponse} "刷新成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "刷新令牌无效"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	result, err := h.authService.Refresh(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // GetCurrentUser godoc
 // @Summary 获取当前用户信息
 // @Description 获取当前登录用户的详细信息
@@ -76,13 +149,93 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 
 // Logout godoc
 // @Summary 退出登录
-// @Description 退出当前用户登录状态
+// @Description 退出当前会话，访问令牌与对应的刷新令牌立即失效；API 密钥认证的请求没有会话，返回 400
 // @Tags 认证
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} response.Response "退出成功"
+// @Failure 400 {object} response.Response "使用 API 密钥认证"
+// @Failure 500 {object} response.Response "退出失败"
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
+	if middleware.GetAPIKeyID(c) > 0 {
+		response.BadRequest(c, service.ErrNoSession.Error())
+		return
+	}
+
+	err := h.authService.Logout(middleware.GetUserID(c), middleware.GetTokenID(c), middleware.GetTokenExpiresAt(c))
+	if errors.Is(err, service.ErrNoSession) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "退出失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// LogoutAll godoc
+// @Summary 退出全部会话
+// @Description 退出当前用户在所有设备上的登录状态
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "退出成功"
+// @Failure 500 {object} response.Response "退出失败"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	if err := h.authService.LogoutAll(middleware.GetUserID(c)); err != nil {
+		response.InternalError(c, "退出失败")
+		return
+	}
+
 	response.Success(c, nil)
 }
+
+// LoginHistory godoc
+// @Summary 登录历史
+// @Description 分页查询登录历史。普通用户只能查看自己的记录，拥有 user:manage 权限的用户可按条件查询全部记录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param userId query int false "用户 ID"
+// @Param username query string false "用户名"
+// @Param ip query string false "IP 地址"
+// @Param success query bool false "是否成功"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /auth/login-history [get]
+func (h *AuthHandler) LoginHistory(c *gin.Context) {
+	var req dto.LoginHistoryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	if !utils.HasPermission(middleware.GetPermissions(c), model.PermUserManage) {
+		req.UserID = middleware.GetUserID(c)
+		req.Username = ""
+	}
+
+	histories, total, err := h.authService.ListLoginHistory(req.Page, req.PageSize, req.UserID, req.Username, req.IP, req.Success)
+	if err != nil {
+		response.InternalError(c, "获取登录历史失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(histories, total, req.Page, req.PageSize))
+}
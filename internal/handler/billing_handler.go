@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type BillingHandler struct {
+	billingService *service.BillingService
+}
+
+func NewBillingHandler(billingService *service.BillingService) *BillingHandler {
+	return &BillingHandler{billingService: billingService}
+}
+
+// Run godoc
+// @Summary 按合同生成租金
+// @Description 为账期内应收租金的合同按房间生成 rent 费用，不足整月的按天折算；已生成的不会重复生成。
+// @Description dryRun=true 时只预览，items 中 result 为 pending（将要生成）或 exists（已生成）
+// @Tags 账单
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param period query string true "账期 (YYYY-MM)"
+// @Param dryRun query bool false "只预览不生成"
+// @Success 200 {object} response.Response{data=service.BillingRun} "生成结果"
+// @Failure 400 {object} response.Response "账期格式错误"
+// @Failure 500 {object} response.Response "生成失败"
+// @Router /billing/runs [post]
+func (h *BillingHandler) Run(c *gin.Context) {
+	var req dto.BillingRunRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请指定账期，格式为 YYYY-MM")
+		return
+	}
+
+	// 生成的租金及其余额抵扣由账单服务在事务中记录审计
+	run, err := h.billingService.Run(req.Period, req.DryRun, currentActor(c))
+	if errors.Is(err, service.ErrInvalidBillingPeriod) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "生成租金失败")
+		return
+	}
+
+	response.Success(c, run)
+}
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,7 +35,7 @@ func NewContractHandler(contractService *service.ContractService) *ContractHandl
 // @Param page query int false "页码" default(1)
 // @Param pageSize query int false "每页数量" default(10)
 // @Param keyword query string false "搜索关键字"
-// @Param status query string false "状态筛选" Enums(draft, active, expired, terminated)
+// @Param status query string false "状态筛选" Enums(draft, pending_approval, active, expiring, expired, terminated, renewed)
 // @Param startDateFrom query string false "开始日期起始 (YYYY-MM-DD)"
 // @Param startDateTo query string false "开始日期结束 (YYYY-MM-DD)"
 // @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
@@ -73,9 +78,55 @@ func (h *ContractHandler) List(c *gin.Context) {
 	response.Success(c, dto.NewPageResult(contracts, total, req.Page, req.PageSize))
 }
 
+// Expiring godoc
+// @Summary 即将到期的合同
+// @Description 返回指定天数内到期、仍在租的合同（active、expiring），按到期日升序，daysLeft 为剩余天数
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param within query string false "查询范围，如 60d" default(30d)
+// @Success 200 {object} response.Response{data=[]service.ExpiringContract} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /contracts/expiring [get]
+func (h *ContractHandler) Expiring(c *gin.Context) {
+	var req dto.ExpiringContractRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	within, err := parseDays(req.Within)
+	if err != nil {
+		response.BadRequest(c, "within 格式错误，应为天数，如 60d")
+		return
+	}
+
+	contracts, err := h.contractService.ListExpiring(within, time.Now())
+	if err != nil {
+		response.InternalError(c, "获取即将到期合同失败")
+		return
+	}
+
+	response.Success(c, contracts)
+}
+
+// parseDays 解析 "60d" 或 "60" 形式的天数，范围 0 到 3650
+func parseDays(s string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+	if err != nil {
+		return 0, err
+	}
+	if days < 0 || days > 3650 {
+		return 0, fmt.Errorf("days out of range: %d", days)
+	}
+	return days, nil
+}
+
 // GetByID godoc
 // @Summary 获取合同详情
-// @Description 根据 ID 获取合同详细信息
+// @Description 根据 ID 获取合同详细信息；存在续签关系时 renewalChain 按时间顺序列出整条续签链（含本合同）
 // @Tags 合同管理
 // @Accept json
 // @Produce json
@@ -84,6 +135,7 @@ func (h *ContractHandler) List(c *gin.Context) {
 // @Success 200 {object} response.Response{data=model.Contract} "获取成功"
 // @Failure 400 {object} response.Response "无效的 ID"
 // @Failure 404 {object} response.Response "合同不存在"
+// @Header 200 {string} ETag "记录版本号，更新时通过 If-Match 回传"
 // @Router /contracts/{id} [get]
 func (h *ContractHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -98,19 +150,21 @@ func (h *ContractHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, contract.Version)
 	response.Success(c, contract)
 }
 
 // Create godoc
 // @Summary 创建合同
-// @Description 创建新的合同
+// @Description 创建新的合同，可通过 escalation 约定续签或每个周年日的租金递增方式，通过 billing 约定计费周期、缴费日与预付/后付
 // @Tags 合同管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.CreateContractRequest true "创建合同请求"
 // @Success 200 {object} response.Response{data=model.Contract} "创建成功"
-// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 400 {object} response.Response "请求参数错误或房间不存在"
+// @Failure 409 {object} response.Response{data=service.ContractTransitionError} "初始状态不是 draft 或 pending_approval（code 40901）"
 // @Failure 500 {object} response.Response "创建失败"
 // @Router /contracts [post]
 func (h *ContractHandler) Create(c *gin.Context) {
@@ -128,16 +182,23 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		Amount:     req.Amount,
 		Status:     req.Status,
 	}
-
-	if contract.Status == "" {
-		contract.Status = "draft"
+	if req.Escalation != nil {
+		contract.Escalation = toContractEscalation(req.Escalation)
+	}
+	if req.Billing != nil {
+		contract.Billing = toContractBilling(req.Billing)
 	}
 
-	if err := h.contractService.Create(contract); err != nil {
+	err := h.contractService.Create(contract, toContractRooms(req.Rooms), currentActor(c))
+	if respondContractError(c, err) {
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "创建合同失败")
 		return
 	}
 
+	setETag(c, contract.Version)
 	response.Success(c, contract)
 }
 
@@ -149,11 +210,16 @@ func (h *ContractHandler) Create(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "合同 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
 // @Param request body dto.UpdateContractRequest true "更新合同请求"
 // @Success 200 {object} response.Response{data=model.Contract} "更新成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 404 {object} response.Response "合同不存在"
+// @Failure 409 {object} response.Response{data=model.Contract} "保存时数据已被他人修改（返回当前数据）；修改状态返回 code 40901；房间已被占用或租期重叠"
+// @Failure 412 {object} response.Response{data=model.Contract} "If-Match 与当前版本不一致，返回当前数据"
+// @Failure 428 {object} response.Response "未携带 If-Match（开启 require_if_match 时）"
 // @Failure 500 {object} response.Response "更新失败"
+// @Header 200 {string} ETag "更新后的版本号"
 // @Router /contracts/{id} [put]
 func (h *ContractHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -168,6 +234,10 @@ func (h *ContractHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, contract.Version, contract) {
+		return
+	}
+
 	var req dto.UpdateContractRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "请求参数错误")
@@ -192,12 +262,28 @@ func (h *ContractHandler) Update(c *gin.Context) {
 	if req.Status != "" {
 		contract.Status = req.Status
 	}
+	if req.Escalation != nil {
+		contract.Escalation = toContractEscalation(req.Escalation)
+	}
+	if req.Billing != nil {
+		contract.Billing = toContractBilling(req.Billing)
+	}
 
-	if err := h.contractService.Update(contract); err != nil {
+	err = h.contractService.Update(contract, toContractRooms(req.Rooms), currentActor(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.contractService.GetByID(contract.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if respondContractError(c, err) {
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "更新合同失败")
 		return
 	}
 
+	setETag(c, contract.Version)
 	response.Success(c, contract)
 }
 
@@ -211,6 +297,8 @@ func (h *ContractHandler) Update(c *gin.Context) {
 // @Param id path int true "合同 ID"
 // @Success 200 {object} response.Response "删除成功"
 // @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 409 {object} response.Response "合同仍在生效中"
 // @Failure 500 {object} response.Response "删除失败"
 // @Router /contracts/{id} [delete]
 func (h *ContractHandler) Delete(c *gin.Context) {
@@ -220,10 +308,519 @@ func (h *ContractHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.contractService.Delete(uint(id)); err != nil {
+	contract, err := h.contractService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "合同不存在")
+		return
+	}
+
+	err = h.contractService.Delete(contract.ID, currentActor(c))
+	if errors.Is(err, service.ErrContractActive) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "删除合同失败")
 		return
 	}
 
 	response.Success(c, nil)
 }
+
+// Trash godoc
+// @Summary 获取合同回收站
+// @Description 分页获取已删除的合同
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /contracts/trash [get]
+func (h *ContractHandler) Trash(c *gin.Context) {
+	var req dto.TrashListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	contracts, total, err := h.contractService.ListTrash(req.Page, req.PageSize)
+	if err != nil {
+		response.InternalError(c, "获取合同回收站失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(contracts, total, req.Page, req.PageSize))
+}
+
+// Restore godoc
+// @Summary 恢复合同
+// @Description 从回收站恢复已删除的合同
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Success 200 {object} response.Response{data=model.Contract} "恢复成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该合同"
+// @Failure 409 {object} response.Response "所属租户已删除"
+// @Failure 500 {object} response.Response "恢复失败"
+// @Router /contracts/{id}/restore [post]
+func (h *ContractHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	before, err := h.contractService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该合同")
+		return
+	}
+
+	contract, err := h.contractService.Restore(before.ID, currentActor(c))
+	if errors.Is(err, service.ErrTenantDeleted) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "恢复合同失败")
+		return
+	}
+
+	response.Success(c, contract)
+}
+
+// Purge godoc
+// @Summary 永久删除合同
+// @Description 永久删除回收站中的合同，不可恢复
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该合同"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /contracts/{id}/purge [delete]
+func (h *ContractHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	contract, err := h.contractService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该合同")
+		return
+	}
+
+	err = h.contractService.Purge(contract.ID, currentActor(c))
+	if err != nil {
+		response.InternalError(c, "永久删除合同失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Transitions godoc
+// @Summary 合同状态变更历史
+// @Description 按时间顺序返回合同的状态变更记录，包含操作人与原因
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Success 200 {object} response.Response{data=[]model.ContractTransition} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /contracts/{id}/transitions [get]
+func (h *ContractHandler) Transitions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	if _, err := h.contractService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "合同不存在")
+		return
+	}
+
+	transitions, err := h.contractService.ListTransitions(uint(id))
+	if err != nil {
+		response.InternalError(c, "获取合同状态变更历史失败")
+		return
+	}
+
+	response.Success(c, transitions)
+}
+
+// Submit godoc
+// @Summary 提交合同审批
+// @Description 草稿合同提交审批，状态变为 pending_approval
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
+// @Param request body dto.ContractTransitionRequest false "变更原因"
+// @Success 200 {object} response.Response{data=model.Contract} "提交成功"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 409 {object} response.Response{data=service.ContractTransitionError} "当前状态不允许提交（code 40901）或数据已被他人修改"
+// @Router /contracts/{id}/submit [post]
+func (h *ContractHandler) Submit(c *gin.Context) {
+	var req dto.ContractTransitionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	h.transition(c, model.ContractStatusPendingApproval, req.Reason)
+}
+
+// Activate godoc
+// @Summary 审批通过并生效合同
+// @Description 待审批合同审批通过后生效，占用合同租赁的房间；需 contract:approve 权限
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
+// @Param request body dto.ContractTransitionRequest false "变更原因"
+// @Success 200 {object} response.Response{data=model.Contract} "生效成功"
+// @Failure 400 {object} response.Response "合同未指定租赁房间"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 409 {object} response.Response{data=service.ContractTransitionError} "当前状态不允许生效（code 40901）、房间已被占用或租期重叠"
+// @Router /contracts/{id}/activate [post]
+func (h *ContractHandler) Activate(c *gin.Context) {
+	var req dto.ContractTransitionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	h.transition(c, model.ContractStatusActive, req.Reason)
+}
+
+// Terminate godoc
+// @Summary 终止合同
+// @Description 提前终止合同或作废草稿、待审批合同，释放合同租赁的房间，必须填写原因
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
+// @Param request body dto.TerminateContractRequest true "终止原因"
+// @Success 200 {object} response.Response{data=model.Contract} "终止成功"
+// @Failure 400 {object} response.Response "未填写终止原因"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 409 {object} response.Response{data=service.ContractTransitionError} "当前状态不允许终止（code 40901）或数据已被他人修改"
+// @Router /contracts/{id}/terminate [post]
+func (h *ContractHandler) Terminate(c *gin.Context) {
+	var req dto.TerminateContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请填写终止原因")
+		return
+	}
+	h.transition(c, model.ContractStatusTerminated, req.Reason)
+}
+
+// Renew godoc
+// @Summary 续签合同
+// @Description 为生效中、即将到期或已到期的合同创建续签合同并关联原合同，租赁房间沿用原合同，
+// @Description 月租金按原合同的递增条款计算。续签合同以草稿或待审批状态创建，生效时原合同变为 renewed。
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "原合同 ID"
+// @Param If-Match header string false "原合同读取时返回的 ETag"
+// @Param request body dto.RenewContractRequest false "续签参数"
+// @Success 200 {object} response.Response{data=model.Contract} "续签合同"
+// @Failure 400 {object} response.Response "请求参数错误、租期无效或缺少 CPI 数据"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 409 {object} response.Response{data=service.ContractTransitionError} "当前状态不允许续签（code 40901）、已存在续签合同或数据已被他人修改"
+// @Router /contracts/{id}/renew [post]
+func (h *ContractHandler) Renew(c *gin.Context) {
+	var req dto.RenewContractRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	contract, err := h.contractService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "合同不存在")
+		return
+	}
+
+	if !checkIfMatch(c, contract.Version, contract) {
+		return
+	}
+
+	opts := service.RenewalOptions{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Amount:    req.Amount,
+		Status:    req.Status,
+		Reason:    req.Reason,
+	}
+	if req.Escalation != nil {
+		escalation := toContractEscalation(req.Escalation)
+		opts.Escalation = &escalation
+	}
+
+	successor, err := h.contractService.Renew(contract, opts, currentActor(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.contractService.GetByID(contract.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if respondContractError(c, err) {
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "续签合同失败")
+		return
+	}
+
+	setETag(c, successor.Version)
+	response.Success(c, successor)
+}
+
+// RentAdjustments godoc
+// @Summary 合同租金调整记录
+// @Description 按生效日期返回合同各房间的租金递增记录，包括续签时和周年日的递增
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Success 200 {object} response.Response{data=[]model.RentAdjustment} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /contracts/{id}/rent-adjustments [get]
+func (h *ContractHandler) RentAdjustments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	if _, err := h.contractService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "合同不存在")
+		return
+	}
+
+	adjustments, err := h.contractService.ListRentAdjustments(uint(id))
+	if err != nil {
+		response.InternalError(c, "获取租金调整记录失败")
+		return
+	}
+
+	response.Success(c, adjustments)
+}
+
+// ListCPIIndices godoc
+// @Summary CPI 涨幅列表
+// @Description 返回本地维护的各年度 CPI 涨幅，按 CPI 递增的合同在某年递增时使用上一年的涨幅
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.CPIIndex} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /cpi-indices [get]
+func (h *ContractHandler) ListCPIIndices(c *gin.Context) {
+	indices, err := h.contractService.ListCPIIndices()
+	if err != nil {
+		response.InternalError(c, "获取 CPI 涨幅失败")
+		return
+	}
+
+	response.Success(c, indices)
+}
+
+// SaveCPIIndex godoc
+// @Summary 设置 CPI 涨幅
+// @Description 新增或覆盖指定年份的 CPI 涨幅（百分比）
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param year path int true "年份"
+// @Param request body dto.SaveCPIIndexRequest true "CPI 涨幅"
+// @Success 200 {object} response.Response{data=model.CPIIndex} "保存成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "保存失败"
+// @Router /cpi-indices/{year} [put]
+func (h *ContractHandler) SaveCPIIndex(c *gin.Context) {
+	year, ok := parseYear(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SaveCPIIndexRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	index, err := h.contractService.SaveCPIIndex(year, *req.Rate)
+	if err != nil {
+		response.InternalError(c, "保存 CPI 涨幅失败")
+		return
+	}
+
+	response.Success(c, index)
+}
+
+// DeleteCPIIndex godoc
+// @Summary 删除 CPI 涨幅
+// @Description 删除指定年份的 CPI 涨幅，已执行的租金递增不受影响
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param year path int true "年份"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的年份"
+// @Failure 404 {object} response.Response "该年份没有 CPI 数据"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /cpi-indices/{year} [delete]
+func (h *ContractHandler) DeleteCPIIndex(c *gin.Context) {
+	year, ok := parseYear(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.contractService.GetCPIIndex(year); err != nil {
+		response.NotFound(c, "该年份没有 CPI 数据")
+		return
+	}
+
+	if err := h.contractService.DeleteCPIIndex(year); err != nil {
+		response.InternalError(c, "删除 CPI 涨幅失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// parseYear 解析路径中的年份，无效时直接响应 400
+func parseYear(c *gin.Context) (int, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 1900 || year > 2999 {
+		response.BadRequest(c, "无效的年份")
+		return 0, false
+	}
+	return year, true
+}
+
+// transition 读取合同并校验 If-Match 后执行状态变更，审计日志由合同服务在事务中记录
+func (h *ContractHandler) transition(c *gin.Context, to, reason string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	contract, err := h.contractService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "合同不存在")
+		return
+	}
+
+	if !checkIfMatch(c, contract.Version, contract) {
+		return
+	}
+
+	err = h.contractService.Transition(contract, to, reason, currentActor(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.contractService.GetByID(contract.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if respondContractError(c, err) {
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "变更合同状态失败")
+		return
+	}
+
+	setETag(c, contract.Version)
+	response.Success(c, contract)
+}
+
+// toContractRooms 转换请求中的租赁房间，保留 nil 以区分未传与清空
+func toContractRooms(reqs []dto.ContractRoomRequest) []model.ContractRoom {
+	if reqs == nil {
+		return nil
+	}
+	rooms := make([]model.ContractRoom, 0, len(reqs))
+	for _, r := range reqs {
+		rooms = append(rooms, model.ContractRoom{RoomID: r.RoomID, MonthlyRent: r.MonthlyRent})
+	}
+	return rooms
+}
+
+func toContractEscalation(req *dto.ContractEscalationRequest) model.ContractEscalation {
+	return model.ContractEscalation{Type: req.Type, Value: req.Value, Timing: req.Timing}
+}
+
+func toContractBilling(req *dto.ContractBillingRequest) model.ContractBilling {
+	return model.ContractBilling{Cycle: req.Cycle, DueDay: req.DueDay, Mode: req.Mode}
+}
+
+// respondContractError 处理合同状态与租赁房间相关的业务错误，已响应时返回 true
+func respondContractError(c *gin.Context, err error) bool {
+	var transitionErr *service.ContractTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		response.ErrorWithData(c, http.StatusConflict, response.CodeInvalidStateTransition, err.Error(), transitionErr)
+	case errors.Is(err, service.ErrInvalidContractTransition):
+		response.ErrorWithHTTPStatus(c, http.StatusConflict, response.CodeInvalidStateTransition, err.Error())
+	case errors.Is(err, service.ErrRoomOccupied), errors.Is(err, service.ErrRoomLeaseOverlap),
+		errors.Is(err, service.ErrContractRenewed):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, service.ErrRoomNotFound), errors.Is(err, service.ErrContractRoomDuplicate),
+		errors.Is(err, service.ErrContractNoRooms), errors.Is(err, service.ErrTenantNotFound),
+		errors.Is(err, service.ErrContractPeriod), errors.Is(err, service.ErrInvalidEscalation),
+		errors.Is(err, service.ErrCPIIndexMissing), errors.Is(err, service.ErrInvalidBilling):
+		response.BadRequest(c, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON 绑定可省略的请求体，请求体为空时保持零值，格式错误时返回 400
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "请求参数错误")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+// setETag 以记录版本号作为 ETag 返回
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// checkIfMatch 校验 If-Match 请求头，未携带时放行；与当前版本不一致时返回 412 并附带当前数据
+func checkIfMatch(c *gin.Context, version uint, current interface{}) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || ifMatches(header, version) {
+		return true
+	}
+
+	setETag(c, version)
+	response.PreconditionFailed(c, "数据已被他人修改，请刷新后重试", current)
+	return false
+}
+
+// ifMatches 支持 *、弱校验前缀 W/ 以及逗号分隔的多个 ETag
+func ifMatches(header string, version uint) bool {
+	expected := strconv.FormatUint(uint64(version), 10)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		tag = strings.TrimPrefix(tag, "W/")
+		if strings.Trim(tag, `"`) == expected {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -30,8 +32,8 @@ func NewFeeHandler(feeService *service.FeeService) *FeeHandler {
 // @Param pageSize query int false "每页数量" default(10)
 // @Param tenantId query int false "租户 ID"
 // @Param roomNo query string false "房间号"
-// @Param feeType query string false "费用类型" Enums(rent, water, electricity, property, other)
-// @Param status query string false "状态" Enums(unpaid, paid, overdue)
+// @Param feeType query string false "费用类型" Enums(rent, water, electricity, property, other, late_fee)
+// @Param status query string false "状态" Enums(unpaid, partially_paid, paid, overdue, waived)
 // @Param period query string false "账期 (如: 2024-03)"
 // @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
 // @Failure 500 {object} response.Response "服务器错误"
@@ -70,6 +72,7 @@ func (h *FeeHandler) List(c *gin.Context) {
 // @Success 200 {object} response.Response{data=model.Fee} "获取成功"
 // @Failure 400 {object} response.Response "无效的 ID"
 // @Failure 404 {object} response.Response "费用记录不存在"
+// @Header 200 {string} ETag "记录版本号，更新时通过 If-Match 回传"
 // @Router /fees/{id} [get]
 func (h *FeeHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -84,6 +87,7 @@ func (h *FeeHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, fee.Version)
 	response.Success(c, fee)
 }
 
@@ -120,27 +124,40 @@ func (h *FeeHandler) Create(c *gin.Context) {
 		fee.Status = "unpaid"
 	}
 
-	if err := h.feeService.Create(fee); err != nil {
+	err := h.feeService.Create(fee, currentActor(c))
+	if errors.Is(err, service.ErrFeeStatusDerived) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "创建费用记录失败")
 		return
 	}
 
+	// 新建与余额抵扣的审计由费用服务在事务中记录
+	setETag(c, fee.Version)
 	response.Success(c, fee)
 }
 
 // Update godoc
 // @Summary 更新费用
-// @Description 更新费用记录
+// @Description 更新费用记录，已有收款、余额抵扣等关联记录的费用不能改为其他租户；
+// @Description 已开入未作废发票的费用不能修改金额、租户、类型与账期
 // @Tags 费用管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "费用 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
 // @Param request body dto.UpdateFeeRequest true "更新费用请求"
 // @Success 200 {object} response.Response{data=model.Fee} "更新成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 404 {object} response.Response "费用记录不存在"
+// @Failure 409 {object} response.Response{data=model.Fee} "保存时数据已被他人修改，返回当前数据；或已有收款等关联记录的费用改为其他租户，或修改已开票的费用"
+// @Failure 412 {object} response.Response{data=model.Fee} "If-Match 与当前版本不一致，返回当前数据"
+// @Failure 428 {object} response.Response "未携带 If-Match（开启 require_if_match 时）"
 // @Failure 500 {object} response.Response "更新失败"
+// @Header 200 {string} ETag "更新后的版本号"
 // @Router /fees/{id} [put]
 func (h *FeeHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -155,6 +172,10 @@ func (h *FeeHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, fee.Version, fee) {
+		return
+	}
+
 	var req dto.UpdateFeeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "请求参数错误")
@@ -183,11 +204,27 @@ func (h *FeeHandler) Update(c *gin.Context) {
 		fee.Status = req.Status
 	}
 
-	if err := h.feeService.Update(fee); err != nil {
+	err = h.feeService.Update(fee, currentActor(c))
+	if errors.Is(err, service.ErrFeeStatusDerived) || errors.Is(err, service.ErrFeeAmountBelowPaid) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrFeeTenantLocked) || errors.Is(err, service.ErrFeeInvoiced) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.feeService.GetByID(fee.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "更新费用记录失败")
 		return
 	}
 
+	// 修改的审计由费用服务在事务中记录
+	setETag(c, fee.Version)
 	response.Success(c, fee)
 }
 
@@ -201,6 +238,8 @@ func (h *FeeHandler) Update(c *gin.Context) {
 // @Param id path int true "费用 ID"
 // @Success 200 {object} response.Response "删除成功"
 // @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "费用不存在"
+// @Failure 409 {object} response.Response "费用已有收款记录或已开具发票"
 // @Failure 500 {object} response.Response "删除失败"
 // @Router /fees/{id} [delete]
 func (h *FeeHandler) Delete(c *gin.Context) {
@@ -210,7 +249,18 @@ func (h *FeeHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.feeService.Delete(uint(id)); err != nil {
+	fee, err := h.feeService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "费用不存在")
+		return
+	}
+
+	err = h.feeService.Delete(fee.ID, currentActor(c))
+	if errors.Is(err, service.ErrFeeHasPayments) || errors.Is(err, service.ErrFeeInvoiced) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "删除费用记录失败")
 		return
 	}
@@ -220,15 +270,18 @@ func (h *FeeHandler) Delete(c *gin.Context) {
 
 // Pay godoc
 // @Summary 确认缴费
-// @Description 确认费用已缴纳
+// @Description 为费用登记一笔收款：amount 省略时收取全部未收金额，小于未收金额时费用变为 partially_paid；
+// @Description method 省略时按现金登记，paidDate 为到账时间
 // @Tags 费用管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "费用 ID"
 // @Param request body dto.PayFeeRequest false "缴费请求"
-// @Success 200 {object} response.Response "缴费成功"
-// @Failure 400 {object} response.Response "无效的 ID"
+// @Success 200 {object} response.Response{data=model.Payment} "缴费成功，返回收款记录"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "费用不存在"
+// @Failure 409 {object} response.Response "该费用已缴纳或收款金额超过未收金额"
 // @Failure 500 {object} response.Response "确认缴费失败"
 // @Router /fees/{id}/pay [post]
 func (h *FeeHandler) Pay(c *gin.Context) {
@@ -239,12 +292,234 @@ func (h *FeeHandler) Pay(c *gin.Context) {
 	}
 
 	var req dto.PayFeeRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	fee, err := h.feeService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "费用不存在")
+		return
+	}
+
+	payment := &model.Payment{
+		Amount:    req.Amount,
+		Method:    req.Method,
+		Reference: req.Reference,
+		Remark:    req.Remark,
+	}
+	if req.PaidDate != nil {
+		payment.ReceivedAt = *req.PaidDate
+	}
+
+	err = h.feeService.Pay(fee.ID, payment, currentActor(c))
+	if err != nil {
+		if !respondPaymentError(c, err) {
+			response.InternalError(c, "确认缴费失败")
+		}
+		return
+	}
+
+	// 收款与费用的审计由收款服务在事务中记录
+	response.Success(c, payment)
+}
+
+// Overdue godoc
+// @Summary 获取逾期费用
+// @Description 分页获取已标记逾期的费用，逾期最久的在前；daysPastDue 为已过到期日的天数，lateFee 为已生成的滞纳金
+// @Tags 费用管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
+// @Param feeType query string false "费用类型" Enums(rent, water, electricity, property, other, late_fee)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /fees/overdue [get]
+func (h *FeeHandler) Overdue(c *gin.Context) {
+	var req dto.OverdueFeeListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	fees, total, err := h.feeService.ListOverdue(req.Page, req.PageSize, req.TenantID, req.FeeType, time.Now())
+	if err != nil {
+		response.InternalError(c, "获取逾期费用失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(fees, total, req.Page, req.PageSize))
+}
+
+// Waive godoc
+// @Summary 减免滞纳金
+// @Description 减免逾期检查生成的滞纳金，减免后状态为 waived，不再计入欠费也不再累计；已收取部分金额的须先冲正收款
+// @Tags 费用管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "滞纳金费用 ID"
+// @Param request body dto.WaiveFeeRequest true "减免原因"
+// @Success 200 {object} response.Response{data=model.Fee} "减免成功"
+// @Failure 400 {object} response.Response "请求参数错误或该费用不是滞纳金"
+// @Failure 404 {object} response.Response "费用不存在"
+// @Failure 409 {object} response.Response "已减免、已缴纳或已有收款"
+// @Failure 500 {object} response.Response "减免失败"
+// @Router /fees/{id}/waive [post]
+func (h *FeeHandler) Waive(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	var req dto.WaiveFeeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		req.PaidDate = nil
+		response.BadRequest(c, "请填写减免原因")
+		return
+	}
+
+	if _, err := h.feeService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "费用不存在")
+		return
 	}
 
-	if err := h.feeService.Pay(uint(id), req.PaidDate); err != nil {
-		response.InternalError(c, "确认缴费失败")
+	// 减免的审计由费用服务在事务中记录
+	fee, err := h.feeService.Waive(uint(id), req.Reason, currentActor(c))
+	switch {
+	case errors.Is(err, service.ErrFeeNotPenalty):
+		response.BadRequest(c, err.Error())
+		return
+	case errors.Is(err, service.ErrFeeWaived), errors.Is(err, service.ErrFeeAlreadyPaid), errors.Is(err, service.ErrFeeHasPayments):
+		response.Conflict(c, err.Error())
+		return
+	case err != nil:
+		response.InternalError(c, "减免滞纳金失败")
+		return
+	}
+
+	response.Success(c, fee)
+}
+
+// Trash godoc
+// @Summary 获取费用回收站
+// @Description 分页获取已删除的费用
+// @Tags 费用管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /fees/trash [get]
+func (h *FeeHandler) Trash(c *gin.Context) {
+	var req dto.TrashListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	fees, total, err := h.feeService.ListTrash(req.Page, req.PageSize)
+	if err != nil {
+		response.InternalError(c, "获取费用回收站失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(fees, total, req.Page, req.PageSize))
+}
+
+// Restore godoc
+// @Summary 恢复费用
+// @Description 从回收站恢复已删除的费用
+// @Tags 费用管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "费用 ID"
+// @Success 200 {object} response.Response{data=model.Fee} "恢复成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该费用"
+// @Failure 409 {object} response.Response "所属租户已删除"
+// @Failure 500 {object} response.Response "恢复失败"
+// @Router /fees/{id}/restore [post]
+func (h *FeeHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	before, err := h.feeService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该费用")
+		return
+	}
+
+	fee, err := h.feeService.Restore(before.ID, currentActor(c))
+	if errors.Is(err, service.ErrTenantDeleted) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "恢复费用失败")
+		return
+	}
+
+	response.Success(c, fee)
+}
+
+// Purge godoc
+// @Summary 永久删除费用
+// @Description 永久删除回收站中的费用，不可恢复
+// @Tags 费用管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "费用 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该费用"
+// @Failure 409 {object} response.Response "费用已有收款记录或已开具发票"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /fees/{id}/purge [delete]
+func (h *FeeHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	fee, err := h.feeService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该费用")
+		return
+	}
+
+	err = h.feeService.Purge(fee.ID, currentActor(c))
+	if errors.Is(err, service.ErrFeeHasPayments) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "永久删除费用失败")
 		return
 	}
 
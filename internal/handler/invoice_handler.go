@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService}
+}
+
+// List godoc
+// @Summary 获取发票列表
+// @Description 分页获取发票与红字发票，按创建时间倒序
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
+// @Param kind query string false "类型" Enums(invoice, credit_note)
+// @Param status query string false "状态" Enums(draft, issued, void)
+// @Param period query string false "账期"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /invoices [get]
+func (h *InvoiceHandler) List(c *gin.Context) {
+	var req dto.InvoiceListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	invoices, total, err := h.invoiceService.List(req.Page, req.PageSize, req.TenantID, req.Kind, req.Status, req.Period)
+	if err != nil {
+		response.InternalError(c, "获取发票列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(invoices, total, req.Page, req.PageSize))
+}
+
+// GetByID godoc
+// @Summary 获取发票详情
+// @Description 获取发票及其明细
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "发票 ID"
+// @Success 200 {object} response.Response{data=model.Invoice} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "发票不存在"
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "发票不存在")
+		return
+	}
+
+	response.Success(c, invoice)
+}
+
+// PDF godoc
+// @Summary 下载发票 PDF
+// @Description 按配置的出租方抬头生成发票或红字发票的 PDF，草稿与已作废的发票在标题中注明
+// @Tags 发票管理
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "发票 ID"
+// @Success 200 {file} file "PDF 文件"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "发票不存在"
+// @Router /invoices/{id}/pdf [get]
+func (h *InvoiceHandler) PDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	invoice, data, err := h.invoiceService.RenderPDF(uint(id))
+	if err != nil {
+		response.NotFound(c, "发票不存在")
+		return
+	}
+
+	name := fmt.Sprintf("invoice-draft-%d.pdf", invoice.ID)
+	if invoice.InvoiceNo != nil {
+		name = *invoice.InvoiceNo + ".pdf"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	c.Data(200, "application/pdf", data)
+}
+
+// Create godoc
+// @Summary 创建草稿发票
+// @Description 将租户的费用汇总为一张草稿发票，按费用类型的税率拆分不含税金额与税额。
+// @Description feeIds 省略时汇总该租户在 period 账期内尚未开票的全部费用；每笔费用只能开入一张未作废的发票
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateInvoiceRequest true "创建发票请求"
+// @Success 200 {object} response.Response{data=model.Invoice} "创建成功"
+// @Failure 400 {object} response.Response "请求参数错误或没有可开票的费用"
+// @Failure 409 {object} response.Response "费用已开具发票"
+// @Failure 500 {object} response.Response "创建失败"
+// @Router /invoices [post]
+func (h *InvoiceHandler) Create(c *gin.Context) {
+	var req dto.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	invoice := &model.Invoice{
+		TenantID: req.TenantID,
+		Period:   req.Period,
+		Remark:   req.Remark,
+	}
+	if err := h.invoiceService.Create(invoice, req.FeeIDs, currentActor(c)); err != nil {
+		if !respondInvoiceError(c, err) {
+			response.InternalError(c, "创建发票失败")
+		}
+		return
+	}
+
+	if created, err := h.invoiceService.GetByID(invoice.ID); err == nil {
+		invoice = created
+	}
+
+	response.Success(c, invoice)
+}
+
+// Delete godoc
+// @Summary 删除草稿发票
+// @Description 只能删除草稿发票，其中的费用可重新开票；已开具的发票只能作废
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "发票 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "发票不存在"
+// @Failure 409 {object} response.Response "发票不是草稿"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /invoices/{id} [delete]
+func (h *InvoiceHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "发票不存在")
+		return
+	}
+
+	if err := h.invoiceService.Delete(invoice.ID, currentActor(c)); err != nil {
+		if !respondInvoiceError(c, err) {
+			response.InternalError(c, "删除发票失败")
+		}
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Issue godoc
+// @Summary 开具发票
+// @Description 开具草稿发票并按开具年度分配连续编号（INV-年度-序号），开具后不能修改或删除
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "发票 ID"
+// @Success 200 {object} response.Response{data=model.Invoice} "开具成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "发票不存在"
+// @Failure 409 {object} response.Response "发票不是草稿"
+// @Failure 500 {object} response.Response "开具失败"
+// @Router /invoices/{id}/issue [post]
+func (h *InvoiceHandler) Issue(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	if _, err := h.invoiceService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "发票不存在")
+		return
+	}
+
+	invoice, err := h.invoiceService.Issue(uint(id), currentActor(c))
+	if err != nil {
+		if !respondInvoiceError(c, err) {
+			response.InternalError(c, "开具发票失败")
+		}
+		return
+	}
+
+	response.Success(c, invoice)
+}
+
+// Void godoc
+// @Summary 作废发票
+// @Description 作废已开具的发票或红字发票，作废后保留编号，其中的费用可重新开票。已被红字发票冲减的发票须先作废红字发票。
+// @Description 作废红字发票时一并作废其开具的贷项，贷项计入的余额已被抵扣或退款时不能作废
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "发票 ID"
+// @Param request body dto.VoidInvoiceRequest true "作废原因"
+// @Success 200 {object} response.Response{data=model.Invoice} "作废成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "发票不存在"
+// @Failure 409 {object} response.Response "发票未开具、已作废或已冲红，或红字发票的贷项已被使用"
+// @Failure 500 {object} response.Response "作废失败"
+// @Router /invoices/{id}/void [post]
+func (h *InvoiceHandler) Void(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	var req dto.VoidInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请填写作废原因")
+		return
+	}
+
+	if _, err := h.invoiceService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "发票不存在")
+		return
+	}
+
+	invoice, err := h.invoiceService.Void(uint(id), req.Reason, currentActor(c))
+	if err != nil {
+		if !respondInvoiceError(c, err) {
+			response.InternalError(c, "作废发票失败")
+		}
+		return
+	}
+
+	response.Success(c, invoice)
+}
+
+// CreditNote godoc
+// @Summary 开具红字发票
+// @Description 冲减已开具发票的部分或全部金额，红字发票金额为负数并分配 CN-年度-序号 编号。
+// @Description lines 省略时冲减全部剩余金额，每行冲减金额不能超过该明细尚未冲减的金额。
+// @Description 红字发票同时为其中的费用开具贷项（invoiceId 指向红字发票）并抵扣未结清的费用
+// @Tags 发票管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "原发票 ID"
+// @Param request body dto.CreateCreditNoteRequest true "冲红请求"
+// @Success 200 {object} response.Response{data=model.Invoice} "开具成功，返回红字发票"
+// @Failure 400 {object} response.Response "请求参数错误或明细无效"
+// @Failure 404 {object} response.Response "发票不存在"
+// @Failure 409 {object} response.Response "发票未开具，或冲红金额超过可冲减金额或费用可开具的贷项金额"
+// @Failure 500 {object} response.Response "开具失败"
+// @Router /invoices/{id}/credit-notes [post]
+func (h *InvoiceHandler) CreditNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	var req dto.CreateCreditNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if _, err := h.invoiceService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "发票不存在")
+		return
+	}
+
+	lines := make([]service.CreditLine, 0, len(req.Lines))
+	for _, l := range req.Lines {
+		lines = append(lines, service.CreditLine{LineID: l.LineID, Amount: l.Amount})
+	}
+	note, err := h.invoiceService.CreditNote(uint(id), req.Reason, lines, currentActor(c))
+	if err != nil {
+		if !respondInvoiceError(c, err) {
+			response.InternalError(c, "开具红字发票失败")
+		}
+		return
+	}
+
+	response.Success(c, note)
+}
+
+// respondInvoiceError 将发票业务错误转换为响应，未识别的错误返回 false
+func respondInvoiceError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidInvoice), errors.Is(err, service.ErrInvoiceEmpty):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrFeeInvoiced), errors.Is(err, service.ErrInvoiceNotDraft),
+		errors.Is(err, service.ErrInvoiceNotIssued), errors.Is(err, service.ErrInvoiceCredited),
+		errors.Is(err, service.ErrCreditExceedsAmount), errors.Is(err, service.ErrCreditExceedsFee),
+		errors.Is(err, service.ErrInvoiceCreditUsed):
+		response.Conflict(c, err.Error())
+	default:
+		return false
+	}
+	return true
+}
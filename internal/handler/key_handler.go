@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type KeyHandler struct {
+	keyService *service.KeyService
+}
+
+func NewKeyHandler(keyService *service.KeyService) *KeyHandler {
+	return &KeyHandler{keyService: keyService}
+}
+
+// JWKS godoc
+// @Summary 获取 JWT 公钥集合
+// @Description 以 JWKS 格式返回当前可用于验签的公钥（RFC 7517），供其他服务离线校验访问令牌；HS256 模式下返回空集合
+// @Tags 签名密钥
+// @Produce json
+// @Success 200 {object} utils.JWKS "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /.well-known/jwks.json [get]
+func (h *KeyHandler) JWKS(c *gin.Context) {
+	jwks, err := h.keyService.JWKS()
+	if err != nil {
+		response.InternalError(c, "获取公钥失败")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
+
+// List godoc
+// @Summary 获取签名密钥列表
+// @Description 获取所有 JWT 签名密钥的公钥及状态（不含私钥）
+// @Tags 签名密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.SigningKey} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /keys [get]
+func (h *KeyHandler) List(c *gin.Context) {
+	keys, err := h.keyService.List()
+	if err != nil {
+		response.InternalError(c, "获取签名密钥失败")
+		return
+	}
+
+	response.Success(c, keys)
+}
+
+// Rotate godoc
+// @Summary 轮换签名密钥
+// @Description 立即生成新的签名密钥，旧密钥在 rotation_overlap 内仍可验签
+// @Tags 签名密钥
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.SigningKey} "轮换成功"
+// @Failure 400 {object} response.Response "HS256 模式不支持轮换"
+// @Router /keys/rotate [post]
+func (h *KeyHandler) Rotate(c *gin.Context) {
+	key, err := h.keyService.Rotate()
+	if errors.Is(err, service.ErrRotationUnsupported) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "轮换签名密钥失败")
+		return
+	}
+
+	response.Success(c, key)
+}
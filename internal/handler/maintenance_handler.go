@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +29,7 @@ func NewMaintenanceHandler(maintenanceService *service.MaintenanceService) *Main
 // @Security BearerAuth
 // @Param page query int false "页码" default(1)
 // @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
 // @Param keyword query string false "搜索关键字"
 // @Param type query string false "维修类型" Enums(electrical, plumbing, appliance, furniture, other)
 // @Param status query string false "状态" Enums(pending, processing, completed, cancelled)
@@ -49,7 +51,7 @@ func (h *MaintenanceHandler) List(c *gin.Context) {
 		req.PageSize = 10
 	}
 
-	maintenances, total, err := h.maintenanceService.List(req.Page, req.PageSize, req.Keyword, req.Type, req.Status, req.Priority)
+	maintenances, total, err := h.maintenanceService.List(req.Page, req.PageSize, req.TenantID, req.Keyword, req.Type, req.Status, req.Priority)
 	if err != nil {
 		response.InternalError(c, "获取维修工单列表失败")
 		return
@@ -69,6 +71,7 @@ func (h *MaintenanceHandler) List(c *gin.Context) {
 // @Success 200 {object} response.Response{data=model.Maintenance} "获取成功"
 // @Failure 400 {object} response.Response "无效的 ID"
 // @Failure 404 {object} response.Response "维修工单不存在"
+// @Header 200 {string} ETag "记录版本号，更新时通过 If-Match 回传"
 // @Router /maintenance/{id} [get]
 func (h *MaintenanceHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -83,6 +86,7 @@ func (h *MaintenanceHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, maintenance.Version)
 	response.Success(c, maintenance)
 }
 
@@ -117,11 +121,12 @@ func (h *MaintenanceHandler) Create(c *gin.Context) {
 		maintenance.Priority = "medium"
 	}
 
-	if err := h.maintenanceService.Create(maintenance); err != nil {
+	if err := h.maintenanceService.Create(maintenance, currentActor(c)); err != nil {
 		response.InternalError(c, "创建维修工单失败")
 		return
 	}
 
+	setETag(c, maintenance.Version)
 	response.Success(c, maintenance)
 }
 
@@ -133,11 +138,16 @@ func (h *MaintenanceHandler) Create(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "工单 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
 // @Param request body dto.UpdateMaintenanceRequest true "更新工单请求"
 // @Success 200 {object} response.Response{data=model.Maintenance} "更新成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 404 {object} response.Response "维修工单不存在"
+// @Failure 409 {object} response.Response{data=model.Maintenance} "保存时数据已被他人修改，返回当前数据"
+// @Failure 412 {object} response.Response{data=model.Maintenance} "If-Match 与当前版本不一致，返回当前数据"
+// @Failure 428 {object} response.Response "未携带 If-Match（开启 require_if_match 时）"
 // @Failure 500 {object} response.Response "更新失败"
+// @Header 200 {string} ETag "更新后的版本号"
 // @Router /maintenance/{id} [put]
 func (h *MaintenanceHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -152,6 +162,10 @@ func (h *MaintenanceHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, maintenance.Version, maintenance) {
+		return
+	}
+
 	var req dto.UpdateMaintenanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "请求参数错误")
@@ -180,11 +194,18 @@ func (h *MaintenanceHandler) Update(c *gin.Context) {
 		maintenance.Assignee = req.Assignee
 	}
 
-	if err := h.maintenanceService.Update(maintenance); err != nil {
+	err = h.maintenanceService.Update(maintenance, currentActor(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.maintenanceService.GetByID(maintenance.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "更新维修工单失败")
 		return
 	}
 
+	setETag(c, maintenance.Version)
 	response.Success(c, maintenance)
 }
 
@@ -198,6 +219,7 @@ func (h *MaintenanceHandler) Update(c *gin.Context) {
 // @Param id path int true "工单 ID"
 // @Success 200 {object} response.Response "删除成功"
 // @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "维修工单不存在"
 // @Failure 500 {object} response.Response "删除失败"
 // @Router /maintenance/{id} [delete]
 func (h *MaintenanceHandler) Delete(c *gin.Context) {
@@ -207,7 +229,13 @@ func (h *MaintenanceHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.maintenanceService.Delete(uint(id)); err != nil {
+	maintenance, err := h.maintenanceService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "维修工单不存在")
+		return
+	}
+
+	if err := h.maintenanceService.Delete(maintenance.ID, currentActor(c)); err != nil {
 		response.InternalError(c, "删除维修工单失败")
 		return
 	}
@@ -241,7 +269,13 @@ func (h *MaintenanceHandler) Assign(c *gin.Context) {
 		return
 	}
 
-	if err := h.maintenanceService.Assign(uint(id), req.Assignee); err != nil {
+	maintenance, err := h.maintenanceService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "维修工单不存在")
+		return
+	}
+
+	if err := h.maintenanceService.Assign(maintenance.ID, req.Assignee, currentActor(c)); err != nil {
 		response.InternalError(c, "指派维修人员失败")
 		return
 	}
@@ -260,6 +294,7 @@ func (h *MaintenanceHandler) Assign(c *gin.Context) {
 // @Param request body dto.CompleteMaintenanceRequest false "完成请求"
 // @Success 200 {object} response.Response "完成成功"
 // @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 409 {object} response.Response "工单已完成或已取消"
 // @Failure 500 {object} response.Response "完成失败"
 // @Router /maintenance/{id}/complete [post]
 func (h *MaintenanceHandler) Complete(c *gin.Context) {
@@ -274,10 +309,131 @@ func (h *MaintenanceHandler) Complete(c *gin.Context) {
 		req.CompletedAt = nil
 	}
 
-	if err := h.maintenanceService.Complete(uint(id), req.CompletedAt); err != nil {
+	maintenance, err := h.maintenanceService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "维修工单不存在")
+		return
+	}
+
+	err = h.maintenanceService.Complete(maintenance.ID, req.CompletedAt, currentActor(c))
+	if errors.Is(err, service.ErrMaintenanceClosed) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "完成工单失败")
 		return
 	}
 
 	response.Success(c, nil)
 }
+
+// Trash godoc
+// @Summary 获取维修工单回收站
+// @Description 分页获取已删除的维修工单
+// @Tags 维修管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /maintenance/trash [get]
+func (h *MaintenanceHandler) Trash(c *gin.Context) {
+	var req dto.TrashListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	maintenances, total, err := h.maintenanceService.ListTrash(req.Page, req.PageSize)
+	if err != nil {
+		response.InternalError(c, "获取维修工单回收站失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(maintenances, total, req.Page, req.PageSize))
+}
+
+// Restore godoc
+// @Summary 恢复维修工单
+// @Description 从回收站恢复已删除的维修工单
+// @Tags 维修管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "维修工单 ID"
+// @Success 200 {object} response.Response{data=model.Maintenance} "恢复成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该维修工单"
+// @Failure 409 {object} response.Response "所属租户已删除"
+// @Failure 500 {object} response.Response "恢复失败"
+// @Router /maintenance/{id}/restore [post]
+func (h *MaintenanceHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	before, err := h.maintenanceService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该维修工单")
+		return
+	}
+
+	maintenance, err := h.maintenanceService.Restore(before.ID, currentActor(c))
+	if errors.Is(err, service.ErrTenantDeleted) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "恢复维修工单失败")
+		return
+	}
+
+	response.Success(c, maintenance)
+}
+
+// Purge godoc
+// @Summary 永久删除维修工单
+// @Description 永久删除回收站中的维修工单，不可恢复
+// @Tags 维修管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "维修工单 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该维修工单"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /maintenance/{id}/purge [delete]
+func (h *MaintenanceHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	maintenance, err := h.maintenanceService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该维修工单")
+		return
+	}
+
+	err = h.maintenanceService.Purge(maintenance.ID, currentActor(c))
+	if err != nil {
+		response.InternalError(c, "永久删除维修工单失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
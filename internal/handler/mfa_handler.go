@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// Setup godoc
+// @Summary 获取两步验证密钥
+// @Description 生成 TOTP 密钥与 otpauth URI（可生成二维码供身份验证器扫描），需调用启用接口确认后生效
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=service.MFASetupResponse} "获取成功"
+// @Failure 400 {object} response.Response "已启用两步验证"
+// @Router /auth/mfa/setup [post]
+func (h *MFAHandler) Setup(c *gin.Context) {
+	result, err := h.mfaService.Setup(middleware.GetUserID(c))
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Enable godoc
+// @Summary 启用两步验证
+// @Description 提交身份验证器中的验证码确认绑定，返回仅展示一次的恢复码
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MFACodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=service.RecoveryCodesResponse} "启用成功"
+// @Failure 400 {object} response.Response "验证码错误"
+// @Router /auth/mfa/enable [post]
+func (h *MFAHandler) Enable(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	result, err := h.mfaService.Enable(middleware.GetUserID(c), req.Code)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Disable godoc
+// @Summary 关闭两步验证
+// @Description 校验密码与验证码后关闭两步验证，策略要求启用的角色不能关闭
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MFADisableRequest true "关闭请求"
+// @Success 200 {object} response.Response "关闭成功"
+// @Failure 400 {object} response.Response "密码或验证码错误"
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req dto.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := h.mfaService.Disable(middleware.GetUserID(c), req.Password, req.Code); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary 重新生成恢复码
+// @Description 校验验证码后重新生成恢复码，旧恢复码全部失效
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MFACodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=service.RecoveryCodesResponse} "生成成功"
+// @Failure 400 {object} response.Response "验证码错误"
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	result, err := h.mfaService.RegenerateRecoveryCodes(middleware.GetUserID(c), req.Code)
+	if err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Reset godoc
+// @Summary 重置用户两步验证
+// @Description 管理员为丢失身份验证器的用户清除两步验证绑定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} response.Response "重置成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /users/{id}/mfa/reset [post]
+func (h *MFAHandler) Reset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	if err := h.mfaService.Reset(uint(id)); err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	response.Success(c, nil)
+}
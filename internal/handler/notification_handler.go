@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// List godoc
+// @Summary 获取通知列表
+// @Description 分页获取站内通知（如合同续租提醒），按生成时间倒序
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param type query string false "通知类型" Enums(contract_expiry)
+// @Param unread query bool false "只看未读"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	var req dto.NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	notifications, total, err := h.notificationService.List(req.Page, req.PageSize, req.Type, req.Unread)
+	if err != nil {
+		response.InternalError(c, "获取通知列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(notifications, total, req.Page, req.PageSize))
+}
+
+// MarkRead godoc
+// @Summary 标记通知已读
+// @Description 标记通知为已读，重复调用保留首次阅读时间
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通知 ID"
+// @Success 200 {object} response.Response{data=model.Notification} "标记成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "通知不存在"
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(uint(id))
+	if err != nil {
+		response.NotFound(c, "通知不存在")
+		return
+	}
+
+	response.Success(c, notification)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// List godoc
+// @Summary 获取收款列表
+// @Description 分页获取收款记录，按到账时间倒序
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
+// @Param method query string false "收款方式" Enums(cash, bank_transfer, wechat, alipay, other)
+// @Param status query string false "状态" Enums(completed, reversed)
+// @Param from query string false "到账日期起 (YYYY-MM-DD)"
+// @Param to query string false "到账日期止 (YYYY-MM-DD，含当天)"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /payments [get]
+func (h *PaymentHandler) List(c *gin.Context) {
+	var req dto.PaymentListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	var from, to *time.Time
+	if req.From != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err == nil {
+			from = &t
+		}
+	}
+	if req.To != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err == nil {
+			t = t.AddDate(0, 0, 1)
+			to = &t
+		}
+	}
+
+	payments, total, err := h.paymentService.List(req.Page, req.PageSize, req.TenantID, req.Method, req.Status, from, to)
+	if err != nil {
+		response.InternalError(c, "获取收款列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(payments, total, req.Page, req.PageSize))
+}
+
+// GetByID godoc
+// @Summary 获取收款详情
+// @Description 获取收款及其分配到各费用的明细
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "收款 ID"
+// @Success 200 {object} response.Response{data=model.Payment} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "收款记录不存在"
+// @Router /payments/{id} [get]
+func (h *PaymentHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	payment, err := h.paymentService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "收款记录不存在")
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+// Create godoc
+// @Summary 登记收款
+// @Description 登记一笔实际到账的收款并分配到同一租户的一笔或多笔费用，分配金额合计不能超过收款金额。
+// @Description 未指定分配明细时按到期日从早到晚自动冲抵该租户未结清的费用；费用按已收金额变为 partially_paid 或 paid。
+// @Description 未分配的部分记为 credited 转入租户账户余额
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreatePaymentRequest true "登记收款请求"
+// @Success 200 {object} response.Response{data=model.Payment} "登记成功"
+// @Failure 400 {object} response.Response "请求参数错误或分配明细无效"
+// @Failure 409 {object} response.Response "收款金额超过费用未收金额"
+// @Failure 500 {object} response.Response "登记失败"
+// @Router /payments [post]
+func (h *PaymentHandler) Create(c *gin.Context) {
+	var req dto.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	payment := &model.Payment{
+		TenantID:  req.TenantID,
+		Amount:    req.Amount,
+		Method:    req.Method,
+		Reference: req.Reference,
+		Remark:    req.Remark,
+	}
+	if req.ReceivedAt != nil {
+		payment.ReceivedAt = *req.ReceivedAt
+	}
+	for _, a := range req.Allocations {
+		payment.Allocations = append(payment.Allocations, model.PaymentAllocation{FeeID: a.FeeID, Amount: a.Amount})
+	}
+
+	if err := h.paymentService.Create(payment, currentActor(c)); err != nil {
+		if !respondPaymentError(c, err) {
+			response.InternalError(c, "登记收款失败")
+		}
+		return
+	}
+
+	if created, err := h.paymentService.GetByID(payment.ID); err == nil {
+		payment = created
+	}
+
+	response.Success(c, payment)
+}
+
+// Reverse godoc
+// @Summary 冲正收款
+// @Description 冲正登记错误或退回的收款：分配到各费用的金额全部退回并重新计算费用状态，收款不再计入收入
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "收款 ID"
+// @Param request body dto.ReversePaymentRequest true "冲正原因"
+// @Success 200 {object} response.Response{data=model.Payment} "冲正成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "收款记录不存在"
+// @Failure 409 {object} response.Response "该收款已冲正、已有退款或转入的余额已被使用"
+// @Failure 500 {object} response.Response "冲正失败"
+// @Router /payments/{id}/reverse [post]
+func (h *PaymentHandler) Reverse(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	var req dto.ReversePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请填写冲正原因")
+		return
+	}
+
+	if _, err := h.paymentService.GetByID(uint(id)); err != nil {
+		response.NotFound(c, "收款记录不存在")
+		return
+	}
+
+	payment, err := h.paymentService.Reverse(uint(id), req.Reason, currentActor(c))
+	if err != nil {
+		if !respondPaymentError(c, err) {
+			response.InternalError(c, "冲正收款失败")
+		}
+		return
+	}
+
+	response.Success(c, payment)
+}
+
+// ListByFee godoc
+// @Summary 获取费用的收款记录
+// @Description 获取分配到该费用的全部收款（包括已冲正的收款），按到账时间排列
+// @Tags 费用管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "费用 ID"
+// @Success 200 {object} response.Response{data=[]model.Payment} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /fees/{id}/payments [get]
+func (h *PaymentHandler) ListByFee(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	payments, err := h.paymentService.ListByFee(uint(id))
+	if err != nil {
+		response.InternalError(c, "获取收款记录失败")
+		return
+	}
+
+	response.Success(c, payments)
+}
+
+// respondPaymentError 将收款登记与冲正的业务错误转换为响应，未识别的错误返回 false
+func respondPaymentError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidPayment):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrPaymentExceedsBalance), errors.Is(err, service.ErrPaymentReversed),
+		errors.Is(err, service.ErrFeeAlreadyPaid), errors.Is(err, service.ErrFeeWaived),
+		errors.Is(err, service.ErrPaymentRefunded), errors.Is(err, service.ErrPaymentCreditUsed):
+		response.Conflict(c, err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,441 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+// PortalHandler 租户自助门户，所有数据都限定为当前登录账号关联的租户
+type PortalHandler struct {
+	tenantService      *service.TenantService
+	feeService         *service.FeeService
+	paymentService     *service.PaymentService
+	contractService    *service.ContractService
+	roomService        *service.RoomService
+	maintenanceService *service.MaintenanceService
+	accountService     *service.AccountService
+}
+
+func NewPortalHandler(
+	tenantService *service.TenantService,
+	feeService *service.FeeService,
+	paymentService *service.PaymentService,
+	contractService *service.ContractService,
+	roomService *service.RoomService,
+	maintenanceService *service.MaintenanceService,
+	accountService *service.AccountService,
+) *PortalHandler {
+	return &PortalHandler{
+		tenantService:      tenantService,
+		feeService:         feeService,
+		paymentService:     paymentService,
+		contractService:    contractService,
+		roomService:        roomService,
+		maintenanceService: maintenanceService,
+		accountService:     accountService,
+	}
+}
+
+// tenantID 返回当前账号关联的租户，未关联时直接返回 403
+func (h *PortalHandler) tenantID(c *gin.Context) (uint, bool) {
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == 0 {
+		response.Forbidden(c, "当前账号未关联租户")
+		return 0, false
+	}
+	return tenantID, true
+}
+
+// GetProfile godoc
+// @Summary 获取租户信息
+// @Description 获取当前账号关联的租户信息
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.Tenant} "获取成功"
+// @Failure 403 {object} response.Response "当前账号未关联租户"
+// @Router /portal/profile [get]
+func (h *PortalHandler) GetProfile(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantService.GetByID(tenantID)
+	if err != nil {
+		response.NotFound(c, "租户不存在")
+		return
+	}
+
+	response.Success(c, tenant)
+}
+
+// ListFees godoc
+// @Summary 获取本租户费用列表
+// @Description 分页获取当前租户的费用账单
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param feeType query string false "费用类型" Enums(rent, water, electricity, property, other)
+// @Param status query string false "状态" Enums(unpaid, paid, overdue)
+// @Param period query string false "账期 (如: 2024-03)"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /portal/fees [get]
+func (h *PortalHandler) ListFees(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.FeeListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	fees, total, err := h.feeService.List(req.Page, req.PageSize, tenantID, req.RoomNo, req.FeeType, req.Status, req.Period)
+	if err != nil {
+		response.InternalError(c, "获取费用列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(fees, total, req.Page, req.PageSize))
+}
+
+// GetFee godoc
+// @Summary 获取本租户费用详情
+// @Description 根据 ID 获取当前租户的费用详情
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "费用 ID"
+// @Success 200 {object} response.Response{data=model.Fee} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "费用记录不存在"
+// @Router /portal/fees/{id} [get]
+func (h *PortalHandler) GetFee(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	fee, err := h.feeService.GetByID(uint(id))
+	if err != nil || fee.TenantID != tenantID {
+		response.NotFound(c, "费用记录不存在")
+		return
+	}
+
+	response.Success(c, fee)
+}
+
+// ListPayments godoc
+// @Summary 获取本租户缴费记录
+// @Description 分页获取当前租户的收款记录及其分配到各费用的明细，按到账时间倒序，包括部分缴费和已冲正的收款
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态" Enums(completed, reversed)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /portal/payments [get]
+func (h *PortalHandler) ListPayments(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PaymentListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	payments, total, err := h.paymentService.List(req.Page, req.PageSize, tenantID, "", req.Status, nil, nil)
+	if err != nil {
+		response.InternalError(c, "获取缴费记录失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(payments, total, req.Page, req.PageSize))
+}
+
+// GetStatement godoc
+// @Summary 获取本租户对账单
+// @Description 获取当前租户指定区间内的对账单，包括期初余额、逐笔往来与期末余额
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "开始日期 (YYYY-MM-DD)"
+// @Param to query string false "截止日期 (YYYY-MM-DD，含当天)"
+// @Success 200 {object} response.Response{data=service.Statement} "获取成功"
+// @Failure 400 {object} response.Response "日期格式错误"
+// @Failure 403 {object} response.Response "当前账号未关联租户"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /portal/statement [get]
+func (h *PortalHandler) GetStatement(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+	respondStatement(c, h.accountService, tenantID)
+}
+
+// ListContracts godoc
+// @Summary 获取本租户合同
+// @Description 获取当前租户的全部合同
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.Contract} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /portal/contracts [get]
+func (h *PortalHandler) ListContracts(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	contracts, err := h.contractService.ListByTenant(tenantID)
+	if err != nil {
+		response.InternalError(c, "获取合同列表失败")
+		return
+	}
+
+	response.Success(c, contracts)
+}
+
+// GetContract godoc
+// @Summary 获取本租户合同详情
+// @Description 根据 ID 获取当前租户的合同详情
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "合同 ID"
+// @Success 200 {object} response.Response{data=model.Contract} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "合同不存在"
+// @Router /portal/contracts/{id} [get]
+func (h *PortalHandler) GetContract(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	contract, err := h.contractService.GetByID(uint(id))
+	if err != nil || contract.TenantID != tenantID {
+		response.NotFound(c, "合同不存在")
+		return
+	}
+
+	response.Success(c, contract)
+}
+
+// ListRooms godoc
+// @Summary 获取本租户房间
+// @Description 获取当前租户租用的房间
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.Room} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /portal/rooms [get]
+func (h *PortalHandler) ListRooms(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	rooms, err := h.roomService.ListByTenant(tenantID)
+	if err != nil {
+		response.InternalError(c, "获取房间列表失败")
+		return
+	}
+
+	response.Success(c, rooms)
+}
+
+// ListMaintenance godoc
+// @Summary 获取本租户维修工单
+// @Description 分页获取当前租户提交的维修工单
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态" Enums(pending, processing, completed, cancelled)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /portal/maintenance [get]
+func (h *PortalHandler) ListMaintenance(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MaintenanceListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	maintenances, total, err := h.maintenanceService.List(req.Page, req.PageSize, tenantID, req.Keyword, req.Type, req.Status, req.Priority)
+	if err != nil {
+		response.InternalError(c, "获取维修工单列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(maintenances, total, req.Page, req.PageSize))
+}
+
+// GetMaintenance godoc
+// @Summary 获取本租户维修工单详情
+// @Description 根据 ID 获取当前租户的维修工单详情
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "工单 ID"
+// @Success 200 {object} response.Response{data=model.Maintenance} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "维修工单不存在"
+// @Router /portal/maintenance/{id} [get]
+func (h *PortalHandler) GetMaintenance(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	maintenance, err := h.maintenanceService.GetByID(uint(id))
+	if err != nil || maintenance.TenantID != tenantID {
+		response.NotFound(c, "维修工单不存在")
+		return
+	}
+
+	response.Success(c, maintenance)
+}
+
+// CreateMaintenance godoc
+// @Summary 提交维修申请
+// @Description 当前租户提交维修申请，房间号必须是本租户租用的房间
+// @Tags 租户门户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PortalMaintenanceRequest true "维修申请"
+// @Success 200 {object} response.Response{data=model.Maintenance} "提交成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "提交失败"
+// @Router /portal/maintenance [post]
+func (h *PortalHandler) CreateMaintenance(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PortalMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.RoomNo != "" {
+		rooms, err := h.roomService.ListByTenant(tenantID)
+		if err != nil {
+			response.InternalError(c, "提交维修申请失败")
+			return
+		}
+		if !containsRoom(rooms, req.RoomNo) {
+			response.BadRequest(c, "房间不属于当前租户")
+			return
+		}
+	}
+
+	maintenance := &model.Maintenance{
+		TenantID:    tenantID,
+		RoomNo:      req.RoomNo,
+		Type:        req.Type,
+		Description: req.Description,
+		Priority:    req.Priority,
+		Status:      "pending",
+	}
+
+	if maintenance.Priority == "" {
+		maintenance.Priority = "medium"
+	}
+
+	if err := h.maintenanceService.Create(maintenance, currentActor(c)); err != nil {
+		response.InternalError(c, "提交维修申请失败")
+		return
+	}
+
+	response.Success(c, maintenance)
+}
+
+func containsRoom(rooms []model.Room, roomNo string) bool {
+	for _, room := range rooms {
+		if room.RoomNo == roomNo {
+			return true
+		}
+	}
+	return false
+}
@@ -6,10 +6,22 @@ import (
 
 var ProviderSet = wire.NewSet(
 	NewAuthHandler,
+	NewUserHandler,
+	NewMFAHandler,
+	NewKeyHandler,
+	NewAPIKeyHandler,
+	NewAuditHandler,
 	NewTenantHandler,
 	NewContractHandler,
 	NewRoomHandler,
 	NewFeeHandler,
 	NewMaintenanceHandler,
 	NewReportHandler,
+	NewPortalHandler,
+	NewNotificationHandler,
+	NewBillingHandler,
+	NewPaymentHandler,
+	NewInvoiceHandler,
+	NewReceiptHandler,
+	NewAccountHandler,
 )
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type ReceiptHandler struct {
+	receiptService *service.ReceiptService
+}
+
+func NewReceiptHandler(receiptService *service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+// List godoc
+// @Summary 获取收据列表
+// @Description 分页获取收款收据，按开具顺序倒序
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param tenantId query int false "租户 ID"
+// @Param status query string false "状态" Enums(valid, void)
+// @Param receiptNo query string false "收据编号"
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /receipts [get]
+func (h *ReceiptHandler) List(c *gin.Context) {
+	var req dto.ReceiptListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	receipts, total, err := h.receiptService.List(req.Page, req.PageSize, req.TenantID, req.Status, req.ReceiptNo)
+	if err != nil {
+		response.InternalError(c, "获取收据列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(receipts, total, req.Page, req.PageSize))
+}
+
+// GetByID godoc
+// @Summary 获取收据详情
+// @Description 获取收据的编号、状态与打印次数
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "收据 ID"
+// @Success 200 {object} response.Response{data=model.Receipt} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "收据不存在"
+// @Router /receipts/{id} [get]
+func (h *ReceiptHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	receipt, err := h.receiptService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "收据不存在")
+		return
+	}
+
+	response.Success(c, receipt)
+}
+
+// GetByPayment godoc
+// @Summary 获取收款的收据
+// @Description 获取登记收款时开具的收据
+// @Tags 收款管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "收款 ID"
+// @Success 200 {object} response.Response{data=model.Receipt} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "收据不存在"
+// @Router /payments/{id}/receipt [get]
+func (h *ReceiptHandler) GetByPayment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	receipt, err := h.receiptService.GetByPayment(uint(id))
+	if err != nil {
+		response.NotFound(c, "收据不存在")
+		return
+	}
+
+	response.Success(c, receipt)
+}
+
+// PDF godoc
+// @Summary 下载收据 PDF
+// @Description 生成收据 PDF，包含租户、房间、费用类型、账期、金额大小写与收款人。
+// @Description 每次下载计为一次打印，第二次起标题注明补打；收款冲正后收据作废，标题注明已作废
+// @Tags 收款管理
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "收据 ID"
+// @Success 200 {file} file "PDF 文件"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "收据不存在"
+// @Router /receipts/{id}/pdf [get]
+func (h *ReceiptHandler) PDF(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	receipt, data, err := h.receiptService.RenderPDF(uint(id))
+	if err != nil {
+		response.NotFound(c, "收据不存在")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, receipt.ReceiptNo))
+	c.Data(200, "application/pdf", data)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -68,6 +69,7 @@ func (h *RoomHandler) List(c *gin.Context) {
 // @Success 200 {object} response.Response{data=model.Room} "获取成功"
 // @Failure 400 {object} response.Response "无效的 ID"
 // @Failure 404 {object} response.Response "房间不存在"
+// @Header 200 {string} ETag "记录版本号，更新时通过 If-Match 回传"
 // @Router /rooms/{id} [get]
 func (h *RoomHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -82,6 +84,7 @@ func (h *RoomHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, room.Version)
 	response.Success(c, room)
 }
 
@@ -117,11 +120,12 @@ func (h *RoomHandler) Create(c *gin.Context) {
 		room.Status = "vacant"
 	}
 
-	if err := h.roomService.Create(room); err != nil {
+	if err := h.roomService.Create(room, currentActor(c)); err != nil {
 		response.InternalError(c, "创建房间失败")
 		return
 	}
 
+	setETag(c, room.Version)
 	response.Success(c, room)
 }
 
@@ -133,11 +137,16 @@ func (h *RoomHandler) Create(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "房间 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
 // @Param request body dto.UpdateRoomRequest true "更新房间请求"
 // @Success 200 {object} response.Response{data=model.Room} "更新成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 404 {object} response.Response "房间不存在"
+// @Failure 409 {object} response.Response{data=model.Room} "保存时数据已被他人修改，返回当前数据"
+// @Failure 412 {object} response.Response{data=model.Room} "If-Match 与当前版本不一致，返回当前数据"
+// @Failure 428 {object} response.Response "未携带 If-Match（开启 require_if_match 时）"
 // @Failure 500 {object} response.Response "更新失败"
+// @Header 200 {string} ETag "更新后的版本号"
 // @Router /rooms/{id} [put]
 func (h *RoomHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -152,6 +161,10 @@ func (h *RoomHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, room.Version, room) {
+		return
+	}
+
 	var req dto.UpdateRoomRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "请求参数错误")
@@ -177,11 +190,18 @@ func (h *RoomHandler) Update(c *gin.Context) {
 		room.Status = req.Status
 	}
 
-	if err := h.roomService.Update(room); err != nil {
+	err = h.roomService.Update(room, currentActor(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.roomService.GetByID(room.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "更新房间失败")
 		return
 	}
 
+	setETag(c, room.Version)
 	response.Success(c, room)
 }
 
@@ -195,6 +215,8 @@ func (h *RoomHandler) Update(c *gin.Context) {
 // @Param id path int true "房间 ID"
 // @Success 200 {object} response.Response "删除成功"
 // @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "房间不存在"
+// @Failure 409 {object} response.Response "房间仍有生效合同"
 // @Failure 500 {object} response.Response "删除失败"
 // @Router /rooms/{id} [delete]
 func (h *RoomHandler) Delete(c *gin.Context) {
@@ -204,7 +226,18 @@ func (h *RoomHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.roomService.Delete(uint(id)); err != nil {
+	room, err := h.roomService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "房间不存在")
+		return
+	}
+
+	err = h.roomService.Delete(room.ID, currentActor(c))
+	if errors.Is(err, service.ErrRoomUnderLease) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "删除房间失败")
 		return
 	}
@@ -237,10 +270,131 @@ func (h *RoomHandler) AssignTenant(c *gin.Context) {
 		return
 	}
 
-	if err := h.roomService.AssignTenant(uint(id), req.TenantID); err != nil {
+	room, err := h.roomService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "房间不存在")
+		return
+	}
+
+	if err := h.roomService.AssignTenant(room.ID, req.TenantID, currentActor(c)); err != nil {
 		response.Error(c, 400, err.Error())
 		return
 	}
 
 	response.Success(c, nil)
 }
+
+// Trash godoc
+// @Summary 获取房间回收站
+// @Description 分页获取已删除的房间
+// @Tags 房间管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /rooms/trash [get]
+func (h *RoomHandler) Trash(c *gin.Context) {
+	var req dto.TrashListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	rooms, total, err := h.roomService.ListTrash(req.Page, req.PageSize)
+	if err != nil {
+		response.InternalError(c, "获取房间回收站失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(rooms, total, req.Page, req.PageSize))
+}
+
+// Restore godoc
+// @Summary 恢复房间
+// @Description 从回收站恢复已删除的房间
+// @Tags 房间管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "房间 ID"
+// @Success 200 {object} response.Response{data=model.Room} "恢复成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该房间"
+// @Failure 409 {object} response.Response "所属租户已删除"
+// @Failure 500 {object} response.Response "恢复失败"
+// @Router /rooms/{id}/restore [post]
+func (h *RoomHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	before, err := h.roomService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该房间")
+		return
+	}
+
+	room, err := h.roomService.Restore(before.ID, currentActor(c))
+	if errors.Is(err, service.ErrTenantDeleted) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "恢复房间失败")
+		return
+	}
+
+	response.Success(c, room)
+}
+
+// Purge godoc
+// @Summary 永久删除房间
+// @Description 永久删除回收站中的房间，不可恢复
+// @Tags 房间管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "房间 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该房间"
+// @Failure 409 {object} response.Response "仍有合同关联该房间"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /rooms/{id}/purge [delete]
+func (h *RoomHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	room, err := h.roomService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该房间")
+		return
+	}
+
+	err = h.roomService.Purge(room.ID, currentActor(c))
+	if errors.Is(err, service.ErrRoomReferenced) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "永久删除房间失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
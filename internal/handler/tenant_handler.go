@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -67,6 +68,7 @@ func (h *TenantHandler) List(c *gin.Context) {
 // @Success 200 {object} response.Response{data=model.Tenant} "获取成功"
 // @Failure 400 {object} response.Response "无效的 ID"
 // @Failure 404 {object} response.Response "租户不存在"
+// @Header 200 {string} ETag "记录版本号，更新时通过 If-Match 回传"
 // @Router /tenants/{id} [get]
 func (h *TenantHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -81,6 +83,7 @@ func (h *TenantHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, tenant.Version)
 	response.Success(c, tenant)
 }
 
@@ -115,11 +118,12 @@ func (h *TenantHandler) Create(c *gin.Context) {
 		tenant.Status = "active"
 	}
 
-	if err := h.tenantService.Create(tenant); err != nil {
+	if err := h.tenantService.Create(tenant, currentActor(c)); err != nil {
 		response.InternalError(c, "创建租户失败")
 		return
 	}
 
+	setETag(c, tenant.Version)
 	response.Success(c, tenant)
 }
 
@@ -131,11 +135,16 @@ func (h *TenantHandler) Create(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "租户 ID"
+// @Param If-Match header string false "读取时返回的 ETag"
 // @Param request body dto.UpdateTenantRequest true "更新租户请求"
 // @Success 200 {object} response.Response{data=model.Tenant} "更新成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 404 {object} response.Response "租户不存在"
+// @Failure 409 {object} response.Response{data=model.Tenant} "保存时数据已被他人修改，返回当前数据"
+// @Failure 412 {object} response.Response{data=model.Tenant} "If-Match 与当前版本不一致，返回当前数据"
+// @Failure 428 {object} response.Response "未携带 If-Match（开启 require_if_match 时）"
 // @Failure 500 {object} response.Response "更新失败"
+// @Header 200 {string} ETag "更新后的版本号"
 // @Router /tenants/{id} [put]
 func (h *TenantHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -150,6 +159,10 @@ func (h *TenantHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, tenant.Version, tenant) {
+		return
+	}
+
 	var req dto.UpdateTenantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, "请求参数错误")
@@ -172,17 +185,24 @@ func (h *TenantHandler) Update(c *gin.Context) {
 		tenant.Status = req.Status
 	}
 
-	if err := h.tenantService.Update(tenant); err != nil {
+	err = h.tenantService.Update(tenant, currentActor(c))
+	if errors.Is(err, service.ErrVersionConflict) {
+		current, _ := h.tenantService.GetByID(tenant.ID)
+		response.ConflictWithData(c, err.Error(), current)
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "更新租户失败")
 		return
 	}
 
+	setETag(c, tenant.Version)
 	response.Success(c, tenant)
 }
 
 // Delete godoc
 // @Summary 删除租户
-// @Description 删除指定租户
+// @Description 将指定租户移入回收站，仍有生效合同、在租房间或未缴费用时拒绝删除
 // @Tags 租户管理
 // @Accept json
 // @Produce json
@@ -190,6 +210,8 @@ func (h *TenantHandler) Update(c *gin.Context) {
 // @Param id path int true "租户 ID"
 // @Success 200 {object} response.Response "删除成功"
 // @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "租户不存在"
+// @Failure 409 {object} response.Response "租户仍有生效合同、在租房间或未缴费用"
 // @Failure 500 {object} response.Response "删除失败"
 // @Router /tenants/{id} [delete]
 func (h *TenantHandler) Delete(c *gin.Context) {
@@ -199,10 +221,131 @@ func (h *TenantHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.tenantService.Delete(uint(id)); err != nil {
+	tenant, err := h.tenantService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "租户不存在")
+		return
+	}
+
+	err = h.tenantService.Delete(tenant.ID, currentActor(c))
+	if errors.Is(err, service.ErrTenantInUse) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
 		response.InternalError(c, "删除租户失败")
 		return
 	}
 
 	response.Success(c, nil)
 }
+
+// Trash godoc
+// @Summary 获取租户回收站
+// @Description 分页获取已删除的租户
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /tenants/trash [get]
+func (h *TenantHandler) Trash(c *gin.Context) {
+	var req dto.TrashListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	tenants, total, err := h.tenantService.ListTrash(req.Page, req.PageSize)
+	if err != nil {
+		response.InternalError(c, "获取租户回收站失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(tenants, total, req.Page, req.PageSize))
+}
+
+// Restore godoc
+// @Summary 恢复租户
+// @Description 从回收站恢复已删除的租户
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "租户 ID"
+// @Success 200 {object} response.Response{data=model.Tenant} "恢复成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该租户"
+// @Failure 500 {object} response.Response "恢复失败"
+// @Router /tenants/{id}/restore [post]
+func (h *TenantHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	before, err := h.tenantService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该租户")
+		return
+	}
+
+	tenant, err := h.tenantService.Restore(before.ID, currentActor(c))
+	if err != nil {
+		response.InternalError(c, "恢复租户失败")
+		return
+	}
+
+	response.Success(c, tenant)
+}
+
+// Purge godoc
+// @Summary 永久删除租户
+// @Description 永久删除回收站中的租户，不可恢复
+// @Tags 租户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "租户 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "回收站中不存在该租户"
+// @Failure 409 {object} response.Response "仍有记录关联该租户"
+// @Failure 500 {object} response.Response "删除失败"
+// @Router /tenants/{id}/purge [delete]
+func (h *TenantHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	tenant, err := h.tenantService.GetTrashedByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "回收站中不存在该租户")
+		return
+	}
+
+	err = h.tenantService.Purge(tenant.ID, currentActor(c))
+	if errors.Is(err, service.ErrTenantReferenced) {
+		response.Conflict(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalError(c, "永久删除租户失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
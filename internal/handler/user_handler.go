@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/dto"
+	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// List godoc
+// @Summary 获取用户列表
+// @Description 分页获取用户列表，支持关键字搜索、角色和状态筛选
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "搜索关键字"
+// @Param role query string false "角色筛选" Enums(admin, user)
+// @Param status query string false "状态筛选" Enums(active, disabled)
+// @Success 200 {object} response.Response{data=dto.PageResult} "获取成功"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users [get]
+func (h *UserHandler) List(c *gin.Context) {
+	var req dto.UserListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	users, total, err := h.userService.List(req.Page, req.PageSize, req.Keyword, req.Role, req.Status)
+	if err != nil {
+		response.InternalError(c, "获取用户列表失败")
+		return
+	}
+
+	response.Success(c, dto.NewPageResult(users, total, req.Page, req.PageSize))
+}
+
+// GetByID godoc
+// @Summary 获取用户详情
+// @Description 根据 ID 获取用户详细信息
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} response.Response{data=model.User} "获取成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /users/{id} [get]
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	user, err := h.userService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// Create godoc
+// @Summary 创建用户
+// @Description 创建新的用户账号并分配角色与权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateUserRequest true "创建用户请求"
+// @Success 200 {object} response.Response{data=model.User} "创建成功"
+// @Failure 400 {object} response.Response "请求参数错误或用户名已存在"
+// @Router /users [post]
+func (h *UserHandler) Create(c *gin.Context) {
+	var req dto.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	user := &model.User{
+		Username:    req.Username,
+		Nickname:    req.Nickname,
+		Avatar:      req.Avatar,
+		Role:        req.Role,
+		Permissions: req.Permissions,
+		TenantID:    req.TenantID,
+	}
+
+	if err := h.userService.Create(user, req.Password); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// Update godoc
+// @Summary 更新用户
+// @Description 更新用户信息、角色与权限
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Param request body dto.UpdateUserRequest true "更新用户请求"
+// @Success 200 {object} response.Response{data=model.User} "更新成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /users/{id} [put]
+func (h *UserHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	user, err := h.userService.GetByID(uint(id))
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	var req dto.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if req.Nickname != "" {
+		user.Nickname = req.Nickname
+	}
+	if req.Avatar != "" {
+		user.Avatar = req.Avatar
+	}
+	if req.Role != "" {
+		user.Role = req.Role
+	}
+	if req.Permissions != nil {
+		user.Permissions = req.Permissions
+	}
+	if req.TenantID != nil {
+		user.TenantID = req.TenantID
+	}
+
+	if err := h.userService.Update(user); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// Delete godoc
+// @Summary 删除用户
+// @Description 删除指定用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Router /users/{id} [delete]
+func (h *UserHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	if err := h.userService.Delete(middleware.GetUserID(c), uint(id)); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// UpdateStatus godoc
+// @Summary 启用/禁用用户
+// @Description 启用或禁用指定用户账号
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Param request body dto.UpdateUserStatusRequest true "状态请求"
+// @Success 200 {object} response.Response "操作成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /users/{id}/status [put]
+func (h *UserHandler) UpdateStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	var req dto.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := h.userService.SetStatus(middleware.GetUserID(c), uint(id), req.Status); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Unlock godoc
+// @Summary 解锁用户
+// @Description 解除用户因连续登录失败产生的锁定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} response.Response "解锁成功"
+// @Failure 400 {object} response.Response "无效的 ID"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的 ID")
+		return
+	}
+
+	if err := h.userService.Unlock(uint(id)); err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ChangePassword godoc
+// @Summary 修改密码
+// @Description 当前用户修改自己的密码，需提供原密码
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ChangePasswordRequest true "修改密码请求"
+// @Success 200 {object} response.Response "修改成功"
+// @Failure 400 {object} response.Response "请求参数错误或原密码错误"
+// @Router /auth/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := h.userService.ChangePassword(middleware.GetUserID(c), req.OldPassword, req.NewPassword); err != nil {
+		response.Error(c, 400, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -2,16 +2,39 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
-	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
 	"yuxialuozi_graduation_design_backend/pkg/response"
 	"yuxialuozi_graduation_design_backend/pkg/utils"
 )
 
-func JWTAuth(cfg *config.Config) gin.HandlerFunc {
+// APIKeyAuthenticator 校验 X-API-Key 请求头中的明文密钥与来源 IP
+type APIKeyAuthenticator func(rawKey, ip string) (*model.APIKey, error)
+
+// JWTAuth 校验 Authorization: Bearer 访问令牌；请求携带 X-API-Key 时改为校验 API 密钥，
+// 密钥的权限范围写入上下文，由 Authorize 与所属用户权限取交集。
+func JWTAuth(keyLookup utils.KeyLookup, tokenRepo repository.TokenRepository, apiKeyAuth APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader("X-API-Key"); rawKey != "" {
+			apiKey, err := apiKeyAuth(rawKey, c.ClientIP())
+			if err != nil {
+				response.Unauthorized(c, err.Error())
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", apiKey.UserID)
+			c.Set("apiKeyID", apiKey.ID)
+			c.Set("apiKeyScopes", []string(apiKey.Permissions))
+
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			response.Unauthorized(c, "请先登录")
@@ -26,16 +49,25 @@ func JWTAuth(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.ParseToken(parts[1], cfg.JWT.Secret)
-		if err != nil {
+		claims, err := utils.ParseToken(parts[1], keyLookup)
+		if err != nil || claims.Purpose != "" {
 			response.Unauthorized(c, "无效的 token")
 			c.Abort()
 			return
 		}
 
+		revoked, err := tokenRepo.IsAccessTokenRevoked(claims.ID)
+		if err != nil || revoked {
+			response.Unauthorized(c, "token 已失效")
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("tokenID", claims.ID)
+		c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
 
 		c.Next()
 	}
@@ -64,3 +96,28 @@ func GetRole(c *gin.Context) string {
 	}
 	return role.(string)
 }
+
+func GetTokenID(c *gin.Context) string {
+	tokenID, exists := c.Get("tokenID")
+	if !exists {
+		return ""
+	}
+	return tokenID.(string)
+}
+
+func GetTokenExpiresAt(c *gin.Context) time.Time {
+	expiresAt, exists := c.Get("tokenExpiresAt")
+	if !exists {
+		return time.Time{}
+	}
+	return expiresAt.(time.Time)
+}
+
+// GetAPIKeyID 返回本次请求使用的 API 密钥 ID，使用访问令牌认证时返回 0
+func GetAPIKeyID(c *gin.Context) uint {
+	apiKeyID, exists := c.Get("apiKeyID")
+	if !exists {
+		return 0
+	}
+	return apiKeyID.(uint)
+}
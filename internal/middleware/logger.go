@@ -28,6 +28,7 @@ func Logger() gin.HandlerFunc {
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
 			zap.String("client_ip", c.ClientIP()),
+			zap.String("request_id", GetRequestID(c)),
 		)
 	}
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/pkg/response"
+)
+
+// RequireIfMatch 开启 server.require_if_match 后，PUT 请求必须携带 If-Match，否则返回 428
+func RequireIfMatch(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.Server.RequireIfMatch && c.Request.Method == http.MethodPut && c.GetHeader("If-Match") == "" {
+			response.PreconditionRequired(c, "请携带 If-Match 请求头")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
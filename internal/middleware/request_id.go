@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID 为每个请求分配请求 ID，优先沿用上游传入的 X-Request-ID，并回写到响应头
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID, _ = utils.GenerateRandomToken(16)
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+func GetRequestID(c *gin.Context) string {
+	requestID, exists := c.Get("requestID")
+	if !exists {
+		return ""
+	}
+	return requestID.(string)
+}
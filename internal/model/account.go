@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// CreditNote 给予租户的贷项，如减免租金、多收退还前的挂账，编号如 CR-2026-000001。
+// 贷项计入租户账户余额，开具后自动抵扣未结清的费用；FeeID 为所针对的费用，开具时优先抵扣该费用。
+// InvoiceID 为对应的红字发票：费用已开具发票时贷项随之开具红字发票，红字发票也会为其中的费用开具贷项；
+// 红字发票作废时其贷项一并作废，作废的贷项不再计入账户余额
+type CreditNote struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	CreditNo   string     `gorm:"size:30;not null;uniqueIndex" json:"creditNo"`
+	TenantID   uint       `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant     `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string     `gorm:"-" json:"tenantName"`
+	FeeID      *uint      `gorm:"index" json:"feeId"`
+	Amount     float64    `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Reason     string     `gorm:"size:500;not null" json:"reason"`
+	IssuedAt   time.Time  `gorm:"not null" json:"issuedAt"`
+	IssuedBy   string     `gorm:"size:50" json:"issuedBy"`
+	InvoiceID  *uint      `gorm:"index" json:"invoiceId"`
+	VoidedAt   *time.Time `json:"voidedAt"`
+	VoidedBy   string     `gorm:"size:50" json:"voidedBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (CreditNote) TableName() string {
+	return "credit_notes"
+}
+
+// Refund 从租户账户余额中退还给租户的款项，编号如 RF-2026-000001。
+// PaymentID 为原路退回的收款，同一收款的退款合计不超过收款金额
+type Refund struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	RefundNo   string    `gorm:"size:30;not null;uniqueIndex" json:"refundNo"`
+	TenantID   uint      `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant    `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string    `gorm:"-" json:"tenantName"`
+	PaymentID  *uint     `gorm:"index" json:"paymentId"`
+	Amount     float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Method     string    `gorm:"size:20;not null" json:"method"`
+	Reference  string    `gorm:"size:100" json:"reference"`
+	Reason     string    `gorm:"size:500;not null" json:"reason"`
+	RefundedAt time.Time `gorm:"not null;index" json:"refundedAt"`
+	// OperatorID、OperatorName 为登记退款的账号
+	OperatorID   uint      `json:"operatorId"`
+	OperatorName string    `gorm:"size:50" json:"operatorName"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// CreditApplication 账户余额抵扣一笔费用的记录，抵扣金额计入费用的已收金额。
+// 账户余额 = 未作废的贷项合计 + 未冲正收款转入的金额 - 退款合计 - 抵扣合计
+type CreditApplication struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"not null;index" json:"tenantId"`
+	FeeID     uint      `gorm:"not null;index" json:"feeId"`
+	Amount    float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	AppliedAt time.Time `gorm:"not null" json:"appliedAt"`
+	AppliedBy string    `gorm:"size:50" json:"appliedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (CreditApplication) TableName() string {
+	return "credit_applications"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APIKey 供外部系统调用接口的 API 密钥，归属于某个用户。
+// 明文形如 tk_<Prefix>_<secret>，仅在创建时返回一次，数据库只保存哈希值；
+// Prefix 用于识别与查找密钥。Permissions 为密钥的权限范围，实际生效的是其与所属用户权限的交集。
+type APIKey struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UserID      uint           `gorm:"not null;index" json:"userId"`
+	Name        string         `gorm:"size:100;not null" json:"name"`
+	Prefix      string         `gorm:"uniqueIndex;size:16;not null" json:"prefix"`
+	KeyHash     string         `gorm:"size:64;not null" json:"-"`
+	Permissions pq.StringArray `gorm:"type:text[]" json:"permissions" swaggertype:"array,string"`
+	// AllowedIPs 允许调用的来源 IP 或 CIDR，为空表示不限制
+	AllowedIPs pq.StringArray `gorm:"type:text[]" json:"allowedIps" swaggertype:"array,string"`
+	ExpiresAt  *time.Time     `json:"expiresAt"`
+	LastUsedAt *time.Time     `json:"lastUsedAt"`
+	LastUsedIP string         `gorm:"size:64" json:"lastUsedIp"`
+	RevokedAt  *time.Time     `json:"revokedAt"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
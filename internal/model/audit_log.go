@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+const (
+	AuditEntityTenant      = "tenant"
+	AuditEntityContract    = "contract"
+	AuditEntityRoom        = "room"
+	AuditEntityFee         = "fee"
+	AuditEntityPayment     = "payment"
+	AuditEntityInvoice     = "invoice"
+	AuditEntityCreditNote  = "credit_note"
+	AuditEntityRefund      = "refund"
+	AuditEntityMaintenance = "maintenance"
+
+	AuditActionCreate     = "create"
+	AuditActionUpdate     = "update"
+	AuditActionDelete     = "delete"
+	AuditActionAssign     = "assign"
+	AuditActionPay        = "pay"
+	AuditActionComplete   = "complete"
+	AuditActionRestore    = "restore"
+	AuditActionPurge      = "purge"
+	AuditActionTransition = "transition"
+	AuditActionReverse    = "reverse"
+	AuditActionWaive      = "waive"
+	AuditActionIssue      = "issue"
+	AuditActionVoid       = "void"
+	AuditActionCredit     = "credit"
+	AuditActionOverdue    = "overdue"
+	AuditActionAccrue     = "accrue"
+)
+
+// AuditLog 数据变更审计记录。
+// Before / After 为变更前后的字段值：创建只有 After，删除只有 Before，更新只保留发生变化的字段。
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorID    uint      `gorm:"index" json:"actorId"`
+	ActorName  string    `gorm:"size:50" json:"actorName"`
+	APIKeyID   *uint     `json:"apiKeyId"`
+	EntityType string    `gorm:"size:30;index:idx_audit_logs_entity,priority:1;not null" json:"entityType"`
+	EntityID   uint      `gorm:"index:idx_audit_logs_entity,priority:2;not null" json:"entityId"`
+	Action     string    `gorm:"size:20;index;not null" json:"action"`
+	Before     JSON      `gorm:"type:jsonb" json:"before" swaggertype:"object"`
+	After      JSON      `gorm:"type:jsonb" json:"after" swaggertype:"object"`
+	IP         string    `gorm:"size:64" json:"ip"`
+	RequestID  string    `gorm:"size:64;index" json:"requestId"`
+	CreatedAt  time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// JSON 以原始 JSON 存取的 jsonb 字段
+type JSON []byte
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return errors.New("unsupported type for JSON column")
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[:0], data...)
+	return nil
+}
@@ -2,22 +2,251 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// 合同状态
+const (
+	ContractStatusDraft           = "draft"
+	ContractStatusPendingApproval = "pending_approval"
+	ContractStatusActive          = "active"
+	ContractStatusExpiring        = "expiring"
+	ContractStatusExpired         = "expired"
+	ContractStatusTerminated      = "terminated"
+	ContractStatusRenewed         = "renewed"
 )
 
+// ContractTransitions 合同生命周期中允许的状态流转，terminated 与 renewed 为终态：
+//
+//	draft → pending_approval → active → expiring → expired / terminated / renewed
+//
+// 审批可退回草稿；草稿与待审批合同可直接终止（作废）；即将到期的合同延期后可恢复为生效。
+// 空字符串对应新建合同，只能以草稿或待审批状态创建。
+var ContractTransitions = map[string][]string{
+	"":                            {ContractStatusDraft, ContractStatusPendingApproval},
+	ContractStatusDraft:           {ContractStatusPendingApproval, ContractStatusTerminated},
+	ContractStatusPendingApproval: {ContractStatusActive, ContractStatusDraft, ContractStatusTerminated},
+	ContractStatusActive:          {ContractStatusExpiring, ContractStatusExpired, ContractStatusTerminated, ContractStatusRenewed},
+	ContractStatusExpiring:        {ContractStatusActive, ContractStatusExpired, ContractStatusTerminated, ContractStatusRenewed},
+	ContractStatusExpired:         {ContractStatusRenewed},
+}
+
+// CanTransitionContract 判断合同能否从 from 状态变更为 to 状态
+func CanTransitionContract(from, to string) bool {
+	for _, s := range ContractTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OccupyingContractStatuses 处于这些状态的合同占用其租赁的房间
+var OccupyingContractStatuses = []string{ContractStatusActive, ContractStatusExpiring}
+
+// ContractOccupiesRooms 判断该状态的合同是否占用房间
+func ContractOccupiesRooms(status string) bool {
+	for _, s := range OccupyingContractStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// 租金递增方式
+const (
+	EscalationNone    = "none"
+	EscalationPercent = "percent"
+	EscalationAmount  = "amount"
+	EscalationCPI     = "cpi"
+)
+
+// 租金递增时机：renewal 仅在续签时递增，anniversary 在续签时以及租期内每个周年日递增
+const (
+	EscalationOnRenewal     = "renewal"
+	EscalationOnAnniversary = "anniversary"
+)
+
+// ContractEscalation 租金递增条款。Value 按 Type 解释：percent 为百分比，amount 为每间房间月租金的增加额，
+// cpi 为在上一年 CPI 涨幅之上额外增加的百分比。AppliedYears 为已执行的周年递增次数
+type ContractEscalation struct {
+	Type         string  `gorm:"size:20;not null;default:'none'" json:"type"`
+	Value        float64 `gorm:"type:decimal(10,2);not null;default:0" json:"value"`
+	Timing       string  `gorm:"size:20;not null;default:'renewal'" json:"timing"`
+	AppliedYears int     `gorm:"not null;default:0" json:"appliedYears"`
+}
+
+// Enabled 判断是否约定了租金递增
+func (e ContractEscalation) Enabled() bool {
+	return e.Type != "" && e.Type != EscalationNone
+}
+
+// BillableContractStatuses 账单生成时计租的合同状态。已到期、已续签的合同仍需补齐租期内
+// 尚未生成的租金（如后付的最后一期）；生效后终止的合同计租到终止日，未生效即作废的合同不计租
+var BillableContractStatuses = []string{ContractStatusActive, ContractStatusExpiring, ContractStatusExpired, ContractStatusRenewed, ContractStatusTerminated}
+
+// 计费周期
+const (
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+)
+
+// 收租方式：advance 在计费周期开始的月份收取（先付后租），arrears 在计费周期结束后的下一个月收取
+const (
+	BillingInAdvance = "advance"
+	BillingInArrears = "arrears"
+)
+
+// ContractBilling 计费条款。季度按自然季度划分；DueDay 为账单所在月份的缴费日，取 1 到 28
+type ContractBilling struct {
+	Cycle  string `gorm:"size:20;not null;default:'monthly'" json:"cycle"`
+	DueDay int    `gorm:"not null;default:1" json:"dueDay"`
+	Mode   string `gorm:"size:20;not null;default:'advance'" json:"mode"`
+}
+
 type Contract struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	TenantID   uint      `gorm:"not null;index" json:"tenantId"`
-	Tenant     Tenant    `gorm:"foreignKey:TenantID" json:"-"`
-	TenantName string    `gorm:"-" json:"tenantName"`
-	ContractNo string    `gorm:"uniqueIndex;size:50;not null" json:"contractNo"`
-	StartDate  time.Time `json:"startDate"`
-	EndDate    time.Time `json:"endDate"`
-	Amount     float64   `gorm:"type:decimal(10,2)" json:"amount"`
-	Status     string    `gorm:"size:20;default:'draft'" json:"status"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	ID         uint               `gorm:"primaryKey" json:"id"`
+	TenantID   uint               `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant             `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string             `gorm:"-" json:"tenantName"`
+	ContractNo string             `gorm:"uniqueIndex;size:50;not null" json:"contractNo"`
+	StartDate  time.Time          `json:"startDate"`
+	EndDate    time.Time          `json:"endDate"`
+	Amount     float64            `gorm:"type:decimal(10,2)" json:"amount"`
+	Status     string             `gorm:"size:20;default:'draft'" json:"status"`
+	Rooms      []ContractRoom     `gorm:"foreignKey:ContractID" json:"rooms"`
+	Escalation ContractEscalation `gorm:"embedded;embeddedPrefix:escalation_" json:"escalation"`
+	Billing    ContractBilling    `gorm:"embedded;embeddedPrefix:billing_" json:"billing"`
+	// PredecessorID 续签时指向被续签的合同
+	PredecessorID *uint `gorm:"index" json:"predecessorId"`
+	// TerminatedAt 生效中的合同提前终止的时间，终止当天为租期的最后一天；草稿、待审批合同作废时为空
+	TerminatedAt *time.Time `json:"terminatedAt"`
+	// RenewalChain 续签链，从最早的合同到最新的续签合同，仅在详情中返回
+	RenewalChain []ContractChainItem `gorm:"-" json:"renewalChain,omitempty"`
+	Version      uint                `gorm:"not null;default:1" json:"version"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	UpdatedAt    time.Time           `json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt      `gorm:"index" json:"deletedAt" swaggertype:"string"`
+}
+
+// ContractChainItem 续签链中的一份合同
+type ContractChainItem struct {
+	ID          uint      `json:"id"`
+	ContractNo  string    `json:"contractNo"`
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate"`
+	Status      string    `json:"status"`
+	MonthlyRent float64   `json:"monthlyRent"`
 }
 
 func (Contract) TableName() string {
 	return "contracts"
 }
+
+// HasRoom 判断合同是否租赁了该房间
+func (c *Contract) HasRoom(roomID uint) bool {
+	for _, room := range c.Rooms {
+		if room.RoomID == roomID {
+			return true
+		}
+	}
+	return false
+}
+
+// MonthlyRent 合同全部租赁房间的月租金合计
+func (c *Contract) MonthlyRent() float64 {
+	var total float64
+	for _, room := range c.Rooms {
+		total += room.MonthlyRent
+	}
+	return total
+}
+
+// LeaseEnd 返回租期结束的日期（零点）。租期不含到期日当天：到期日当天起合同即视为结束，
+// 计租、到期检查与租期重叠判断共用这一规则，续签合同从原合同到期日起租。
+// 提前终止的合同租期截至终止当天，即于终止日的次日结束
+func (c *Contract) LeaseEnd() time.Time {
+	y, m, d := c.EndDate.Date()
+	end := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	if c.TerminatedAt != nil {
+		y, m, d = c.TerminatedAt.Date()
+		if terminated := time.Date(y, m, d+1, 0, 0, 0, 0, time.Local); terminated.Before(end) {
+			return terminated
+		}
+	}
+	return end
+}
+
+// LeaseEndedOn 判断合同在 day 当天是否已结束租期
+func (c *Contract) LeaseEndedOn(day time.Time) bool {
+	y, m, d := day.Date()
+	return !time.Date(y, m, d, 0, 0, 0, 0, time.Local).Before(c.LeaseEnd())
+}
+
+// Overlaps 判断两份合同的租期是否重叠，一份的结束时间等于另一份的开始时间不算重叠
+func (c *Contract) Overlaps(other *Contract) bool {
+	return c.StartDate.Before(other.EndDate) && other.StartDate.Before(c.EndDate)
+}
+
+// ContractRoom 合同租赁的房间，一份合同可租赁多间房间并分别约定月租金
+type ContractRoom struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ContractID  uint      `gorm:"not null;uniqueIndex:idx_contract_rooms_contract_room,priority:1" json:"contractId"`
+	RoomID      uint      `gorm:"not null;uniqueIndex:idx_contract_rooms_contract_room,priority:2;index" json:"roomId"`
+	Room        *Room     `gorm:"foreignKey:RoomID" json:"-"`
+	RoomNo      string    `gorm:"-" json:"roomNo"`
+	MonthlyRent float64   `gorm:"type:decimal(10,2)" json:"monthlyRent"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (ContractRoom) TableName() string {
+	return "contract_rooms"
+}
+
+// ContractTransition 合同状态变更记录，FromStatus 为空表示创建合同时的初始状态
+type ContractTransition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ContractID uint      `gorm:"not null;index" json:"contractId"`
+	FromStatus string    `gorm:"size:20;not null;default:''" json:"fromStatus"`
+	ToStatus   string    `gorm:"size:20;not null" json:"toStatus"`
+	Reason     string    `gorm:"size:500" json:"reason"`
+	ActorID    uint      `json:"actorId"`
+	ActorName  string    `gorm:"size:50" json:"actorName"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (ContractTransition) TableName() string {
+	return "contract_transitions"
+}
+
+// RentAdjustment 合同房间的租金调整记录，续签与周年递增时生成
+type RentAdjustment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ContractID    uint      `gorm:"not null;index" json:"contractId"`
+	RoomID        uint      `gorm:"not null" json:"roomId"`
+	RoomNo        string    `gorm:"-" json:"roomNo"`
+	EffectiveDate time.Time `json:"effectiveDate"`
+	OldRent       float64   `gorm:"type:decimal(10,2)" json:"oldRent"`
+	NewRent       float64   `gorm:"type:decimal(10,2)" json:"newRent"`
+	Reason        string    `gorm:"size:200" json:"reason"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+func (RentAdjustment) TableName() string {
+	return "contract_rent_adjustments"
+}
+
+// CPIIndex 本地维护的居民消费价格指数年度涨幅，Rate 为百分比（如 2.1 表示上涨 2.1%）
+type CPIIndex struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Year      int       `gorm:"uniqueIndex;not null" json:"year"`
+	Rate      float64   `gorm:"type:decimal(6,2);not null" json:"rate"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (CPIIndex) TableName() string {
+	return "cpi_indices"
+}
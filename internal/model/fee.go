@@ -1,25 +1,52 @@
 package model
 
 import (
+	"math"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// FeeTypeLateFee 逾期检查任务按滞纳金规则生成的滞纳金
+const FeeTypeLateFee = "late_fee"
+
 type Fee struct {
-	ID         uint       `gorm:"primaryKey" json:"id"`
-	TenantID   uint       `gorm:"not null;index" json:"tenantId"`
-	Tenant     Tenant     `gorm:"foreignKey:TenantID" json:"-"`
-	TenantName string     `gorm:"-" json:"tenantName"`
-	RoomNo     string     `gorm:"size:20" json:"roomNo"`
-	FeeType    string     `gorm:"size:20;not null" json:"feeType"`
-	Amount     float64    `gorm:"type:decimal(10,2)" json:"amount"`
-	Period     string     `gorm:"size:20" json:"period"`
-	DueDate    time.Time  `json:"dueDate"`
-	PaidDate   *time.Time `json:"paidDate"`
-	Status     string     `gorm:"size:20;default:'unpaid'" json:"status"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	UpdatedAt  time.Time  `json:"updatedAt"`
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	TenantID   uint   `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string `gorm:"-" json:"tenantName"`
+	RoomNo     string `gorm:"size:20" json:"roomNo"`
+	// ContractID 由账单生成的租金关联的合同，手工录入的费用为空
+	ContractID *uint   `gorm:"index" json:"contractId"`
+	FeeType    string  `gorm:"size:20;not null" json:"feeType"`
+	Amount     float64 `gorm:"type:decimal(10,2)" json:"amount"`
+	// PaidAmount 未冲正收款分配到该费用的金额与账户余额抵扣的金额合计，由收款登记、冲正与余额抵扣维护；Balance 为尚未收取的金额
+	PaidAmount float64   `gorm:"type:decimal(10,2);not null;default:0" json:"paidAmount"`
+	Balance    float64   `gorm:"-" json:"balance"`
+	Period     string    `gorm:"size:20" json:"period"`
+	DueDate    time.Time `json:"dueDate"`
+	// PaidDate 结清费用的收款日期，未结清时为空
+	PaidDate *time.Time `json:"paidDate"`
+	// Status 为 unpaid、partially_paid、paid、overdue 或 waived，其中 paid、partially_paid 由已收金额决定，
+	// overdue 由逾期检查任务在超过宽限期后标记，waived 为已减免的滞纳金
+	Status string `gorm:"size:20;default:'unpaid'" json:"status"`
+	// PenaltyOfID 滞纳金所针对的逾期费用，每笔逾期费用至多生成一笔滞纳金
+	PenaltyOfID *uint `gorm:"index" json:"penaltyOfId"`
+	// WaivedAt、WaivedBy、WaiveReason 减免滞纳金的时间、操作人与原因
+	WaivedAt    *time.Time     `json:"waivedAt"`
+	WaivedBy    string         `gorm:"size:50" json:"waivedBy"`
+	WaiveReason string         `gorm:"size:500" json:"waiveReason"`
+	Version     uint           `gorm:"not null;default:1" json:"version"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deletedAt" swaggertype:"string"`
 }
 
 func (Fee) TableName() string {
 	return "fees"
 }
+
+// Outstanding 费用尚未收取的金额
+func (f *Fee) Outstanding() float64 {
+	return math.Round((f.Amount-f.PaidAmount)*100) / 100
+}
@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// 单据类型：credit_note 为红字发票，冲减原发票的部分或全部金额，金额为负数
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+// 发票状态：草稿没有编号，可删除；开具时按年度分配连续编号，之后只能作废，作废的发票保留编号
+const (
+	InvoiceStatusDraft  = "draft"
+	InvoiceStatusIssued = "issued"
+	InvoiceStatusVoid   = "void"
+)
+
+// Invoice 按账期汇总租户费用的发票或冲减原发票的红字发票。金额均为含税金额，
+// Subtotal 为不含税金额合计，TaxAmount 为税额合计，Total 为价税合计
+type Invoice struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	// InvoiceNo 开具时分配，如 INV-2026-000001、CN-2026-000001，草稿为空
+	InvoiceNo  *string `gorm:"size:30;uniqueIndex" json:"invoiceNo"`
+	Kind       string  `gorm:"size:20;not null;default:'invoice'" json:"kind"`
+	TenantID   uint    `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant  `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string  `gorm:"-" json:"tenantName"`
+	Period     string  `gorm:"size:20" json:"period"`
+	// OriginalID 红字发票冲减的原发票
+	OriginalID *uint         `gorm:"index" json:"originalId"`
+	Status     string        `gorm:"size:20;not null;default:'draft'" json:"status"`
+	Subtotal   float64       `gorm:"type:decimal(12,2);not null" json:"subtotal"`
+	TaxAmount  float64       `gorm:"type:decimal(12,2);not null" json:"taxAmount"`
+	Total      float64       `gorm:"type:decimal(12,2);not null" json:"total"`
+	Lines      []InvoiceLine `gorm:"foreignKey:InvoiceID" json:"lines"`
+	// CreditedAmount 已开具且未作废的红字发票冲减的金额合计（正数）
+	CreditedAmount float64    `gorm:"type:decimal(12,2);not null;default:0" json:"creditedAmount"`
+	Remark         string     `gorm:"size:500" json:"remark"`
+	IssuedAt       *time.Time `json:"issuedAt"`
+	IssuedBy       string     `gorm:"size:50" json:"issuedBy"`
+	VoidedAt       *time.Time `json:"voidedAt"`
+	VoidedBy       string     `gorm:"size:50" json:"voidedBy"`
+	VoidReason     string     `gorm:"size:500" json:"voidReason"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceLine 发票明细，发票的每行对应一笔费用，红字发票的每行对应被冲减的原发票明细。
+// Amount 为不含税金额，Total 为价税合计
+type InvoiceLine struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	InvoiceID uint   `gorm:"not null;index" json:"invoiceId"`
+	FeeID     *uint  `gorm:"index" json:"feeId"`
+	FeeType   string `gorm:"size:20" json:"feeType"`
+	RoomNo    string `gorm:"size:20" json:"roomNo"`
+	Period    string `gorm:"size:20" json:"period"`
+	// OriginalLineID 红字发票明细冲减的原发票明细
+	OriginalLineID *uint     `gorm:"index" json:"originalLineId"`
+	Description    string    `gorm:"size:200" json:"description"`
+	Amount         float64   `gorm:"type:decimal(12,2);not null" json:"amount"`
+	TaxRate        float64   `gorm:"type:decimal(5,4);not null" json:"taxRate"`
+	TaxAmount      float64   `gorm:"type:decimal(12,2);not null" json:"taxAmount"`
+	Total          float64   `gorm:"type:decimal(12,2);not null" json:"total"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+func (InvoiceLine) TableName() string {
+	return "invoice_lines"
+}
+
+// InvoiceSequence 各编号系列（发票 INV、红字发票 CN、收据 RC）每年已分配的最后一个编号，
+// 开具单据时在同一事务内递增，事务回滚时编号一并回滚，保证编号连续
+type InvoiceSequence struct {
+	Series string `gorm:"primaryKey;size:10" json:"series"`
+	Year   int    `gorm:"primaryKey" json:"year"`
+	LastNo int    `gorm:"not null" json:"lastNo"`
+}
+
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
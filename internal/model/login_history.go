@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+type LoginHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index" json:"userId"`
+	Username  string    `gorm:"size:50;index" json:"username"`
+	IP        string    `gorm:"size:64;index" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"userAgent"`
+	Success   bool      `json:"success"`
+	Reason    string    `gorm:"size:100" json:"reason"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (LoginHistory) TableName() string {
+	return "login_histories"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+const (
+	NotificationTypeContractExpiry = "contract_expiry"
+)
+
+// Notification 站内通知。DedupKey 标识触发通知的事件，同一事件只生成一条通知
+type Notification struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Type       string     `gorm:"size:30;index;not null" json:"type"`
+	DedupKey   string     `gorm:"size:100;uniqueIndex;not null" json:"-"`
+	TenantID   *uint      `gorm:"index" json:"tenantId"`
+	ContractID *uint      `gorm:"index" json:"contractId"`
+	Title      string     `gorm:"size:200;not null" json:"title"`
+	Content    string     `gorm:"size:1000" json:"content"`
+	ReadAt     *time.Time `json:"readAt"`
+	CreatedAt  time.Time  `gorm:"index" json:"createdAt"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,81 @@
+package model
+
+import "time"
+
+// 收款方式
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodWechat       = "wechat"
+	PaymentMethodAlipay       = "alipay"
+	PaymentMethodOther        = "other"
+)
+
+// PaymentMethods 支持的收款方式
+var PaymentMethods = []string{PaymentMethodCash, PaymentMethodBankTransfer, PaymentMethodWechat, PaymentMethodAlipay, PaymentMethodOther}
+
+// 收款状态：reversed 为已冲正，冲正后分配到各费用的金额全部退回，不再计入收入
+const (
+	PaymentStatusCompleted = "completed"
+	PaymentStatusReversed  = "reversed"
+)
+
+// Payment 一笔实际到账的收款，可分配到同一租户的一笔或多笔费用，
+// 各分配金额之和等于收款金额。收款不可修改，登记错误时冲正后重新登记
+type Payment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant    `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string    `gorm:"-" json:"tenantName"`
+	Amount     float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Method     string    `gorm:"size:20;not null" json:"method"`
+	Reference  string    `gorm:"size:100" json:"reference"`
+	ReceivedAt time.Time `gorm:"not null;index" json:"receivedAt"`
+	// OperatorID、OperatorName 为登记收款的账号，系统迁移的历史收款为空
+	OperatorID   uint                `json:"operatorId"`
+	OperatorName string              `gorm:"size:50" json:"operatorName"`
+	Status       string              `gorm:"size:20;not null;default:'completed'" json:"status"`
+	Remark       string              `gorm:"size:500" json:"remark"`
+	Allocations  []PaymentAllocation `gorm:"foreignKey:PaymentID" json:"allocations"`
+	// Credited 收款中未分配到费用的部分，转入租户账户余额，可抵扣之后的费用或退款
+	Credited float64 `gorm:"type:decimal(10,2);not null;default:0" json:"credited"`
+	// Receipt 登记收款时开具的收据
+	Receipt *Receipt `gorm:"foreignKey:PaymentID" json:"receipt"`
+	// ReversedAt、ReversedBy、ReversalReason 在冲正时填写
+	ReversedAt     *time.Time `json:"reversedAt"`
+	ReversedBy     string     `gorm:"size:50" json:"reversedBy"`
+	ReversalReason string     `gorm:"size:500" json:"reversalReason"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// PaymentAllocation 收款分配到一笔费用的金额，FeeType、RoomNo、Period 取自该费用
+type PaymentAllocation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PaymentID uint      `gorm:"not null;uniqueIndex:idx_payment_allocations_payment_fee,priority:1" json:"paymentId"`
+	FeeID     uint      `gorm:"not null;uniqueIndex:idx_payment_allocations_payment_fee,priority:2;index" json:"feeId"`
+	Fee       *Fee      `gorm:"foreignKey:FeeID" json:"-"`
+	FeeType   string    `gorm:"-" json:"feeType"`
+	RoomNo    string    `gorm:"-" json:"roomNo"`
+	Period    string    `gorm:"-" json:"period"`
+	Amount    float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (PaymentAllocation) TableName() string {
+	return "payment_allocations"
+}
+
+// IsPaymentMethod 判断是否为支持的收款方式
+func IsPaymentMethod(method string) bool {
+	for _, m := range PaymentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
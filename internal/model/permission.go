@@ -0,0 +1,90 @@
+package model
+
+// 角色
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+	// RoleTenant 租户自助门户账号，必须关联 TenantID，只能访问 /api/portal 下本租户的数据
+	RoleTenant = "tenant"
+)
+
+// 用户状态
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+// 权限标识，格式为 资源:操作，支持 * 通配
+const (
+	PermTenantRead   = "tenant:read"
+	PermTenantWrite  = "tenant:write"
+	PermTenantDelete = "tenant:delete"
+	PermTenantPurge  = "tenant:purge"
+
+	PermContractRead    = "contract:read"
+	PermContractWrite   = "contract:write"
+	PermContractDelete  = "contract:delete"
+	PermContractPurge   = "contract:purge"
+	PermContractApprove = "contract:approve"
+
+	PermRoomRead   = "room:read"
+	PermRoomWrite  = "room:write"
+	PermRoomDelete = "room:delete"
+	PermRoomPurge  = "room:purge"
+	PermRoomAssign = "room:assign"
+
+	PermFeeRead    = "fee:read"
+	PermFeeWrite   = "fee:write"
+	PermFeeDelete  = "fee:delete"
+	PermFeePurge   = "fee:purge"
+	PermFeePay     = "fee:pay"
+	PermFeeReverse = "fee:reverse"
+	PermFeeWaive   = "fee:waive"
+	PermFeeCredit  = "fee:credit"
+	PermFeeRefund  = "fee:refund"
+
+	PermInvoiceRead  = "invoice:read"
+	PermInvoiceWrite = "invoice:write"
+	PermInvoiceVoid  = "invoice:void"
+
+	PermMaintenanceRead     = "maintenance:read"
+	PermMaintenanceWrite    = "maintenance:write"
+	PermMaintenanceDelete   = "maintenance:delete"
+	PermMaintenancePurge    = "maintenance:purge"
+	PermMaintenanceAssign   = "maintenance:assign"
+	PermMaintenanceComplete = "maintenance:complete"
+
+	PermReportView = "report:view"
+
+	PermNotificationRead = "notification:read"
+
+	PermUserManage = "user:manage"
+	PermKeyManage  = "key:manage"
+	PermAuditView  = "audit:view"
+
+	PermPortalAccess = "portal:access"
+)
+
+// RolePermissions 角色默认拥有的权限，与用户自身的 Permissions 合并后生效
+var RolePermissions = map[string][]string{
+	RoleAdmin: {"*"},
+	RoleUser: {
+		PermTenantRead,
+		PermContractRead,
+		PermRoomRead,
+		PermFeeRead,
+		PermInvoiceRead,
+		PermMaintenanceRead,
+		PermReportView,
+		PermNotificationRead,
+	},
+	RoleTenant: {PermPortalAccess},
+}
+
+// EffectivePermissions 返回用户角色默认权限与自身权限的并集
+func (u *User) EffectivePermissions() []string {
+	perms := make([]string, 0, len(RolePermissions[u.Role])+len(u.Permissions))
+	perms = append(perms, RolePermissions[u.Role]...)
+	perms = append(perms, u.Permissions...)
+	return perms
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// 收据状态：收款冲正时收据随之作废，作废的收据保留编号
+const (
+	ReceiptStatusValid = "valid"
+	ReceiptStatusVoid  = "void"
+)
+
+// Receipt 登记收款时自动开具的收据，每笔收款一张，编号如 RC-2026-000001。
+// 收据内容取自收款及其分配明细，可重复打印，PrintCount 为已打印次数
+type Receipt struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ReceiptNo  string    `gorm:"size:30;not null;uniqueIndex" json:"receiptNo"`
+	PaymentID  uint      `gorm:"not null;uniqueIndex" json:"paymentId"`
+	TenantID   uint      `gorm:"not null;index" json:"tenantId"`
+	Tenant     Tenant    `gorm:"foreignKey:TenantID" json:"-"`
+	TenantName string    `gorm:"-" json:"tenantName"`
+	Amount     float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Status     string    `gorm:"size:20;not null;default:'valid'" json:"status"`
+	IssuedAt   time.Time `gorm:"not null" json:"issuedAt"`
+	IssuedBy   string    `gorm:"size:50" json:"issuedBy"`
+	PrintCount int       `gorm:"not null;default:0" json:"printCount"`
+	// LastPrintedAt 最近一次下载 PDF 的时间
+	LastPrintedAt *time.Time `json:"lastPrintedAt"`
+	VoidedAt      *time.Time `json:"voidedAt"`
+	VoidedBy      string     `gorm:"size:50" json:"voidedBy"`
+	VoidReason    string     `gorm:"size:500" json:"voidReason"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+func (Receipt) TableName() string {
+	return "receipts"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// SigningKey JWT 非对称签名密钥。同一时刻只有一把未退役的密钥用于签发，
+// 退役后的密钥在 ExpiresAt 之前仍用于验签并发布在 JWKS 中。
+type SigningKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	KID        string     `gorm:"uniqueIndex;size:32;not null" json:"kid"`
+	Algorithm  string     `gorm:"size:10;not null" json:"algorithm"`
+	PrivateKey string     `gorm:"type:text;not null" json:"-"`
+	PublicKey  string     `gorm:"type:text;not null" json:"publicKey"`
+	RetiredAt  *time.Time `json:"retiredAt"`
+	ExpiresAt  *time.Time `gorm:"index" json:"expiresAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (SigningKey) TableName() string {
+	return "signing_keys"
+}
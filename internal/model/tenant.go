@@ -2,17 +2,21 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Tenant struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	Name          string    `gorm:"size:100;not null" json:"name"`
-	ContactPerson string    `gorm:"size:50" json:"contactPerson"`
-	Phone         string    `gorm:"size:20" json:"phone"`
-	Email         string    `gorm:"size:100" json:"email"`
-	Status        string    `gorm:"size:20;default:'active'" json:"status"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Name          string         `gorm:"size:100;not null" json:"name"`
+	ContactPerson string         `gorm:"size:50" json:"contactPerson"`
+	Phone         string         `gorm:"size:20" json:"phone"`
+	Email         string         `gorm:"size:100" json:"email"`
+	Status        string         `gorm:"size:20;default:'active'" json:"status"`
+	Version       uint           `gorm:"not null;default:1" json:"version"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deletedAt" swaggertype:"string"`
 }
 
 func (Tenant) TableName() string {
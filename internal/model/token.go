@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，数据库只保存哈希值。
+// 同一次登录产生的刷新令牌共享 FamilyID，每次刷新都会轮换出新令牌，
+// 已使用或已撤销的令牌再次出现时视为被盗用，整个 family 会被撤销。
+type RefreshToken struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"not null;index" json:"userId"`
+	TokenHash       string     `gorm:"uniqueIndex;size:64;not null" json:"-"`
+	FamilyID        string     `gorm:"index;size:32;not null" json:"familyId"`
+	AccessJTI       string     `gorm:"index;size:32" json:"-"`
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	UsedAt          *time.Time `json:"usedAt"`
+	RevokedAt       *time.Time `json:"revokedAt"`
+	IP              string     `gorm:"size:64" json:"ip"`
+	UserAgent       string     `gorm:"size:255" json:"userAgent"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RevokedToken 访问令牌黑名单，按 jti 记录，过期后可清理
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JTI       string    `gorm:"uniqueIndex;size:32;not null" json:"jti"`
+	UserID    uint      `gorm:"index" json:"userId"`
+	ExpiresAt time.Time `gorm:"index" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -14,8 +14,21 @@ type User struct {
 	Avatar      string         `gorm:"size:255" json:"avatar"`
 	Role        string         `gorm:"size:20;default:'user'" json:"role"`
 	Permissions pq.StringArray `gorm:"type:text[]" json:"permissions" swaggertype:"array,string"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
+	Status      string         `gorm:"size:20;default:'active'" json:"status"`
+	// TenantID 租户门户账号关联的租户，仅 tenant 角色使用
+	TenantID *uint `gorm:"index" json:"tenantId"`
+	// 连续登录失败次数与锁定截止时间，登录成功或管理员解锁后清零
+	FailedAttempts int        `gorm:"default:0" json:"failedAttempts"`
+	LockedUntil    *time.Time `json:"lockedUntil"`
+	// 两步验证：MFASecret 为已启用的密钥，MFAPendingSecret 为绑定中尚未确认的密钥，
+	// MFARecoveryCodes 保存恢复码的哈希，MFALastStep 用于拒绝同一验证码重复使用
+	MFAEnabled       bool           `gorm:"default:false" json:"mfaEnabled"`
+	MFASecret        string         `gorm:"size:64" json:"-"`
+	MFAPendingSecret string         `gorm:"size:64" json:"-"`
+	MFARecoveryCodes pq.StringArray `gorm:"type:text[]" json:"-"`
+	MFALastStep      int64          `json:"-"`
+	CreatedAt        time.Time      `json:"createdAt"`
+	UpdatedAt        time.Time      `json:"updatedAt"`
 }
 
 // User represents a user in the system.
@@ -27,10 +40,13 @@ type UserResponse struct {
 	Avatar      string   `json:"avatar" example:"https://example.com/avatar.jpg"`
 	Role        string   `json:"role" example:"admin"`
 	Permissions []string `json:"permissions" swaggertype:"array,string" example:"[\"read\", \"write\"]"`
+	Status      string   `json:"status" example:"active"`
+	MFAEnabled  bool     `json:"mfaEnabled" example:"false"`
+	TenantID    *uint    `json:"tenantId" example:"1"`
 	CreatedAt   string   `json:"createdAt" example:"2024-01-01T00:00:00Z"`
 	UpdatedAt   string   `json:"updatedAt" example:"2024-01-01T00:00:00Z"`
 }
 
 func (User) TableName() string {
 	return "users"
-}
\ No newline at end of file
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func preloadAccountTenant(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tenant", withTrashed)
+}
+
+func (r *accountRepository) CreateCreditNote(note *model.CreditNote) error {
+	return r.db.Omit(clause.Associations).Create(note).Error
+}
+
+func (r *accountRepository) FindCreditNoteByID(id uint) (*model.CreditNote, error) {
+	var note model.CreditNote
+	if err := r.db.Scopes(preloadAccountTenant).First(&note, id).Error; err != nil {
+		return nil, err
+	}
+	note.TenantName = note.Tenant.Name
+	return &note, nil
+}
+
+func (r *accountRepository) ListCreditNotes(page, pageSize int, tenantID uint) ([]model.CreditNote, int64, error) {
+	var notes []model.CreditNote
+	var total int64
+
+	query := r.db.Model(&model.CreditNote{}).Scopes(preloadAccountTenant)
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("id DESC").Find(&notes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range notes {
+		notes[i].TenantName = notes[i].Tenant.Name
+	}
+
+	return notes, total, nil
+}
+
+// FindCreditNotesByTenant 按开具时间返回租户的全部贷项
+func (r *accountRepository) FindCreditNotesByTenant(tenantID uint) ([]model.CreditNote, error) {
+	var notes []model.CreditNote
+	if err := r.db.Where("tenant_id = ?", tenantID).Order("issued_at, id").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (r *accountRepository) FindCreditNotesByInvoice(invoiceID uint) ([]model.CreditNote, error) {
+	var notes []model.CreditNote
+	if err := r.db.Where("invoice_id = ?", invoiceID).Order("id").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// VoidCreditNote 只更新作废信息
+func (r *accountRepository) VoidCreditNote(note *model.CreditNote) error {
+	return r.db.Model(&model.CreditNote{}).Where("id = ?", note.ID).
+		Updates(map[string]interface{}{"voided_at": note.VoidedAt, "voided_by": note.VoidedBy}).Error
+}
+
+func (r *accountRepository) SumCreditNotesByFee(feeID uint) (float64, error) {
+	var total float64
+	err := r.db.Model(&model.CreditNote{}).Select("COALESCE(SUM(amount), 0)").Where("fee_id = ? AND voided_at IS NULL", feeID).Scan(&total).Error
+	return total, err
+}
+
+func (r *accountRepository) CreateRefund(refund *model.Refund) error {
+	return r.db.Omit(clause.Associations).Create(refund).Error
+}
+
+func (r *accountRepository) FindRefundByID(id uint) (*model.Refund, error) {
+	var refund model.Refund
+	if err := r.db.Scopes(preloadAccountTenant).First(&refund, id).Error; err != nil {
+		return nil, err
+	}
+	refund.TenantName = refund.Tenant.Name
+	return &refund, nil
+}
+
+func (r *accountRepository) ListRefunds(page, pageSize int, tenantID uint) ([]model.Refund, int64, error) {
+	var refunds []model.Refund
+	var total int64
+
+	query := r.db.Model(&model.Refund{}).Scopes(preloadAccountTenant)
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("refunded_at DESC, id DESC").Find(&refunds).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range refunds {
+		refunds[i].TenantName = refunds[i].Tenant.Name
+	}
+
+	return refunds, total, nil
+}
+
+// FindRefundsByTenant 按退款时间返回租户的全部退款
+func (r *accountRepository) FindRefundsByTenant(tenantID uint) ([]model.Refund, error) {
+	var refunds []model.Refund
+	if err := r.db.Where("tenant_id = ?", tenantID).Order("refunded_at, id").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+func (r *accountRepository) SumRefundsByPayment(paymentID uint) (float64, error) {
+	var total float64
+	err := r.db.Model(&model.Refund{}).Select("COALESCE(SUM(amount), 0)").Where("payment_id = ?", paymentID).Scan(&total).Error
+	return total, err
+}
+
+func (r *accountRepository) CreateApplication(application *model.CreditApplication) error {
+	return r.db.Create(application).Error
+}
+
+// CreditBalance 在数据库中汇总未作废的贷项、未冲正收款转入的金额、退款与抵扣，调用方需先锁定租户行以免并发扣减
+func (r *accountRepository) CreditBalance(tenantID uint) (float64, error) {
+	var balance float64
+	err := r.db.Raw(`SELECT
+		COALESCE((SELECT SUM(amount) FROM credit_notes WHERE tenant_id = @id AND voided_at IS NULL), 0)
+		+ COALESCE((SELECT SUM(credited) FROM payments WHERE tenant_id = @id AND status = 'completed'), 0)
+		- COALESCE((SELECT SUM(amount) FROM refunds WHERE tenant_id = @id), 0)
+		- COALESCE((SELECT SUM(amount) FROM credit_applications WHERE tenant_id = @id), 0)`,
+		map[string]interface{}{"id": tenantID}).Scan(&balance).Error
+	return balance, err
+}
+
+func (r *accountRepository) NextNumber(series string, year int) (int, error) {
+	return nextSequence(r.db, series, year)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) List(page, pageSize int, userID uint) ([]model.APIKey, int64, error) {
+	var keys []model.APIKey
+	var total int64
+
+	query := r.db.Model(&model.APIKey{})
+
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return keys, total, nil
+}
+
+func (r *apiKeyRepository) Revoke(id uint, revokedAt time.Time) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+// TouchLastUsed 记录密钥最近一次使用的时间与来源 IP
+func (r *apiKeyRepository) TouchLastUsed(id uint, ip string, usedAt time.Time) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"last_used_at": usedAt,
+		"last_used_ip": ip,
+	}).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(log *model.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *auditLogRepository) List(page, pageSize int, entityType string, entityID, actorID uint, action, requestID string, from, to *time.Time) ([]model.AuditLog, int64, error) {
+	var logs []model.AuditLog
+	var total int64
+
+	query := r.db.Model(&model.AuditLog{})
+
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID > 0 {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if actorID > 0 {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", to)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// ListByEntity 按时间顺序返回单个实体的全部变更记录
+func (r *auditLogRepository) ListByEntity(entityType string, entityID uint) ([]model.AuditLog, error) {
+	var logs []model.AuditLog
+	if err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -8,28 +8,55 @@ import (
 	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
-type ContractRepository struct {
+type contractRepository struct {
 	db *gorm.DB
 }
 
-func NewContractRepository(db *gorm.DB) *ContractRepository {
-	return &ContractRepository{db: db}
+func NewContractRepository(db *gorm.DB) ContractRepository {
+	return &contractRepository{db: db}
 }
 
-func (r *ContractRepository) Create(contract *model.Contract) error {
-	return r.db.Create(contract).Error
+// preloadRooms 预加载租赁房间及房间号，房间进入回收站后仍显示房间号
+func preloadRooms(db *gorm.DB) *gorm.DB {
+	return db.Preload("Rooms", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Rooms.Room", withTrashed)
 }
 
-func (r *ContractRepository) FindByID(id uint) (*model.Contract, error) {
+// fillContract 填充租户名称与房间号
+func fillContract(contract *model.Contract) {
+	contract.TenantName = contract.Tenant.Name
+	for i := range contract.Rooms {
+		if contract.Rooms[i].Room != nil {
+			contract.Rooms[i].RoomNo = contract.Rooms[i].Room.RoomNo
+		}
+	}
+}
+
+// Create 只保存合同本身，租赁房间通过 ReplaceRooms 保存
+func (r *contractRepository) Create(contract *model.Contract) error {
+	return r.db.Omit("Rooms").Create(contract).Error
+}
+
+func (r *contractRepository) FindByID(id uint) (*model.Contract, error) {
 	var contract model.Contract
-	if err := r.db.Preload("Tenant").First(&contract, id).Error; err != nil {
+	if err := r.db.Scopes(preloadRooms).Preload("Tenant", withTrashed).First(&contract, id).Error; err != nil {
 		return nil, err
 	}
-	contract.TenantName = contract.Tenant.Name
+	fillContract(&contract)
 	return &contract, nil
 }
 
-func (r *ContractRepository) FindByContractNo(contractNo string) (*model.Contract, error) {
+func (r *contractRepository) FindByIDForUpdate(id uint) (*model.Contract, error) {
+	var contract model.Contract
+	if err := r.db.Scopes(forUpdate, preloadRooms).Preload("Tenant", withTrashed).First(&contract, id).Error; err != nil {
+		return nil, err
+	}
+	fillContract(&contract)
+	return &contract, nil
+}
+
+func (r *contractRepository) FindByContractNo(contractNo string) (*model.Contract, error) {
 	var contract model.Contract
 	if err := r.db.Where("contract_no = ?", contractNo).First(&contract).Error; err != nil {
 		return nil, err
@@ -37,19 +64,19 @@ func (r *ContractRepository) FindByContractNo(contractNo string) (*model.Contrac
 	return &contract, nil
 }
 
-func (r *ContractRepository) Update(contract *model.Contract) error {
-	return r.db.Save(contract).Error
+func (r *contractRepository) Update(contract *model.Contract) error {
+	return updateWithVersion(r.db, contract, &contract.Version)
 }
 
-func (r *ContractRepository) Delete(id uint) error {
+func (r *contractRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Contract{}, id).Error
 }
 
-func (r *ContractRepository) List(page, pageSize int, keyword, status string, startDateFrom, startDateTo *time.Time) ([]model.Contract, int64, error) {
+func (r *contractRepository) List(page, pageSize int, keyword, status string, startDateFrom, startDateTo *time.Time) ([]model.Contract, int64, error) {
 	var contracts []model.Contract
 	var total int64
 
-	query := r.db.Model(&model.Contract{}).Preload("Tenant")
+	query := r.db.Model(&model.Contract{}).Scopes(preloadRooms).Preload("Tenant", withTrashed)
 
 	if keyword != "" {
 		query = query.Where("contract_no ILIKE ?", "%"+keyword+"%")
@@ -72,24 +99,204 @@ func (r *ContractRepository) List(page, pageSize int, keyword, status string, st
 	}
 
 	for i := range contracts {
-		contracts[i].TenantName = contracts[i].Tenant.Name
+		fillContract(&contracts[i])
 	}
 
 	return contracts, total, nil
 }
 
-func (r *ContractRepository) FindByTenantID(tenantID uint) ([]model.Contract, error) {
+func (r *contractRepository) FindByTenantID(tenantID uint) ([]model.Contract, error) {
+	var contracts []model.Contract
+	if err := r.db.Scopes(preloadRooms).Where("tenant_id = ?", tenantID).Find(&contracts).Error; err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		fillContract(&contracts[i])
+	}
+	return contracts, nil
+}
+
+// FindByRoom 查询租赁了该房间的指定状态合同，不传状态时返回全部
+func (r *contractRepository) FindByRoom(roomID uint, statuses ...string) ([]model.Contract, error) {
 	var contracts []model.Contract
-	if err := r.db.Where("tenant_id = ?", tenantID).Find(&contracts).Error; err != nil {
+	query := r.db.Where("id IN (?)", r.db.Model(&model.ContractRoom{}).Select("contract_id").Where("room_id = ?", roomID))
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Order("start_date").Find(&contracts).Error; err != nil {
 		return nil, err
 	}
 	return contracts, nil
 }
 
-func (r *ContractRepository) CountByStatus(status string) (int64, error) {
+// ReplaceRooms 用 rooms 替换合同的全部租赁房间，须在 UnitOfWork 内调用
+func (r *contractRepository) ReplaceRooms(contractID uint, rooms []model.ContractRoom) error {
+	if err := r.db.Where("contract_id = ?", contractID).Delete(&model.ContractRoom{}).Error; err != nil {
+		return err
+	}
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	for i := range rooms {
+		rooms[i].ID = 0
+		rooms[i].ContractID = contractID
+	}
+	return r.db.Omit("Room").Create(&rooms).Error
+}
+
+func (r *contractRepository) CreateTransition(transition *model.ContractTransition) error {
+	return r.db.Create(transition).Error
+}
+
+// FindTransitions 按时间顺序返回合同的状态变更记录
+func (r *contractRepository) FindTransitions(contractID uint) ([]model.ContractTransition, error) {
+	var transitions []model.ContractTransition
+	if err := r.db.Where("contract_id = ?", contractID).Order("created_at, id").Find(&transitions).Error; err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// FindSuccessors 查询以该合同为前序的续签合同，不含回收站中的合同
+func (r *contractRepository) FindSuccessors(id uint) ([]model.Contract, error) {
+	var contracts []model.Contract
+	if err := r.db.Scopes(preloadRooms).Where("predecessor_id = ?", id).Order("id").Find(&contracts).Error; err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		fillContract(&contracts[i])
+	}
+	return contracts, nil
+}
+
+// FindByEscalationTiming 查询约定了租金递增且递增时机为 timing 的指定状态合同
+func (r *contractRepository) FindByEscalationTiming(timing string, statuses ...string) ([]model.Contract, error) {
+	var contracts []model.Contract
+	query := r.db.Scopes(preloadRooms).Preload("Tenant", withTrashed).
+		Where("escalation_type <> ? AND escalation_timing = ?", model.EscalationNone, timing)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Order("id").Find(&contracts).Error; err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		fillContract(&contracts[i])
+	}
+	return contracts, nil
+}
+
+func (r *contractRepository) CreateRentAdjustment(adjustment *model.RentAdjustment) error {
+	return r.db.Create(adjustment).Error
+}
+
+// FindRentAdjustments 按生效日期顺序返回合同的租金调整记录
+func (r *contractRepository) FindRentAdjustments(contractID uint) ([]model.RentAdjustment, error) {
+	var adjustments []model.RentAdjustment
+	if err := r.db.Table("contract_rent_adjustments AS a").
+		Select("a.*, rooms.room_no").
+		Joins("LEFT JOIN rooms ON rooms.id = a.room_id").
+		Where("a.contract_id = ?", contractID).
+		Order("a.effective_date, a.id").
+		Scan(&adjustments).Error; err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// CountByTenant 统计租户名下指定状态的合同数，不传状态时统计全部
+func (r *contractRepository) CountByTenant(tenantID uint, statuses ...string) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.Contract{}).Where("tenant_id = ?", tenantID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindEndingBefore 查询到期日早于 before 的指定状态合同，按到期日升序
+func (r *contractRepository) FindEndingBefore(before time.Time, statuses ...string) ([]model.Contract, error) {
+	var contracts []model.Contract
+	query := r.db.Scopes(preloadRooms).Preload("Tenant", withTrashed).Where("end_date < ?", before)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Order("end_date, id").Find(&contracts).Error; err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		fillContract(&contracts[i])
+	}
+	return contracts, nil
+}
+
+// FindOverlapping 查询租期与 [start, end] 有交集的指定状态合同，租期不含到期日当天，与 Contract.Overlaps 一致
+func (r *contractRepository) FindOverlapping(start, end time.Time, statuses ...string) ([]model.Contract, error) {
+	var contracts []model.Contract
+	query := r.db.Scopes(preloadRooms).Preload("Tenant", withTrashed).
+		Where("start_date <= ? AND end_date > ?", end, start)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Order("id").Find(&contracts).Error; err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		fillContract(&contracts[i])
+	}
+	return contracts, nil
+}
+
+// CountByStatus 统计处于任一指定状态的合同数，不传状态时统计全部
+func (r *contractRepository) CountByStatus(statuses ...string) (int64, error) {
 	var count int64
-	if err := r.db.Model(&model.Contract{}).Where("status = ?", status).Count(&count).Error; err != nil {
+	query := r.db.Model(&model.Contract{})
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+func (r *contractRepository) ListTrashed(page, pageSize int) ([]model.Contract, int64, error) {
+	var contracts []model.Contract
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Contract{}).Scopes(preloadRooms).Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL")
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("deleted_at DESC").Find(&contracts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range contracts {
+		fillContract(&contracts[i])
+	}
+
+	return contracts, total, nil
+}
+
+func (r *contractRepository) FindTrashedByID(id uint) (*model.Contract, error) {
+	var contract model.Contract
+	if err := r.db.Unscoped().Scopes(preloadRooms).Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL").First(&contract, id).Error; err != nil {
+		return nil, err
+	}
+	fillContract(&contract)
+	return &contract, nil
+}
+
+func (r *contractRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.Contract{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *contractRepository) Purge(id uint) error {
+	return r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Contract{}, id).Error
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type cpiIndexRepository struct {
+	db *gorm.DB
+}
+
+func NewCPIIndexRepository(db *gorm.DB) CPIIndexRepository {
+	return &cpiIndexRepository{db: db}
+}
+
+func (r *cpiIndexRepository) List() ([]model.CPIIndex, error) {
+	var indices []model.CPIIndex
+	if err := r.db.Order("year").Find(&indices).Error; err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (r *cpiIndexRepository) FindByYear(year int) (*model.CPIIndex, error) {
+	var index model.CPIIndex
+	if err := r.db.Where("year = ?", year).First(&index).Error; err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+func (r *cpiIndexRepository) Save(index *model.CPIIndex) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "year"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(index).Error
+}
+
+func (r *cpiIndexRepository) Delete(year int) error {
+	return r.db.Where("year = ?", year).Delete(&model.CPIIndex{}).Error
+}
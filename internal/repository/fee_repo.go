@@ -4,44 +4,82 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
-type FeeRepository struct {
+type feeRepository struct {
 	db *gorm.DB
 }
 
-func NewFeeRepository(db *gorm.DB) *FeeRepository {
-	return &FeeRepository{db: db}
+func NewFeeRepository(db *gorm.DB) FeeRepository {
+	return &feeRepository{db: db}
 }
 
-func (r *FeeRepository) Create(fee *model.Fee) error {
+// fillFee 填充租户名称与未收金额
+func fillFee(fee *model.Fee) {
+	fee.TenantName = fee.Tenant.Name
+	fee.Balance = fee.Outstanding()
+}
+
+func (r *feeRepository) Create(fee *model.Fee) error {
 	return r.db.Create(fee).Error
 }
 
-func (r *FeeRepository) FindByID(id uint) (*model.Fee, error) {
+func (r *feeRepository) CreateIfAbsent(fee *model.Fee) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "contract_id"}, {Name: "room_no"}, {Name: "period"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "contract_id IS NOT NULL AND fee_type = 'rent'"}}},
+		DoNothing:   true,
+	}).Create(fee)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ExistsContractRent 判断合同该房间该账期的租金是否已生成，回收站中的费用同样计入
+func (r *feeRepository) ExistsContractRent(contractID uint, roomNo, period string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.Fee{}).
+		Where("contract_id = ? AND room_no = ? AND period = ? AND fee_type = ?", contractID, roomNo, period, "rent").
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *feeRepository) FindByID(id uint) (*model.Fee, error) {
 	var fee model.Fee
-	if err := r.db.Preload("Tenant").First(&fee, id).Error; err != nil {
+	if err := r.db.Preload("Tenant", withTrashed).First(&fee, id).Error; err != nil {
 		return nil, err
 	}
-	fee.TenantName = fee.Tenant.Name
+	fillFee(&fee)
+	return &fee, nil
+}
+
+// FindByIDForUpdate 读取费用并加排他锁
+func (r *feeRepository) FindByIDForUpdate(id uint) (*model.Fee, error) {
+	var fee model.Fee
+	if err := r.db.Scopes(forUpdate).First(&fee, id).Error; err != nil {
+		return nil, err
+	}
+	fee.Balance = fee.Outstanding()
 	return &fee, nil
 }
 
-func (r *FeeRepository) Update(fee *model.Fee) error {
-	return r.db.Save(fee).Error
+func (r *feeRepository) Update(fee *model.Fee) error {
+	return updateWithVersion(r.db, fee, &fee.Version)
 }
 
-func (r *FeeRepository) Delete(id uint) error {
+func (r *feeRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Fee{}, id).Error
 }
 
-func (r *FeeRepository) List(page, pageSize int, tenantID uint, roomNo, feeType, status, period string) ([]model.Fee, int64, error) {
+func (r *feeRepository) List(page, pageSize int, tenantID uint, roomNo, feeType, status, period string) ([]model.Fee, int64, error) {
 	var fees []model.Fee
 	var total int64
 
-	query := r.db.Model(&model.Fee{}).Preload("Tenant")
+	query := r.db.Model(&model.Fee{}).Preload("Tenant", withTrashed)
 
 	if tenantID > 0 {
 		query = query.Where("tenant_id = ?", tenantID)
@@ -67,31 +105,159 @@ func (r *FeeRepository) List(page, pageSize int, tenantID uint, roomNo, feeType,
 	}
 
 	for i := range fees {
-		fees[i].TenantName = fees[i].Tenant.Name
+		fillFee(&fees[i])
 	}
 
 	return fees, total, nil
 }
 
-func (r *FeeRepository) SumByTypeAndPeriod(feeType string, start, end time.Time) (float64, error) {
+// FindOutstanding 租户尚未结清的费用，按到期日从早到晚排列
+func (r *feeRepository) FindOutstanding(tenantID uint) ([]model.Fee, error) {
+	var fees []model.Fee
+	err := r.db.Preload("Tenant", withTrashed).
+		Where("tenant_id = ? AND status IN ? AND paid_amount < amount", tenantID, outstandingStatuses).
+		Order("due_date, id").
+		Find(&fees).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range fees {
+		fillFee(&fees[i])
+	}
+	return fees, nil
+}
+
+// FindByTenant 租户的全部费用（不含回收站中的费用），按到期日从早到晚排列
+func (r *feeRepository) FindByTenant(tenantID uint) ([]model.Fee, error) {
+	var fees []model.Fee
+	err := r.db.Preload("Tenant", withTrashed).
+		Where("tenant_id = ?", tenantID).
+		Order("due_date, id").
+		Find(&fees).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range fees {
+		fillFee(&fees[i])
+	}
+	return fees, nil
+}
+
+// FindDueBefore 到期日早于 before 且处于指定状态的费用，按到期日排列
+func (r *feeRepository) FindDueBefore(before time.Time, statuses ...string) ([]model.Fee, error) {
+	var fees []model.Fee
+	err := r.db.Preload("Tenant", withTrashed).
+		Where("due_date < ? AND status IN ?", before, statuses).
+		Order("due_date, id").
+		Find(&fees).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range fees {
+		fillFee(&fees[i])
+	}
+	return fees, nil
+}
+
+// ListOverdue 分页获取逾期费用，逾期最久的在前
+func (r *feeRepository) ListOverdue(page, pageSize int, tenantID uint, feeType string) ([]model.Fee, int64, error) {
+	var fees []model.Fee
+	var total int64
+
+	query := r.db.Model(&model.Fee{}).Preload("Tenant", withTrashed).Where("status = ?", "overdue")
+
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	if feeType != "" {
+		query = query.Where("fee_type = ?", feeType)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("due_date, id").Find(&fees).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range fees {
+		fillFee(&fees[i])
+	}
+
+	return fees, total, nil
+}
+
+func (r *feeRepository) FindPenalties(feeIDs []uint) ([]model.Fee, error) {
+	var fees []model.Fee
+	if len(feeIDs) == 0 {
+		return fees, nil
+	}
+	if err := r.db.Unscoped().Where("penalty_of_id IN ?", feeIDs).Find(&fees).Error; err != nil {
+		return nil, err
+	}
+	for i := range fees {
+		fees[i].Balance = fees[i].Outstanding()
+	}
+	return fees, nil
+}
+
+// FindByTenantPeriod 租户该账期的全部费用，按到期日排列
+func (r *feeRepository) FindByTenantPeriod(tenantID uint, period string) ([]model.Fee, error) {
+	var fees []model.Fee
+	err := r.db.Preload("Tenant", withTrashed).
+		Where("tenant_id = ? AND period = ?", tenantID, period).
+		Order("due_date, id").
+		Find(&fees).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range fees {
+		fillFee(&fees[i])
+	}
+	return fees, nil
+}
+
+// outstandingStatuses 尚未结清的费用状态
+var outstandingStatuses = []string{"unpaid", "partially_paid", "overdue"}
+
+// received 未冲正收款中到账时间在 start 与 end 之间的分配明细，关联所分配的费用（含回收站中的费用）
+func (r *feeRepository) received(start, end time.Time) *gorm.DB {
+	return r.db.Table("payment_allocations").
+		Joins("JOIN payments ON payments.id = payment_allocations.payment_id").
+		Joins("JOIN fees ON fees.id = payment_allocations.fee_id").
+		Where("payments.status = ? AND payments.received_at >= ? AND payments.received_at <= ?", model.PaymentStatusCompleted, start, end)
+}
+
+func (r *feeRepository) SumByTypeAndPeriod(feeType string, start, end time.Time) (float64, error) {
 	var sum float64
-	err := r.db.Model(&model.Fee{}).
-		Where("fee_type = ? AND status = 'paid' AND paid_date >= ? AND paid_date <= ?", feeType, start, end).
-		Select("COALESCE(SUM(amount), 0)").
+	err := r.received(start, end).
+		Where("fees.fee_type = ?", feeType).
+		Select("COALESCE(SUM(payment_allocations.amount), 0)").
 		Scan(&sum).Error
 	return sum, err
 }
 
-func (r *FeeRepository) SumByPeriod(start, end time.Time) (float64, error) {
+func (r *feeRepository) SumByPeriod(start, end time.Time) (float64, error) {
 	var sum float64
-	err := r.db.Model(&model.Fee{}).
-		Where("status = 'paid' AND paid_date >= ? AND paid_date <= ?", start, end).
-		Select("COALESCE(SUM(amount), 0)").
+	err := r.received(start, end).
+		Select("COALESCE(SUM(payment_allocations.amount), 0)").
 		Scan(&sum).Error
 	return sum, err
 }
 
-func (r *FeeRepository) CountByStatus(status string) (int64, error) {
+func (r *feeRepository) CountByTenant(tenantID uint, statuses ...string) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.Fee{}).Where("tenant_id = ?", tenantID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *feeRepository) CountByStatus(status string) (int64, error) {
 	var count int64
 	if err := r.db.Model(&model.Fee{}).Where("status = ?", status).Count(&count).Error; err != nil {
 		return 0, err
@@ -99,11 +265,11 @@ func (r *FeeRepository) CountByStatus(status string) (int64, error) {
 	return count, nil
 }
 
-func (r *FeeRepository) SumUnpaidAmount() (float64, error) {
+func (r *feeRepository) SumUnpaidAmount() (float64, error) {
 	var sum float64
 	err := r.db.Model(&model.Fee{}).
-		Where("status IN ('unpaid', 'overdue')").
-		Select("COALESCE(SUM(amount), 0)").
+		Where("status IN ?", outstandingStatuses).
+		Select("COALESCE(SUM(amount - paid_amount), 0)").
 		Scan(&sum).Error
 	return sum, err
 }
@@ -113,12 +279,12 @@ type FeeComposition struct {
 	Amount  float64 `json:"amount"`
 }
 
-func (r *FeeRepository) GetComposition(start, end time.Time) ([]FeeComposition, error) {
+func (r *feeRepository) GetComposition(start, end time.Time) ([]FeeComposition, error) {
 	var compositions []FeeComposition
-	err := r.db.Model(&model.Fee{}).
-		Select("fee_type, COALESCE(SUM(amount), 0) as amount").
-		Where("status = 'paid' AND paid_date >= ? AND paid_date <= ?", start, end).
-		Group("fee_type").
+	err := r.received(start, end).
+		Select("fees.fee_type, COALESCE(SUM(payment_allocations.amount), 0) as amount").
+		Group("fees.fee_type").
+		Order("fees.fee_type").
 		Scan(&compositions).Error
 	return compositions, err
 }
@@ -128,12 +294,11 @@ type IncomeByMonth struct {
 	Amount float64 `json:"amount"`
 }
 
-func (r *FeeRepository) GetIncomeByMonth(start, end time.Time) ([]IncomeByMonth, error) {
+func (r *feeRepository) GetIncomeByMonth(start, end time.Time) ([]IncomeByMonth, error) {
 	var incomes []IncomeByMonth
-	err := r.db.Model(&model.Fee{}).
-		Select("TO_CHAR(paid_date, 'YYYY-MM') as month, COALESCE(SUM(amount), 0) as amount").
-		Where("status = 'paid' AND paid_date >= ? AND paid_date <= ?", start, end).
-		Group("TO_CHAR(paid_date, 'YYYY-MM')").
+	err := r.received(start, end).
+		Select("TO_CHAR(payments.received_at, 'YYYY-MM') as month, COALESCE(SUM(payment_allocations.amount), 0) as amount").
+		Group("TO_CHAR(payments.received_at, 'YYYY-MM')").
 		Order("month ASC").
 		Scan(&incomes).Error
 	return incomes, err
@@ -145,15 +310,68 @@ type TenantFeeRanking struct {
 	Amount     float64 `json:"amount"`
 }
 
-func (r *FeeRepository) GetTenantRanking(limit int, start, end time.Time) ([]TenantFeeRanking, error) {
+func (r *feeRepository) GetTenantRanking(limit int, start, end time.Time) ([]TenantFeeRanking, error) {
 	var rankings []TenantFeeRanking
-	err := r.db.Model(&model.Fee{}).
-		Select("fees.tenant_id, tenants.name as tenant_name, COALESCE(SUM(fees.amount), 0) as amount").
+	err := r.received(start, end).
+		Select("fees.tenant_id, tenants.name as tenant_name, COALESCE(SUM(payment_allocations.amount), 0) as amount").
 		Joins("LEFT JOIN tenants ON fees.tenant_id = tenants.id").
-		Where("fees.status = 'paid' AND fees.paid_date >= ? AND fees.paid_date <= ?", start, end).
 		Group("fees.tenant_id, tenants.name").
 		Order("amount DESC").
 		Limit(limit).
 		Scan(&rankings).Error
 	return rankings, err
 }
+
+func (r *feeRepository) ListTrashed(page, pageSize int) ([]model.Fee, int64, error) {
+	var fees []model.Fee
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Fee{}).Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL")
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("deleted_at DESC").Find(&fees).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range fees {
+		fillFee(&fees[i])
+	}
+
+	return fees, total, nil
+}
+
+func (r *feeRepository) FindTrashedByID(id uint) (*model.Fee, error) {
+	var fee model.Fee
+	if err := r.db.Unscoped().Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL").First(&fee, id).Error; err != nil {
+		return nil, err
+	}
+	fillFee(&fee)
+	return &fee, nil
+}
+
+func (r *feeRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.Fee{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// HasReferences 判断费用是否有收款分配、发票明细、贷项、余额抵扣或关联的滞纳金（含回收站中的滞纳金）
+func (r *feeRepository) HasReferences(id uint) (bool, error) {
+	var count int64
+	for _, m := range []interface{}{&model.PaymentAllocation{}, &model.InvoiceLine{}, &model.CreditNote{}, &model.CreditApplication{}} {
+		if err := r.db.Model(m).Where("fee_id = ?", id).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	if err := r.db.Unscoped().Model(&model.Fee{}).Where("penalty_of_id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *feeRepository) Purge(id uint) error {
+	return r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Fee{}, id).Error
+}
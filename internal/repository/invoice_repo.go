@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// preloadLines 按行号预加载发票明细与租户，租户进入回收站后发票仍显示租户名称
+func preloadLines(db *gorm.DB) *gorm.DB {
+	return db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Tenant", withTrashed)
+}
+
+func (r *invoiceRepository) Create(invoice *model.Invoice) error {
+	return r.db.Create(invoice).Error
+}
+
+func (r *invoiceRepository) FindByID(id uint) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := r.db.Scopes(preloadLines).First(&invoice, id).Error; err != nil {
+		return nil, err
+	}
+	invoice.TenantName = invoice.Tenant.Name
+	return &invoice, nil
+}
+
+// FindByIDForUpdate 读取发票并加排他锁，防止同一发票被重复开具、作废或超额冲红
+func (r *invoiceRepository) FindByIDForUpdate(id uint) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := r.db.Scopes(forUpdate, preloadLines).First(&invoice, id).Error; err != nil {
+		return nil, err
+	}
+	invoice.TenantName = invoice.Tenant.Name
+	return &invoice, nil
+}
+
+// Update 只更新编号、状态及开具、作废、冲红信息，金额与明细创建后不再修改
+func (r *invoiceRepository) Update(invoice *model.Invoice) error {
+	return r.db.Model(invoice).
+		Select("invoice_no", "status", "credited_amount", "issued_at", "issued_by", "voided_at", "voided_by", "void_reason", "updated_at").
+		Omit(clause.Associations).
+		Updates(invoice).Error
+}
+
+func (r *invoiceRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Invoice{}, id).Error
+}
+
+func (r *invoiceRepository) List(page, pageSize int, tenantID uint, kind, status, period string) ([]model.Invoice, int64, error) {
+	var invoices []model.Invoice
+	var total int64
+
+	query := r.db.Model(&model.Invoice{}).Scopes(preloadLines)
+
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("id DESC").Find(&invoices).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range invoices {
+		invoices[i].TenantName = invoices[i].Tenant.Name
+	}
+
+	return invoices, total, nil
+}
+
+func (r *invoiceRepository) FindCreditNotes(originalID uint) ([]model.Invoice, error) {
+	var invoices []model.Invoice
+	err := r.db.Scopes(preloadLines).
+		Where("original_id = ? AND kind = ?", originalID, model.InvoiceKindCreditNote).
+		Order("id").
+		Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range invoices {
+		invoices[i].TenantName = invoices[i].Tenant.Name
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) FindInvoicedFees(feeIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(feeIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&model.InvoiceLine{}).
+		Joins("JOIN invoices ON invoices.id = invoice_lines.invoice_id").
+		Where("invoices.kind = ? AND invoices.status <> ?", model.InvoiceKindInvoice, model.InvoiceStatusVoid).
+		Where("invoice_lines.fee_id IN ?", feeIDs).
+		Distinct().
+		Pluck("invoice_lines.fee_id", &ids).Error
+	return ids, err
+}
+
+func (r *invoiceRepository) FindIssuedByFee(feeID uint) (*model.Invoice, error) {
+	var invoice model.Invoice
+	err := r.db.Scopes(preloadLines).
+		Where("kind = ? AND status = ?", model.InvoiceKindInvoice, model.InvoiceStatusIssued).
+		Where("id IN (?)", r.db.Model(&model.InvoiceLine{}).Select("invoice_id").Where("fee_id = ?", feeID)).
+		First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	invoice.TenantName = invoice.Tenant.Name
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) NextNumber(series string, year int) (int, error) {
+	return nextSequence(r.db, series, year)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type loginHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginHistoryRepository(db *gorm.DB) LoginHistoryRepository {
+	return &loginHistoryRepository{db: db}
+}
+
+func (r *loginHistoryRepository) Create(history *model.LoginHistory) error {
+	return r.db.Create(history).Error
+}
+
+func (r *loginHistoryRepository) List(page, pageSize int, userID uint, username, ip string, success *bool) ([]model.LoginHistory, int64, error) {
+	var histories []model.LoginHistory
+	var total int64
+
+	query := r.db.Model(&model.LoginHistory{})
+
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if success != nil {
+		query = query.Where("success = ?", *success)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&histories).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return histories, total, nil
+}
+
+// CountFailuresByIPSince 统计某 IP 自 since 起的登录失败次数
+func (r *loginHistoryRepository) CountFailuresByIPSince(ip string, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.LoginHistory{}).
+		Where("ip = ? AND success = ? AND created_at >= ?", ip, false, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
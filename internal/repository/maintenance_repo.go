@@ -8,28 +8,37 @@ import (
 	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
-type MaintenanceRepository struct {
+type maintenanceRepository struct {
 	db *gorm.DB
 }
 
-func NewMaintenanceRepository(db *gorm.DB) *MaintenanceRepository {
-	return &MaintenanceRepository{db: db}
+func NewMaintenanceRepository(db *gorm.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: db}
 }
 
-func (r *MaintenanceRepository) Create(maintenance *model.Maintenance) error {
+func (r *maintenanceRepository) Create(maintenance *model.Maintenance) error {
 	return r.db.Create(maintenance).Error
 }
 
-func (r *MaintenanceRepository) FindByID(id uint) (*model.Maintenance, error) {
+func (r *maintenanceRepository) FindByID(id uint) (*model.Maintenance, error) {
 	var maintenance model.Maintenance
-	if err := r.db.Preload("Tenant").First(&maintenance, id).Error; err != nil {
+	if err := r.db.Preload("Tenant", withTrashed).First(&maintenance, id).Error; err != nil {
 		return nil, err
 	}
 	maintenance.TenantName = maintenance.Tenant.Name
 	return &maintenance, nil
 }
 
-func (r *MaintenanceRepository) FindByTicketNo(ticketNo string) (*model.Maintenance, error) {
+// FindByIDForUpdate 读取工单并加排他锁
+func (r *maintenanceRepository) FindByIDForUpdate(id uint) (*model.Maintenance, error) {
+	var maintenance model.Maintenance
+	if err := r.db.Scopes(forUpdate).First(&maintenance, id).Error; err != nil {
+		return nil, err
+	}
+	return &maintenance, nil
+}
+
+func (r *maintenanceRepository) FindByTicketNo(ticketNo string) (*model.Maintenance, error) {
 	var maintenance model.Maintenance
 	if err := r.db.Where("ticket_no = ?", ticketNo).First(&maintenance).Error; err != nil {
 		return nil, err
@@ -37,20 +46,23 @@ func (r *MaintenanceRepository) FindByTicketNo(ticketNo string) (*model.Maintena
 	return &maintenance, nil
 }
 
-func (r *MaintenanceRepository) Update(maintenance *model.Maintenance) error {
-	return r.db.Save(maintenance).Error
+func (r *maintenanceRepository) Update(maintenance *model.Maintenance) error {
+	return updateWithVersion(r.db, maintenance, &maintenance.Version)
 }
 
-func (r *MaintenanceRepository) Delete(id uint) error {
+func (r *maintenanceRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Maintenance{}, id).Error
 }
 
-func (r *MaintenanceRepository) List(page, pageSize int, keyword, maintenanceType, status, priority string) ([]model.Maintenance, int64, error) {
+func (r *maintenanceRepository) List(page, pageSize int, tenantID uint, keyword, maintenanceType, status, priority string) ([]model.Maintenance, int64, error) {
 	var maintenances []model.Maintenance
 	var total int64
 
-	query := r.db.Model(&model.Maintenance{}).Preload("Tenant")
+	query := r.db.Model(&model.Maintenance{}).Preload("Tenant", withTrashed)
 
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
 	if keyword != "" {
 		query = query.Where("ticket_no ILIKE ? OR description ILIKE ?", "%"+keyword+"%", "%"+keyword+"%")
 	}
@@ -78,7 +90,7 @@ func (r *MaintenanceRepository) List(page, pageSize int, keyword, maintenanceTyp
 	return maintenances, total, nil
 }
 
-func (r *MaintenanceRepository) CountByStatus(status string) (int64, error) {
+func (r *maintenanceRepository) CountByStatus(status string) (int64, error) {
 	var count int64
 	if err := r.db.Model(&model.Maintenance{}).Where("status = ?", status).Count(&count).Error; err != nil {
 		return 0, err
@@ -91,7 +103,7 @@ type MaintenanceStats struct {
 	Count int64  `json:"count"`
 }
 
-func (r *MaintenanceRepository) GetStatsByType(start, end time.Time) ([]MaintenanceStats, error) {
+func (r *maintenanceRepository) GetStatsByType(start, end time.Time) ([]MaintenanceStats, error) {
 	var stats []MaintenanceStats
 	err := r.db.Model(&model.Maintenance{}).
 		Select("type, COUNT(*) as count").
@@ -106,7 +118,7 @@ type MaintenanceStatusStats struct {
 	Count  int64  `json:"count"`
 }
 
-func (r *MaintenanceRepository) GetStatsByStatus(start, end time.Time) ([]MaintenanceStatusStats, error) {
+func (r *maintenanceRepository) GetStatsByStatus(start, end time.Time) ([]MaintenanceStatusStats, error) {
 	var stats []MaintenanceStatusStats
 	err := r.db.Model(&model.Maintenance{}).
 		Select("status, COUNT(*) as count").
@@ -116,7 +128,7 @@ func (r *MaintenanceRepository) GetStatsByStatus(start, end time.Time) ([]Mainte
 	return stats, err
 }
 
-func (r *MaintenanceRepository) GetLastTicketNo() (string, error) {
+func (r *maintenanceRepository) GetLastTicketNo() (string, error) {
 	var maintenance model.Maintenance
 	err := r.db.Order("created_at DESC").First(&maintenance).Error
 	if err != nil {
@@ -127,3 +139,40 @@ func (r *MaintenanceRepository) GetLastTicketNo() (string, error) {
 	}
 	return maintenance.TicketNo, nil
 }
+
+func (r *maintenanceRepository) ListTrashed(page, pageSize int) ([]model.Maintenance, int64, error) {
+	var maintenances []model.Maintenance
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Maintenance{}).Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL")
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("deleted_at DESC").Find(&maintenances).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range maintenances {
+		maintenances[i].TenantName = maintenances[i].Tenant.Name
+	}
+
+	return maintenances, total, nil
+}
+
+func (r *maintenanceRepository) FindTrashedByID(id uint) (*model.Maintenance, error) {
+	var maintenance model.Maintenance
+	if err := r.db.Unscoped().Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL").First(&maintenance, id).Error; err != nil {
+		return nil, err
+	}
+	maintenance.TenantName = maintenance.Tenant.Name
+	return &maintenance, nil
+}
+
+func (r *maintenanceRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.Maintenance{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *maintenanceRepository) Purge(id uint) error {
+	return r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Maintenance{}, id).Error
+}
@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type accountRepository struct {
+	s *Store
+}
+
+func NewAccountRepository(s *Store) repository.AccountRepository {
+	return &accountRepository{s: s}
+}
+
+func (r *accountRepository) CreateCreditNote(note *model.CreditNote) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	if err := d.tenantExists(note.TenantID); err != nil {
+		return err
+	}
+	if note.FeeID != nil {
+		if _, ok := d.fees[*note.FeeID]; !ok {
+			return fmt.Errorf("foreign key violation: fee %d does not exist", *note.FeeID)
+		}
+	}
+	if note.InvoiceID != nil {
+		if _, ok := d.invoices[*note.InvoiceID]; !ok {
+			return fmt.Errorf("foreign key violation: invoice %d does not exist", *note.InvoiceID)
+		}
+	}
+	for _, existing := range d.creditNotes {
+		if existing.CreditNo == note.CreditNo {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	note.ID = d.nextID("credit_notes")
+	touch(&note.CreatedAt, nil)
+	stored := *note
+	stored.Tenant, stored.TenantName = model.Tenant{}, ""
+	d.creditNotes[note.ID] = stored
+	return nil
+}
+
+func (r *accountRepository) FindCreditNoteByID(id uint) (*model.CreditNote, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	note, ok := r.s.data.creditNotes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	note.TenantName = r.s.data.tenantName(note.TenantID)
+	return &note, nil
+}
+
+func (r *accountRepository) ListCreditNotes(page, pageSize int, tenantID uint) ([]model.CreditNote, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notes := filter(r.s.data.creditNotes, func(n model.CreditNote) bool {
+		return tenantID == 0 || n.TenantID == tenantID
+	})
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].ID > notes[j].ID })
+
+	result, total := paginate(notes, page, pageSize)
+	for i := range result {
+		result[i].TenantName = r.s.data.tenantName(result[i].TenantID)
+	}
+	return result, total, nil
+}
+
+func (r *accountRepository) FindCreditNotesByTenant(tenantID uint) ([]model.CreditNote, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notes := filter(r.s.data.creditNotes, func(n model.CreditNote) bool { return n.TenantID == tenantID })
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].IssuedAt.Before(notes[j].IssuedAt) })
+	return notes, nil
+}
+
+func (r *accountRepository) FindCreditNotesByInvoice(invoiceID uint) ([]model.CreditNote, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return filter(r.s.data.creditNotes, func(n model.CreditNote) bool {
+		return n.InvoiceID != nil && *n.InvoiceID == invoiceID
+	}), nil
+}
+
+func (r *accountRepository) VoidCreditNote(note *model.CreditNote) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.data.creditNotes[note.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.VoidedAt, stored.VoidedBy = note.VoidedAt, note.VoidedBy
+	r.s.data.creditNotes[note.ID] = stored
+	return nil
+}
+
+func (r *accountRepository) SumCreditNotesByFee(feeID uint) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var total float64
+	for _, n := range r.s.data.creditNotes {
+		if n.FeeID != nil && *n.FeeID == feeID && n.VoidedAt == nil {
+			total += n.Amount
+		}
+	}
+	return roundCents(total), nil
+}
+
+func (r *accountRepository) CreateRefund(refund *model.Refund) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	if err := d.tenantExists(refund.TenantID); err != nil {
+		return err
+	}
+	if refund.PaymentID != nil {
+		if _, ok := d.payments[*refund.PaymentID]; !ok {
+			return fmt.Errorf("foreign key violation: payment %d does not exist", *refund.PaymentID)
+		}
+	}
+	for _, existing := range d.refunds {
+		if existing.RefundNo == refund.RefundNo {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	refund.ID = d.nextID("refunds")
+	touch(&refund.CreatedAt, nil)
+	stored := *refund
+	stored.Tenant, stored.TenantName = model.Tenant{}, ""
+	d.refunds[refund.ID] = stored
+	return nil
+}
+
+func (r *accountRepository) FindRefundByID(id uint) (*model.Refund, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	refund, ok := r.s.data.refunds[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	refund.TenantName = r.s.data.tenantName(refund.TenantID)
+	return &refund, nil
+}
+
+func (r *accountRepository) ListRefunds(page, pageSize int, tenantID uint) ([]model.Refund, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	refunds := filter(r.s.data.refunds, func(rf model.Refund) bool {
+		return tenantID == 0 || rf.TenantID == tenantID
+	})
+	sort.SliceStable(refunds, func(i, j int) bool {
+		return newestFirst(refunds[i].RefundedAt, refunds[j].RefundedAt, refunds[i].ID, refunds[j].ID)
+	})
+
+	result, total := paginate(refunds, page, pageSize)
+	for i := range result {
+		result[i].TenantName = r.s.data.tenantName(result[i].TenantID)
+	}
+	return result, total, nil
+}
+
+func (r *accountRepository) FindRefundsByTenant(tenantID uint) ([]model.Refund, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	refunds := filter(r.s.data.refunds, func(rf model.Refund) bool { return rf.TenantID == tenantID })
+	sort.SliceStable(refunds, func(i, j int) bool { return refunds[i].RefundedAt.Before(refunds[j].RefundedAt) })
+	return refunds, nil
+}
+
+func (r *accountRepository) SumRefundsByPayment(paymentID uint) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var total float64
+	for _, rf := range r.s.data.refunds {
+		if rf.PaymentID != nil && *rf.PaymentID == paymentID {
+			total += rf.Amount
+		}
+	}
+	return roundCents(total), nil
+}
+
+func (r *accountRepository) CreateApplication(application *model.CreditApplication) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	if err := d.tenantExists(application.TenantID); err != nil {
+		return err
+	}
+	if _, ok := d.fees[application.FeeID]; !ok {
+		return fmt.Errorf("foreign key violation: fee %d does not exist", application.FeeID)
+	}
+
+	application.ID = d.nextID("credit_applications")
+	touch(&application.CreatedAt, nil)
+	d.creditApplications[application.ID] = *application
+	return nil
+}
+
+func (r *accountRepository) CreditBalance(tenantID uint) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	var balance float64
+	for _, n := range d.creditNotes {
+		if n.TenantID == tenantID && n.VoidedAt == nil {
+			balance += n.Amount
+		}
+	}
+	for _, p := range d.payments {
+		if p.TenantID == tenantID && p.Status == model.PaymentStatusCompleted {
+			balance += p.Credited
+		}
+	}
+	for _, rf := range d.refunds {
+		if rf.TenantID == tenantID {
+			balance -= rf.Amount
+		}
+	}
+	for _, a := range d.creditApplications {
+		if a.TenantID == tenantID {
+			balance -= a.Amount
+		}
+	}
+	return roundCents(balance), nil
+}
+
+func (r *accountRepository) NextNumber(series string, year int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.data.nextSequence(series, year), nil
+}
+
+// roundCents 模拟 decimal(10,2) 求和，避免浮点累加误差
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type apiKeyRepository struct {
+	s *Store
+}
+
+func NewAPIKeyRepository(s *Store) repository.APIKeyRepository {
+	return &apiKeyRepository{s: s}
+}
+
+func (r *apiKeyRepository) Create(key *model.APIKey) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, k := range r.s.data.apiKeys {
+		if k.Prefix == key.Prefix {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	key.ID = r.s.data.nextID("api_keys")
+	touch(&key.CreatedAt, &key.UpdatedAt)
+	r.s.data.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*model.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key, ok := r.s.data.apiKeys[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, key := range r.s.data.apiKeys {
+		if key.Prefix == prefix {
+			return &key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *apiKeyRepository) List(page, pageSize int, userID uint) ([]model.APIKey, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	keys := filter(r.s.data.apiKeys, func(k model.APIKey) bool {
+		return userID == 0 || k.UserID == userID
+	})
+	sort.SliceStable(keys, func(i, j int) bool {
+		return newestFirst(keys[i].CreatedAt, keys[j].CreatedAt, keys[i].ID, keys[j].ID)
+	})
+
+	result, total := paginate(keys, page, pageSize)
+	return result, total, nil
+}
+
+func (r *apiKeyRepository) Revoke(id uint, revokedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if key, ok := r.s.data.apiKeys[id]; ok && key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+		key.UpdatedAt = time.Now()
+		r.s.data.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uint, ip string, usedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if key, ok := r.s.data.apiKeys[id]; ok {
+		key.LastUsedAt = &usedAt
+		key.LastUsedIP = ip
+		r.s.data.apiKeys[id] = key
+	}
+	return nil
+}
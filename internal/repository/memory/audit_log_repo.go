@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type auditLogRepository struct {
+	s *Store
+}
+
+func NewAuditLogRepository(s *Store) repository.AuditLogRepository {
+	return &auditLogRepository{s: s}
+}
+
+func (r *auditLogRepository) Create(log *model.AuditLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	log.ID = r.s.data.nextID("audit_logs")
+	touch(&log.CreatedAt, nil)
+	r.s.data.auditLogs[log.ID] = *log
+	return nil
+}
+
+func (r *auditLogRepository) List(page, pageSize int, entityType string, entityID, actorID uint, action, requestID string, from, to *time.Time) ([]model.AuditLog, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	logs := filter(r.s.data.auditLogs, func(l model.AuditLog) bool {
+		return (entityType == "" || l.EntityType == entityType) &&
+			(entityID == 0 || l.EntityID == entityID) &&
+			(actorID == 0 || l.ActorID == actorID) &&
+			(action == "" || l.Action == action) &&
+			(requestID == "" || l.RequestID == requestID) &&
+			(from == nil || !l.CreatedAt.Before(*from)) &&
+			(to == nil || l.CreatedAt.Before(*to))
+	})
+	sort.SliceStable(logs, func(i, j int) bool {
+		return newestFirst(logs[i].CreatedAt, logs[j].CreatedAt, logs[i].ID, logs[j].ID)
+	})
+
+	result, total := paginate(logs, page, pageSize)
+	return result, total, nil
+}
+
+func (r *auditLogRepository) ListByEntity(entityType string, entityID uint) ([]model.AuditLog, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	logs := filter(r.s.data.auditLogs, func(l model.AuditLog) bool {
+		return l.EntityType == entityType && l.EntityID == entityID
+	})
+	sort.SliceStable(logs, func(i, j int) bool {
+		return !newestFirst(logs[i].CreatedAt, logs[j].CreatedAt, logs[i].ID, logs[j].ID)
+	})
+	return logs, nil
+}
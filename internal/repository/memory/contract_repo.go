@@ -0,0 +1,446 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type contractRepository struct {
+	s *Store
+}
+
+func NewContractRepository(s *Store) repository.ContractRepository {
+	return &contractRepository{s: s}
+}
+
+// load 返回副本并填充租户名称与租赁房间，调用方须持有 mu
+func (r *contractRepository) load(c model.Contract) model.Contract {
+	c.TenantName = r.s.data.tenantName(c.TenantID)
+	c.Rooms = filter(r.s.data.contractRooms, func(cr model.ContractRoom) bool { return cr.ContractID == c.ID })
+	for i := range c.Rooms {
+		c.Rooms[i].RoomNo = r.s.data.rooms[c.Rooms[i].RoomID].RoomNo
+	}
+	return c
+}
+
+func (r *contractRepository) save(c model.Contract) {
+	c.Tenant = model.Tenant{}
+	c.TenantName = ""
+	c.Rooms = nil
+	c.RenewalChain = nil
+	r.s.data.contracts[c.ID] = c
+}
+
+// checkContract 模拟租户与前序合同的外键约束
+func (r *contractRepository) checkContract(contract *model.Contract) error {
+	if err := r.s.data.tenantExists(contract.TenantID); err != nil {
+		return err
+	}
+	if contract.PredecessorID != nil {
+		if _, ok := r.s.data.contracts[*contract.PredecessorID]; !ok {
+			return fmt.Errorf("foreign key violation: contract %d does not exist", *contract.PredecessorID)
+		}
+	}
+	return nil
+}
+
+func (r *contractRepository) Create(contract *model.Contract) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.checkContract(contract); err != nil {
+		return err
+	}
+	for _, c := range r.s.data.contracts {
+		if c.ContractNo == contract.ContractNo {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	contract.ID = r.s.data.nextID("contracts")
+	if contract.Status == "" {
+		contract.Status = model.ContractStatusDraft
+	}
+	if contract.Escalation.Type == "" {
+		contract.Escalation.Type = model.EscalationNone
+	}
+	if contract.Escalation.Timing == "" {
+		contract.Escalation.Timing = model.EscalationOnRenewal
+	}
+	if contract.Billing.Cycle == "" {
+		contract.Billing.Cycle = model.BillingMonthly
+	}
+	if contract.Billing.DueDay == 0 {
+		contract.Billing.DueDay = 1
+	}
+	if contract.Billing.Mode == "" {
+		contract.Billing.Mode = model.BillingInAdvance
+	}
+	if contract.Version == 0 {
+		contract.Version = 1
+	}
+	touch(&contract.CreatedAt, &contract.UpdatedAt)
+	r.save(*contract)
+	return nil
+}
+
+func (r *contractRepository) FindByID(id uint) (*model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contract, ok := r.s.data.contracts[id]
+	if !ok || contract.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	contract = r.load(contract)
+	return &contract, nil
+}
+
+func (r *contractRepository) FindByIDForUpdate(id uint) (*model.Contract, error) {
+	return r.FindByID(id)
+}
+
+func (r *contractRepository) FindByContractNo(contractNo string) (*model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, contract := range r.s.data.contracts {
+		if contract.ContractNo == contractNo && !contract.DeletedAt.Valid {
+			return &contract, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *contractRepository) Update(contract *model.Contract) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.contracts[contract.ID]
+	if !ok || current.DeletedAt.Valid || current.Version != contract.Version {
+		return repository.ErrVersionConflict
+	}
+	if err := r.checkContract(contract); err != nil {
+		return err
+	}
+
+	contract.Version++
+	contract.UpdatedAt = time.Now()
+	r.save(*contract)
+	return nil
+}
+
+func (r *contractRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if contract, ok := r.s.data.contracts[id]; ok && !contract.DeletedAt.Valid {
+		contract.DeletedAt = softDeleted(time.Now())
+		r.s.data.contracts[id] = contract
+	}
+	return nil
+}
+
+func (r *contractRepository) List(page, pageSize int, keyword, status string, startDateFrom, startDateTo *time.Time) ([]model.Contract, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid &&
+			(keyword == "" || containsFold(c.ContractNo, keyword)) &&
+			(status == "" || c.Status == status) &&
+			(startDateFrom == nil || !c.StartDate.Before(*startDateFrom)) &&
+			(startDateTo == nil || !c.StartDate.After(*startDateTo))
+	})
+	sort.SliceStable(contracts, func(i, j int) bool {
+		return newestFirst(contracts[i].CreatedAt, contracts[j].CreatedAt, contracts[i].ID, contracts[j].ID)
+	})
+
+	result, total := paginate(contracts, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *contractRepository) FindByTenantID(tenantID uint) ([]model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && c.TenantID == tenantID
+	})
+	for i := range contracts {
+		contracts[i] = r.load(contracts[i])
+	}
+	return contracts, nil
+}
+
+func (r *contractRepository) FindByRoom(roomID uint, statuses ...string) ([]model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	leased := map[uint]bool{}
+	for _, cr := range r.s.data.contractRooms {
+		if cr.RoomID == roomID {
+			leased[cr.ContractID] = true
+		}
+	}
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && leased[c.ID] && inStatuses(c.Status, statuses)
+	})
+	sort.SliceStable(contracts, func(i, j int) bool { return contracts[i].StartDate.Before(contracts[j].StartDate) })
+	return contracts, nil
+}
+
+func (r *contractRepository) ReplaceRooms(contractID uint, rooms []model.ContractRoom) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.contracts[contractID]; !ok {
+		return fmt.Errorf("foreign key violation: contract %d does not exist", contractID)
+	}
+	seen := map[uint]bool{}
+	for _, room := range rooms {
+		if _, ok := r.s.data.rooms[room.RoomID]; !ok {
+			return fmt.Errorf("foreign key violation: room %d does not exist", room.RoomID)
+		}
+		if seen[room.RoomID] {
+			return gorm.ErrDuplicatedKey
+		}
+		seen[room.RoomID] = true
+	}
+
+	for id, cr := range r.s.data.contractRooms {
+		if cr.ContractID == contractID {
+			delete(r.s.data.contractRooms, id)
+		}
+	}
+	for i := range rooms {
+		rooms[i].ID = r.s.data.nextID("contract_rooms")
+		rooms[i].ContractID = contractID
+		rooms[i].Room = nil
+		touch(&rooms[i].CreatedAt, nil)
+		stored := rooms[i]
+		stored.RoomNo = ""
+		r.s.data.contractRooms[stored.ID] = stored
+	}
+	return nil
+}
+
+func (r *contractRepository) CreateTransition(transition *model.ContractTransition) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.contracts[transition.ContractID]; !ok {
+		return fmt.Errorf("foreign key violation: contract %d does not exist", transition.ContractID)
+	}
+
+	transition.ID = r.s.data.nextID("contract_transitions")
+	touch(&transition.CreatedAt, nil)
+	r.s.data.transitions[transition.ID] = *transition
+	return nil
+}
+
+func (r *contractRepository) FindTransitions(contractID uint) ([]model.ContractTransition, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return filter(r.s.data.transitions, func(t model.ContractTransition) bool {
+		return t.ContractID == contractID
+	}), nil
+}
+
+func (r *contractRepository) FindSuccessors(id uint) ([]model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && c.PredecessorID != nil && *c.PredecessorID == id
+	})
+	for i := range contracts {
+		contracts[i] = r.load(contracts[i])
+	}
+	return contracts, nil
+}
+
+func (r *contractRepository) FindByEscalationTiming(timing string, statuses ...string) ([]model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && c.Escalation.Enabled() && c.Escalation.Timing == timing && inStatuses(c.Status, statuses)
+	})
+	for i := range contracts {
+		contracts[i] = r.load(contracts[i])
+	}
+	return contracts, nil
+}
+
+func (r *contractRepository) CreateRentAdjustment(adjustment *model.RentAdjustment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.contracts[adjustment.ContractID]; !ok {
+		return fmt.Errorf("foreign key violation: contract %d does not exist", adjustment.ContractID)
+	}
+	if _, ok := r.s.data.rooms[adjustment.RoomID]; !ok {
+		return fmt.Errorf("foreign key violation: room %d does not exist", adjustment.RoomID)
+	}
+
+	adjustment.ID = r.s.data.nextID("contract_rent_adjustments")
+	touch(&adjustment.CreatedAt, nil)
+	stored := *adjustment
+	stored.RoomNo = ""
+	r.s.data.rentAdjustments[stored.ID] = stored
+	return nil
+}
+
+func (r *contractRepository) FindRentAdjustments(contractID uint) ([]model.RentAdjustment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	adjustments := filter(r.s.data.rentAdjustments, func(a model.RentAdjustment) bool { return a.ContractID == contractID })
+	sort.SliceStable(adjustments, func(i, j int) bool {
+		return adjustments[i].EffectiveDate.Before(adjustments[j].EffectiveDate)
+	})
+	for i := range adjustments {
+		adjustments[i].RoomNo = r.s.data.rooms[adjustments[i].RoomID].RoomNo
+	}
+	return adjustments, nil
+}
+
+func (r *contractRepository) CountByTenant(tenantID uint, statuses ...string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && c.TenantID == tenantID && inStatuses(c.Status, statuses)
+	})
+	return int64(len(contracts)), nil
+}
+
+func (r *contractRepository) FindEndingBefore(before time.Time, statuses ...string) ([]model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && c.EndDate.Before(before) && inStatuses(c.Status, statuses)
+	})
+	sort.SliceStable(contracts, func(i, j int) bool { return contracts[i].EndDate.Before(contracts[j].EndDate) })
+	for i := range contracts {
+		contracts[i] = r.load(contracts[i])
+	}
+	return contracts, nil
+}
+
+func (r *contractRepository) FindOverlapping(start, end time.Time, statuses ...string) ([]model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && !c.StartDate.After(end) && c.EndDate.After(start) && inStatuses(c.Status, statuses)
+	})
+	for i := range contracts {
+		contracts[i] = r.load(contracts[i])
+	}
+	return contracts, nil
+}
+
+func (r *contractRepository) CountByStatus(statuses ...string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool {
+		return !c.DeletedAt.Valid && inStatuses(c.Status, statuses)
+	})
+	return int64(len(contracts)), nil
+}
+
+func (r *contractRepository) ListTrashed(page, pageSize int) ([]model.Contract, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contracts := filter(r.s.data.contracts, func(c model.Contract) bool { return c.DeletedAt.Valid })
+	sort.SliceStable(contracts, func(i, j int) bool {
+		return contracts[i].DeletedAt.Time.After(contracts[j].DeletedAt.Time)
+	})
+
+	result, total := paginate(contracts, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *contractRepository) FindTrashedByID(id uint) (*model.Contract, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	contract, ok := r.s.data.contracts[id]
+	if !ok || !contract.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	contract = r.load(contract)
+	return &contract, nil
+}
+
+func (r *contractRepository) Restore(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if contract, ok := r.s.data.contracts[id]; ok {
+		contract.DeletedAt = gorm.DeletedAt{}
+		r.s.data.contracts[id] = contract
+	}
+	return nil
+}
+
+func (r *contractRepository) Purge(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if contract, ok := r.s.data.contracts[id]; ok && contract.DeletedAt.Valid {
+		delete(r.s.data.contracts, id)
+		for crID, cr := range r.s.data.contractRooms {
+			if cr.ContractID == id {
+				delete(r.s.data.contractRooms, crID)
+			}
+		}
+		for tID, t := range r.s.data.transitions {
+			if t.ContractID == id {
+				delete(r.s.data.transitions, tID)
+			}
+		}
+		for nID, n := range r.s.data.notifications {
+			if n.ContractID != nil && *n.ContractID == id {
+				delete(r.s.data.notifications, nID)
+			}
+		}
+		for aID, a := range r.s.data.rentAdjustments {
+			if a.ContractID == id {
+				delete(r.s.data.rentAdjustments, aID)
+			}
+		}
+		for fID, f := range r.s.data.fees {
+			if f.ContractID != nil && *f.ContractID == id {
+				f.ContractID = nil
+				r.s.data.fees[fID] = f
+			}
+		}
+		for cID, c := range r.s.data.contracts {
+			if c.PredecessorID != nil && *c.PredecessorID == id {
+				c.PredecessorID = nil
+				r.s.data.contracts[cID] = c
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type cpiIndexRepository struct {
+	s *Store
+}
+
+func NewCPIIndexRepository(s *Store) repository.CPIIndexRepository {
+	return &cpiIndexRepository{s: s}
+}
+
+func (r *cpiIndexRepository) List() ([]model.CPIIndex, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	indices := filter(r.s.data.cpiIndices, nil)
+	sort.SliceStable(indices, func(i, j int) bool { return indices[i].Year < indices[j].Year })
+	return indices, nil
+}
+
+func (r *cpiIndexRepository) FindByYear(year int) (*model.CPIIndex, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, index := range r.s.data.cpiIndices {
+		if index.Year == year {
+			return &index, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *cpiIndexRepository) Save(index *model.CPIIndex) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range r.s.data.cpiIndices {
+		if existing.Year == index.Year {
+			existing.Rate = index.Rate
+			existing.UpdatedAt = now
+			r.s.data.cpiIndices[id] = existing
+			*index = existing
+			return nil
+		}
+	}
+
+	index.ID = r.s.data.nextID("cpi_indices")
+	touch(&index.CreatedAt, &index.UpdatedAt)
+	r.s.data.cpiIndices[index.ID] = *index
+	return nil
+}
+
+func (r *cpiIndexRepository) Delete(year int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, index := range r.s.data.cpiIndices {
+		if index.Year == year {
+			delete(r.s.data.cpiIndices, id)
+		}
+	}
+	return nil
+}
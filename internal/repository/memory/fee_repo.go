@@ -0,0 +1,482 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type feeRepository struct {
+	s *Store
+}
+
+func NewFeeRepository(s *Store) repository.FeeRepository {
+	return &feeRepository{s: s}
+}
+
+func (r *feeRepository) load(fee model.Fee) model.Fee {
+	fee.TenantName = r.s.data.tenantName(fee.TenantID)
+	fee.Balance = fee.Outstanding()
+	return fee
+}
+
+func (r *feeRepository) save(fee model.Fee) {
+	fee.Tenant = model.Tenant{}
+	fee.TenantName = ""
+	fee.Balance = 0
+	r.s.data.fees[fee.ID] = fee
+}
+
+// active 未删除的费用，对应 GORM 默认的软删除过滤
+func (r *feeRepository) active(keep func(model.Fee) bool) []model.Fee {
+	return filter(r.s.data.fees, func(f model.Fee) bool {
+		return !f.DeletedAt.Valid && keep(f)
+	})
+}
+
+// receivedAllocation 一笔收款分配到费用的金额及收款、费用本身
+type receivedAllocation struct {
+	payment model.Payment
+	fee     model.Fee
+	amount  float64
+}
+
+// received 对应未冲正收款中 received_at BETWEEN start AND end 的分配明细，费用含回收站中的记录
+func (r *feeRepository) received(start, end time.Time) []receivedAllocation {
+	d := r.s.data
+	var out []receivedAllocation
+	for _, a := range filter(d.allocations, nil) {
+		p := d.payments[a.PaymentID]
+		if p.Status == model.PaymentStatusCompleted && between(&p.ReceivedAt, start, end) {
+			out = append(out, receivedAllocation{payment: p, fee: d.fees[a.FeeID], amount: a.Amount})
+		}
+	}
+	return out
+}
+
+func (r *feeRepository) Create(fee *model.Fee) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.create(fee)
+}
+
+func (r *feeRepository) create(fee *model.Fee) error {
+	if err := r.s.data.tenantExists(fee.TenantID); err != nil {
+		return err
+	}
+	if fee.ContractID != nil {
+		if _, ok := r.s.data.contracts[*fee.ContractID]; !ok {
+			return fmt.Errorf("foreign key violation: contract %d does not exist", *fee.ContractID)
+		}
+		if fee.FeeType == "rent" && r.rentExists(*fee.ContractID, fee.RoomNo, fee.Period) {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	if fee.PenaltyOfID != nil {
+		if _, ok := r.s.data.fees[*fee.PenaltyOfID]; !ok {
+			return fmt.Errorf("foreign key violation: fee %d does not exist", *fee.PenaltyOfID)
+		}
+		for _, f := range r.s.data.fees {
+			if f.PenaltyOfID != nil && *f.PenaltyOfID == *fee.PenaltyOfID {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+	}
+
+	fee.ID = r.s.data.nextID("fees")
+	if fee.Status == "" {
+		fee.Status = "unpaid"
+	}
+	if fee.Version == 0 {
+		fee.Version = 1
+	}
+	touch(&fee.CreatedAt, &fee.UpdatedAt)
+	r.save(*fee)
+	return nil
+}
+
+func (r *feeRepository) CreateIfAbsent(fee *model.Fee) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if fee.ContractID != nil && fee.FeeType == "rent" && r.rentExists(*fee.ContractID, fee.RoomNo, fee.Period) {
+		return false, nil
+	}
+	if err := r.create(fee); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *feeRepository) ExistsContractRent(contractID uint, roomNo, period string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.rentExists(contractID, roomNo, period), nil
+}
+
+// rentExists 模拟 idx_fees_contract_rent_period 唯一索引，包含已软删除的费用
+func (r *feeRepository) rentExists(contractID uint, roomNo, period string) bool {
+	for _, f := range r.s.data.fees {
+		if f.ContractID != nil && *f.ContractID == contractID && f.RoomNo == roomNo && f.Period == period && f.FeeType == "rent" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *feeRepository) FindByID(id uint) (*model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fee, ok := r.s.data.fees[id]
+	if !ok || fee.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	fee = r.load(fee)
+	return &fee, nil
+}
+
+func (r *feeRepository) FindByIDForUpdate(id uint) (*model.Fee, error) {
+	return r.FindByID(id)
+}
+
+func (r *feeRepository) Update(fee *model.Fee) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.fees[fee.ID]
+	if !ok || current.DeletedAt.Valid || current.Version != fee.Version {
+		return repository.ErrVersionConflict
+	}
+	if err := r.s.data.tenantExists(fee.TenantID); err != nil {
+		return err
+	}
+
+	fee.Version++
+	fee.UpdatedAt = time.Now()
+	r.save(*fee)
+	return nil
+}
+
+func (r *feeRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if fee, ok := r.s.data.fees[id]; ok && !fee.DeletedAt.Valid {
+		fee.DeletedAt = softDeleted(time.Now())
+		r.s.data.fees[id] = fee
+	}
+	return nil
+}
+
+func (r *feeRepository) List(page, pageSize int, tenantID uint, roomNo, feeType, status, period string) ([]model.Fee, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool {
+		return (tenantID == 0 || f.TenantID == tenantID) &&
+			(roomNo == "" || f.RoomNo == roomNo) &&
+			(feeType == "" || f.FeeType == feeType) &&
+			(status == "" || f.Status == status) &&
+			(period == "" || f.Period == period)
+	})
+	sort.SliceStable(fees, func(i, j int) bool { return fees[i].DueDate.After(fees[j].DueDate) })
+
+	result, total := paginate(fees, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+// outstandingStatuses 尚未结清的费用状态
+var outstandingStatuses = []string{"unpaid", "partially_paid", "overdue"}
+
+func (r *feeRepository) FindOutstanding(tenantID uint) ([]model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool {
+		return f.TenantID == tenantID && inStatuses(f.Status, outstandingStatuses) && f.PaidAmount < f.Amount
+	})
+	sort.SliceStable(fees, func(i, j int) bool { return fees[i].DueDate.Before(fees[j].DueDate) })
+	for i := range fees {
+		fees[i] = r.load(fees[i])
+	}
+	return fees, nil
+}
+
+func (r *feeRepository) FindByTenant(tenantID uint) ([]model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool { return f.TenantID == tenantID })
+	sort.SliceStable(fees, func(i, j int) bool { return fees[i].DueDate.Before(fees[j].DueDate) })
+	for i := range fees {
+		fees[i] = r.load(fees[i])
+	}
+	return fees, nil
+}
+
+func (r *feeRepository) FindDueBefore(before time.Time, statuses ...string) ([]model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool {
+		return f.DueDate.Before(before) && inStatuses(f.Status, statuses)
+	})
+	sort.SliceStable(fees, func(i, j int) bool { return fees[i].DueDate.Before(fees[j].DueDate) })
+	for i := range fees {
+		fees[i] = r.load(fees[i])
+	}
+	return fees, nil
+}
+
+func (r *feeRepository) ListOverdue(page, pageSize int, tenantID uint, feeType string) ([]model.Fee, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool {
+		return f.Status == "overdue" &&
+			(tenantID == 0 || f.TenantID == tenantID) &&
+			(feeType == "" || f.FeeType == feeType)
+	})
+	sort.SliceStable(fees, func(i, j int) bool { return fees[i].DueDate.Before(fees[j].DueDate) })
+
+	result, total := paginate(fees, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *feeRepository) FindPenalties(feeIDs []uint) ([]model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ids := map[uint]bool{}
+	for _, id := range feeIDs {
+		ids[id] = true
+	}
+	fees := filter(r.s.data.fees, func(f model.Fee) bool { return f.PenaltyOfID != nil && ids[*f.PenaltyOfID] })
+	for i := range fees {
+		fees[i] = r.load(fees[i])
+	}
+	return fees, nil
+}
+
+func (r *feeRepository) FindByTenantPeriod(tenantID uint, period string) ([]model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool { return f.TenantID == tenantID && f.Period == period })
+	sort.SliceStable(fees, func(i, j int) bool { return fees[i].DueDate.Before(fees[j].DueDate) })
+	for i := range fees {
+		fees[i] = r.load(fees[i])
+	}
+	return fees, nil
+}
+
+func (r *feeRepository) SumByTypeAndPeriod(feeType string, start, end time.Time) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var sum float64
+	for _, a := range r.received(start, end) {
+		if a.fee.FeeType == feeType {
+			sum += a.amount
+		}
+	}
+	return sum, nil
+}
+
+func (r *feeRepository) SumByPeriod(start, end time.Time) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var sum float64
+	for _, a := range r.received(start, end) {
+		sum += a.amount
+	}
+	return sum, nil
+}
+
+func (r *feeRepository) CountByTenant(tenantID uint, statuses ...string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool {
+		return f.TenantID == tenantID && inStatuses(f.Status, statuses)
+	})
+	return int64(len(fees)), nil
+}
+
+func (r *feeRepository) CountByStatus(status string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := r.active(func(f model.Fee) bool { return f.Status == status })
+	return int64(len(fees)), nil
+}
+
+func (r *feeRepository) SumUnpaidAmount() (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var sum float64
+	for _, f := range r.active(func(f model.Fee) bool { return inStatuses(f.Status, outstandingStatuses) }) {
+		sum += f.Amount - f.PaidAmount
+	}
+	return sum, nil
+}
+
+func (r *feeRepository) GetComposition(start, end time.Time) ([]repository.FeeComposition, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	byType := map[string]float64{}
+	for _, a := range r.received(start, end) {
+		byType[a.fee.FeeType] += a.amount
+	}
+
+	compositions := []repository.FeeComposition{}
+	for feeType, amount := range byType {
+		compositions = append(compositions, repository.FeeComposition{FeeType: feeType, Amount: amount})
+	}
+	sort.Slice(compositions, func(i, j int) bool { return compositions[i].FeeType < compositions[j].FeeType })
+	return compositions, nil
+}
+
+func (r *feeRepository) GetIncomeByMonth(start, end time.Time) ([]repository.IncomeByMonth, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	byMonth := map[string]float64{}
+	for _, a := range r.received(start, end) {
+		byMonth[a.payment.ReceivedAt.Format("2006-01")] += a.amount
+	}
+
+	incomes := []repository.IncomeByMonth{}
+	for month, amount := range byMonth {
+		incomes = append(incomes, repository.IncomeByMonth{Month: month, Amount: amount})
+	}
+	sort.Slice(incomes, func(i, j int) bool { return incomes[i].Month < incomes[j].Month })
+	return incomes, nil
+}
+
+func (r *feeRepository) GetTenantRanking(limit int, start, end time.Time) ([]repository.TenantFeeRanking, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	byTenant := map[uint]float64{}
+	for _, a := range r.received(start, end) {
+		byTenant[a.fee.TenantID] += a.amount
+	}
+
+	rankings := []repository.TenantFeeRanking{}
+	for tenantID, amount := range byTenant {
+		rankings = append(rankings, repository.TenantFeeRanking{
+			TenantID:   tenantID,
+			TenantName: r.s.data.tenantName(tenantID),
+			Amount:     amount,
+		})
+	}
+	sort.Slice(rankings, func(i, j int) bool {
+		if rankings[i].Amount != rankings[j].Amount {
+			return rankings[i].Amount > rankings[j].Amount
+		}
+		return rankings[i].TenantID < rankings[j].TenantID
+	})
+	if limit > 0 && len(rankings) > limit {
+		rankings = rankings[:limit]
+	}
+	return rankings, nil
+}
+
+func (r *feeRepository) ListTrashed(page, pageSize int) ([]model.Fee, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fees := filter(r.s.data.fees, func(f model.Fee) bool { return f.DeletedAt.Valid })
+	sort.SliceStable(fees, func(i, j int) bool {
+		return fees[i].DeletedAt.Time.After(fees[j].DeletedAt.Time)
+	})
+
+	result, total := paginate(fees, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *feeRepository) FindTrashedByID(id uint) (*model.Fee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	fee, ok := r.s.data.fees[id]
+	if !ok || !fee.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	fee = r.load(fee)
+	return &fee, nil
+}
+
+func (r *feeRepository) Restore(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if fee, ok := r.s.data.fees[id]; ok {
+		fee.DeletedAt = gorm.DeletedAt{}
+		r.s.data.fees[id] = fee
+	}
+	return nil
+}
+
+func (r *feeRepository) HasReferences(id uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, a := range r.s.data.allocations {
+		if a.FeeID == id {
+			return true, nil
+		}
+	}
+	for _, l := range r.s.data.invoiceLines {
+		if l.FeeID != nil && *l.FeeID == id {
+			return true, nil
+		}
+	}
+	for _, n := range r.s.data.creditNotes {
+		if n.FeeID != nil && *n.FeeID == id {
+			return true, nil
+		}
+	}
+	for _, a := range r.s.data.creditApplications {
+		if a.FeeID == id {
+			return true, nil
+		}
+	}
+	for _, f := range r.s.data.fees {
+		if f.PenaltyOfID != nil && *f.PenaltyOfID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *feeRepository) Purge(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if fee, ok := r.s.data.fees[id]; ok && fee.DeletedAt.Valid {
+		delete(r.s.data.fees, id)
+	}
+	return nil
+}
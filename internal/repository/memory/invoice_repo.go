@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type invoiceRepository struct {
+	s *Store
+}
+
+func NewInvoiceRepository(s *Store) repository.InvoiceRepository {
+	return &invoiceRepository{s: s}
+}
+
+// load 填充租户名称与按行号排序的明细
+func (r *invoiceRepository) load(invoice model.Invoice) model.Invoice {
+	d := r.s.data
+	invoice.TenantName = d.tenantName(invoice.TenantID)
+	invoice.Lines = filter(d.invoiceLines, func(l model.InvoiceLine) bool { return l.InvoiceID == invoice.ID })
+	return invoice
+}
+
+func (r *invoiceRepository) save(invoice model.Invoice) {
+	invoice.Tenant = model.Tenant{}
+	invoice.TenantName = ""
+	invoice.Lines = nil
+	r.s.data.invoices[invoice.ID] = invoice
+}
+
+func (r *invoiceRepository) Create(invoice *model.Invoice) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	if err := d.tenantExists(invoice.TenantID); err != nil {
+		return err
+	}
+	if invoice.OriginalID != nil {
+		if _, ok := d.invoices[*invoice.OriginalID]; !ok {
+			return fmt.Errorf("foreign key violation: invoice %d does not exist", *invoice.OriginalID)
+		}
+	}
+	if invoice.InvoiceNo != nil && r.numberTaken(*invoice.InvoiceNo, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+	for _, l := range invoice.Lines {
+		if l.FeeID != nil {
+			if _, ok := d.fees[*l.FeeID]; !ok {
+				return fmt.Errorf("foreign key violation: fee %d does not exist", *l.FeeID)
+			}
+		}
+	}
+
+	invoice.ID = d.nextID("invoices")
+	if invoice.Status == "" {
+		invoice.Status = model.InvoiceStatusDraft
+	}
+	touch(&invoice.CreatedAt, &invoice.UpdatedAt)
+	for i := range invoice.Lines {
+		l := &invoice.Lines[i]
+		l.ID = d.nextID("invoice_lines")
+		l.InvoiceID = invoice.ID
+		touch(&l.CreatedAt, nil)
+		d.invoiceLines[l.ID] = *l
+	}
+	r.save(*invoice)
+	return nil
+}
+
+// numberTaken 模拟 invoice_no 唯一索引
+func (r *invoiceRepository) numberTaken(no string, exceptID uint) bool {
+	for _, inv := range r.s.data.invoices {
+		if inv.ID != exceptID && inv.InvoiceNo != nil && *inv.InvoiceNo == no {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *invoiceRepository) FindByID(id uint) (*model.Invoice, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	invoice, ok := r.s.data.invoices[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	invoice = r.load(invoice)
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) FindByIDForUpdate(id uint) (*model.Invoice, error) {
+	return r.FindByID(id)
+}
+
+func (r *invoiceRepository) Update(invoice *model.Invoice) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.invoices[invoice.ID]
+	if !ok {
+		return nil
+	}
+	if invoice.InvoiceNo != nil && r.numberTaken(*invoice.InvoiceNo, invoice.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+	current.InvoiceNo = invoice.InvoiceNo
+	current.Status = invoice.Status
+	current.CreditedAmount = invoice.CreditedAmount
+	current.IssuedAt = invoice.IssuedAt
+	current.IssuedBy = invoice.IssuedBy
+	current.VoidedAt = invoice.VoidedAt
+	current.VoidedBy = invoice.VoidedBy
+	current.VoidReason = invoice.VoidReason
+	current.UpdatedAt = time.Now()
+	invoice.UpdatedAt = current.UpdatedAt
+	r.save(current)
+	return nil
+}
+
+func (r *invoiceRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	for _, inv := range d.invoices {
+		if inv.OriginalID != nil && *inv.OriginalID == id {
+			return fmt.Errorf("foreign key violation: invoice %d is referenced by credit note %d", id, inv.ID)
+		}
+	}
+	for lineID, l := range d.invoiceLines {
+		if l.InvoiceID == id {
+			delete(d.invoiceLines, lineID)
+		}
+	}
+	delete(d.invoices, id)
+	return nil
+}
+
+func (r *invoiceRepository) List(page, pageSize int, tenantID uint, kind, status, period string) ([]model.Invoice, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	invoices := filter(r.s.data.invoices, func(inv model.Invoice) bool {
+		return (tenantID == 0 || inv.TenantID == tenantID) &&
+			(kind == "" || inv.Kind == kind) &&
+			(status == "" || inv.Status == status) &&
+			(period == "" || inv.Period == period)
+	})
+	sort.SliceStable(invoices, func(i, j int) bool { return invoices[i].ID > invoices[j].ID })
+
+	result, total := paginate(invoices, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *invoiceRepository) FindCreditNotes(originalID uint) ([]model.Invoice, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	invoices := filter(r.s.data.invoices, func(inv model.Invoice) bool {
+		return inv.Kind == model.InvoiceKindCreditNote && inv.OriginalID != nil && *inv.OriginalID == originalID
+	})
+	for i := range invoices {
+		invoices[i] = r.load(invoices[i])
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) FindInvoicedFees(feeIDs []uint) ([]uint, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	wanted := map[uint]bool{}
+	for _, id := range feeIDs {
+		wanted[id] = true
+	}
+	found := map[uint]bool{}
+	ids := []uint{}
+	for _, l := range filter(d.invoiceLines, nil) {
+		inv := d.invoices[l.InvoiceID]
+		if l.FeeID == nil || !wanted[*l.FeeID] || found[*l.FeeID] ||
+			inv.Kind != model.InvoiceKindInvoice || inv.Status == model.InvoiceStatusVoid {
+			continue
+		}
+		found[*l.FeeID] = true
+		ids = append(ids, *l.FeeID)
+	}
+	return ids, nil
+}
+
+func (r *invoiceRepository) FindIssuedByFee(feeID uint) (*model.Invoice, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	for _, l := range filter(d.invoiceLines, nil) {
+		inv := d.invoices[l.InvoiceID]
+		if l.FeeID != nil && *l.FeeID == feeID && inv.Kind == model.InvoiceKindInvoice && inv.Status == model.InvoiceStatusIssued {
+			invoice := r.load(inv)
+			return &invoice, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *invoiceRepository) NextNumber(series string, year int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.data.nextSequence(series, year), nil
+}
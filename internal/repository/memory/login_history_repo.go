@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type loginHistoryRepository struct {
+	s *Store
+}
+
+func NewLoginHistoryRepository(s *Store) repository.LoginHistoryRepository {
+	return &loginHistoryRepository{s: s}
+}
+
+func (r *loginHistoryRepository) Create(history *model.LoginHistory) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	history.ID = r.s.data.nextID("login_histories")
+	touch(&history.CreatedAt, nil)
+	r.s.data.loginHistories[history.ID] = *history
+	return nil
+}
+
+func (r *loginHistoryRepository) List(page, pageSize int, userID uint, username, ip string, success *bool) ([]model.LoginHistory, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	histories := filter(r.s.data.loginHistories, func(h model.LoginHistory) bool {
+		return (userID == 0 || (h.UserID != nil && *h.UserID == userID)) &&
+			(username == "" || h.Username == username) &&
+			(ip == "" || h.IP == ip) &&
+			(success == nil || h.Success == *success)
+	})
+	sort.SliceStable(histories, func(i, j int) bool {
+		return newestFirst(histories[i].CreatedAt, histories[j].CreatedAt, histories[i].ID, histories[j].ID)
+	})
+
+	result, total := paginate(histories, page, pageSize)
+	return result, total, nil
+}
+
+func (r *loginHistoryRepository) CountFailuresByIPSince(ip string, since time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	failures := filter(r.s.data.loginHistories, func(h model.LoginHistory) bool {
+		return h.IP == ip && !h.Success && !h.CreatedAt.Before(since)
+	})
+	return int64(len(failures)), nil
+}
@@ -0,0 +1,260 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type maintenanceRepository struct {
+	s *Store
+}
+
+func NewMaintenanceRepository(s *Store) repository.MaintenanceRepository {
+	return &maintenanceRepository{s: s}
+}
+
+func (r *maintenanceRepository) load(m model.Maintenance) model.Maintenance {
+	m.TenantName = r.s.data.tenantName(m.TenantID)
+	return m
+}
+
+func (r *maintenanceRepository) save(m model.Maintenance) {
+	m.Tenant = model.Tenant{}
+	m.TenantName = ""
+	r.s.data.maintenances[m.ID] = m
+}
+
+func (r *maintenanceRepository) active(keep func(model.Maintenance) bool) []model.Maintenance {
+	return filter(r.s.data.maintenances, func(m model.Maintenance) bool {
+		return !m.DeletedAt.Valid && keep(m)
+	})
+}
+
+func (r *maintenanceRepository) checkTicket(maintenance *model.Maintenance) error {
+	if err := r.s.data.tenantExists(maintenance.TenantID); err != nil {
+		return err
+	}
+	for _, other := range r.s.data.maintenances {
+		if other.TicketNo == maintenance.TicketNo && other.ID != maintenance.ID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	return nil
+}
+
+func (r *maintenanceRepository) Create(maintenance *model.Maintenance) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.checkTicket(maintenance); err != nil {
+		return err
+	}
+
+	maintenance.ID = r.s.data.nextID("maintenances")
+	if maintenance.Priority == "" {
+		maintenance.Priority = "medium"
+	}
+	if maintenance.Status == "" {
+		maintenance.Status = "pending"
+	}
+	if maintenance.Version == 0 {
+		maintenance.Version = 1
+	}
+	touch(&maintenance.CreatedAt, &maintenance.UpdatedAt)
+	r.save(*maintenance)
+	return nil
+}
+
+func (r *maintenanceRepository) FindByID(id uint) (*model.Maintenance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	maintenance, ok := r.s.data.maintenances[id]
+	if !ok || maintenance.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	maintenance = r.load(maintenance)
+	return &maintenance, nil
+}
+
+func (r *maintenanceRepository) FindByIDForUpdate(id uint) (*model.Maintenance, error) {
+	return r.FindByID(id)
+}
+
+func (r *maintenanceRepository) FindByTicketNo(ticketNo string) (*model.Maintenance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, maintenance := range r.s.data.maintenances {
+		if maintenance.TicketNo == ticketNo && !maintenance.DeletedAt.Valid {
+			return &maintenance, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *maintenanceRepository) Update(maintenance *model.Maintenance) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.maintenances[maintenance.ID]
+	if !ok || current.DeletedAt.Valid || current.Version != maintenance.Version {
+		return repository.ErrVersionConflict
+	}
+	if err := r.checkTicket(maintenance); err != nil {
+		return err
+	}
+
+	maintenance.Version++
+	maintenance.UpdatedAt = time.Now()
+	r.save(*maintenance)
+	return nil
+}
+
+func (r *maintenanceRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if maintenance, ok := r.s.data.maintenances[id]; ok && !maintenance.DeletedAt.Valid {
+		maintenance.DeletedAt = softDeleted(time.Now())
+		r.s.data.maintenances[id] = maintenance
+	}
+	return nil
+}
+
+func (r *maintenanceRepository) List(page, pageSize int, tenantID uint, keyword, maintenanceType, status, priority string) ([]model.Maintenance, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	maintenances := r.active(func(m model.Maintenance) bool {
+		return (tenantID == 0 || m.TenantID == tenantID) &&
+			(keyword == "" || containsFold(m.TicketNo, keyword) || containsFold(m.Description, keyword)) &&
+			(maintenanceType == "" || m.Type == maintenanceType) &&
+			(status == "" || m.Status == status) &&
+			(priority == "" || m.Priority == priority)
+	})
+	sort.SliceStable(maintenances, func(i, j int) bool {
+		return newestFirst(maintenances[i].CreatedAt, maintenances[j].CreatedAt, maintenances[i].ID, maintenances[j].ID)
+	})
+
+	result, total := paginate(maintenances, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *maintenanceRepository) CountByStatus(status string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return int64(len(r.active(func(m model.Maintenance) bool { return m.Status == status }))), nil
+}
+
+func (r *maintenanceRepository) createdBetween(start, end time.Time) []model.Maintenance {
+	return r.active(func(m model.Maintenance) bool {
+		return between(&m.CreatedAt, start, end)
+	})
+}
+
+func (r *maintenanceRepository) GetStatsByType(start, end time.Time) ([]repository.MaintenanceStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	counts := map[string]int64{}
+	for _, m := range r.createdBetween(start, end) {
+		counts[m.Type]++
+	}
+
+	stats := []repository.MaintenanceStats{}
+	for t, count := range counts {
+		stats = append(stats, repository.MaintenanceStats{Type: t, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats, nil
+}
+
+func (r *maintenanceRepository) GetStatsByStatus(start, end time.Time) ([]repository.MaintenanceStatusStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	counts := map[string]int64{}
+	for _, m := range r.createdBetween(start, end) {
+		counts[m.Status]++
+	}
+
+	stats := []repository.MaintenanceStatusStats{}
+	for status, count := range counts {
+		stats = append(stats, repository.MaintenanceStatusStats{Status: status, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Status < stats[j].Status })
+	return stats, nil
+}
+
+func (r *maintenanceRepository) GetLastTicketNo() (string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	maintenances := r.active(func(model.Maintenance) bool { return true })
+	if len(maintenances) == 0 {
+		return "", nil
+	}
+	sort.SliceStable(maintenances, func(i, j int) bool {
+		return newestFirst(maintenances[i].CreatedAt, maintenances[j].CreatedAt, maintenances[i].ID, maintenances[j].ID)
+	})
+	return maintenances[0].TicketNo, nil
+}
+
+func (r *maintenanceRepository) ListTrashed(page, pageSize int) ([]model.Maintenance, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	maintenances := filter(r.s.data.maintenances, func(m model.Maintenance) bool { return m.DeletedAt.Valid })
+	sort.SliceStable(maintenances, func(i, j int) bool {
+		return maintenances[i].DeletedAt.Time.After(maintenances[j].DeletedAt.Time)
+	})
+
+	result, total := paginate(maintenances, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *maintenanceRepository) FindTrashedByID(id uint) (*model.Maintenance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	maintenance, ok := r.s.data.maintenances[id]
+	if !ok || !maintenance.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	maintenance = r.load(maintenance)
+	return &maintenance, nil
+}
+
+func (r *maintenanceRepository) Restore(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if maintenance, ok := r.s.data.maintenances[id]; ok {
+		maintenance.DeletedAt = gorm.DeletedAt{}
+		r.s.data.maintenances[id] = maintenance
+	}
+	return nil
+}
+
+func (r *maintenanceRepository) Purge(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if maintenance, ok := r.s.data.maintenances[id]; ok && maintenance.DeletedAt.Valid {
+		delete(r.s.data.maintenances, id)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type notificationRepository struct {
+	s *Store
+}
+
+func NewNotificationRepository(s *Store) repository.NotificationRepository {
+	return &notificationRepository{s: s}
+}
+
+func (r *notificationRepository) CreateIfAbsent(notification *model.Notification) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, n := range r.s.data.notifications {
+		if n.DedupKey == notification.DedupKey {
+			return false, nil
+		}
+	}
+	if notification.TenantID != nil {
+		if err := r.s.data.tenantExists(*notification.TenantID); err != nil {
+			return false, err
+		}
+	}
+	if notification.ContractID != nil {
+		if _, ok := r.s.data.contracts[*notification.ContractID]; !ok {
+			return false, fmt.Errorf("foreign key violation: contract %d does not exist", *notification.ContractID)
+		}
+	}
+
+	notification.ID = r.s.data.nextID("notifications")
+	touch(&notification.CreatedAt, nil)
+	r.s.data.notifications[notification.ID] = *notification
+	return true, nil
+}
+
+func (r *notificationRepository) FindByID(id uint) (*model.Notification, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notification, ok := r.s.data.notifications[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) List(page, pageSize int, notificationType string, unreadOnly bool) ([]model.Notification, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notifications := filter(r.s.data.notifications, func(n model.Notification) bool {
+		return (notificationType == "" || n.Type == notificationType) &&
+			(!unreadOnly || n.ReadAt == nil)
+	})
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return newestFirst(notifications[i].CreatedAt, notifications[j].CreatedAt, notifications[i].ID, notifications[j].ID)
+	})
+
+	result, total := paginate(notifications, page, pageSize)
+	return result, total, nil
+}
+
+func (r *notificationRepository) MarkRead(id uint, readAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if notification, ok := r.s.data.notifications[id]; ok && notification.ReadAt == nil {
+		notification.ReadAt = &readAt
+		r.s.data.notifications[id] = notification
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type paymentRepository struct {
+	s *Store
+}
+
+func NewPaymentRepository(s *Store) repository.PaymentRepository {
+	return &paymentRepository{s: s}
+}
+
+// load 填充租户名称、按费用排序的分配明细与收据
+func (r *paymentRepository) load(payment model.Payment) model.Payment {
+	d := r.s.data
+	payment.TenantName = d.tenantName(payment.TenantID)
+	payment.Allocations = []model.PaymentAllocation{}
+	for _, a := range filter(d.allocations, func(a model.PaymentAllocation) bool { return a.PaymentID == payment.ID }) {
+		fee := d.fees[a.FeeID]
+		a.FeeType, a.RoomNo, a.Period = fee.FeeType, fee.RoomNo, fee.Period
+		payment.Allocations = append(payment.Allocations, a)
+	}
+	sort.SliceStable(payment.Allocations, func(i, j int) bool {
+		return payment.Allocations[i].FeeID < payment.Allocations[j].FeeID
+	})
+	payment.Receipt = nil
+	for _, receipt := range d.receipts {
+		if receipt.PaymentID == payment.ID {
+			receipt := receipt
+			payment.Receipt = &receipt
+		}
+	}
+	return payment
+}
+
+func (r *paymentRepository) save(payment model.Payment) {
+	payment.Tenant = model.Tenant{}
+	payment.TenantName = ""
+	payment.Allocations = nil
+	payment.Receipt = nil
+	r.s.data.payments[payment.ID] = payment
+}
+
+func (r *paymentRepository) Create(payment *model.Payment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	if err := d.tenantExists(payment.TenantID); err != nil {
+		return err
+	}
+	seen := map[uint]bool{}
+	for _, a := range payment.Allocations {
+		if _, ok := d.fees[a.FeeID]; !ok {
+			return fmt.Errorf("foreign key violation: fee %d does not exist", a.FeeID)
+		}
+		if seen[a.FeeID] {
+			return gorm.ErrDuplicatedKey
+		}
+		seen[a.FeeID] = true
+	}
+
+	payment.ID = d.nextID("payments")
+	if payment.Status == "" {
+		payment.Status = model.PaymentStatusCompleted
+	}
+	touch(&payment.CreatedAt, &payment.UpdatedAt)
+	for i := range payment.Allocations {
+		a := &payment.Allocations[i]
+		a.ID = d.nextID("payment_allocations")
+		a.PaymentID = payment.ID
+		touch(&a.CreatedAt, nil)
+		stored := *a
+		stored.Fee, stored.FeeType, stored.RoomNo, stored.Period = nil, "", "", ""
+		d.allocations[a.ID] = stored
+	}
+	r.save(*payment)
+	return nil
+}
+
+func (r *paymentRepository) FindByID(id uint) (*model.Payment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	payment, ok := r.s.data.payments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	payment = r.load(payment)
+	return &payment, nil
+}
+
+func (r *paymentRepository) FindByIDForUpdate(id uint) (*model.Payment, error) {
+	return r.FindByID(id)
+}
+
+func (r *paymentRepository) Update(payment *model.Payment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.payments[payment.ID]
+	if !ok {
+		return nil
+	}
+	current.Status = payment.Status
+	current.ReversedAt = payment.ReversedAt
+	current.ReversedBy = payment.ReversedBy
+	current.ReversalReason = payment.ReversalReason
+	current.UpdatedAt = time.Now()
+	payment.UpdatedAt = current.UpdatedAt
+	r.save(current)
+	return nil
+}
+
+func (r *paymentRepository) List(page, pageSize int, tenantID uint, method, status string, from, to *time.Time) ([]model.Payment, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	payments := filter(r.s.data.payments, func(p model.Payment) bool {
+		return (tenantID == 0 || p.TenantID == tenantID) &&
+			(method == "" || p.Method == method) &&
+			(status == "" || p.Status == status) &&
+			(from == nil || !p.ReceivedAt.Before(*from)) &&
+			(to == nil || p.ReceivedAt.Before(*to))
+	})
+	sort.SliceStable(payments, func(i, j int) bool {
+		return newestFirst(payments[i].ReceivedAt, payments[j].ReceivedAt, payments[i].ID, payments[j].ID)
+	})
+
+	result, total := paginate(payments, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *paymentRepository) FindByTenant(tenantID uint) ([]model.Payment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	payments := filter(r.s.data.payments, func(p model.Payment) bool { return p.TenantID == tenantID })
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].ReceivedAt.Before(payments[j].ReceivedAt) })
+	for i := range payments {
+		payments[i] = r.load(payments[i])
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) FindByFee(feeID uint) ([]model.Payment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ids := map[uint]bool{}
+	for _, a := range r.s.data.allocations {
+		if a.FeeID == feeID {
+			ids[a.PaymentID] = true
+		}
+	}
+	payments := filter(r.s.data.payments, func(p model.Payment) bool { return ids[p.ID] })
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].ReceivedAt.Before(payments[j].ReceivedAt) })
+	for i := range payments {
+		payments[i] = r.load(payments[i])
+	}
+	return payments, nil
+}
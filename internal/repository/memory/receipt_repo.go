@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type receiptRepository struct {
+	s *Store
+}
+
+func NewReceiptRepository(s *Store) repository.ReceiptRepository {
+	return &receiptRepository{s: s}
+}
+
+func (r *receiptRepository) load(receipt model.Receipt) model.Receipt {
+	receipt.TenantName = r.s.data.tenantName(receipt.TenantID)
+	return receipt
+}
+
+func (r *receiptRepository) Create(receipt *model.Receipt) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	if _, ok := d.payments[receipt.PaymentID]; !ok {
+		return fmt.Errorf("foreign key violation: payment %d does not exist", receipt.PaymentID)
+	}
+	if err := d.tenantExists(receipt.TenantID); err != nil {
+		return err
+	}
+	for _, existing := range d.receipts {
+		if existing.PaymentID == receipt.PaymentID || existing.ReceiptNo == receipt.ReceiptNo {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	receipt.ID = d.nextID("receipts")
+	if receipt.Status == "" {
+		receipt.Status = model.ReceiptStatusValid
+	}
+	touch(&receipt.CreatedAt, &receipt.UpdatedAt)
+	stored := *receipt
+	stored.Tenant, stored.TenantName = model.Tenant{}, ""
+	d.receipts[receipt.ID] = stored
+	return nil
+}
+
+func (r *receiptRepository) FindByID(id uint) (*model.Receipt, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	receipt, ok := r.s.data.receipts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	receipt = r.load(receipt)
+	return &receipt, nil
+}
+
+func (r *receiptRepository) FindByPayment(paymentID uint) (*model.Receipt, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, receipt := range r.s.data.receipts {
+		if receipt.PaymentID == paymentID {
+			receipt = r.load(receipt)
+			return &receipt, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *receiptRepository) Update(receipt *model.Receipt) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.receipts[receipt.ID]
+	if !ok {
+		return nil
+	}
+	current.Status = receipt.Status
+	current.VoidedAt = receipt.VoidedAt
+	current.VoidedBy = receipt.VoidedBy
+	current.VoidReason = receipt.VoidReason
+	current.UpdatedAt = time.Now()
+	receipt.UpdatedAt = current.UpdatedAt
+	r.s.data.receipts[receipt.ID] = current
+	return nil
+}
+
+func (r *receiptRepository) RecordPrint(id uint, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.receipts[id]
+	if !ok {
+		return nil
+	}
+	current.PrintCount++
+	current.LastPrintedAt = &at
+	r.s.data.receipts[id] = current
+	return nil
+}
+
+func (r *receiptRepository) List(page, pageSize int, tenantID uint, status, receiptNo string) ([]model.Receipt, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	receipts := filter(r.s.data.receipts, func(rc model.Receipt) bool {
+		return (tenantID == 0 || rc.TenantID == tenantID) &&
+			(status == "" || rc.Status == status) &&
+			(receiptNo == "" || rc.ReceiptNo == receiptNo)
+	})
+	sort.SliceStable(receipts, func(i, j int) bool { return receipts[i].ID > receipts[j].ID })
+
+	result, total := paginate(receipts, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *receiptRepository) NextNumber(series string, year int) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.data.nextSequence(series, year), nil
+}
@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type roomRepository struct {
+	s *Store
+}
+
+func NewRoomRepository(s *Store) repository.RoomRepository {
+	return &roomRepository{s: s}
+}
+
+func (r *roomRepository) load(room model.Room) model.Room {
+	if room.TenantID != nil {
+		room.TenantName = r.s.data.tenantName(*room.TenantID)
+	}
+	return room
+}
+
+func (r *roomRepository) save(room model.Room) {
+	room.Tenant = nil
+	room.TenantName = ""
+	r.s.data.rooms[room.ID] = room
+}
+
+func (r *roomRepository) checkRoom(room *model.Room) error {
+	if room.TenantID != nil {
+		if err := r.s.data.tenantExists(*room.TenantID); err != nil {
+			return err
+		}
+	}
+	for _, other := range r.s.data.rooms {
+		if other.RoomNo == room.RoomNo && other.ID != room.ID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	return nil
+}
+
+func (r *roomRepository) Create(room *model.Room) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if err := r.checkRoom(room); err != nil {
+		return err
+	}
+
+	room.ID = r.s.data.nextID("rooms")
+	if room.Status == "" {
+		room.Status = "vacant"
+	}
+	if room.Version == 0 {
+		room.Version = 1
+	}
+	touch(&room.CreatedAt, &room.UpdatedAt)
+	r.save(*room)
+	return nil
+}
+
+func (r *roomRepository) FindByID(id uint) (*model.Room, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	room, ok := r.s.data.rooms[id]
+	if !ok || room.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	room = r.load(room)
+	return &room, nil
+}
+
+func (r *roomRepository) FindByIDForUpdate(id uint) (*model.Room, error) {
+	return r.FindByID(id)
+}
+
+func (r *roomRepository) FindByRoomNo(roomNo string) (*model.Room, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, room := range r.s.data.rooms {
+		if room.RoomNo == roomNo && !room.DeletedAt.Valid {
+			return &room, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *roomRepository) Update(room *model.Room) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.rooms[room.ID]
+	if !ok || current.DeletedAt.Valid || current.Version != room.Version {
+		return repository.ErrVersionConflict
+	}
+	if err := r.checkRoom(room); err != nil {
+		return err
+	}
+
+	room.Version++
+	room.UpdatedAt = time.Now()
+	r.save(*room)
+	return nil
+}
+
+func (r *roomRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if room, ok := r.s.data.rooms[id]; ok && !room.DeletedAt.Valid {
+		room.DeletedAt = softDeleted(time.Now())
+		r.s.data.rooms[id] = room
+	}
+	return nil
+}
+
+func (r *roomRepository) List(page, pageSize int, keyword, building, status string) ([]model.Room, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rooms := filter(r.s.data.rooms, func(room model.Room) bool {
+		return !room.DeletedAt.Valid &&
+			(keyword == "" || containsFold(room.RoomNo, keyword)) &&
+			(building == "" || room.Building == building) &&
+			(status == "" || room.Status == status)
+	})
+	sortRooms(rooms)
+
+	result, total := paginate(rooms, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *roomRepository) FindByTenantID(tenantID uint) ([]model.Room, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rooms := r.byTenant(tenantID)
+	sortRooms(rooms)
+	return rooms, nil
+}
+
+func (r *roomRepository) CountByTenant(tenantID uint) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return int64(len(r.byTenant(tenantID))), nil
+}
+
+func (r *roomRepository) byTenant(tenantID uint) []model.Room {
+	return filter(r.s.data.rooms, func(room model.Room) bool {
+		return !room.DeletedAt.Valid && room.TenantID != nil && *room.TenantID == tenantID
+	})
+}
+
+func (r *roomRepository) CountByStatus(status string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rooms := filter(r.s.data.rooms, func(room model.Room) bool {
+		return !room.DeletedAt.Valid && room.Status == status
+	})
+	return int64(len(rooms)), nil
+}
+
+func (r *roomRepository) CountTotal() (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rooms := filter(r.s.data.rooms, func(room model.Room) bool { return !room.DeletedAt.Valid })
+	return int64(len(rooms)), nil
+}
+
+func (r *roomRepository) GetBuildings() ([]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	seen := map[string]bool{}
+	buildings := []string{}
+	for _, room := range filter(r.s.data.rooms, func(room model.Room) bool { return !room.DeletedAt.Valid }) {
+		if !seen[room.Building] {
+			seen[room.Building] = true
+			buildings = append(buildings, room.Building)
+		}
+	}
+	sort.Strings(buildings)
+	return buildings, nil
+}
+
+func (r *roomRepository) ListTrashed(page, pageSize int) ([]model.Room, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rooms := filter(r.s.data.rooms, func(room model.Room) bool { return room.DeletedAt.Valid })
+	sort.SliceStable(rooms, func(i, j int) bool {
+		return rooms[i].DeletedAt.Time.After(rooms[j].DeletedAt.Time)
+	})
+
+	result, total := paginate(rooms, page, pageSize)
+	for i := range result {
+		result[i] = r.load(result[i])
+	}
+	return result, total, nil
+}
+
+func (r *roomRepository) FindTrashedByID(id uint) (*model.Room, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	room, ok := r.s.data.rooms[id]
+	if !ok || !room.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	room = r.load(room)
+	return &room, nil
+}
+
+func (r *roomRepository) Restore(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if room, ok := r.s.data.rooms[id]; ok {
+		room.DeletedAt = gorm.DeletedAt{}
+		r.s.data.rooms[id] = room
+	}
+	return nil
+}
+
+func (r *roomRepository) HasReferences(id uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, cr := range r.s.data.contractRooms {
+		if cr.RoomID == id {
+			return true, nil
+		}
+	}
+	for _, adj := range r.s.data.rentAdjustments {
+		if adj.RoomID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *roomRepository) Purge(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if room, ok := r.s.data.rooms[id]; ok && room.DeletedAt.Valid {
+		delete(r.s.data.rooms, id)
+	}
+	return nil
+}
+
+func sortRooms(rooms []model.Room) {
+	sort.SliceStable(rooms, func(i, j int) bool { return rooms[i].RoomNo < rooms[j].RoomNo })
+}
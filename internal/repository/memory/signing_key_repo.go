@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type signingKeyRepository struct {
+	s *Store
+}
+
+func NewSigningKeyRepository(s *Store) repository.SigningKeyRepository {
+	return &signingKeyRepository{s: s}
+}
+
+func (r *signingKeyRepository) FindValid() ([]model.SigningKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	keys := filter(r.s.data.signingKeys, func(k model.SigningKey) bool {
+		return k.RetiredAt == nil || (k.ExpiresAt != nil && k.ExpiresAt.After(now))
+	})
+	sortKeys(keys)
+	return keys, nil
+}
+
+func (r *signingKeyRepository) List() ([]model.SigningKey, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	keys := filter(r.s.data.signingKeys, nil)
+	sortKeys(keys)
+	return keys, nil
+}
+
+func (r *signingKeyRepository) Rotate(key *model.SigningKey, expiresAt, dueBefore time.Time) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, k := range r.s.data.signingKeys {
+		if k.RetiredAt == nil && k.CreatedAt.After(dueBefore) {
+			return false, nil
+		}
+	}
+
+	now := time.Now()
+	for id, k := range r.s.data.signingKeys {
+		if k.RetiredAt == nil {
+			k.RetiredAt = &now
+			k.ExpiresAt = &expiresAt
+			r.s.data.signingKeys[id] = k
+		}
+	}
+
+	key.ID = r.s.data.nextID("signing_keys")
+	touch(&key.CreatedAt, nil)
+	r.s.data.signingKeys[key.ID] = *key
+	return true, nil
+}
+
+func sortKeys(keys []model.SigningKey) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return newestFirst(keys[i].CreatedAt, keys[j].CreatedAt, keys[i].ID, keys[j].ID)
+	})
+}
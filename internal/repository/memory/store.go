@@ -0,0 +1,280 @@
+// Package memory 提供仓储接口的内存实现，用于测试和无数据库的本地演示，
+// 数据仅保存在进程内，重启即丢失。
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+// Store 所有内存仓储共享的数据集。mu 保护单次读写；txMu 串行化 UnitOfWork，
+// 事务开始时保存快照，失败时整体回滚
+type Store struct {
+	mu   sync.Mutex
+	txMu sync.Mutex
+	data *dataset
+}
+
+type dataset struct {
+	seq                map[string]uint
+	users              map[uint]model.User
+	refreshTokens      map[uint]model.RefreshToken
+	revokedTokens      map[uint]model.RevokedToken
+	loginHistories     map[uint]model.LoginHistory
+	signingKeys        map[uint]model.SigningKey
+	apiKeys            map[uint]model.APIKey
+	auditLogs          map[uint]model.AuditLog
+	tenants            map[uint]model.Tenant
+	contracts          map[uint]model.Contract
+	contractRooms      map[uint]model.ContractRoom
+	transitions        map[uint]model.ContractTransition
+	rentAdjustments    map[uint]model.RentAdjustment
+	cpiIndices         map[uint]model.CPIIndex
+	rooms              map[uint]model.Room
+	fees               map[uint]model.Fee
+	payments           map[uint]model.Payment
+	allocations        map[uint]model.PaymentAllocation
+	invoices           map[uint]model.Invoice
+	invoiceLines       map[uint]model.InvoiceLine
+	invoiceSeq         map[string]int
+	receipts           map[uint]model.Receipt
+	creditNotes        map[uint]model.CreditNote
+	refunds            map[uint]model.Refund
+	creditApplications map[uint]model.CreditApplication
+	maintenances       map[uint]model.Maintenance
+	notifications      map[uint]model.Notification
+}
+
+func NewStore() *Store {
+	return &Store{data: &dataset{
+		seq:                map[string]uint{},
+		users:              map[uint]model.User{},
+		refreshTokens:      map[uint]model.RefreshToken{},
+		revokedTokens:      map[uint]model.RevokedToken{},
+		loginHistories:     map[uint]model.LoginHistory{},
+		signingKeys:        map[uint]model.SigningKey{},
+		apiKeys:            map[uint]model.APIKey{},
+		auditLogs:          map[uint]model.AuditLog{},
+		tenants:            map[uint]model.Tenant{},
+		contracts:          map[uint]model.Contract{},
+		contractRooms:      map[uint]model.ContractRoom{},
+		transitions:        map[uint]model.ContractTransition{},
+		rentAdjustments:    map[uint]model.RentAdjustment{},
+		cpiIndices:         map[uint]model.CPIIndex{},
+		rooms:              map[uint]model.Room{},
+		fees:               map[uint]model.Fee{},
+		payments:           map[uint]model.Payment{},
+		allocations:        map[uint]model.PaymentAllocation{},
+		invoices:           map[uint]model.Invoice{},
+		invoiceLines:       map[uint]model.InvoiceLine{},
+		invoiceSeq:         map[string]int{},
+		receipts:           map[uint]model.Receipt{},
+		creditNotes:        map[uint]model.CreditNote{},
+		refunds:            map[uint]model.Refund{},
+		creditApplications: map[uint]model.CreditApplication{},
+		maintenances:       map[uint]model.Maintenance{},
+		notifications:      map[uint]model.Notification{},
+	}}
+}
+
+func (d *dataset) clone() *dataset {
+	return &dataset{
+		seq:                copyMap(d.seq),
+		users:              copyMap(d.users),
+		refreshTokens:      copyMap(d.refreshTokens),
+		revokedTokens:      copyMap(d.revokedTokens),
+		loginHistories:     copyMap(d.loginHistories),
+		signingKeys:        copyMap(d.signingKeys),
+		apiKeys:            copyMap(d.apiKeys),
+		auditLogs:          copyMap(d.auditLogs),
+		tenants:            copyMap(d.tenants),
+		contracts:          copyMap(d.contracts),
+		contractRooms:      copyMap(d.contractRooms),
+		transitions:        copyMap(d.transitions),
+		rentAdjustments:    copyMap(d.rentAdjustments),
+		cpiIndices:         copyMap(d.cpiIndices),
+		rooms:              copyMap(d.rooms),
+		fees:               copyMap(d.fees),
+		payments:           copyMap(d.payments),
+		allocations:        copyMap(d.allocations),
+		invoices:           copyMap(d.invoices),
+		invoiceLines:       copyMap(d.invoiceLines),
+		invoiceSeq:         copyMap(d.invoiceSeq),
+		receipts:           copyMap(d.receipts),
+		creditNotes:        copyMap(d.creditNotes),
+		refunds:            copyMap(d.refunds),
+		creditApplications: copyMap(d.creditApplications),
+		maintenances:       copyMap(d.maintenances),
+		notifications:      copyMap(d.notifications),
+	}
+}
+
+// nextID 模拟自增主键，序列不随回滚复用，与 PostgreSQL 行为一致
+func (d *dataset) nextID(table string) uint {
+	d.seq[table]++
+	return d.seq[table]
+}
+
+// nextSequence 模拟 invoice_sequences 的单据编号，与 nextID 不同，编号随 UnitOfWork 的快照一起回滚
+func (d *dataset) nextSequence(series string, year int) int {
+	key := fmt.Sprintf("%s:%d", series, year)
+	d.invoiceSeq[key]++
+	return d.invoiceSeq[key]
+}
+
+// tenantName 填充关联的租户名称，已软删除的租户同样返回名称
+func (d *dataset) tenantName(id uint) string {
+	return d.tenants[id].Name
+}
+
+// tenantExists 模拟外键约束
+func (d *dataset) tenantExists(id uint) error {
+	if _, ok := d.tenants[id]; !ok {
+		return fmt.Errorf("foreign key violation: tenant %d does not exist", id)
+	}
+	return nil
+}
+
+type unitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) repository.UnitOfWork {
+	return &unitOfWork{store: store}
+}
+
+// Do 串行执行事务；不支持嵌套调用
+func (u *unitOfWork) Do(fn func(tx *repository.Tx) error) (err error) {
+	s := u.store
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.data.clone()
+	s.mu.Unlock()
+
+	rollback := func() {
+		s.mu.Lock()
+		seq := s.data.seq
+		s.data = snapshot
+		s.data.seq = seq
+		s.mu.Unlock()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&repository.Tx{
+		Tenants:      NewTenantRepository(s),
+		Contracts:    NewContractRepository(s),
+		Rooms:        NewRoomRepository(s),
+		Fees:         NewFeeRepository(s),
+		Payments:     NewPaymentRepository(s),
+		Invoices:     NewInvoiceRepository(s),
+		Receipts:     NewReceiptRepository(s),
+		Accounts:     NewAccountRepository(s),
+		Maintenances: NewMaintenanceRepository(s),
+		AuditLogs:    NewAuditLogRepository(s),
+	}); err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// filter 按主键升序返回满足条件的记录
+func filter[T any](m map[uint]T, keep func(T) bool) []T {
+	ids := make([]uint, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	out := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if keep == nil || keep(m[id]) {
+			out = append(out, m[id])
+		}
+	}
+	return out
+}
+
+// paginate 返回指定页的数据与总数，页码从 1 开始
+func paginate[T any](items []T, page, pageSize int) ([]T, int64) {
+	total := int64(len(items))
+	start := (page - 1) * pageSize
+	if start < 0 {
+		start = 0
+	}
+	if start >= len(items) {
+		return []T{}, total
+	}
+	end := start + pageSize
+	if pageSize <= 0 || end > len(items) {
+		end = len(items)
+	}
+	return items[start:end], total
+}
+
+// newestFirst 按创建时间倒序，时间相同时按主键倒序
+func newestFirst(ai, bi time.Time, aID, bID uint) bool {
+	if !ai.Equal(bi) {
+		return ai.After(bi)
+	}
+	return aID > bID
+}
+
+// containsFold 对应 SQL 中的 ILIKE '%keyword%'
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func inStatuses(status string, statuses []string) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func between(t *time.Time, start, end time.Time) bool {
+	return t != nil && !t.Before(start) && !t.After(end)
+}
+
+func softDeleted(now time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: now, Valid: true}
+}
+
+func touch(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt != nil && createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt != nil && updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
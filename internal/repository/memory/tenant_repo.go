@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type tenantRepository struct {
+	s *Store
+}
+
+func NewTenantRepository(s *Store) repository.TenantRepository {
+	return &tenantRepository{s: s}
+}
+
+func (r *tenantRepository) Create(tenant *model.Tenant) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tenant.ID = r.s.data.nextID("tenants")
+	if tenant.Status == "" {
+		tenant.Status = "active"
+	}
+	if tenant.Version == 0 {
+		tenant.Version = 1
+	}
+	touch(&tenant.CreatedAt, &tenant.UpdatedAt)
+	r.s.data.tenants[tenant.ID] = *tenant
+	return nil
+}
+
+func (r *tenantRepository) FindByID(id uint) (*model.Tenant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tenant, ok := r.s.data.tenants[id]
+	if !ok || tenant.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tenant, nil
+}
+
+// FindByIDForShare 内存实现由 UnitOfWork 串行化，无需额外加锁
+func (r *tenantRepository) FindByIDForShare(id uint) (*model.Tenant, error) {
+	return r.FindByID(id)
+}
+
+func (r *tenantRepository) FindByIDForUpdate(id uint) (*model.Tenant, error) {
+	return r.FindByID(id)
+}
+
+func (r *tenantRepository) Update(tenant *model.Tenant) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	current, ok := r.s.data.tenants[tenant.ID]
+	if !ok || current.DeletedAt.Valid || current.Version != tenant.Version {
+		return repository.ErrVersionConflict
+	}
+
+	tenant.Version++
+	tenant.UpdatedAt = time.Now()
+	r.s.data.tenants[tenant.ID] = *tenant
+	return nil
+}
+
+func (r *tenantRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if tenant, ok := r.s.data.tenants[id]; ok && !tenant.DeletedAt.Valid {
+		tenant.DeletedAt = softDeleted(time.Now())
+		r.s.data.tenants[id] = tenant
+	}
+	return nil
+}
+
+func (r *tenantRepository) List(page, pageSize int, keyword, status string) ([]model.Tenant, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tenants := filter(r.s.data.tenants, func(t model.Tenant) bool {
+		return !t.DeletedAt.Valid &&
+			(keyword == "" || containsFold(t.Name, keyword) || containsFold(t.ContactPerson, keyword) || containsFold(t.Phone, keyword)) &&
+			(status == "" || t.Status == status)
+	})
+	sort.SliceStable(tenants, func(i, j int) bool {
+		return newestFirst(tenants[i].CreatedAt, tenants[j].CreatedAt, tenants[i].ID, tenants[j].ID)
+	})
+
+	result, total := paginate(tenants, page, pageSize)
+	return result, total, nil
+}
+
+func (r *tenantRepository) FindAll() ([]model.Tenant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return filter(r.s.data.tenants, func(t model.Tenant) bool { return !t.DeletedAt.Valid }), nil
+}
+
+func (r *tenantRepository) ListTrashed(page, pageSize int) ([]model.Tenant, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tenants := filter(r.s.data.tenants, func(t model.Tenant) bool { return t.DeletedAt.Valid })
+	sort.SliceStable(tenants, func(i, j int) bool {
+		return tenants[i].DeletedAt.Time.After(tenants[j].DeletedAt.Time)
+	})
+
+	result, total := paginate(tenants, page, pageSize)
+	return result, total, nil
+}
+
+func (r *tenantRepository) FindTrashedByID(id uint) (*model.Tenant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tenant, ok := r.s.data.tenants[id]
+	if !ok || !tenant.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) Restore(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if tenant, ok := r.s.data.tenants[id]; ok {
+		tenant.DeletedAt = gorm.DeletedAt{}
+		r.s.data.tenants[id] = tenant
+	}
+	return nil
+}
+
+func (r *tenantRepository) HasReferences(id uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	d := r.s.data
+	for _, c := range d.contracts {
+		if c.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, room := range d.rooms {
+		if room.TenantID != nil && *room.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, f := range d.fees {
+		if f.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, p := range d.payments {
+		if p.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, inv := range d.invoices {
+		if inv.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, n := range d.creditNotes {
+		if n.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, rf := range d.refunds {
+		if rf.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, m := range d.maintenances {
+		if m.TenantID == id {
+			return true, nil
+		}
+	}
+	for _, u := range d.users {
+		if u.TenantID != nil && *u.TenantID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *tenantRepository) Purge(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if tenant, ok := r.s.data.tenants[id]; ok && tenant.DeletedAt.Valid {
+		delete(r.s.data.tenants, id)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type tokenRepository struct {
+	s *Store
+}
+
+func NewTokenRepository(s *Store) repository.TokenRepository {
+	return &tokenRepository{s: s}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.data.refreshTokens {
+		if t.TokenHash == token.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	token.ID = r.s.data.nextID("refresh_tokens")
+	touch(&token.CreatedAt, nil)
+	r.s.data.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *tokenRepository) FindRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	return r.findRefreshToken(func(t model.RefreshToken) bool { return t.TokenHash == tokenHash })
+}
+
+func (r *tokenRepository) FindRefreshTokenByAccessJTI(jti string) (*model.RefreshToken, error) {
+	return r.findRefreshToken(func(t model.RefreshToken) bool { return t.AccessJTI == jti })
+}
+
+func (r *tokenRepository) findRefreshToken(match func(model.RefreshToken) bool) (*model.RefreshToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	tokens := filter(r.s.data.refreshTokens, match)
+	if len(tokens) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tokens[0], nil
+}
+
+func (r *tokenRepository) UpdateRefreshToken(token *model.RefreshToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.data.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *tokenRepository) ClaimRefreshToken(id uint, usedAt time.Time) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token, ok := r.s.data.refreshTokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &usedAt
+	r.s.data.refreshTokens[id] = token
+	return true, nil
+}
+
+func (r *tokenRepository) FindActiveRefreshTokensByUser(userID uint) ([]model.RefreshToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	return filter(r.s.data.refreshTokens, func(t model.RefreshToken) bool {
+		return t.UserID == userID && t.RevokedAt == nil && t.ExpiresAt.After(now)
+	}), nil
+}
+
+func (r *tokenRepository) FindActiveRefreshTokensByFamily(familyID string) ([]model.RefreshToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return filter(r.s.data.refreshTokens, func(t model.RefreshToken) bool {
+		return t.FamilyID == familyID && t.RevokedAt == nil
+	}), nil
+}
+
+func (r *tokenRepository) RevokeAccessToken(jti string, userID uint, expiresAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.data.revokedTokens {
+		if t.JTI == jti {
+			return nil
+		}
+	}
+
+	id := r.s.data.nextID("revoked_tokens")
+	r.s.data.revokedTokens[id] = model.RevokedToken{
+		ID:        id,
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	return nil
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.data.revokedTokens {
+		if t.JTI == jti {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *tokenRepository) DeleteExpired() error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for id, t := range r.s.data.revokedTokens {
+		if t.ExpiresAt.Before(now) {
+			delete(r.s.data.revokedTokens, id)
+		}
+	}
+	for id, t := range r.s.data.refreshTokens {
+		if t.ExpiresAt.Before(now) {
+			delete(r.s.data.refreshTokens, id)
+		}
+	}
+	return nil
+}
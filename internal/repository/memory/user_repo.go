@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type userRepository struct {
+	s *Store
+}
+
+func NewUserRepository(s *Store) repository.UserRepository {
+	return &userRepository{s: s}
+}
+
+func (r *userRepository) Create(user *model.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, u := range r.s.data.users {
+		if u.Username == user.Username {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	user.ID = r.s.data.nextID("users")
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	if user.Status == "" {
+		user.Status = "active"
+	}
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	r.s.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) FindByID(id uint) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.data.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(username string) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.data.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *userRepository) Update(user *model.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, u := range r.s.data.users {
+		if u.Username == user.Username && u.ID != user.ID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	touch(&user.CreatedAt, nil)
+	user.UpdatedAt = time.Now()
+	r.s.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) IncrementFailedAttempts(id uint, maxAttempts int, now time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.data.users[id]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if maxAttempts > 0 && user.FailedAttempts >= maxAttempts && user.LockedUntil != nil && !user.LockedUntil.After(now) {
+		user.FailedAttempts = 1
+	} else {
+		user.FailedAttempts++
+	}
+	user.UpdatedAt = now
+	r.s.data.users[id] = user
+	return user.FailedAttempts, nil
+}
+
+func (r *userRepository) LockUntil(id uint, until time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.data.users[id]
+	if !ok {
+		return nil
+	}
+	if user.LockedUntil == nil || user.LockedUntil.Before(until) {
+		user.LockedUntil = &until
+		r.s.data.users[id] = user
+	}
+	return nil
+}
+
+func (r *userRepository) Delete(id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.data.users, id)
+	return nil
+}
+
+func (r *userRepository) List(page, pageSize int, keyword, role, status string) ([]model.User, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	users := filter(r.s.data.users, func(u model.User) bool {
+		return (keyword == "" || containsFold(u.Username, keyword) || containsFold(u.Nickname, keyword)) &&
+			(role == "" || u.Role == role) &&
+			(status == "" || u.Status == status)
+	})
+	sort.SliceStable(users, func(i, j int) bool {
+		return newestFirst(users[i].CreatedAt, users[j].CreatedAt, users[i].ID, users[j].ID)
+	})
+
+	result, total := paginate(users, page, pageSize)
+	return result, total, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// CreateIfAbsent 依赖 dedup_key 唯一索引去重，多个实例同时扫描时也只会写入一条
+func (r *notificationRepository) CreateIfAbsent(notification *model.Notification) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *notificationRepository) FindByID(id uint) (*model.Notification, error) {
+	var notification model.Notification
+	if err := r.db.First(&notification, id).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) List(page, pageSize int, notificationType string, unreadOnly bool) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	query := r.db.Model(&model.Notification{})
+
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC, id DESC").Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// MarkRead 只记录首次阅读时间
+func (r *notificationRepository) MarkRead(id uint, readAt time.Time) error {
+	return r.db.Model(&model.Notification{}).Where("id = ? AND read_at IS NULL", id).Update("read_at", readAt).Error
+}
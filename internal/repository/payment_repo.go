@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// preloadAllocations 预加载分配明细、所分配的费用与收据，费用进入回收站后仍显示费用信息
+func preloadAllocations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Allocations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("fee_id")
+	}).Preload("Allocations.Fee", withTrashed).Preload("Tenant", withTrashed).Preload("Receipt")
+}
+
+// fillPayment 填充租户名称与分配明细中的费用信息
+func fillPayment(payment *model.Payment) {
+	payment.TenantName = payment.Tenant.Name
+	for i := range payment.Allocations {
+		if fee := payment.Allocations[i].Fee; fee != nil {
+			payment.Allocations[i].FeeType = fee.FeeType
+			payment.Allocations[i].RoomNo = fee.RoomNo
+			payment.Allocations[i].Period = fee.Period
+		}
+	}
+}
+
+func (r *paymentRepository) Create(payment *model.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *paymentRepository) FindByID(id uint) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.Scopes(preloadAllocations).First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	fillPayment(&payment)
+	return &payment, nil
+}
+
+// FindByIDForUpdate 读取收款并加排他锁，防止同一笔收款被重复冲正
+func (r *paymentRepository) FindByIDForUpdate(id uint) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.Scopes(forUpdate, preloadAllocations).First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	fillPayment(&payment)
+	return &payment, nil
+}
+
+// Update 只更新状态与冲正信息，金额与分配明细创建后不再修改
+func (r *paymentRepository) Update(payment *model.Payment) error {
+	return r.db.Model(payment).
+		Select("status", "reversed_at", "reversed_by", "reversal_reason", "updated_at").
+		Omit(clause.Associations).
+		Updates(payment).Error
+}
+
+func (r *paymentRepository) List(page, pageSize int, tenantID uint, method, status string, from, to *time.Time) ([]model.Payment, int64, error) {
+	var payments []model.Payment
+	var total int64
+
+	query := r.db.Model(&model.Payment{}).Scopes(preloadAllocations)
+
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	if method != "" {
+		query = query.Where("method = ?", method)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if from != nil {
+		query = query.Where("received_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("received_at < ?", *to)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("received_at DESC, id DESC").Find(&payments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range payments {
+		fillPayment(&payments[i])
+	}
+
+	return payments, total, nil
+}
+
+// FindByTenant 按到账时间返回租户的全部收款，包括已冲正的收款
+func (r *paymentRepository) FindByTenant(tenantID uint) ([]model.Payment, error) {
+	var payments []model.Payment
+	if err := r.db.Scopes(preloadAllocations).Where("tenant_id = ?", tenantID).Order("received_at, id").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	for i := range payments {
+		fillPayment(&payments[i])
+	}
+	return payments, nil
+}
+
+// FindByFee 按到账时间返回分配到该费用的全部收款，包括已冲正的收款
+func (r *paymentRepository) FindByFee(feeID uint) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.Scopes(preloadAllocations).
+		Where("id IN (?)", r.db.Model(&model.PaymentAllocation{}).Select("payment_id").Where("fee_id = ?", feeID)).
+		Order("received_at, id").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range payments {
+		fillPayment(&payments[i])
+	}
+	return payments, nil
+}
@@ -6,9 +6,21 @@ import (
 
 var ProviderSet = wire.NewSet(
 	NewUserRepository,
+	NewTokenRepository,
+	NewLoginHistoryRepository,
+	NewSigningKeyRepository,
+	NewAPIKeyRepository,
+	NewAuditLogRepository,
 	NewTenantRepository,
 	NewContractRepository,
+	NewCPIIndexRepository,
 	NewRoomRepository,
 	NewFeeRepository,
+	NewPaymentRepository,
+	NewInvoiceRepository,
+	NewReceiptRepository,
+	NewAccountRepository,
 	NewMaintenanceRepository,
+	NewNotificationRepository,
+	NewUnitOfWork,
 )
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type receiptRepository struct {
+	db *gorm.DB
+}
+
+func NewReceiptRepository(db *gorm.DB) ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+func preloadReceiptTenant(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tenant", withTrashed)
+}
+
+func (r *receiptRepository) Create(receipt *model.Receipt) error {
+	return r.db.Omit(clause.Associations).Create(receipt).Error
+}
+
+func (r *receiptRepository) FindByID(id uint) (*model.Receipt, error) {
+	var receipt model.Receipt
+	if err := r.db.Scopes(preloadReceiptTenant).First(&receipt, id).Error; err != nil {
+		return nil, err
+	}
+	receipt.TenantName = receipt.Tenant.Name
+	return &receipt, nil
+}
+
+func (r *receiptRepository) FindByPayment(paymentID uint) (*model.Receipt, error) {
+	var receipt model.Receipt
+	if err := r.db.Scopes(preloadReceiptTenant).Where("payment_id = ?", paymentID).First(&receipt).Error; err != nil {
+		return nil, err
+	}
+	receipt.TenantName = receipt.Tenant.Name
+	return &receipt, nil
+}
+
+// Update 只更新状态与作废信息，打印次数由 RecordPrint 维护
+func (r *receiptRepository) Update(receipt *model.Receipt) error {
+	return r.db.Model(receipt).
+		Select("status", "voided_at", "voided_by", "void_reason", "updated_at").
+		Omit(clause.Associations).
+		Updates(receipt).Error
+}
+
+// RecordPrint 在数据库中递增打印次数，并发下载时不丢失计数
+func (r *receiptRepository) RecordPrint(id uint, at time.Time) error {
+	return r.db.Model(&model.Receipt{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"print_count":     gorm.Expr("print_count + 1"),
+		"last_printed_at": at,
+	}).Error
+}
+
+func (r *receiptRepository) List(page, pageSize int, tenantID uint, status, receiptNo string) ([]model.Receipt, int64, error) {
+	var receipts []model.Receipt
+	var total int64
+
+	query := r.db.Model(&model.Receipt{}).Scopes(preloadReceiptTenant)
+
+	if tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if receiptNo != "" {
+		query = query.Where("receipt_no = ?", receiptNo)
+	}
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("id DESC").Find(&receipts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range receipts {
+		receipts[i].TenantName = receipts[i].Tenant.Name
+	}
+
+	return receipts, total, nil
+}
+
+func (r *receiptRepository) NextNumber(series string, year int) (int, error) {
+	return nextSequence(r.db, series, year)
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+// 仓储接口。默认实现基于 PostgreSQL（本包），database.driver 为 memory 时使用
+// repository/memory 中的内存实现，两者行为保持一致：记录不存在返回 gorm.ErrRecordNotFound，
+// 版本号不匹配返回 ErrVersionConflict，ForUpdate / ForShare 系列方法须在 UnitOfWork 内调用。
+
+// UserRepository 系统账号
+type UserRepository interface {
+	Create(user *model.User) error
+	FindByID(id uint) (*model.User, error)
+	FindByUsername(username string) (*model.User, error)
+	Update(user *model.User) error
+	Delete(id uint) error
+	List(page, pageSize int, keyword, role, status string) ([]model.User, int64, error)
+	// IncrementFailedAttempts 原子地累加连续登录失败次数并返回累加后的值；
+	// 失败次数已达 maxAttempts 且锁定已到期时从 1 重新计数
+	IncrementFailedAttempts(id uint, maxAttempts int, now time.Time) (int, error)
+	// LockUntil 将账号锁定到 until，已有更晚的锁定时保持不变
+	LockUntil(id uint, until time.Time) error
+}
+
+// TokenRepository 刷新令牌与访问令牌黑名单
+type TokenRepository interface {
+	CreateRefreshToken(token *model.RefreshToken) error
+	FindRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error)
+	FindRefreshTokenByAccessJTI(jti string) (*model.RefreshToken, error)
+	UpdateRefreshToken(token *model.RefreshToken) error
+	// ClaimRefreshToken 将未使用且未撤销的刷新令牌标记为已使用，令牌已被使用或撤销时返回 false
+	ClaimRefreshToken(id uint, usedAt time.Time) (bool, error)
+	FindActiveRefreshTokensByUser(userID uint) ([]model.RefreshToken, error)
+	FindActiveRefreshTokensByFamily(familyID string) ([]model.RefreshToken, error)
+	RevokeAccessToken(jti string, userID uint, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	DeleteExpired() error
+}
+
+// LoginHistoryRepository 登录历史
+type LoginHistoryRepository interface {
+	Create(history *model.LoginHistory) error
+	List(page, pageSize int, userID uint, username, ip string, success *bool) ([]model.LoginHistory, int64, error)
+	CountFailuresByIPSince(ip string, since time.Time) (int64, error)
+}
+
+// SigningKeyRepository JWT 签名密钥
+type SigningKeyRepository interface {
+	FindValid() ([]model.SigningKey, error)
+	List() ([]model.SigningKey, error)
+	// Rotate 在没有创建于 dueBefore 之后的生效密钥时写入新密钥并退役旧密钥，
+	// 返回是否完成轮换；其他副本已抢先轮换时返回 false
+	Rotate(key *model.SigningKey, expiresAt, dueBefore time.Time) (bool, error)
+}
+
+// APIKeyRepository API 密钥
+type APIKeyRepository interface {
+	Create(key *model.APIKey) error
+	FindByID(id uint) (*model.APIKey, error)
+	FindByPrefix(prefix string) (*model.APIKey, error)
+	List(page, pageSize int, userID uint) ([]model.APIKey, int64, error)
+	Revoke(id uint, revokedAt time.Time) error
+	TouchLastUsed(id uint, ip string, usedAt time.Time) error
+}
+
+// AuditLogRepository 审计日志
+type AuditLogRepository interface {
+	Create(log *model.AuditLog) error
+	List(page, pageSize int, entityType string, entityID, actorID uint, action, requestID string, from, to *time.Time) ([]model.AuditLog, int64, error)
+	ListByEntity(entityType string, entityID uint) ([]model.AuditLog, error)
+}
+
+// TenantRepository 租户；Delete 为软删除，Purge 仅删除回收站中的记录
+type TenantRepository interface {
+	Create(tenant *model.Tenant) error
+	FindByID(id uint) (*model.Tenant, error)
+	FindByIDForShare(id uint) (*model.Tenant, error)
+	FindByIDForUpdate(id uint) (*model.Tenant, error)
+	Update(tenant *model.Tenant) error
+	Delete(id uint) error
+	List(page, pageSize int, keyword, status string) ([]model.Tenant, int64, error)
+	FindAll() ([]model.Tenant, error)
+	ListTrashed(page, pageSize int) ([]model.Tenant, int64, error)
+	FindTrashedByID(id uint) (*model.Tenant, error)
+	Restore(id uint) error
+	HasReferences(id uint) (bool, error)
+	Purge(id uint) error
+}
+
+// ContractRepository 合同
+type ContractRepository interface {
+	Create(contract *model.Contract) error
+	FindByID(id uint) (*model.Contract, error)
+	FindByIDForUpdate(id uint) (*model.Contract, error)
+	FindByContractNo(contractNo string) (*model.Contract, error)
+	Update(contract *model.Contract) error
+	Delete(id uint) error
+	List(page, pageSize int, keyword, status string, startDateFrom, startDateTo *time.Time) ([]model.Contract, int64, error)
+	FindByTenantID(tenantID uint) ([]model.Contract, error)
+	FindByRoom(roomID uint, statuses ...string) ([]model.Contract, error)
+	ReplaceRooms(contractID uint, rooms []model.ContractRoom) error
+	CreateTransition(transition *model.ContractTransition) error
+	FindTransitions(contractID uint) ([]model.ContractTransition, error)
+	FindSuccessors(id uint) ([]model.Contract, error)
+	FindByEscalationTiming(timing string, statuses ...string) ([]model.Contract, error)
+	CreateRentAdjustment(adjustment *model.RentAdjustment) error
+	FindRentAdjustments(contractID uint) ([]model.RentAdjustment, error)
+	FindEndingBefore(before time.Time, statuses ...string) ([]model.Contract, error)
+	FindOverlapping(start, end time.Time, statuses ...string) ([]model.Contract, error)
+	CountByTenant(tenantID uint, statuses ...string) (int64, error)
+	CountByStatus(statuses ...string) (int64, error)
+	ListTrashed(page, pageSize int) ([]model.Contract, int64, error)
+	FindTrashedByID(id uint) (*model.Contract, error)
+	Restore(id uint) error
+	Purge(id uint) error
+}
+
+// CPIIndexRepository 本地维护的 CPI 年度涨幅，Save 按年份新增或覆盖
+type CPIIndexRepository interface {
+	List() ([]model.CPIIndex, error)
+	FindByYear(year int) (*model.CPIIndex, error)
+	Save(index *model.CPIIndex) error
+	Delete(year int) error
+}
+
+// RoomRepository 房间
+type RoomRepository interface {
+	Create(room *model.Room) error
+	FindByID(id uint) (*model.Room, error)
+	FindByIDForUpdate(id uint) (*model.Room, error)
+	FindByRoomNo(roomNo string) (*model.Room, error)
+	Update(room *model.Room) error
+	Delete(id uint) error
+	List(page, pageSize int, keyword, building, status string) ([]model.Room, int64, error)
+	FindByTenantID(tenantID uint) ([]model.Room, error)
+	CountByTenant(tenantID uint) (int64, error)
+	CountByStatus(status string) (int64, error)
+	CountTotal() (int64, error)
+	GetBuildings() ([]string, error)
+	ListTrashed(page, pageSize int) ([]model.Room, int64, error)
+	FindTrashedByID(id uint) (*model.Room, error)
+	Restore(id uint) error
+	HasReferences(id uint) (bool, error)
+	Purge(id uint) error
+}
+
+// FeeRepository 费用及收入统计。CreateIfAbsent 用于账单生成的租金，同一合同、房间号、账期已存在
+// （含回收站中的费用）时不插入并返回 false。收入统计按未冲正收款的到账时间汇总分配到费用的金额。
+// FindPenalties 返回逾期费用关联的滞纳金，包含回收站中的滞纳金
+type FeeRepository interface {
+	Create(fee *model.Fee) error
+	CreateIfAbsent(fee *model.Fee) (bool, error)
+	ExistsContractRent(contractID uint, roomNo, period string) (bool, error)
+	FindByID(id uint) (*model.Fee, error)
+	FindByIDForUpdate(id uint) (*model.Fee, error)
+	Update(fee *model.Fee) error
+	Delete(id uint) error
+	List(page, pageSize int, tenantID uint, roomNo, feeType, status, period string) ([]model.Fee, int64, error)
+	FindOutstanding(tenantID uint) ([]model.Fee, error)
+	FindByTenant(tenantID uint) ([]model.Fee, error)
+	FindDueBefore(before time.Time, statuses ...string) ([]model.Fee, error)
+	ListOverdue(page, pageSize int, tenantID uint, feeType string) ([]model.Fee, int64, error)
+	FindPenalties(feeIDs []uint) ([]model.Fee, error)
+	FindByTenantPeriod(tenantID uint, period string) ([]model.Fee, error)
+	SumByTypeAndPeriod(feeType string, start, end time.Time) (float64, error)
+	SumByPeriod(start, end time.Time) (float64, error)
+	CountByTenant(tenantID uint, statuses ...string) (int64, error)
+	CountByStatus(status string) (int64, error)
+	SumUnpaidAmount() (float64, error)
+	GetComposition(start, end time.Time) ([]FeeComposition, error)
+	GetIncomeByMonth(start, end time.Time) ([]IncomeByMonth, error)
+	GetTenantRanking(limit int, start, end time.Time) ([]TenantFeeRanking, error)
+	ListTrashed(page, pageSize int) ([]model.Fee, int64, error)
+	FindTrashedByID(id uint) (*model.Fee, error)
+	Restore(id uint) error
+	HasReferences(id uint) (bool, error)
+	Purge(id uint) error
+}
+
+// PaymentRepository 收款记录。Create 同时保存分配明细；收款创建后只能通过 Update 更新冲正信息
+type PaymentRepository interface {
+	Create(payment *model.Payment) error
+	FindByID(id uint) (*model.Payment, error)
+	FindByIDForUpdate(id uint) (*model.Payment, error)
+	Update(payment *model.Payment) error
+	List(page, pageSize int, tenantID uint, method, status string, from, to *time.Time) ([]model.Payment, int64, error)
+	FindByFee(feeID uint) ([]model.Payment, error)
+	FindByTenant(tenantID uint) ([]model.Payment, error)
+}
+
+// InvoiceRepository 发票与红字发票。FindInvoicedFees 返回已在未作废发票（含草稿）上的费用；
+// FindIssuedByFee 返回包含该费用的已开具发票，没有时返回 gorm.ErrRecordNotFound；
+// NextNumber 递增并返回编号系列在该年度的下一个编号，须在事务中调用，行锁持有到事务结束，回滚时编号一并回滚
+type InvoiceRepository interface {
+	Create(invoice *model.Invoice) error
+	FindByID(id uint) (*model.Invoice, error)
+	FindByIDForUpdate(id uint) (*model.Invoice, error)
+	Update(invoice *model.Invoice) error
+	Delete(id uint) error
+	List(page, pageSize int, tenantID uint, kind, status, period string) ([]model.Invoice, int64, error)
+	FindCreditNotes(originalID uint) ([]model.Invoice, error)
+	FindInvoicedFees(feeIDs []uint) ([]uint, error)
+	FindIssuedByFee(feeID uint) (*model.Invoice, error)
+	NextNumber(series string, year int) (int, error)
+}
+
+// ReceiptRepository 收款收据。Update 只更新作废信息，RecordPrint 原子递增打印次数；
+// NextNumber 与发票共用编号序列，须在事务中调用
+type ReceiptRepository interface {
+	Create(receipt *model.Receipt) error
+	FindByID(id uint) (*model.Receipt, error)
+	FindByPayment(paymentID uint) (*model.Receipt, error)
+	Update(receipt *model.Receipt) error
+	RecordPrint(id uint, at time.Time) error
+	List(page, pageSize int, tenantID uint, status, receiptNo string) ([]model.Receipt, int64, error)
+	NextNumber(series string, year int) (int, error)
+}
+
+// AccountRepository 租户账户：贷项通知单、退款与账户余额抵扣记录，创建后除贷项的作废信息外不再修改。
+// SumCreditNotesByFee 与 CreditBalance 不计作废的贷项，CreditBalance 为租户的贷项合计与收款转入的金额减去退款与抵扣合计；
+// NextNumber 与发票共用编号序列，须在事务中调用
+type AccountRepository interface {
+	CreateCreditNote(note *model.CreditNote) error
+	FindCreditNoteByID(id uint) (*model.CreditNote, error)
+	ListCreditNotes(page, pageSize int, tenantID uint) ([]model.CreditNote, int64, error)
+	FindCreditNotesByTenant(tenantID uint) ([]model.CreditNote, error)
+	FindCreditNotesByInvoice(invoiceID uint) ([]model.CreditNote, error)
+	VoidCreditNote(note *model.CreditNote) error
+	SumCreditNotesByFee(feeID uint) (float64, error)
+	CreateRefund(refund *model.Refund) error
+	FindRefundByID(id uint) (*model.Refund, error)
+	ListRefunds(page, pageSize int, tenantID uint) ([]model.Refund, int64, error)
+	FindRefundsByTenant(tenantID uint) ([]model.Refund, error)
+	SumRefundsByPayment(paymentID uint) (float64, error)
+	CreateApplication(application *model.CreditApplication) error
+	CreditBalance(tenantID uint) (float64, error)
+	NextNumber(series string, year int) (int, error)
+}
+
+// MaintenanceRepository 维修工单
+type MaintenanceRepository interface {
+	Create(maintenance *model.Maintenance) error
+	FindByID(id uint) (*model.Maintenance, error)
+	FindByIDForUpdate(id uint) (*model.Maintenance, error)
+	FindByTicketNo(ticketNo string) (*model.Maintenance, error)
+	Update(maintenance *model.Maintenance) error
+	Delete(id uint) error
+	List(page, pageSize int, tenantID uint, keyword, maintenanceType, status, priority string) ([]model.Maintenance, int64, error)
+	CountByStatus(status string) (int64, error)
+	GetStatsByType(start, end time.Time) ([]MaintenanceStats, error)
+	GetStatsByStatus(start, end time.Time) ([]MaintenanceStatusStats, error)
+	GetLastTicketNo() (string, error)
+	ListTrashed(page, pageSize int) ([]model.Maintenance, int64, error)
+	FindTrashedByID(id uint) (*model.Maintenance, error)
+	Restore(id uint) error
+	Purge(id uint) error
+}
+
+// NotificationRepository 站内通知
+type NotificationRepository interface {
+	CreateIfAbsent(notification *model.Notification) (bool, error)
+	FindByID(id uint) (*model.Notification, error)
+	List(page, pageSize int, notificationType string, unreadOnly bool) ([]model.Notification, int64, error)
+	MarkRead(id uint, readAt time.Time) error
+}
@@ -6,21 +6,21 @@ import (
 	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
-type RoomRepository struct {
+type roomRepository struct {
 	db *gorm.DB
 }
 
-func NewRoomRepository(db *gorm.DB) *RoomRepository {
-	return &RoomRepository{db: db}
+func NewRoomRepository(db *gorm.DB) RoomRepository {
+	return &roomRepository{db: db}
 }
 
-func (r *RoomRepository) Create(room *model.Room) error {
+func (r *roomRepository) Create(room *model.Room) error {
 	return r.db.Create(room).Error
 }
 
-func (r *RoomRepository) FindByID(id uint) (*model.Room, error) {
+func (r *roomRepository) FindByID(id uint) (*model.Room, error) {
 	var room model.Room
-	if err := r.db.Preload("Tenant").First(&room, id).Error; err != nil {
+	if err := r.db.Preload("Tenant", withTrashed).First(&room, id).Error; err != nil {
 		return nil, err
 	}
 	if room.Tenant != nil {
@@ -29,7 +29,16 @@ func (r *RoomRepository) FindByID(id uint) (*model.Room, error) {
 	return &room, nil
 }
 
-func (r *RoomRepository) FindByRoomNo(roomNo string) (*model.Room, error) {
+// FindByIDForUpdate 读取房间并加排他锁，并发分配同一房间时后到者等待前者提交
+func (r *roomRepository) FindByIDForUpdate(id uint) (*model.Room, error) {
+	var room model.Room
+	if err := r.db.Scopes(forUpdate).First(&room, id).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *roomRepository) FindByRoomNo(roomNo string) (*model.Room, error) {
 	var room model.Room
 	if err := r.db.Where("room_no = ?", roomNo).First(&room).Error; err != nil {
 		return nil, err
@@ -37,19 +46,19 @@ func (r *RoomRepository) FindByRoomNo(roomNo string) (*model.Room, error) {
 	return &room, nil
 }
 
-func (r *RoomRepository) Update(room *model.Room) error {
-	return r.db.Save(room).Error
+func (r *roomRepository) Update(room *model.Room) error {
+	return updateWithVersion(r.db, room, &room.Version)
 }
 
-func (r *RoomRepository) Delete(id uint) error {
+func (r *roomRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Room{}, id).Error
 }
 
-func (r *RoomRepository) List(page, pageSize int, keyword, building, status string) ([]model.Room, int64, error) {
+func (r *roomRepository) List(page, pageSize int, keyword, building, status string) ([]model.Room, int64, error) {
 	var rooms []model.Room
 	var total int64
 
-	query := r.db.Model(&model.Room{}).Preload("Tenant")
+	query := r.db.Model(&model.Room{}).Preload("Tenant", withTrashed)
 
 	if keyword != "" {
 		query = query.Where("room_no ILIKE ?", "%"+keyword+"%")
@@ -77,7 +86,23 @@ func (r *RoomRepository) List(page, pageSize int, keyword, building, status stri
 	return rooms, total, nil
 }
 
-func (r *RoomRepository) CountByStatus(status string) (int64, error) {
+func (r *roomRepository) FindByTenantID(tenantID uint) ([]model.Room, error) {
+	var rooms []model.Room
+	if err := r.db.Where("tenant_id = ?", tenantID).Order("room_no ASC").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *roomRepository) CountByTenant(tenantID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Room{}).Where("tenant_id = ?", tenantID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *roomRepository) CountByStatus(status string) (int64, error) {
 	var count int64
 	if err := r.db.Model(&model.Room{}).Where("status = ?", status).Count(&count).Error; err != nil {
 		return 0, err
@@ -85,7 +110,7 @@ func (r *RoomRepository) CountByStatus(status string) (int64, error) {
 	return count, nil
 }
 
-func (r *RoomRepository) CountTotal() (int64, error) {
+func (r *roomRepository) CountTotal() (int64, error) {
 	var count int64
 	if err := r.db.Model(&model.Room{}).Count(&count).Error; err != nil {
 		return 0, err
@@ -93,10 +118,65 @@ func (r *RoomRepository) CountTotal() (int64, error) {
 	return count, nil
 }
 
-func (r *RoomRepository) GetBuildings() ([]string, error) {
+func (r *roomRepository) GetBuildings() ([]string, error) {
 	var buildings []string
 	if err := r.db.Model(&model.Room{}).Distinct("building").Pluck("building", &buildings).Error; err != nil {
 		return nil, err
 	}
 	return buildings, nil
 }
+
+func (r *roomRepository) ListTrashed(page, pageSize int) ([]model.Room, int64, error) {
+	var rooms []model.Room
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Room{}).Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL")
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("deleted_at DESC").Find(&rooms).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range rooms {
+		if rooms[i].Tenant != nil {
+			rooms[i].TenantName = rooms[i].Tenant.Name
+		}
+	}
+
+	return rooms, total, nil
+}
+
+func (r *roomRepository) FindTrashedByID(id uint) (*model.Room, error) {
+	var room model.Room
+	if err := r.db.Unscoped().Preload("Tenant", withTrashed).Where("deleted_at IS NOT NULL").First(&room, id).Error; err != nil {
+		return nil, err
+	}
+	if room.Tenant != nil {
+		room.TenantName = room.Tenant.Name
+	}
+	return &room, nil
+}
+
+func (r *roomRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.Room{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// HasReferences 判断是否有合同（包括回收站中的合同）租赁过该房间或有该房间的租金调整记录
+func (r *roomRepository) HasReferences(id uint) (bool, error) {
+	for _, m := range []interface{}{&model.ContractRoom{}, &model.RentAdjustment{}} {
+		var count int64
+		if err := r.db.Model(m).Where("room_id = ?", id).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *roomRepository) Purge(id uint) error {
+	return r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Room{}, id).Error
+}
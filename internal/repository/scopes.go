@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrVersionConflict = errors.New("记录已被他人修改，请刷新后重试")
+
+// withTrashed 预加载关联时包含已软删除的记录，避免租户进入回收站后历史数据丢失租户名称
+func withTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// updateWithVersion 以乐观锁方式保存整条记录：仅当数据库中的版本号仍为读取时的版本才更新，
+// 成功后版本号加一；版本已变化时返回 ErrVersionConflict，且不修改 version。
+// 不使用 Save，因为 Save 在未更新到任何行时会退化为插入。
+func updateWithVersion(db *gorm.DB, value interface{}, version *uint) error {
+	current := *version
+	*version = current + 1
+
+	result := db.Model(value).
+		Where("version = ?", current).
+		Select("*").
+		Omit(clause.Associations).
+		Updates(value)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = current
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "gorm.io/gorm"
+
+// nextSequence 以 INSERT ... ON CONFLICT DO UPDATE 原子递增单据编号，并发开具时在该行上排队，
+// 行锁持有到事务结束
+func nextSequence(db *gorm.DB, series string, year int) (int, error) {
+	var no int
+	err := db.Raw(`INSERT INTO invoice_sequences (series, year, last_no) VALUES (?, ?, 1)
+		ON CONFLICT (series, year) DO UPDATE SET last_no = invoice_sequences.last_no + 1
+		RETURNING last_no`, series, year).Scan(&no).Error
+	return no, err
+}
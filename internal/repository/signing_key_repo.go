@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+// FindValid 返回仍可用于验签的密钥：未退役或退役后尚未过期，按创建时间倒序
+func (r *signingKeyRepository) FindValid() ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	if err := r.db.Where("retired_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *signingKeyRepository) List() ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	if err := r.db.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// signingKeyLockKey 密钥轮换使用的 PostgreSQL 事务级咨询锁，串行化多副本的轮换
+const signingKeyLockKey int64 = 0x6a776b726f74
+
+// Rotate 写入新密钥并退役其余所有未退役的密钥，退役密钥在 expiresAt 前仍可验签。
+// 在咨询锁内检查当前生效密钥，已有创建于 dueBefore 之后的生效密钥时说明其他副本刚完成轮换，不再写入
+func (r *signingKeyRepository) Rotate(key *model.SigningKey, expiresAt, dueBefore time.Time) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockKey).Error; err != nil {
+			return err
+		}
+
+		var fresh int64
+		if err := tx.Model(&model.SigningKey{}).
+			Where("retired_at IS NULL AND created_at > ?", dueBefore).
+			Count(&fresh).Error; err != nil {
+			return err
+		}
+		if fresh > 0 {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.SigningKey{}).
+			Where("retired_at IS NULL").
+			Updates(map[string]interface{}{"retired_at": now, "expires_at": expiresAt}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(key).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
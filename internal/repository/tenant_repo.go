@@ -6,19 +6,19 @@ import (
 	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
-type TenantRepository struct {
+type tenantRepository struct {
 	db *gorm.DB
 }
 
-func NewTenantRepository(db *gorm.DB) *TenantRepository {
-	return &TenantRepository{db: db}
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
 }
 
-func (r *TenantRepository) Create(tenant *model.Tenant) error {
+func (r *tenantRepository) Create(tenant *model.Tenant) error {
 	return r.db.Create(tenant).Error
 }
 
-func (r *TenantRepository) FindByID(id uint) (*model.Tenant, error) {
+func (r *tenantRepository) FindByID(id uint) (*model.Tenant, error) {
 	var tenant model.Tenant
 	if err := r.db.First(&tenant, id).Error; err != nil {
 		return nil, err
@@ -26,15 +26,33 @@ func (r *TenantRepository) FindByID(id uint) (*model.Tenant, error) {
 	return &tenant, nil
 }
 
-func (r *TenantRepository) Update(tenant *model.Tenant) error {
-	return r.db.Save(tenant).Error
+// FindByIDForShare 读取租户并加共享锁，防止事务期间租户被删除
+func (r *tenantRepository) FindByIDForShare(id uint) (*model.Tenant, error) {
+	var tenant model.Tenant
+	if err := r.db.Scopes(forShare).First(&tenant, id).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// FindByIDForUpdate 读取租户并加排他锁
+func (r *tenantRepository) FindByIDForUpdate(id uint) (*model.Tenant, error) {
+	var tenant model.Tenant
+	if err := r.db.Scopes(forUpdate).First(&tenant, id).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
 }
 
-func (r *TenantRepository) Delete(id uint) error {
+func (r *tenantRepository) Update(tenant *model.Tenant) error {
+	return updateWithVersion(r.db, tenant, &tenant.Version)
+}
+
+func (r *tenantRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Tenant{}, id).Error
 }
 
-func (r *TenantRepository) List(page, pageSize int, keyword, status string) ([]model.Tenant, int64, error) {
+func (r *tenantRepository) List(page, pageSize int, keyword, status string) ([]model.Tenant, int64, error) {
 	var tenants []model.Tenant
 	var total int64
 
@@ -58,10 +76,58 @@ func (r *TenantRepository) List(page, pageSize int, keyword, status string) ([]m
 	return tenants, total, nil
 }
 
-func (r *TenantRepository) FindAll() ([]model.Tenant, error) {
+func (r *tenantRepository) FindAll() ([]model.Tenant, error) {
 	var tenants []model.Tenant
 	if err := r.db.Find(&tenants).Error; err != nil {
 		return nil, err
 	}
 	return tenants, nil
 }
+
+// ListTrashed 分页查询回收站中已软删除的记录
+func (r *tenantRepository) ListTrashed(page, pageSize int) ([]model.Tenant, int64, error) {
+	var tenants []model.Tenant
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Tenant{}).Where("deleted_at IS NOT NULL")
+
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("deleted_at DESC").Find(&tenants).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tenants, total, nil
+}
+
+func (r *tenantRepository) FindTrashedByID(id uint) (*model.Tenant, error) {
+	var tenant model.Tenant
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&tenant, id).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.Tenant{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// HasReferences 判断是否仍有记录（包括回收站中的记录）引用该租户
+func (r *tenantRepository) HasReferences(id uint) (bool, error) {
+	for _, m := range []interface{}{&model.Contract{}, &model.Room{}, &model.Fee{}, &model.Payment{}, &model.Invoice{}, &model.CreditNote{}, &model.Refund{}, &model.Maintenance{}, &model.User{}} {
+		var count int64
+		if err := r.db.Unscoped().Model(m).Where("tenant_id = ?", id).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Purge 永久删除回收站中的记录
+func (r *tenantRepository) Purge(id uint) error {
+	return r.db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Tenant{}, id).Error
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tx 同一数据库事务内的仓储集合，由 UnitOfWork.Do 创建，离开回调后不可再使用
+type Tx struct {
+	Tenants      TenantRepository
+	Contracts    ContractRepository
+	Rooms        RoomRepository
+	Fees         FeeRepository
+	Payments     PaymentRepository
+	Invoices     InvoiceRepository
+	Receipts     ReceiptRepository
+	Accounts     AccountRepository
+	Maintenances MaintenanceRepository
+	// AuditLogs 服务层在事务中写入审计日志，随数据变更一同提交
+	AuditLogs AuditLogRepository
+}
+
+// UnitOfWork 让服务层在一个事务中组合多个仓储调用
+type UnitOfWork interface {
+	// Do 在事务中执行 fn，fn 返回错误或发生 panic 时回滚，否则提交
+	Do(fn func(tx *Tx) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(fn func(tx *Tx) error) error {
+	return u.db.Transaction(func(db *gorm.DB) error {
+		return fn(&Tx{
+			Tenants:      NewTenantRepository(db),
+			Contracts:    NewContractRepository(db),
+			Rooms:        NewRoomRepository(db),
+			Fees:         NewFeeRepository(db),
+			Payments:     NewPaymentRepository(db),
+			Invoices:     NewInvoiceRepository(db),
+			Receipts:     NewReceiptRepository(db),
+			Accounts:     NewAccountRepository(db),
+			Maintenances: NewMaintenanceRepository(db),
+			AuditLogs:    NewAuditLogRepository(db),
+		})
+	})
+}
+
+// forUpdate 对查询到的行加排他锁（SELECT ... FOR UPDATE），锁持有到事务结束，须在 UnitOfWork 内使用
+func forUpdate(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// forShare 加共享锁，阻止其他事务修改或删除该行，但不阻塞其他共享读
+func forShare(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "SHARE"})
+}
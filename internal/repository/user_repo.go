@@ -1,24 +1,26 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"yuxialuozi_graduation_design_backend/internal/model"
 )
 
-type UserRepository struct {
+type userRepository struct {
 	db *gorm.DB
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
 }
 
-func (r *UserRepository) Create(user *model.User) error {
+func (r *userRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
 
-func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+func (r *userRepository) FindByID(id uint) (*model.User, error) {
 	var user model.User
 	if err := r.db.First(&user, id).Error; err != nil {
 		return nil, err
@@ -26,7 +28,7 @@ func (r *UserRepository) FindByID(id uint) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
+func (r *userRepository) FindByUsername(username string) (*model.User, error) {
 	var user model.User
 	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
@@ -34,22 +36,54 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) Update(user *model.User) error {
+func (r *userRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
-func (r *UserRepository) Delete(id uint) error {
+func (r *userRepository) IncrementFailedAttempts(id uint, maxAttempts int, now time.Time) (int, error) {
+	var attempts int
+	err := r.db.Raw(`UPDATE users SET
+			failed_attempts = CASE
+				WHEN @max > 0 AND failed_attempts >= @max AND locked_until IS NOT NULL AND locked_until <= @now THEN 1
+				ELSE failed_attempts + 1
+			END,
+			updated_at = @now
+		WHERE id = @id
+		RETURNING failed_attempts`,
+		map[string]interface{}{"id": id, "max": maxAttempts, "now": now}).Scan(&attempts).Error
+	return attempts, err
+}
+
+func (r *userRepository) LockUntil(id uint, until time.Time) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", id, until).
+		Update("locked_until", until).Error
+}
+
+func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&model.User{}, id).Error
 }
 
-func (r *UserRepository) List(page, pageSize int) ([]model.User, int64, error) {
+func (r *userRepository) List(page, pageSize int, keyword, role, status string) ([]model.User, int64, error) {
 	var users []model.User
 	var total int64
 
-	r.db.Model(&model.User{}).Count(&total)
+	query := r.db.Model(&model.User{})
+
+	if keyword != "" {
+		query = query.Where("username ILIKE ? OR nickname ILIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
 
 	offset := (page - 1) * pageSize
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
 
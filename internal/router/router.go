@@ -9,31 +9,66 @@ import (
 	"yuxialuozi_graduation_design_backend/internal/config"
 	"yuxialuozi_graduation_design_backend/internal/handler"
 	"yuxialuozi_graduation_design_backend/internal/middleware"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/internal/service"
 )
 
 var ProviderSet = wire.NewSet(NewRouter)
 
 type Router struct {
-	engine             *gin.Engine
-	config             *config.Config
-	authHandler        *handler.AuthHandler
-	tenantHandler      *handler.TenantHandler
-	contractHandler    *handler.ContractHandler
-	roomHandler        *handler.RoomHandler
-	feeHandler         *handler.FeeHandler
-	maintenanceHandler *handler.MaintenanceHandler
-	reportHandler      *handler.ReportHandler
+	engine              *gin.Engine
+	config              *config.Config
+	userRepo            repository.UserRepository
+	tokenRepo           repository.TokenRepository
+	keyService          *service.KeyService
+	apiKeyService       *service.APIKeyService
+	authHandler         *handler.AuthHandler
+	userHandler         *handler.UserHandler
+	mfaHandler          *handler.MFAHandler
+	keyHandler          *handler.KeyHandler
+	apiKeyHandler       *handler.APIKeyHandler
+	tenantHandler       *handler.TenantHandler
+	contractHandler     *handler.ContractHandler
+	roomHandler         *handler.RoomHandler
+	feeHandler          *handler.FeeHandler
+	maintenanceHandler  *handler.MaintenanceHandler
+	reportHandler       *handler.ReportHandler
+	portalHandler       *handler.PortalHandler
+	auditHandler        *handler.AuditHandler
+	notificationHandler *handler.NotificationHandler
+	billingHandler      *handler.BillingHandler
+	paymentHandler      *handler.PaymentHandler
+	invoiceHandler      *handler.InvoiceHandler
+	receiptHandler      *handler.ReceiptHandler
+	accountHandler      *handler.AccountHandler
 }
 
 func NewRouter(
 	config *config.Config,
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	keyService *service.KeyService,
+	apiKeyService *service.APIKeyService,
 	authHandler *handler.AuthHandler,
+	userHandler *handler.UserHandler,
+	mfaHandler *handler.MFAHandler,
+	keyHandler *handler.KeyHandler,
+	apiKeyHandler *handler.APIKeyHandler,
 	tenantHandler *handler.TenantHandler,
 	contractHandler *handler.ContractHandler,
 	roomHandler *handler.RoomHandler,
 	feeHandler *handler.FeeHandler,
 	maintenanceHandler *handler.MaintenanceHandler,
 	reportHandler *handler.ReportHandler,
+	portalHandler *handler.PortalHandler,
+	auditHandler *handler.AuditHandler,
+	notificationHandler *handler.NotificationHandler,
+	billingHandler *handler.BillingHandler,
+	paymentHandler *handler.PaymentHandler,
+	invoiceHandler *handler.InvoiceHandler,
+	receiptHandler *handler.ReceiptHandler,
+	accountHandler *handler.AccountHandler,
 ) *Router {
 	if config.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -42,15 +77,31 @@ func NewRouter(
 	engine := gin.New()
 
 	r := &Router{
-		engine:             engine,
-		config:             config,
-		authHandler:        authHandler,
-		tenantHandler:      tenantHandler,
-		contractHandler:    contractHandler,
-		roomHandler:        roomHandler,
-		feeHandler:         feeHandler,
-		maintenanceHandler: maintenanceHandler,
-		reportHandler:      reportHandler,
+		engine:              engine,
+		config:              config,
+		userRepo:            userRepo,
+		tokenRepo:           tokenRepo,
+		keyService:          keyService,
+		apiKeyService:       apiKeyService,
+		authHandler:         authHandler,
+		userHandler:         userHandler,
+		mfaHandler:          mfaHandler,
+		keyHandler:          keyHandler,
+		apiKeyHandler:       apiKeyHandler,
+		tenantHandler:       tenantHandler,
+		contractHandler:     contractHandler,
+		roomHandler:         roomHandler,
+		feeHandler:          feeHandler,
+		maintenanceHandler:  maintenanceHandler,
+		reportHandler:       reportHandler,
+		portalHandler:       portalHandler,
+		auditHandler:        auditHandler,
+		notificationHandler: notificationHandler,
+		billingHandler:      billingHandler,
+		paymentHandler:      paymentHandler,
+		invoiceHandler:      invoiceHandler,
+		receiptHandler:      receiptHandler,
+		accountHandler:      accountHandler,
 	}
 
 	r.setupMiddlewares()
@@ -60,81 +111,351 @@ func NewRouter(
 }
 
 func (r *Router) setupMiddlewares() {
+	r.engine.Use(middleware.RequestID())
 	r.engine.Use(middleware.Recovery())
 	r.engine.Use(middleware.Logger())
 	r.engine.Use(middleware.CORS())
 }
 
+// routePermissions 受保护路由所需的权限，key 为 "METHOD /完整路由"。
+// 空字符串表示登录即可访问；未声明的路由会被 Authorize 拒绝。
+var routePermissions = map[string]string{
+	"GET /api/auth/me":                  "",
+	"POST /api/auth/logout":             "",
+	"POST /api/auth/logout-all":         "",
+	"PUT /api/auth/password":            "",
+	"GET /api/auth/login-history":       "",
+	"POST /api/auth/mfa/setup":          "",
+	"POST /api/auth/mfa/enable":         "",
+	"POST /api/auth/mfa/disable":        "",
+	"POST /api/auth/mfa/recovery-codes": "",
+
+	"GET /api/users":                model.PermUserManage,
+	"GET /api/users/:id":            model.PermUserManage,
+	"POST /api/users":               model.PermUserManage,
+	"PUT /api/users/:id":            model.PermUserManage,
+	"DELETE /api/users/:id":         model.PermUserManage,
+	"PUT /api/users/:id/status":     model.PermUserManage,
+	"POST /api/users/:id/unlock":    model.PermUserManage,
+	"POST /api/users/:id/mfa/reset": model.PermUserManage,
+
+	"GET /api/keys":         model.PermKeyManage,
+	"POST /api/keys/rotate": model.PermKeyManage,
+
+	"GET /api/api-keys":        "",
+	"POST /api/api-keys":       "",
+	"DELETE /api/api-keys/:id": "",
+
+	"GET /api/tenants":               model.PermTenantRead,
+	"GET /api/tenants/:id":           model.PermTenantRead,
+	"POST /api/tenants":              model.PermTenantWrite,
+	"PUT /api/tenants/:id":           model.PermTenantWrite,
+	"DELETE /api/tenants/:id":        model.PermTenantDelete,
+	"GET /api/tenants/trash":         model.PermTenantDelete,
+	"POST /api/tenants/:id/restore":  model.PermTenantDelete,
+	"DELETE /api/tenants/:id/purge":  model.PermTenantPurge,
+	"GET /api/tenants/:id/statement": model.PermFeeRead,
+
+	"GET /api/contracts":                      model.PermContractRead,
+	"GET /api/contracts/:id":                  model.PermContractRead,
+	"GET /api/contracts/expiring":             model.PermContractRead,
+	"POST /api/contracts":                     model.PermContractWrite,
+	"PUT /api/contracts/:id":                  model.PermContractWrite,
+	"DELETE /api/contracts/:id":               model.PermContractDelete,
+	"GET /api/contracts/trash":                model.PermContractDelete,
+	"POST /api/contracts/:id/restore":         model.PermContractDelete,
+	"DELETE /api/contracts/:id/purge":         model.PermContractPurge,
+	"GET /api/contracts/:id/transitions":      model.PermContractRead,
+	"POST /api/contracts/:id/submit":          model.PermContractWrite,
+	"POST /api/contracts/:id/activate":        model.PermContractApprove,
+	"POST /api/contracts/:id/terminate":       model.PermContractWrite,
+	"POST /api/contracts/:id/renew":           model.PermContractWrite,
+	"GET /api/contracts/:id/rent-adjustments": model.PermContractRead,
+	"GET /api/cpi-indices":                    model.PermContractRead,
+	"PUT /api/cpi-indices/:year":              model.PermContractWrite,
+	"DELETE /api/cpi-indices/:year":           model.PermContractWrite,
+
+	"GET /api/rooms":              model.PermRoomRead,
+	"GET /api/rooms/:id":          model.PermRoomRead,
+	"POST /api/rooms":             model.PermRoomWrite,
+	"PUT /api/rooms/:id":          model.PermRoomWrite,
+	"DELETE /api/rooms/:id":       model.PermRoomDelete,
+	"GET /api/rooms/trash":        model.PermRoomDelete,
+	"POST /api/rooms/:id/restore": model.PermRoomDelete,
+	"DELETE /api/rooms/:id/purge": model.PermRoomPurge,
+	"POST /api/rooms/:id/assign":  model.PermRoomAssign,
+
+	"GET /api/fees":              model.PermFeeRead,
+	"GET /api/fees/:id":          model.PermFeeRead,
+	"POST /api/fees":             model.PermFeeWrite,
+	"PUT /api/fees/:id":          model.PermFeeWrite,
+	"DELETE /api/fees/:id":       model.PermFeeDelete,
+	"GET /api/fees/trash":        model.PermFeeDelete,
+	"POST /api/fees/:id/restore": model.PermFeeDelete,
+	"DELETE /api/fees/:id/purge": model.PermFeePurge,
+	"POST /api/fees/:id/pay":     model.PermFeePay,
+	"GET /api/fees/:id/payments": model.PermFeeRead,
+	"GET /api/fees/overdue":      model.PermFeeRead,
+	"POST /api/fees/:id/waive":   model.PermFeeWaive,
+	"POST /api/billing/runs":     model.PermFeeWrite,
+
+	"GET /api/payments":              model.PermFeeRead,
+	"GET /api/payments/:id":          model.PermFeeRead,
+	"POST /api/payments":             model.PermFeePay,
+	"POST /api/payments/:id/reverse": model.PermFeeReverse,
+	"GET /api/payments/:id/receipt":  model.PermFeeRead,
+	"GET /api/receipts":              model.PermFeeRead,
+	"GET /api/receipts/:id":          model.PermFeeRead,
+	"GET /api/receipts/:id/pdf":      model.PermFeeRead,
+	"GET /api/credit-notes":          model.PermFeeRead,
+	"GET /api/credit-notes/:id":      model.PermFeeRead,
+	"POST /api/credit-notes":         model.PermFeeCredit,
+	"GET /api/refunds":               model.PermFeeRead,
+	"GET /api/refunds/:id":           model.PermFeeRead,
+	"POST /api/refunds":              model.PermFeeRefund,
+
+	"GET /api/invoices":                   model.PermInvoiceRead,
+	"GET /api/invoices/:id":               model.PermInvoiceRead,
+	"GET /api/invoices/:id/pdf":           model.PermInvoiceRead,
+	"POST /api/invoices":                  model.PermInvoiceWrite,
+	"DELETE /api/invoices/:id":            model.PermInvoiceWrite,
+	"POST /api/invoices/:id/issue":        model.PermInvoiceWrite,
+	"POST /api/invoices/:id/credit-notes": model.PermInvoiceWrite,
+	"POST /api/invoices/:id/void":         model.PermInvoiceVoid,
+
+	"GET /api/maintenance":               model.PermMaintenanceRead,
+	"GET /api/maintenance/:id":           model.PermMaintenanceRead,
+	"POST /api/maintenance":              model.PermMaintenanceWrite,
+	"PUT /api/maintenance/:id":           model.PermMaintenanceWrite,
+	"DELETE /api/maintenance/:id":        model.PermMaintenanceDelete,
+	"GET /api/maintenance/trash":         model.PermMaintenanceDelete,
+	"POST /api/maintenance/:id/restore":  model.PermMaintenanceDelete,
+	"DELETE /api/maintenance/:id/purge":  model.PermMaintenancePurge,
+	"POST /api/maintenance/:id/assign":   model.PermMaintenanceAssign,
+	"POST /api/maintenance/:id/complete": model.PermMaintenanceComplete,
+
+	"GET /api/reports/income":            model.PermReportView,
+	"GET /api/reports/occupancy":         model.PermReportView,
+	"GET /api/reports/fees/composition":  model.PermReportView,
+	"GET /api/reports/maintenance/stats": model.PermReportView,
+	"GET /api/reports/tenants/ranking":   model.PermReportView,
+	"GET /api/reports/dashboard":         model.PermReportView,
+
+	"GET /api/portal/profile":         model.PermPortalAccess,
+	"GET /api/portal/fees":            model.PermPortalAccess,
+	"GET /api/portal/fees/:id":        model.PermPortalAccess,
+	"GET /api/portal/payments":        model.PermPortalAccess,
+	"GET /api/portal/statement":       model.PermPortalAccess,
+	"GET /api/portal/contracts":       model.PermPortalAccess,
+	"GET /api/portal/contracts/:id":   model.PermPortalAccess,
+	"GET /api/portal/rooms":           model.PermPortalAccess,
+	"GET /api/portal/maintenance":     model.PermPortalAccess,
+	"GET /api/portal/maintenance/:id": model.PermPortalAccess,
+	"POST /api/portal/maintenance":    model.PermPortalAccess,
+
+	"GET /api/audit-logs":                       model.PermAuditView,
+	"GET /api/audit-logs/:entityType/:entityId": model.PermAuditView,
+
+	"GET /api/notifications":           model.PermNotificationRead,
+	"POST /api/notifications/:id/read": model.PermNotificationRead,
+}
+
 func (r *Router) setupRoutes() {
 	// Swagger 文档路由
 	r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// JWKS 公钥（public）
+	r.engine.GET("/.well-known/jwks.json", r.keyHandler.JWKS)
+
 	api := r.engine.Group("/api")
 	{
 		// Auth routes (public)
 		auth := api.Group("/auth")
 		{
 			auth.POST("/login", r.authHandler.Login)
+			auth.POST("/refresh", r.authHandler.Refresh)
+			auth.POST("/mfa/verify", r.authHandler.VerifyMFA)
 		}
 
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.JWTAuth(r.config))
+		protected.Use(middleware.JWTAuth(r.keyService.VerificationKey, r.tokenRepo, r.apiKeyService.Authenticate), middleware.Authorize(r.config, r.userRepo, routePermissions))
 		{
 			// Auth (protected)
 			protected.GET("/auth/me", r.authHandler.GetCurrentUser)
 			protected.POST("/auth/logout", r.authHandler.Logout)
+			protected.POST("/auth/logout-all", r.authHandler.LogoutAll)
+			protected.PUT("/auth/password", r.userHandler.ChangePassword)
+			protected.GET("/auth/login-history", r.authHandler.LoginHistory)
+
+			// MFA
+			mfa := protected.Group("/auth/mfa")
+			{
+				mfa.POST("/setup", r.mfaHandler.Setup)
+				mfa.POST("/enable", r.mfaHandler.Enable)
+				mfa.POST("/disable", r.mfaHandler.Disable)
+				mfa.POST("/recovery-codes", r.mfaHandler.RegenerateRecoveryCodes)
+			}
+
+			// Users
+			users := protected.Group("/users")
+			{
+				users.GET("", r.userHandler.List)
+				users.GET("/:id", r.userHandler.GetByID)
+				users.POST("", r.userHandler.Create)
+				users.PUT("/:id", r.userHandler.Update)
+				users.DELETE("/:id", r.userHandler.Delete)
+				users.PUT("/:id/status", r.userHandler.UpdateStatus)
+				users.POST("/:id/unlock", r.userHandler.Unlock)
+				users.POST("/:id/mfa/reset", r.mfaHandler.Reset)
+			}
+
+			// Signing keys
+			keys := protected.Group("/keys")
+			{
+				keys.GET("", r.keyHandler.List)
+				keys.POST("/rotate", r.keyHandler.Rotate)
+			}
+
+			// API keys
+			apiKeys := protected.Group("/api-keys")
+			{
+				apiKeys.GET("", r.apiKeyHandler.List)
+				apiKeys.POST("", r.apiKeyHandler.Create)
+				apiKeys.DELETE("/:id", r.apiKeyHandler.Revoke)
+			}
 
 			// Tenants
-			tenants := protected.Group("/tenants")
+			tenants := protected.Group("/tenants", middleware.RequireIfMatch(r.config))
 			{
 				tenants.GET("", r.tenantHandler.List)
 				tenants.GET("/:id", r.tenantHandler.GetByID)
 				tenants.POST("", r.tenantHandler.Create)
 				tenants.PUT("/:id", r.tenantHandler.Update)
 				tenants.DELETE("/:id", r.tenantHandler.Delete)
+				tenants.GET("/trash", r.tenantHandler.Trash)
+				tenants.POST("/:id/restore", r.tenantHandler.Restore)
+				tenants.DELETE("/:id/purge", r.tenantHandler.Purge)
+				tenants.GET("/:id/statement", r.accountHandler.Statement)
 			}
 
 			// Contracts
-			contracts := protected.Group("/contracts")
+			contracts := protected.Group("/contracts", middleware.RequireIfMatch(r.config))
 			{
 				contracts.GET("", r.contractHandler.List)
 				contracts.GET("/:id", r.contractHandler.GetByID)
+				contracts.GET("/expiring", r.contractHandler.Expiring)
 				contracts.POST("", r.contractHandler.Create)
 				contracts.PUT("/:id", r.contractHandler.Update)
 				contracts.DELETE("/:id", r.contractHandler.Delete)
+				contracts.GET("/trash", r.contractHandler.Trash)
+				contracts.POST("/:id/restore", r.contractHandler.Restore)
+				contracts.DELETE("/:id/purge", r.contractHandler.Purge)
+				contracts.GET("/:id/transitions", r.contractHandler.Transitions)
+				contracts.POST("/:id/submit", r.contractHandler.Submit)
+				contracts.POST("/:id/activate", r.contractHandler.Activate)
+				contracts.POST("/:id/terminate", r.contractHandler.Terminate)
+				contracts.POST("/:id/renew", r.contractHandler.Renew)
+				contracts.GET("/:id/rent-adjustments", r.contractHandler.RentAdjustments)
+			}
+
+			// CPI indices
+			cpiIndices := protected.Group("/cpi-indices")
+			{
+				cpiIndices.GET("", r.contractHandler.ListCPIIndices)
+				cpiIndices.PUT("/:year", r.contractHandler.SaveCPIIndex)
+				cpiIndices.DELETE("/:year", r.contractHandler.DeleteCPIIndex)
 			}
 
 			// Rooms
-			rooms := protected.Group("/rooms")
+			rooms := protected.Group("/rooms", middleware.RequireIfMatch(r.config))
 			{
 				rooms.GET("", r.roomHandler.List)
 				rooms.GET("/:id", r.roomHandler.GetByID)
 				rooms.POST("", r.roomHandler.Create)
 				rooms.PUT("/:id", r.roomHandler.Update)
 				rooms.DELETE("/:id", r.roomHandler.Delete)
+				rooms.GET("/trash", r.roomHandler.Trash)
+				rooms.POST("/:id/restore", r.roomHandler.Restore)
+				rooms.DELETE("/:id/purge", r.roomHandler.Purge)
 				rooms.POST("/:id/assign", r.roomHandler.AssignTenant)
 			}
 
 			// Fees
-			fees := protected.Group("/fees")
+			fees := protected.Group("/fees", middleware.RequireIfMatch(r.config))
 			{
 				fees.GET("", r.feeHandler.List)
 				fees.GET("/:id", r.feeHandler.GetByID)
 				fees.POST("", r.feeHandler.Create)
 				fees.PUT("/:id", r.feeHandler.Update)
 				fees.DELETE("/:id", r.feeHandler.Delete)
+				fees.GET("/trash", r.feeHandler.Trash)
+				fees.POST("/:id/restore", r.feeHandler.Restore)
+				fees.DELETE("/:id/purge", r.feeHandler.Purge)
 				fees.POST("/:id/pay", r.feeHandler.Pay)
+				fees.GET("/:id/payments", r.paymentHandler.ListByFee)
+				fees.GET("/overdue", r.feeHandler.Overdue)
+				fees.POST("/:id/waive", r.feeHandler.Waive)
+			}
+
+			// Payments
+			payments := protected.Group("/payments")
+			{
+				payments.GET("", r.paymentHandler.List)
+				payments.GET("/:id", r.paymentHandler.GetByID)
+				payments.POST("", r.paymentHandler.Create)
+				payments.POST("/:id/reverse", r.paymentHandler.Reverse)
+				payments.GET("/:id/receipt", r.receiptHandler.GetByPayment)
+			}
+
+			// Receipts
+			receipts := protected.Group("/receipts")
+			{
+				receipts.GET("", r.receiptHandler.List)
+				receipts.GET("/:id", r.receiptHandler.GetByID)
+				receipts.GET("/:id/pdf", r.receiptHandler.PDF)
+			}
+
+			// Credit notes
+			creditNotes := protected.Group("/credit-notes")
+			{
+				creditNotes.GET("", r.accountHandler.ListCreditNotes)
+				creditNotes.GET("/:id", r.accountHandler.GetCreditNote)
+				creditNotes.POST("", r.accountHandler.CreateCreditNote)
+			}
+
+			// Refunds
+			refunds := protected.Group("/refunds")
+			{
+				refunds.GET("", r.accountHandler.ListRefunds)
+				refunds.GET("/:id", r.accountHandler.GetRefund)
+				refunds.POST("", r.accountHandler.CreateRefund)
+			}
+
+			// Invoices
+			invoices := protected.Group("/invoices")
+			{
+				invoices.GET("", r.invoiceHandler.List)
+				invoices.GET("/:id", r.invoiceHandler.GetByID)
+				invoices.GET("/:id/pdf", r.invoiceHandler.PDF)
+				invoices.POST("", r.invoiceHandler.Create)
+				invoices.DELETE("/:id", r.invoiceHandler.Delete)
+				invoices.POST("/:id/issue", r.invoiceHandler.Issue)
+				invoices.POST("/:id/void", r.invoiceHandler.Void)
+				invoices.POST("/:id/credit-notes", r.invoiceHandler.CreditNote)
 			}
 
 			// Maintenance
-			maintenance := protected.Group("/maintenance")
+			maintenance := protected.Group("/maintenance", middleware.RequireIfMatch(r.config))
 			{
 				maintenance.GET("", r.maintenanceHandler.List)
 				maintenance.GET("/:id", r.maintenanceHandler.GetByID)
 				maintenance.POST("", r.maintenanceHandler.Create)
 				maintenance.PUT("/:id", r.maintenanceHandler.Update)
 				maintenance.DELETE("/:id", r.maintenanceHandler.Delete)
+				maintenance.GET("/trash", r.maintenanceHandler.Trash)
+				maintenance.POST("/:id/restore", r.maintenanceHandler.Restore)
+				maintenance.DELETE("/:id/purge", r.maintenanceHandler.Purge)
 				maintenance.POST("/:id/assign", r.maintenanceHandler.Assign)
 				maintenance.POST("/:id/complete", r.maintenanceHandler.Complete)
 			}
@@ -149,6 +470,42 @@ func (r *Router) setupRoutes() {
 				reports.GET("/tenants/ranking", r.reportHandler.GetTenantRanking)
 				reports.GET("/dashboard", r.reportHandler.GetDashboard)
 			}
+
+			// Tenant portal
+			portal := protected.Group("/portal")
+			{
+				portal.GET("/profile", r.portalHandler.GetProfile)
+				portal.GET("/fees", r.portalHandler.ListFees)
+				portal.GET("/fees/:id", r.portalHandler.GetFee)
+				portal.GET("/payments", r.portalHandler.ListPayments)
+				portal.GET("/statement", r.portalHandler.GetStatement)
+				portal.GET("/contracts", r.portalHandler.ListContracts)
+				portal.GET("/contracts/:id", r.portalHandler.GetContract)
+				portal.GET("/rooms", r.portalHandler.ListRooms)
+				portal.GET("/maintenance", r.portalHandler.ListMaintenance)
+				portal.GET("/maintenance/:id", r.portalHandler.GetMaintenance)
+				portal.POST("/maintenance", r.portalHandler.CreateMaintenance)
+			}
+
+			// Audit logs
+			auditLogs := protected.Group("/audit-logs")
+			{
+				auditLogs.GET("", r.auditHandler.List)
+				auditLogs.GET("/:entityType/:entityId", r.auditHandler.History)
+			}
+
+			// Notifications
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", r.notificationHandler.List)
+				notifications.POST("/:id/read", r.notificationHandler.MarkRead)
+			}
+
+			// Billing
+			billing := protected.Group("/billing")
+			{
+				billing.POST("/runs", r.billingHandler.Run)
+			}
 		}
 	}
 }
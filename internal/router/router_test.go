@@ -0,0 +1,917 @@
+package router_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository/memory"
+	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+	"yuxialuozi_graduation_design_backend/internal/wire"
+)
+
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type testServer struct {
+	t      *testing.T
+	engine *gin.Engine
+	repos  *storage.Repositories
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Server:   config.ServerConfig{Mode: "test"},
+		Database: config.DatabaseConfig{Driver: storage.DriverMemory},
+		JWT: config.JWTConfig{
+			Algorithm:     "HS256",
+			Secret:        "test-secret",
+			Expire:        "15m",
+			RefreshExpire: "168h",
+		},
+		Login: config.LoginConfig{
+			MaxAttempts:     5,
+			LockoutDuration: "15m",
+			IPMaxAttempts:   100,
+			IPWindow:        "15m",
+		},
+		MFA: config.MFAConfig{Issuer: "test", PendingExpire: "5m"},
+	}
+
+	repos := storage.NewMemoryRepositories(memory.NewStore())
+	return &testServer{
+		t:      t,
+		engine: wire.InitializeAppWithRepositories(cfg, repos).Engine(),
+		repos:  repos,
+	}
+}
+
+func (s *testServer) createUser(username, password, role string) {
+	s.t.Helper()
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if err := s.repos.Users.Create(&model.User{Username: username, Password: string(hashed), Role: role}); err != nil {
+		s.t.Fatalf("create user: %v", err)
+	}
+}
+
+func (s *testServer) do(method, path, token string, body interface{}) (*httptest.ResponseRecorder, apiResponse) {
+	s.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+
+	var resp apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		s.t.Fatalf("%s %s: invalid response body %q", method, path, w.Body.String())
+	}
+	return w, resp
+}
+
+// mustDo 发起请求并要求业务成功，返回 data 解码结果
+func (s *testServer) mustDo(method, path, token string, body, out interface{}) {
+	s.t.Helper()
+	w, resp := s.do(method, path, token, body)
+	if w.Code != http.StatusOK || resp.Code != 0 {
+		s.t.Fatalf("%s %s: status=%d code=%d message=%s", method, path, w.Code, resp.Code, resp.Message)
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			s.t.Fatalf("%s %s: decode data: %v", method, path, err)
+		}
+	}
+}
+
+func (s *testServer) login(username, password string) string {
+	s.t.Helper()
+	var data struct {
+		Token string `json:"token"`
+	}
+	s.mustDo(http.MethodPost, "/api/auth/login", "", map[string]string{"username": username, "password": password}, &data)
+	if data.Token == "" {
+		s.t.Fatal("login returned empty token")
+	}
+	return data.Token
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+
+	token := s.login("admin", "admin123")
+
+	var me struct {
+		Username string `json:"username"`
+		Role     string `json:"role"`
+	}
+	s.mustDo(http.MethodGet, "/api/auth/me", token, nil, &me)
+	if me.Username != "admin" || me.Role != model.RoleAdmin {
+		t.Fatalf("unexpected current user: %+v", me)
+	}
+
+	if _, resp := s.do(http.MethodPost, "/api/auth/login", "", map[string]string{"username": "admin", "password": "wrong"}); resp.Code == 0 {
+		t.Fatal("expected login with wrong password to fail")
+	}
+	if w, _ := s.do(http.MethodGet, "/api/rooms", "", nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", w.Code)
+	}
+
+	// API 密钥没有会话，退出返回 400 而不是把空的令牌 ID 加入黑名单
+	var created struct {
+		Key string `json:"key"`
+	}
+	s.mustDo(http.MethodPost, "/api/api-keys", token, map[string]interface{}{"name": "ci", "permissions": []string{"room:read"}}, &created)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.Header.Set("X-API-Key", created.Key)
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for logout with an API key, got %d: %s", w.Code, w.Body.String())
+	}
+
+	s.mustDo(http.MethodPost, "/api/auth/logout", token, nil, nil)
+	if w, _ := s.do(http.MethodGet, "/api/auth/me", token, nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", w.Code)
+	}
+}
+
+func TestRoomAssignment(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("viewer", "viewer123", model.RoleUser)
+	token := s.login("admin", "admin123")
+
+	var first, second, room struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &first)
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户乙"}, &second)
+	s.mustDo(http.MethodPost, "/api/rooms", token, map[string]interface{}{"roomNo": "A101", "monthlyRent": 3000}, &room)
+
+	assignPath := fmt.Sprintf("/api/rooms/%d/assign", room.ID)
+
+	viewerToken := s.login("viewer", "viewer123")
+	if w, _ := s.do(http.MethodPost, assignPath, viewerToken, map[string]uint{"tenantId": first.ID}); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for user without room:assign, got %d", w.Code)
+	}
+
+	s.mustDo(http.MethodPost, assignPath, token, map[string]uint{"tenantId": first.ID}, nil)
+
+	var got struct {
+		Status     string `json:"status"`
+		TenantID   *uint  `json:"tenantId"`
+		TenantName string `json:"tenantName"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/rooms/%d", room.ID), token, nil, &got)
+	if got.Status != "occupied" || got.TenantID == nil || *got.TenantID != first.ID || got.TenantName != "租户甲" {
+		t.Fatalf("room not assigned: %+v", got)
+	}
+
+	if _, resp := s.do(http.MethodPost, assignPath, token, map[string]uint{"tenantId": second.ID}); resp.Code == 0 {
+		t.Fatal("expected assigning an occupied room to another tenant to fail")
+	}
+}
+
+func TestFeePayment(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	token := s.login("admin", "admin123")
+
+	var tenant, fee struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+		"tenantId": tenant.ID,
+		"feeType":  "rent",
+		"amount":   3000,
+		"period":   "2026-10",
+		"dueDate":  "2026-10-10T00:00:00+08:00",
+	}, &fee)
+
+	payPath := fmt.Sprintf("/api/fees/%d/pay", fee.ID)
+	s.mustDo(http.MethodPost, payPath, token, map[string]string{"paidDate": "2026-10-05T10:00:00+08:00"}, nil)
+
+	var got struct {
+		Status   string  `json:"status"`
+		PaidDate *string `json:"paidDate"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/fees/%d", fee.ID), token, nil, &got)
+	if got.Status != "paid" || got.PaidDate == nil {
+		t.Fatalf("fee not paid: %+v", got)
+	}
+
+	if w, _ := s.do(http.MethodPost, payPath, token, nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate payment, got %d", w.Code)
+	}
+}
+
+func TestPayments(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("viewer", "viewer123", model.RoleUser)
+	token := s.login("admin", "admin123")
+
+	var tenant, rent, water struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	for _, f := range []struct {
+		feeType string
+		amount  float64
+		out     interface{}
+	}{{"rent", 3000, &rent}, {"water", 150, &water}} {
+		s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+			"tenantId": tenant.ID,
+			"feeType":  f.feeType,
+			"amount":   f.amount,
+			"dueDate":  "2026-10-10T00:00:00+08:00",
+		}, f.out)
+	}
+
+	s.mustDo(http.MethodPost, fmt.Sprintf("/api/fees/%d/pay", rent.ID), token, map[string]interface{}{
+		"amount":   1000,
+		"method":   "wechat",
+		"paidDate": "2026-10-03T10:00:00+08:00",
+	}, nil)
+
+	var payment struct {
+		ID          uint   `json:"id"`
+		Status      string `json:"status"`
+		Allocations []struct {
+			FeeID   uint    `json:"feeId"`
+			FeeType string  `json:"feeType"`
+			Amount  float64 `json:"amount"`
+		} `json:"allocations"`
+	}
+	s.mustDo(http.MethodPost, "/api/payments", token, map[string]interface{}{
+		"tenantId":   tenant.ID,
+		"amount":     2150,
+		"method":     "bank_transfer",
+		"reference":  "BT-001",
+		"receivedAt": "2026-10-08T10:00:00+08:00",
+	}, &payment)
+	if payment.Status != "completed" || len(payment.Allocations) != 2 || payment.Allocations[0].FeeType != "rent" {
+		t.Fatalf("unexpected payment: %+v", payment)
+	}
+
+	var fee struct {
+		Status     string  `json:"status"`
+		PaidAmount float64 `json:"paidAmount"`
+		Balance    float64 `json:"balance"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/fees/%d", rent.ID), token, nil, &fee)
+	if fee.Status != "paid" || fee.PaidAmount != 3000 || fee.Balance != 0 {
+		t.Fatalf("rent must be settled: %+v", fee)
+	}
+
+	if w, _ := s.do(http.MethodPost, "/api/payments", token, map[string]interface{}{
+		"tenantId": tenant.ID, "amount": 1, "method": "cash",
+		"allocations": []map[string]interface{}{{"feeId": rent.ID, "amount": 1}},
+	}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for an allocation to a settled fee, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodDelete, fmt.Sprintf("/api/fees/%d", rent.ID), token, nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when deleting a paid fee, got %d", w.Code)
+	}
+
+	viewer := s.login("viewer", "viewer123")
+	reversePath := fmt.Sprintf("/api/payments/%d/reverse", payment.ID)
+	if w, _ := s.do(http.MethodPost, reversePath, viewer, map[string]string{"reason": "退票"}); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for reversal without fee:reverse, got %d", w.Code)
+	}
+	s.mustDo(http.MethodPost, reversePath, token, map[string]string{"reason": "退票"}, nil)
+
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/fees/%d", rent.ID), token, nil, &fee)
+	if fee.Status != "partially_paid" || fee.Balance != 2000 {
+		t.Fatalf("reversal must reopen the rent: %+v", fee)
+	}
+
+	var history []struct {
+		Status string `json:"status"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/fees/%d/payments", rent.ID), viewer, nil, &history)
+	if len(history) != 2 || history[1].Status != "reversed" {
+		t.Fatalf("unexpected fee payment history: %+v", history)
+	}
+
+	var page struct {
+		Total int64 `json:"total"`
+	}
+	s.mustDo(http.MethodGet, "/api/payments?status=completed&from=2026-01-01", token, nil, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected 1 completed payment, got %d", page.Total)
+	}
+
+	var receipt struct {
+		ID         uint   `json:"id"`
+		ReceiptNo  string `json:"receiptNo"`
+		Status     string `json:"status"`
+		VoidReason string `json:"voidReason"`
+		PrintCount int    `json:"printCount"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/payments/%d/receipt", payment.ID), viewer, nil, &receipt)
+	if receipt.ReceiptNo != fmt.Sprintf("RC-%d-000002", time.Now().Year()) || receipt.Status != "void" || receipt.VoidReason != "退票" {
+		t.Fatalf("reversal must void the receipt: %+v", receipt)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/receipts/%d/pdf", receipt.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+viewer)
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("unexpected receipt pdf response: %d", w.Code)
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/receipts/%d", receipt.ID), viewer, nil, &receipt)
+	if receipt.PrintCount != 1 {
+		t.Fatalf("expected the download to be counted as a print, got %d", receipt.PrintCount)
+	}
+}
+
+func TestOverdueFees(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("clerk", "clerk123", model.RoleUser)
+	token := s.login("admin", "admin123")
+	clerk := s.login("clerk", "clerk123")
+
+	var tenant, rent struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+		"tenantId": tenant.ID,
+		"feeType":  "rent",
+		"amount":   3000,
+		"period":   "2025-01",
+		"dueDate":  "2025-01-05T00:00:00+08:00",
+	}, &rent)
+
+	cfg := &config.Config{Overdue: config.OverdueConfig{
+		GraceDays: 5,
+		LateFees:  map[string]config.LateFeeRule{"rent": {DailyRate: 0.0005, MaxAmount: 200}},
+	}}
+	if _, err := service.NewOverdueService(s.repos.Fees, s.repos.UnitOfWork, cfg).Run(time.Now()); err != nil {
+		t.Fatalf("overdue run: %v", err)
+	}
+
+	var overdue struct {
+		Total int64 `json:"total"`
+		List  []struct {
+			ID          uint   `json:"id"`
+			Status      string `json:"status"`
+			DaysPastDue int    `json:"daysPastDue"`
+			LateFee     *struct {
+				ID          uint    `json:"id"`
+				Amount      float64 `json:"amount"`
+				PenaltyOfID *uint   `json:"penaltyOfId"`
+			} `json:"lateFee"`
+		} `json:"list"`
+	}
+	s.mustDo(http.MethodGet, "/api/fees/overdue", clerk, nil, &overdue)
+	if overdue.Total != 1 || overdue.List[0].ID != rent.ID || overdue.List[0].Status != "overdue" || overdue.List[0].DaysPastDue < 365 {
+		t.Fatalf("unexpected overdue fees: %+v", overdue)
+	}
+	lateFee := overdue.List[0].LateFee
+	if lateFee == nil || lateFee.Amount != 200 || lateFee.PenaltyOfID == nil || *lateFee.PenaltyOfID != rent.ID {
+		t.Fatalf("unexpected late fee: %+v", lateFee)
+	}
+
+	waivePath := fmt.Sprintf("/api/fees/%d/waive", lateFee.ID)
+	reason := map[string]string{"reason": "首次逾期，经批准减免"}
+	if w, _ := s.do(http.MethodPost, waivePath, clerk, reason); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without fee:waive, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodPost, waivePath, token, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without reason, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodPost, fmt.Sprintf("/api/fees/%d/waive", rent.ID), token, reason); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for waiving a regular fee, got %d", w.Code)
+	}
+
+	var waived struct {
+		Status      string `json:"status"`
+		WaiveReason string `json:"waiveReason"`
+	}
+	s.mustDo(http.MethodPost, waivePath, token, reason, &waived)
+	if waived.Status != "waived" || waived.WaiveReason != reason["reason"] {
+		t.Fatalf("unexpected waived fee: %+v", waived)
+	}
+	if w, _ := s.do(http.MethodPost, waivePath, token, reason); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate waiver, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodPost, fmt.Sprintf("/api/fees/%d/pay", lateFee.ID), token, nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for paying a waived fee, got %d", w.Code)
+	}
+}
+
+func TestInvoices(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("clerk", "clerk123", model.RoleUser)
+	token := s.login("admin", "admin123")
+	clerk := s.login("clerk", "clerk123")
+
+	var tenant struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	for _, feeType := range []string{"rent", "water"} {
+		s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+			"tenantId": tenant.ID,
+			"feeType":  feeType,
+			"amount":   1000,
+			"period":   "2026-10",
+			"dueDate":  "2026-10-05T00:00:00+08:00",
+		}, nil)
+	}
+
+	request := map[string]interface{}{"tenantId": tenant.ID, "period": "2026-10"}
+	if w, _ := s.do(http.MethodPost, "/api/invoices", clerk, request); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without invoice:write, got %d", w.Code)
+	}
+	var invoice struct {
+		ID        uint    `json:"id"`
+		InvoiceNo *string `json:"invoiceNo"`
+		Status    string  `json:"status"`
+		Total     float64 `json:"total"`
+		Lines     []struct {
+			ID uint `json:"id"`
+		} `json:"lines"`
+	}
+	s.mustDo(http.MethodPost, "/api/invoices", token, request, &invoice)
+	if invoice.Status != "draft" || invoice.InvoiceNo != nil || invoice.Total != 2000 || len(invoice.Lines) != 2 {
+		t.Fatalf("unexpected draft invoice: %+v", invoice)
+	}
+	if w, _ := s.do(http.MethodPost, "/api/invoices", token, request); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 when every fee is invoiced, got %d", w.Code)
+	}
+
+	s.mustDo(http.MethodPost, fmt.Sprintf("/api/invoices/%d/issue", invoice.ID), token, nil, &invoice)
+	if invoice.Status != "issued" || invoice.InvoiceNo == nil || *invoice.InvoiceNo != fmt.Sprintf("INV-%d-000001", time.Now().Year()) {
+		t.Fatalf("unexpected issued invoice: %+v", invoice)
+	}
+	if w, _ := s.do(http.MethodDelete, fmt.Sprintf("/api/invoices/%d", invoice.ID), token, nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for deleting an issued invoice, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/invoices/%d/pdf", invoice.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+clerk)
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("unexpected pdf response: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	creditPath := fmt.Sprintf("/api/invoices/%d/credit-notes", invoice.ID)
+	var note struct {
+		Kind       string  `json:"kind"`
+		Total      float64 `json:"total"`
+		OriginalID *uint   `json:"originalId"`
+	}
+	s.mustDo(http.MethodPost, creditPath, token, map[string]interface{}{
+		"reason": "租金优惠",
+		"lines":  []map[string]interface{}{{"lineId": invoice.Lines[0].ID, "amount": 200}},
+	}, &note)
+	if note.Kind != "credit_note" || note.Total != -200 || note.OriginalID == nil || *note.OriginalID != invoice.ID {
+		t.Fatalf("unexpected credit note: %+v", note)
+	}
+	if w, _ := s.do(http.MethodPost, creditPath, token, map[string]interface{}{
+		"reason": "超额",
+		"lines":  []map[string]interface{}{{"lineId": invoice.Lines[0].ID, "amount": 900}},
+	}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for crediting more than remaining, got %d", w.Code)
+	}
+
+	voidPath := fmt.Sprintf("/api/invoices/%d/void", invoice.ID)
+	if w, _ := s.do(http.MethodPost, voidPath, token, map[string]string{"reason": "开错"}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for voiding a credited invoice, got %d", w.Code)
+	}
+
+	var list struct {
+		Total int64 `json:"total"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/invoices?tenantId=%d&kind=credit_note", tenant.ID), clerk, nil, &list)
+	if list.Total != 1 {
+		t.Fatalf("expected one credit note, got %d", list.Total)
+	}
+}
+
+func TestContractLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("clerk", "clerk123", model.RoleUser)
+	token := s.login("admin", "admin123")
+
+	var tenant, room, contract struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	s.mustDo(http.MethodPost, "/api/rooms", token, map[string]interface{}{"roomNo": "A101", "monthlyRent": 3000}, &room)
+	s.mustDo(http.MethodPost, "/api/contracts", token, map[string]interface{}{
+		"tenantId":  tenant.ID,
+		"startDate": "2026-01-01T00:00:00+08:00",
+		"endDate":   "2026-12-31T00:00:00+08:00",
+		"amount":    36000,
+		"rooms":     []map[string]uint{{"roomId": room.ID}},
+	}, &contract)
+
+	base := fmt.Sprintf("/api/contracts/%d", contract.ID)
+	w, resp := s.do(http.MethodPost, base+"/activate", token, nil)
+	if w.Code != http.StatusConflict || resp.Code != 40901 {
+		t.Fatalf("expected 409/40901 activating a draft, got %d/%d", w.Code, resp.Code)
+	}
+	var transitionErr struct {
+		From    string   `json:"from"`
+		Allowed []string `json:"allowed"`
+	}
+	if err := json.Unmarshal(resp.Data, &transitionErr); err != nil || transitionErr.From != "draft" || len(transitionErr.Allowed) == 0 {
+		t.Fatalf("unexpected transition error data: %s", resp.Data)
+	}
+
+	s.mustDo(http.MethodPost, base+"/submit", token, nil, nil)
+
+	clerkToken := s.login("clerk", "clerk123")
+	if w, _ := s.do(http.MethodPost, base+"/activate", clerkToken, nil); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without contract:approve, got %d", w.Code)
+	}
+
+	s.mustDo(http.MethodPost, base+"/activate", token, map[string]string{"reason": "审批通过"}, nil)
+
+	var got struct {
+		Status string `json:"status"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/rooms/%d", room.ID), token, nil, &got)
+	if got.Status != "occupied" {
+		t.Fatalf("room not occupied after activation: %+v", got)
+	}
+
+	if w, _ := s.do(http.MethodGet, "/api/contracts/expiring?within=soon", token, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid within, got %d", w.Code)
+	}
+	s.mustDo(http.MethodGet, "/api/contracts/expiring?within=60d", token, nil, nil)
+
+	if w, _ := s.do(http.MethodPost, base+"/terminate", token, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 terminating without reason, got %d", w.Code)
+	}
+	s.mustDo(http.MethodPost, base+"/terminate", token, map[string]string{"reason": "提前退租"}, nil)
+
+	var history []struct {
+		FromStatus string `json:"fromStatus"`
+		ToStatus   string `json:"toStatus"`
+		Reason     string `json:"reason"`
+		ActorName  string `json:"actorName"`
+	}
+	s.mustDo(http.MethodGet, base+"/transitions", token, nil, &history)
+	if len(history) != 4 || history[3].ToStatus != "terminated" || history[3].Reason != "提前退租" || history[3].ActorName != "admin" {
+		t.Fatalf("unexpected transition history: %+v", history)
+	}
+}
+
+func TestContractRenewal(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	token := s.login("admin", "admin123")
+
+	var tenant, room, contract struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	s.mustDo(http.MethodPost, "/api/rooms", token, map[string]interface{}{"roomNo": "A101", "monthlyRent": 3000}, &room)
+	s.mustDo(http.MethodPost, "/api/contracts", token, map[string]interface{}{
+		"tenantId":   tenant.ID,
+		"startDate":  "2025-11-01T00:00:00+08:00",
+		"endDate":    "2026-11-01T00:00:00+08:00",
+		"amount":     36000,
+		"status":     "pending_approval",
+		"rooms":      []map[string]uint{{"roomId": room.ID}},
+		"escalation": map[string]interface{}{"type": "cpi", "value": 1},
+	}, &contract)
+
+	base := fmt.Sprintf("/api/contracts/%d", contract.ID)
+	s.mustDo(http.MethodPost, base+"/activate", token, nil, nil)
+
+	if w, resp := s.do(http.MethodPost, base+"/renew", token, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without cpi data, got %d: %s", w.Code, resp.Message)
+	}
+	if w, _ := s.do(http.MethodPut, "/api/cpi-indices/abc", token, map[string]float64{"rate": 2}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid year, got %d", w.Code)
+	}
+	s.mustDo(http.MethodPut, "/api/cpi-indices/2025", token, map[string]float64{"rate": 2}, nil)
+
+	var successor struct {
+		ID            uint   `json:"id"`
+		Status        string `json:"status"`
+		PredecessorID *uint  `json:"predecessorId"`
+		Rooms         []struct {
+			MonthlyRent float64 `json:"monthlyRent"`
+		} `json:"rooms"`
+	}
+	s.mustDo(http.MethodPost, base+"/renew", token, map[string]string{"status": "pending_approval"}, &successor)
+	if successor.PredecessorID == nil || *successor.PredecessorID != contract.ID || successor.Rooms[0].MonthlyRent != 3090 {
+		t.Fatalf("unexpected successor: %+v", successor)
+	}
+	if w, _ := s.do(http.MethodPost, base+"/renew", token, nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 renewing twice, got %d", w.Code)
+	}
+
+	s.mustDo(http.MethodPost, fmt.Sprintf("/api/contracts/%d/activate", successor.ID), token, nil, nil)
+
+	var got struct {
+		Status       string `json:"status"`
+		RenewalChain []struct {
+			ID     uint   `json:"id"`
+			Status string `json:"status"`
+		} `json:"renewalChain"`
+	}
+	s.mustDo(http.MethodGet, base, token, nil, &got)
+	if got.Status != "renewed" || len(got.RenewalChain) != 2 || got.RenewalChain[1].ID != successor.ID || got.RenewalChain[1].Status != "active" {
+		t.Fatalf("unexpected renewal chain: %+v", got)
+	}
+
+	var adjustments []struct {
+		OldRent float64 `json:"oldRent"`
+		NewRent float64 `json:"newRent"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/contracts/%d/rent-adjustments", successor.ID), token, nil, &adjustments)
+	if len(adjustments) != 1 || adjustments[0].OldRent != 3000 || adjustments[0].NewRent != 3090 {
+		t.Fatalf("unexpected rent adjustments: %+v", adjustments)
+	}
+}
+
+func TestBillingRun(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("clerk", "clerk123", model.RoleUser)
+	token := s.login("admin", "admin123")
+
+	var tenant, room, contract struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	s.mustDo(http.MethodPost, "/api/rooms", token, map[string]interface{}{"roomNo": "A101", "monthlyRent": 3100}, &room)
+	s.mustDo(http.MethodPost, "/api/contracts", token, map[string]interface{}{
+		"tenantId":  tenant.ID,
+		"startDate": "2026-11-01T00:00:00+08:00",
+		"endDate":   "2027-11-01T00:00:00+08:00",
+		"status":    "pending_approval",
+		"rooms":     []map[string]uint{{"roomId": room.ID}},
+		"billing":   map[string]interface{}{"cycle": "monthly", "dueDay": 5, "mode": "advance"},
+	}, &contract)
+	s.mustDo(http.MethodPost, fmt.Sprintf("/api/contracts/%d/activate", contract.ID), token, nil, nil)
+
+	if w, _ := s.do(http.MethodPost, "/api/billing/runs", token, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without period, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodPost, "/api/billing/runs?period=2026-13", token, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid period, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodPost, "/api/billing/runs?period=2026-11", s.login("clerk", "clerk123"), nil); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without fee:write, got %d", w.Code)
+	}
+
+	var run struct {
+		Created int     `json:"created"`
+		Pending int     `json:"pending"`
+		Skipped int     `json:"skipped"`
+		Amount  float64 `json:"amount"`
+	}
+	s.mustDo(http.MethodPost, "/api/billing/runs?period=2026-11&dryRun=true", token, nil, &run)
+	if run.Pending != 1 || run.Created != 0 || run.Amount != 3100 {
+		t.Fatalf("unexpected preview: %+v", run)
+	}
+	s.mustDo(http.MethodPost, "/api/billing/runs?period=2026-11", token, nil, &run)
+	if run.Created != 1 || run.Pending != 0 {
+		t.Fatalf("unexpected billing run: %+v", run)
+	}
+	s.mustDo(http.MethodPost, "/api/billing/runs?period=2026-11", token, nil, &run)
+	if run.Created != 0 || run.Skipped != 1 {
+		t.Fatalf("billing run must be idempotent: %+v", run)
+	}
+
+	var fees struct {
+		Total int64 `json:"total"`
+		List  []struct {
+			ContractID *uint  `json:"contractId"`
+			Period     string `json:"period"`
+		} `json:"list"`
+	}
+	s.mustDo(http.MethodGet, "/api/fees?feeType=rent", token, nil, &fees)
+	if fees.Total != 1 || fees.List[0].ContractID == nil || *fees.List[0].ContractID != contract.ID || fees.List[0].Period != "2026-11" {
+		t.Fatalf("unexpected rent fees: %+v", fees)
+	}
+}
+
+func TestIncomeReport(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	token := s.login("admin", "admin123")
+
+	var tenant struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+
+	payments := []struct {
+		feeType  string
+		amount   float64
+		paidDate string
+	}{
+		{"rent", 3000, "2026-09-03T10:00:00+08:00"},
+		{"rent", 3000, "2026-10-03T10:00:00+08:00"},
+		{"water", 150, "2026-10-08T10:00:00+08:00"},
+		{"electricity", 220, ""},
+	}
+	for _, p := range payments {
+		var fee struct {
+			ID uint `json:"id"`
+		}
+		s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+			"tenantId": tenant.ID,
+			"feeType":  p.feeType,
+			"amount":   p.amount,
+			"dueDate":  "2026-10-10T00:00:00+08:00",
+		}, &fee)
+		if p.paidDate != "" {
+			s.mustDo(http.MethodPost, fmt.Sprintf("/api/fees/%d/pay", fee.ID), token, map[string]string{"paidDate": p.paidDate}, nil)
+		}
+	}
+
+	var report struct {
+		Total   float64 `json:"total"`
+		ByMonth []struct {
+			Month  string  `json:"month"`
+			Amount float64 `json:"amount"`
+		} `json:"byMonth"`
+		ByType []struct {
+			FeeType string  `json:"feeType"`
+			Amount  float64 `json:"amount"`
+		} `json:"byType"`
+	}
+	s.mustDo(http.MethodGet, "/api/reports/income?start=2026-09-01&end=2026-10-31", token, nil, &report)
+
+	if report.Total != 6150 {
+		t.Fatalf("expected total 6150, got %v", report.Total)
+	}
+	if len(report.ByMonth) != 2 || report.ByMonth[0].Amount != 3000 || report.ByMonth[1].Amount != 3150 {
+		t.Fatalf("unexpected income by month: %+v", report.ByMonth)
+	}
+	if len(report.ByType) != 2 || report.ByType[0].FeeType != "rent" || report.ByType[0].Amount != 6000 {
+		t.Fatalf("unexpected income by type: %+v", report.ByType)
+	}
+
+	var dashboard struct {
+		TotalTenants int64   `json:"totalTenants"`
+		PendingFees  int64   `json:"pendingFees"`
+		UnpaidAmount float64 `json:"unpaidAmount"`
+	}
+	s.mustDo(http.MethodGet, "/api/reports/dashboard", token, nil, &dashboard)
+	if dashboard.TotalTenants != 1 || dashboard.PendingFees != 1 || dashboard.UnpaidAmount != 220 {
+		t.Fatalf("unexpected dashboard: %+v", dashboard)
+	}
+}
+
+func TestTenantAccount(t *testing.T) {
+	s := newTestServer(t)
+	s.createUser("admin", "admin123", model.RoleAdmin)
+	s.createUser("viewer", "viewer123", model.RoleUser)
+	token := s.login("admin", "admin123")
+	viewer := s.login("viewer", "viewer123")
+	year := time.Now().Year()
+
+	var tenant, rent, water struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodPost, "/api/tenants", token, map[string]string{"name": "租户甲"}, &tenant)
+	s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+		"tenantId": tenant.ID,
+		"feeType":  "rent",
+		"amount":   3000,
+		"dueDate":  "2026-09-05T00:00:00+08:00",
+	}, &rent)
+	s.mustDo(http.MethodPost, fmt.Sprintf("/api/fees/%d/pay", rent.ID), token, map[string]string{"paidDate": "2026-09-03T10:00:00+08:00"}, nil)
+
+	var history []struct {
+		ID uint `json:"id"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/fees/%d/payments", rent.ID), token, nil, &history)
+	if len(history) != 1 {
+		t.Fatalf("expected one payment, got %+v", history)
+	}
+
+	creditBody := map[string]interface{}{"tenantId": tenant.ID, "feeId": rent.ID, "amount": 500, "reason": "停水补偿"}
+	if w, _ := s.do(http.MethodPost, "/api/credit-notes", viewer, creditBody); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for credit note without fee:credit, got %d", w.Code)
+	}
+	var note struct {
+		ID         uint   `json:"id"`
+		CreditNo   string `json:"creditNo"`
+		TenantName string `json:"tenantName"`
+	}
+	s.mustDo(http.MethodPost, "/api/credit-notes", token, creditBody, &note)
+	if note.CreditNo != fmt.Sprintf("CR-%d-000001", year) || note.TenantName != "租户甲" {
+		t.Fatalf("unexpected credit note: %+v", note)
+	}
+
+	s.mustDo(http.MethodPost, "/api/fees", token, map[string]interface{}{
+		"tenantId": tenant.ID,
+		"feeType":  "water",
+		"amount":   200,
+		"dueDate":  "2026-10-05T00:00:00+08:00",
+	}, &water)
+	var fee struct {
+		Status     string  `json:"status"`
+		PaidAmount float64 `json:"paidAmount"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/fees/%d", water.ID), token, nil, &fee)
+	if fee.Status != "paid" || fee.PaidAmount != 200 {
+		t.Fatalf("new fee must be settled from the credit balance: %+v", fee)
+	}
+
+	if w, _ := s.do(http.MethodPost, "/api/refunds", token, map[string]interface{}{
+		"tenantId": tenant.ID, "amount": 400, "method": "cash", "reason": "退还余额",
+	}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for refund above the credit balance, got %d", w.Code)
+	}
+	var refund struct {
+		RefundNo string `json:"refundNo"`
+	}
+	s.mustDo(http.MethodPost, "/api/refunds", token, map[string]interface{}{
+		"tenantId": tenant.ID, "paymentId": history[0].ID, "amount": 300, "method": "bank_transfer", "reason": "退还余额",
+	}, &refund)
+	if refund.RefundNo != fmt.Sprintf("RF-%d-000001", year) {
+		t.Fatalf("unexpected refund: %+v", refund)
+	}
+	if w, _ := s.do(http.MethodPost, fmt.Sprintf("/api/payments/%d/reverse", history[0].ID), token, map[string]string{"reason": "退票"}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when reversing a refunded payment, got %d", w.Code)
+	}
+
+	var statement struct {
+		OpeningBalance float64 `json:"openingBalance"`
+		ClosingBalance float64 `json:"closingBalance"`
+		Outstanding    float64 `json:"outstanding"`
+		CreditBalance  float64 `json:"creditBalance"`
+		Entries        []struct {
+			Type    string  `json:"type"`
+			Balance float64 `json:"balance"`
+		} `json:"entries"`
+	}
+	statementPath := fmt.Sprintf("/api/tenants/%d/statement", tenant.ID)
+	s.mustDo(http.MethodGet, statementPath+"?from=2026-09-04&to=2026-10-05", viewer, nil, &statement)
+	if statement.OpeningBalance != -3000 || statement.ClosingBalance != 200 || len(statement.Entries) != 2 {
+		t.Fatalf("unexpected ranged statement: %+v", statement)
+	}
+	s.mustDo(http.MethodGet, statementPath, viewer, nil, &statement)
+	if statement.ClosingBalance != 0 || statement.Outstanding != 0 || statement.CreditBalance != 0 || len(statement.Entries) != 5 {
+		t.Fatalf("unexpected statement: %+v", statement)
+	}
+	if w, _ := s.do(http.MethodGet, statementPath+"?from=2026/09/01", viewer, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid date, got %d", w.Code)
+	}
+	if w, _ := s.do(http.MethodGet, "/api/tenants/999/statement", viewer, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing tenant, got %d", w.Code)
+	}
+
+	var page struct {
+		Total int64 `json:"total"`
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/credit-notes?tenantId=%d", tenant.ID), viewer, nil, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected 1 credit note, got %d", page.Total)
+	}
+	s.mustDo(http.MethodGet, fmt.Sprintf("/api/refunds?tenantId=%d", tenant.ID), viewer, nil, &page)
+	if page.Total != 1 {
+		t.Fatalf("expected 1 refund, got %d", page.Total)
+	}
+}
@@ -0,0 +1,161 @@
+// Package scheduler 在服务进程内周期执行后台任务，如合同到期检查、周年租金递增、按合同生成租金、费用逾期检查、过期令牌清理。
+// 任务须可重复执行：多副本同时运行或执行失败后重试都不能产生重复数据。
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/wire"
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/service"
+)
+
+var ProviderSet = wire.NewSet(NewScheduler)
+
+// Job 按固定间隔执行的任务，now 为本次执行的时间
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(now time.Time) error
+}
+
+type Scheduler struct {
+	jobs []Job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewScheduler(
+	cfg *config.Config,
+	authService *service.AuthService,
+	expiryService *service.ContractExpiryService,
+	contractService *service.ContractService,
+	billingService *service.BillingService,
+	overdueService *service.OverdueService,
+) *Scheduler {
+	s := &Scheduler{stop: make(chan struct{})}
+	if !cfg.Scheduler.Enabled {
+		return s
+	}
+
+	interval, _ := time.ParseDuration(cfg.Scheduler.Interval)
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	s.Add(Job{
+		Name:     "contract_expiry",
+		Interval: interval,
+		Run: func(now time.Time) error {
+			result, err := expiryService.Run(now)
+			if err != nil {
+				return err
+			}
+			zap.L().Info("contract expiry checked",
+				zap.Int("expiring", result.Expiring),
+				zap.Int("expired", result.Expired),
+				zap.Int("reminders", result.Reminders))
+			return nil
+		},
+	})
+	s.Add(Job{
+		Name:     "rent_escalation",
+		Interval: interval,
+		Run: func(now time.Time) error {
+			count, err := contractService.ApplyAnniversaryEscalations(now)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				zap.L().Info("rent escalated on anniversary", zap.Int("contracts", count))
+			}
+			return nil
+		},
+	})
+	s.Add(Job{
+		Name:     "overdue",
+		Interval: interval,
+		Run: func(now time.Time) error {
+			result, err := overdueService.Run(now)
+			if err != nil {
+				return err
+			}
+			if result.Overdue > 0 || result.PenaltiesCreated > 0 || result.PenaltiesAccrued > 0 {
+				zap.L().Info("overdue fees checked",
+					zap.Int("overdue", result.Overdue),
+					zap.Int("penalties_created", result.PenaltiesCreated),
+					zap.Int("penalties_accrued", result.PenaltiesAccrued))
+			}
+			return nil
+		},
+	})
+	s.Add(Job{
+		Name:     "token_cleanup",
+		Interval: interval,
+		Run: func(time.Time) error {
+			return authService.PurgeExpiredTokens()
+		},
+	})
+	if cfg.Billing.AutoRun {
+		s.Add(Job{
+			Name:     "billing",
+			Interval: interval,
+			Run: func(now time.Time) error {
+				_, err := billingService.RunDue(now)
+				return err
+			},
+		})
+	}
+	return s
+}
+
+// Add 注册任务，须在 Start 之前调用
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start 为每个任务启动一个协程，启动时立即执行一次，之后按间隔执行
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+// Stop 通知全部任务退出并等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(job)
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce 执行一次任务，错误与 panic 只记录日志，不影响下次执行
+func (s *Scheduler) runOnce(job Job) {
+	defer func() {
+		if p := recover(); p != nil {
+			zap.L().Error("scheduled job panicked", zap.String("job", job.Name), zap.Any("panic", p))
+		}
+	}()
+
+	if err := job.Run(time.Now()); err != nil {
+		zap.L().Error("scheduled job failed", zap.String("job", job.Name), zap.Error(err))
+	}
+}
@@ -0,0 +1,533 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+var (
+	ErrInvalidCreditNote    = errors.New("贷项信息无效")
+	ErrCreditExceedsFee     = errors.New("贷项金额超过费用金额")
+	ErrInvalidRefund        = errors.New("退款信息无效")
+	ErrRefundExceedsCredit  = errors.New("退款金额超过账户余额")
+	ErrRefundExceedsPayment = errors.New("退款金额超过收款金额")
+)
+
+// 贷项与退款的编号系列
+const (
+	accountCreditSeries = "CR"
+	refundSeries        = "RF"
+)
+
+// 对账单明细类型：fee 为费用，waiver 为滞纳金减免，payment、reversal 为收款及其冲正，
+// credit_note、credit_note_void 为贷项及其随红字发票作废，refund 为退款
+const (
+	StatementEntryFee        = "fee"
+	StatementEntryWaiver     = "waiver"
+	StatementEntryPayment    = "payment"
+	StatementEntryReversal   = "reversal"
+	StatementEntryCreditNote = "credit_note"
+	StatementEntryCreditVoid = "credit_note_void"
+	StatementEntryRefund     = "refund"
+)
+
+// StatementEntry 对账单的一行。Debit 增加租户应付的余额，Credit 减少余额，Balance 为计入该行后的余额；
+// SourceID 为对应的费用、收款、贷项或退款 ID
+type StatementEntry struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"`
+	SourceID    uint      `json:"sourceId"`
+	Reference   string    `json:"reference"`
+	Description string    `json:"description"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+}
+
+// Statement 租户对账单。余额为正表示租户尚欠的金额，为负表示租户账户中的预存余额；
+// OpeningBalance 为查询区间之前的余额。Outstanding、CreditBalance 为当前未结清费用合计与账户余额，不受查询区间影响
+type Statement struct {
+	TenantID       uint             `json:"tenantId"`
+	TenantName     string           `json:"tenantName"`
+	OpeningBalance float64          `json:"openingBalance"`
+	TotalDebit     float64          `json:"totalDebit"`
+	TotalCredit    float64          `json:"totalCredit"`
+	ClosingBalance float64          `json:"closingBalance"`
+	Outstanding    float64          `json:"outstanding"`
+	CreditBalance  float64          `json:"creditBalance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+type AccountService struct {
+	accountRepo repository.AccountRepository
+	tenantRepo  repository.TenantRepository
+	feeRepo     repository.FeeRepository
+	paymentRepo repository.PaymentRepository
+	uow         repository.UnitOfWork
+}
+
+func NewAccountService(
+	accountRepo repository.AccountRepository,
+	tenantRepo repository.TenantRepository,
+	feeRepo repository.FeeRepository,
+	paymentRepo repository.PaymentRepository,
+	uow repository.UnitOfWork,
+) *AccountService {
+	return &AccountService{
+		accountRepo: accountRepo,
+		tenantRepo:  tenantRepo,
+		feeRepo:     feeRepo,
+		paymentRepo: paymentRepo,
+		uow:         uow,
+	}
+}
+
+// CreateCreditNote 开具贷项并计入租户账户余额，随即抵扣未结清的费用：先抵扣贷项针对的费用，
+// 再按到期日从早到晚抵扣其他费用，抵扣不完的部分留在账户中。同一费用的贷项合计不超过费用金额；
+// 费用已开具发票时同时冲减该发票中的费用明细，开具红字发票并记录在贷项的 InvoiceID 中
+func (s *AccountService) CreateCreditNote(note *model.CreditNote, actor Actor) error {
+	note.Amount = roundMoney(note.Amount)
+	if note.Amount <= 0 {
+		return fmt.Errorf("%w：金额须大于 0", ErrInvalidCreditNote)
+	}
+
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if err := lockAccount(tx, note.TenantID, ErrInvalidCreditNote); err != nil {
+			return err
+		}
+		note.InvoiceID, note.VoidedAt, note.VoidedBy = nil, nil, ""
+		var invoice *model.Invoice
+		if note.FeeID != nil {
+			// 发票先于费用加锁，与红字发票开具贷项的加锁顺序一致
+			found, err := tx.Invoices.FindIssuedByFee(*note.FeeID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if found != nil {
+				if invoice, err = tx.Invoices.FindByIDForUpdate(found.ID); err != nil {
+					return err
+				}
+				if invoice.Status != model.InvoiceStatusIssued {
+					invoice = nil
+				}
+			}
+
+			fee, err := tx.Fees.FindByIDForUpdate(*note.FeeID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w：费用 %d 不存在", ErrInvalidCreditNote, *note.FeeID)
+			}
+			if err != nil {
+				return err
+			}
+			if fee.TenantID != note.TenantID {
+				return fmt.Errorf("%w：费用 %d 不属于该租户", ErrInvalidCreditNote, fee.ID)
+			}
+			if fee.Status == "waived" {
+				return fmt.Errorf("%w：费用 %d 已减免", ErrInvalidCreditNote, fee.ID)
+			}
+			credited, err := tx.Accounts.SumCreditNotesByFee(fee.ID)
+			if err != nil {
+				return err
+			}
+			if available := roundMoney(fee.Amount - credited); note.Amount > available {
+				return fmt.Errorf("%w：费用 %d 可开具的贷项金额为 %.2f", ErrCreditExceedsFee, fee.ID, available)
+			}
+		}
+
+		now := time.Now()
+		if invoice != nil {
+			red, err := creditInvoicedFee(tx, invoice, *note.FeeID, note.Amount, note.Reason, actor, now)
+			if err != nil {
+				return err
+			}
+			note.InvoiceID = &red.ID
+		}
+		if err := issueAccountCredit(tx, note, actor, now); err != nil {
+			return err
+		}
+		return applyCredit(tx, note.TenantID, note.FeeID, actor, now)
+	})
+}
+
+// issueAccountCredit 为贷项分配 CR 系列编号，保存并记录审计
+func issueAccountCredit(tx *repository.Tx, note *model.CreditNote, actor Actor, now time.Time) error {
+	seq, err := tx.Accounts.NextNumber(accountCreditSeries, now.Year())
+	if err != nil {
+		return err
+	}
+	note.CreditNo = documentNo(accountCreditSeries, now.Year(), seq)
+	note.IssuedAt = now
+	note.IssuedBy = actor.Name
+	if err := tx.Accounts.CreateCreditNote(note); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, model.AuditEntityCreditNote, note.ID, model.AuditActionCreate, nil, note)
+}
+
+// creditInvoicedFee 为贷项冲减已锁定发票中该费用的明细，开具红字发票
+func creditInvoicedFee(tx *repository.Tx, invoice *model.Invoice, feeID uint, amount float64, reason string, actor Actor, now time.Time) (*model.Invoice, error) {
+	var lineID uint
+	for _, l := range invoice.Lines {
+		if l.FeeID != nil && *l.FeeID == feeID {
+			lineID = l.ID
+			break
+		}
+	}
+	return issueCreditInvoice(tx, invoice, reason, []CreditLine{{LineID: lineID, Amount: amount}}, actor, now)
+}
+
+// creditInvoiceFees 为红字发票冲减的每笔费用开具关联的贷项，随后抵扣未结清的费用。
+// 已减免的费用不再计入余额；调用方须已锁定租户行
+func creditInvoiceFees(tx *repository.Tx, invoice *model.Invoice, actor Actor, now time.Time) error {
+	var first *uint
+	for _, l := range invoice.Lines {
+		if l.FeeID == nil {
+			continue
+		}
+		fee, err := tx.Fees.FindByID(*l.FeeID)
+		if err != nil {
+			return err
+		}
+		if fee.Status == "waived" {
+			continue
+		}
+		credited, err := tx.Accounts.SumCreditNotesByFee(fee.ID)
+		if err != nil {
+			return err
+		}
+		amount := roundMoney(-l.Total)
+		if available := roundMoney(fee.Amount - credited); amount > available {
+			return fmt.Errorf("%w：费用 %d 可开具的贷项金额为 %.2f", ErrCreditExceedsFee, fee.ID, available)
+		}
+
+		feeID := fee.ID
+		note := &model.CreditNote{
+			TenantID:  invoice.TenantID,
+			FeeID:     &feeID,
+			Amount:    amount,
+			Reason:    fmt.Sprintf("红字发票 %s：%s", *invoice.InvoiceNo, invoice.Remark),
+			InvoiceID: &invoice.ID,
+		}
+		if err := issueAccountCredit(tx, note, actor, now); err != nil {
+			return err
+		}
+		if first == nil {
+			first = &feeID
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return applyCredit(tx, invoice.TenantID, first, actor, now)
+}
+
+// voidInvoiceCredits 作废红字发票开具的贷项。贷项计入的余额须仍留在账户中，
+// 已被抵扣或退款时返回 ErrInvoiceCreditUsed；调用方须已锁定租户行
+func voidInvoiceCredits(tx *repository.Tx, invoice *model.Invoice, actor Actor, now time.Time) error {
+	notes, err := tx.Accounts.FindCreditNotesByInvoice(invoice.ID)
+	if err != nil {
+		return err
+	}
+	var total float64
+	for _, n := range notes {
+		if n.VoidedAt == nil {
+			total = roundMoney(total + n.Amount)
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	balance, err := tx.Accounts.CreditBalance(invoice.TenantID)
+	if err != nil {
+		return err
+	}
+	if total > roundMoney(balance) {
+		return fmt.Errorf("%w：账户余额为 %.2f", ErrInvoiceCreditUsed, roundMoney(balance))
+	}
+
+	for _, n := range notes {
+		if n.VoidedAt != nil {
+			continue
+		}
+		before := n
+		n.VoidedAt = &now
+		n.VoidedBy = actor.Name
+		if err := tx.Accounts.VoidCreditNote(&n); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityCreditNote, n.ID, model.AuditActionVoid, &before, &n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AccountService) GetCreditNote(id uint) (*model.CreditNote, error) {
+	return s.accountRepo.FindCreditNoteByID(id)
+}
+
+func (s *AccountService) ListCreditNotes(page, pageSize int, tenantID uint) ([]model.CreditNote, int64, error) {
+	return s.accountRepo.ListCreditNotes(page, pageSize, tenantID)
+}
+
+// CreateRefund 从租户账户余额中退款，退款金额不超过账户余额。指定原路退回的收款时，
+// 该收款须未冲正，且其退款合计不超过收款金额；有退款的收款不能再冲正
+func (s *AccountService) CreateRefund(refund *model.Refund, actor Actor) error {
+	refund.Amount = roundMoney(refund.Amount)
+	if refund.Amount <= 0 {
+		return fmt.Errorf("%w：金额须大于 0", ErrInvalidRefund)
+	}
+	if !model.IsPaymentMethod(refund.Method) {
+		return fmt.Errorf("%w：不支持的退款方式 %s", ErrInvalidRefund, refund.Method)
+	}
+
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if err := lockAccount(tx, refund.TenantID, ErrInvalidRefund); err != nil {
+			return err
+		}
+		if refund.PaymentID != nil {
+			payment, err := tx.Payments.FindByIDForUpdate(*refund.PaymentID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w：收款 %d 不存在", ErrInvalidRefund, *refund.PaymentID)
+			}
+			if err != nil {
+				return err
+			}
+			if payment.TenantID != refund.TenantID {
+				return fmt.Errorf("%w：收款 %d 不属于该租户", ErrInvalidRefund, payment.ID)
+			}
+			if payment.Status == model.PaymentStatusReversed {
+				return ErrPaymentReversed
+			}
+			refunded, err := tx.Accounts.SumRefundsByPayment(payment.ID)
+			if err != nil {
+				return err
+			}
+			if available := roundMoney(payment.Amount - refunded); refund.Amount > available {
+				return fmt.Errorf("%w：收款 %d 可退金额为 %.2f", ErrRefundExceedsPayment, payment.ID, available)
+			}
+		}
+
+		balance, err := tx.Accounts.CreditBalance(refund.TenantID)
+		if err != nil {
+			return err
+		}
+		if refund.Amount > roundMoney(balance) {
+			return fmt.Errorf("%w：账户余额为 %.2f", ErrRefundExceedsCredit, roundMoney(balance))
+		}
+
+		now := time.Now()
+		if refund.RefundedAt.IsZero() {
+			refund.RefundedAt = now
+		}
+		seq, err := tx.Accounts.NextNumber(refundSeries, now.Year())
+		if err != nil {
+			return err
+		}
+		refund.RefundNo = documentNo(refundSeries, now.Year(), seq)
+		refund.OperatorID = actor.ID
+		refund.OperatorName = actor.Name
+		if err := tx.Accounts.CreateRefund(refund); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRefund, refund.ID, model.AuditActionCreate, nil, refund)
+	})
+}
+
+func (s *AccountService) GetRefund(id uint) (*model.Refund, error) {
+	return s.accountRepo.FindRefundByID(id)
+}
+
+func (s *AccountService) ListRefunds(page, pageSize int, tenantID uint) ([]model.Refund, int64, error) {
+	return s.accountRepo.ListRefunds(page, pageSize, tenantID)
+}
+
+// Statement 生成租户在 [from, to) 内的对账单，from、to 为空时不限。费用按到期日（未设置时按创建时间）计入借方，
+// 收款按到账时间全额、贷项按开具时间计入贷方，冲正、贷项作废与退款计入借方，减免的滞纳金按减免时间计入贷方；
+// 收款未分配的部分转入账户余额，与余额抵扣费用一样只在账户内部转移，不另计入对账单
+func (s *AccountService) Statement(tenantID uint, from, to *time.Time) (*Statement, error) {
+	tenant, err := s.tenantRepo.FindByID(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := s.feeRepo.FindByTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.paymentRepo.FindByTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.accountRepo.FindCreditNotesByTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.accountRepo.FindRefundsByTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	credit, err := s.accountRepo.CreditBalance(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{TenantID: tenant.ID, TenantName: tenant.Name, CreditBalance: roundMoney(credit), Entries: []StatementEntry{}}
+	var entries []StatementEntry
+	for _, fee := range fees {
+		date := fee.DueDate
+		if date.IsZero() {
+			date = fee.CreatedAt
+		}
+		description := feeTypeName(fee.FeeType)
+		if fee.RoomNo != "" {
+			description += "（" + fee.RoomNo + "）"
+		}
+		entries = append(entries, StatementEntry{Date: date, Type: StatementEntryFee, SourceID: fee.ID, Reference: fee.Period, Description: description, Debit: fee.Amount})
+		if fee.Status == "waived" {
+			if fee.WaivedAt != nil {
+				entries = append(entries, StatementEntry{Date: *fee.WaivedAt, Type: StatementEntryWaiver, SourceID: fee.ID, Reference: fee.Period, Description: "减免" + description + "：" + fee.WaiveReason, Credit: fee.Amount})
+			}
+			continue
+		}
+		statement.Outstanding = roundMoney(statement.Outstanding + fee.Outstanding())
+	}
+	for _, p := range payments {
+		reference := ""
+		if p.Receipt != nil {
+			reference = p.Receipt.ReceiptNo
+		}
+		description := "收款（" + paymentMethodName(p.Method) + "）"
+		if p.Credited > 0 {
+			description += fmt.Sprintf("，其中 %.2f 转入账户余额", p.Credited)
+		}
+		entries = append(entries, StatementEntry{Date: p.ReceivedAt, Type: StatementEntryPayment, SourceID: p.ID, Reference: reference, Description: description, Credit: p.Amount})
+		if p.Status == model.PaymentStatusReversed && p.ReversedAt != nil {
+			entries = append(entries, StatementEntry{Date: *p.ReversedAt, Type: StatementEntryReversal, SourceID: p.ID, Reference: reference, Description: "收款冲正：" + p.ReversalReason, Debit: p.Amount})
+		}
+	}
+	for _, n := range notes {
+		entries = append(entries, StatementEntry{Date: n.IssuedAt, Type: StatementEntryCreditNote, SourceID: n.ID, Reference: n.CreditNo, Description: "贷项：" + n.Reason, Credit: n.Amount})
+		if n.VoidedAt != nil {
+			entries = append(entries, StatementEntry{Date: *n.VoidedAt, Type: StatementEntryCreditVoid, SourceID: n.ID, Reference: n.CreditNo, Description: "贷项随红字发票作废", Debit: n.Amount})
+		}
+	}
+	for _, r := range refunds {
+		entries = append(entries, StatementEntry{Date: r.RefundedAt, Type: StatementEntryRefund, SourceID: r.ID, Reference: r.RefundNo, Description: "退款（" + paymentMethodName(r.Method) + "）：" + r.Reason, Debit: r.Amount})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+
+	balance := 0.0
+	for _, e := range entries {
+		if to != nil && !e.Date.Before(*to) {
+			break
+		}
+		balance = roundMoney(balance + e.Debit - e.Credit)
+		if from != nil && e.Date.Before(*from) {
+			statement.OpeningBalance = balance
+			continue
+		}
+		e.Balance = balance
+		statement.TotalDebit = roundMoney(statement.TotalDebit + e.Debit)
+		statement.TotalCredit = roundMoney(statement.TotalCredit + e.Credit)
+		statement.Entries = append(statement.Entries, e)
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// lockAccount 锁定租户行，串行化同一租户账户余额的变动
+func lockAccount(tx *repository.Tx, tenantID uint, invalid error) error {
+	_, err := tx.Tenants.FindByIDForUpdate(tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w：租户不存在", invalid)
+	}
+	return err
+}
+
+// applyCredit 用租户的账户余额抵扣未结清的费用，first 指定的费用优先，其余按到期日从早到晚。
+// 调用方须已锁定租户行；费用按 ID 顺序加锁，与收款登记一致
+func applyCredit(tx *repository.Tx, tenantID uint, first *uint, actor Actor, now time.Time) error {
+	balance, err := tx.Accounts.CreditBalance(tenantID)
+	if err != nil || balance <= 0 {
+		return err
+	}
+	fees, err := tx.Fees.FindOutstanding(tenantID)
+	if err != nil || len(fees) == 0 {
+		return err
+	}
+	if first != nil {
+		sort.SliceStable(fees, func(i, j int) bool { return fees[i].ID == *first && fees[j].ID != *first })
+	}
+
+	ids := make([]uint, len(fees))
+	for i := range fees {
+		ids[i] = fees[i].ID
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	locked := make(map[uint]*model.Fee, len(ids))
+	for _, id := range ids {
+		fee, err := tx.Fees.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		locked[id] = fee
+	}
+
+	for _, f := range fees {
+		if balance <= 0 {
+			break
+		}
+		fee := locked[f.ID]
+		amount := min(balance, fee.Outstanding())
+		if amount <= 0 {
+			continue
+		}
+		if err := creditFee(tx, fee, amount, actor, now); err != nil {
+			return err
+		}
+		balance = roundMoney(balance - amount)
+	}
+	return nil
+}
+
+// applyCreditToFee 新建费用或滞纳金累计增加后用租户的账户余额抵扣该费用的未收金额，抵扣记在 actor 名下
+func applyCreditToFee(tx *repository.Tx, fee *model.Fee, actor Actor) error {
+	if fee.Outstanding() <= 0 || fee.Status == "waived" {
+		return nil
+	}
+	if _, err := tx.Tenants.FindByIDForUpdate(fee.TenantID); err != nil {
+		return err
+	}
+	balance, err := tx.Accounts.CreditBalance(fee.TenantID)
+	if err != nil || balance <= 0 {
+		return err
+	}
+	return creditFee(tx, fee, min(balance, fee.Outstanding()), actor, time.Now())
+}
+
+// creditFee 记录一笔余额抵扣，抵扣金额计入费用的已收金额并更新费用状态，费用的变更记入审计日志
+func creditFee(tx *repository.Tx, fee *model.Fee, amount float64, actor Actor, now time.Time) error {
+	before := *fee
+	if err := tx.Accounts.CreateApplication(&model.CreditApplication{
+		TenantID:  fee.TenantID,
+		FeeID:     fee.ID,
+		Amount:    amount,
+		AppliedAt: now,
+		AppliedBy: actor.Name,
+	}); err != nil {
+		return err
+	}
+	fee.PaidAmount = roundMoney(fee.PaidAmount + amount)
+	settleFee(fee, now)
+	if err := tx.Fees.Update(fee); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionCredit, &before, fee)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+)
+
+func newTestAccountService(repos *storage.Repositories) *AccountService {
+	return NewAccountService(repos.Accounts, repos.Tenants, repos.Fees, repos.Payments, repos.UnitOfWork)
+}
+
+func TestCreditNotesRefundsAndAutoApplication(t *testing.T) {
+	repos := newTestRepositories()
+	accountService := newTestAccountService(repos)
+	paymentService, feeService := newTestPaymentServices(repos)
+	tenantService := NewTenantService(repos.Tenants, repos.Contracts, repos.Rooms, repos.Fees, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 9, 5))
+
+	payment := &model.Payment{Amount: 3000, ReceivedAt: *date(2026, 9, 3)}
+	if err := feeService.Pay(rent.ID, payment, testActor); err != nil {
+		t.Fatalf("pay rent: %v", err)
+	}
+
+	// 已缴清的费用开具贷项后全部留在账户中
+	note := &model.CreditNote{TenantID: tenant.ID, FeeID: &rent.ID, Amount: 500, Reason: "九月停水补偿"}
+	if err := accountService.CreateCreditNote(note, testActor); err != nil {
+		t.Fatalf("create credit note: %v", err)
+	}
+	if note.CreditNo != expectedNo(accountCreditSeries, 1) || note.IssuedBy != testActor.Name {
+		t.Fatalf("unexpected credit note: %+v", note)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 500 {
+		t.Fatalf("expected credit balance 500, got %v", balance)
+	}
+
+	over := &model.CreditNote{TenantID: tenant.ID, FeeID: &rent.ID, Amount: 2600, Reason: "超额"}
+	if err := accountService.CreateCreditNote(over, testActor); !errors.Is(err, ErrCreditExceedsFee) {
+		t.Fatalf("expected ErrCreditExceedsFee, got %v", err)
+	}
+
+	// 新费用自动抵扣账户余额
+	water := mustCreateFee(t, feeService, tenant.ID, "water", 200, date(2026, 10, 5))
+	got, _ := feeService.GetByID(water.ID)
+	if got.Status != "paid" || got.PaidAmount != 200 {
+		t.Fatalf("new fee must be settled from the credit balance: %+v", got)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 300 {
+		t.Fatalf("expected credit balance 300 after application, got %v", balance)
+	}
+
+	if err := tenantService.Delete(tenant.ID, testActor); !errors.Is(err, ErrTenantInUse) {
+		t.Fatalf("expected ErrTenantInUse while the account holds credit, got %v", err)
+	}
+
+	tooMuch := &model.Refund{TenantID: tenant.ID, Amount: 400, Method: model.PaymentMethodCash, Reason: "退还余额"}
+	if err := accountService.CreateRefund(tooMuch, testActor); !errors.Is(err, ErrRefundExceedsCredit) {
+		t.Fatalf("expected ErrRefundExceedsCredit, got %v", err)
+	}
+	refund := &model.Refund{TenantID: tenant.ID, PaymentID: &payment.ID, Amount: 300, Method: model.PaymentMethodBankTransfer, Reason: "退还余额"}
+	if err := accountService.CreateRefund(refund, testActor); err != nil {
+		t.Fatalf("create refund: %v", err)
+	}
+	if refund.RefundNo != expectedNo(refundSeries, 1) || refund.OperatorName != testActor.Name {
+		t.Fatalf("unexpected refund: %+v", refund)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 0 {
+		t.Fatalf("expected empty credit balance after refund, got %v", balance)
+	}
+
+	if _, err := paymentService.Reverse(payment.ID, "银行退票", testActor); !errors.Is(err, ErrPaymentRefunded) {
+		t.Fatalf("expected ErrPaymentRefunded, got %v", err)
+	}
+}
+
+func TestCreditNoteSettlesOutstandingFees(t *testing.T) {
+	repos := newTestRepositories()
+	accountService := newTestAccountService(repos)
+	_, feeService := newTestPaymentServices(repos)
+	tenant := mustCreateTenant(t, repos, "租户乙")
+	other := mustCreateTenant(t, repos, "租户丙")
+	older := mustCreateFee(t, feeService, tenant.ID, "water", 80, date(2026, 8, 5))
+	rent := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 9, 5))
+	foreign := mustCreateFee(t, feeService, other.ID, "rent", 1000, nil)
+
+	bad := &model.CreditNote{TenantID: tenant.ID, FeeID: &foreign.ID, Amount: 100, Reason: "错误"}
+	if err := accountService.CreateCreditNote(bad, testActor); !errors.Is(err, ErrInvalidCreditNote) {
+		t.Fatalf("expected ErrInvalidCreditNote for another tenant's fee, got %v", err)
+	}
+
+	// 贷项先抵扣所针对的费用，即使还有更早到期的费用
+	note := &model.CreditNote{TenantID: tenant.ID, FeeID: &rent.ID, Amount: 3000, Reason: "押金转租金"}
+	if err := accountService.CreateCreditNote(note, testActor); err != nil {
+		t.Fatalf("create credit note: %v", err)
+	}
+	got, _ := feeService.GetByID(rent.ID)
+	if got.Status != "paid" || got.PaidAmount != 3000 {
+		t.Fatalf("targeted fee must be settled first: %+v", got)
+	}
+	got, _ = feeService.GetByID(older.ID)
+	if got.Status != "unpaid" || got.PaidAmount != 0 {
+		t.Fatalf("older fee must be untouched: %+v", got)
+	}
+
+	// 未指定费用的贷项按到期日从早到晚抵扣
+	general := &model.CreditNote{TenantID: tenant.ID, Amount: 50, Reason: "优惠"}
+	if err := accountService.CreateCreditNote(general, testActor); err != nil {
+		t.Fatalf("create credit note: %v", err)
+	}
+	got, _ = feeService.GetByID(older.ID)
+	if got.Status != "partially_paid" || got.PaidAmount != 50 {
+		t.Fatalf("remaining credit must go to the oldest fee: %+v", got)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 0 {
+		t.Fatalf("expected credit fully applied, got %v", balance)
+	}
+
+	// 每次余额抵扣都在费用上留下审计记录
+	for _, id := range []uint{rent.ID, older.ID} {
+		audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityFee, id)
+		if len(audits) != 2 || audits[0].Action != model.AuditActionCreate || audits[1].Action != model.AuditActionCredit || audits[1].ActorName != testActor.Name {
+			t.Fatalf("fee %d: unexpected audit trail %+v", id, audits)
+		}
+	}
+}
+
+func TestCreditNotesFollowInvoices(t *testing.T) {
+	repos := newTestRepositories()
+	accountService := newTestAccountService(repos)
+	invoiceService := newTestInvoiceService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreatePeriodFee(t, repos, tenant.ID, "rent", "2026-10", 1090)
+
+	invoice := &model.Invoice{TenantID: tenant.ID, Period: "2026-10"}
+	if err := invoiceService.Create(invoice, nil, testActor); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+	if _, err := invoiceService.Issue(invoice.ID, testActor); err != nil {
+		t.Fatalf("issue invoice: %v", err)
+	}
+
+	// 已开票费用的贷项同时开具红字发票
+	note := &model.CreditNote{TenantID: tenant.ID, FeeID: &rent.ID, Amount: 200, Reason: "租金优惠"}
+	if err := accountService.CreateCreditNote(note, testActor); err != nil {
+		t.Fatalf("create credit note: %v", err)
+	}
+	if note.InvoiceID == nil {
+		t.Fatalf("credit note on an invoiced fee must reference its red invoice: %+v", note)
+	}
+	red, _ := invoiceService.GetByID(*note.InvoiceID)
+	if red.Kind != model.InvoiceKindCreditNote || red.Total != -200 || *red.OriginalID != invoice.ID || red.Remark != note.Reason {
+		t.Fatalf("unexpected red invoice: %+v", red)
+	}
+	if inv, _ := invoiceService.GetByID(invoice.ID); inv.CreditedAmount != 200 {
+		t.Fatalf("expected credited amount 200, got %v", inv.CreditedAmount)
+	}
+
+	// 红字发票为其中的费用开具贷项，抵扣后费用结清
+	rest, err := invoiceService.CreditNote(invoice.ID, "退租", nil, testActor)
+	if err != nil || rest.Total != -890 {
+		t.Fatalf("unexpected red invoice: %+v, %v", rest, err)
+	}
+	linked, _ := repos.Accounts.FindCreditNotesByInvoice(rest.ID)
+	if len(linked) != 1 || linked[0].Amount != 890 || *linked[0].FeeID != rent.ID {
+		t.Fatalf("red invoice must post a linked credit note: %+v", linked)
+	}
+	if f, _ := repos.Fees.FindByID(rent.ID); f.Status != "paid" || f.PaidAmount != 1090 {
+		t.Fatalf("credited fee must be settled: %+v", f)
+	}
+	if audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityCreditNote, linked[0].ID); len(audits) != 1 || audits[0].Action != model.AuditActionCreate {
+		t.Fatalf("expected the linked credit note to be audited: %+v", audits)
+	}
+
+	// 贷项已抵扣费用，红字发票不能再作废
+	if _, err := invoiceService.Void(rest.ID, "退租取消", testActor); !errors.Is(err, ErrInvoiceCreditUsed) {
+		t.Fatalf("expected ErrInvoiceCreditUsed, got %v", err)
+	}
+
+	statement, err := accountService.Statement(tenant.ID, nil, nil)
+	if err != nil || statement.ClosingBalance != 0 || statement.TotalCredit != 1090 {
+		t.Fatalf("statement must match the credited invoice: %+v, %v", statement, err)
+	}
+}
+
+func TestStatementBalances(t *testing.T) {
+	repos := newTestRepositories()
+	accountService := newTestAccountService(repos)
+	_, feeService := newTestPaymentServices(repos)
+	tenant := mustCreateTenant(t, repos, "租户丁")
+	september := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 9, 5))
+	october := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 10, 5))
+	if err := feeService.Pay(september.ID, &model.Payment{Amount: 3000, ReceivedAt: *date(2026, 9, 3)}, testActor); err != nil {
+		t.Fatalf("pay rent: %v", err)
+	}
+
+	statement, err := accountService.Statement(tenant.ID, date(2026, 9, 4), nil)
+	if err != nil {
+		t.Fatalf("statement: %v", err)
+	}
+	if statement.OpeningBalance != -3000 || statement.ClosingBalance != 3000 || len(statement.Entries) != 2 {
+		t.Fatalf("unexpected statement: %+v", statement)
+	}
+	if statement.Entries[0].SourceID != september.ID || statement.Entries[0].Balance != 0 || statement.Entries[1].Balance != 3000 {
+		t.Fatalf("unexpected running balance: %+v", statement.Entries)
+	}
+
+	statement, _ = accountService.Statement(tenant.ID, date(2026, 10, 1), date(2026, 10, 6))
+	if statement.OpeningBalance != 0 || statement.TotalDebit != 3000 || statement.TotalCredit != 0 || len(statement.Entries) != 1 {
+		t.Fatalf("unexpected ranged statement: %+v", statement)
+	}
+
+	note := &model.CreditNote{TenantID: tenant.ID, FeeID: &october.ID, Amount: 500, Reason: "维修期间租金减免"}
+	if err := accountService.CreateCreditNote(note, testActor); err != nil {
+		t.Fatalf("create credit note: %v", err)
+	}
+	statement, _ = accountService.Statement(tenant.ID, nil, nil)
+	if statement.Outstanding != 2500 || statement.CreditBalance != 0 {
+		t.Fatalf("unexpected account totals: %+v", statement)
+	}
+	if statement.ClosingBalance != statement.Outstanding-statement.CreditBalance {
+		t.Fatalf("closing balance %v must equal outstanding %v minus credit %v",
+			statement.ClosingBalance, statement.Outstanding, statement.CreditBalance)
+	}
+	last := statement.Entries[len(statement.Entries)-1]
+	if last.Type != StatementEntryCreditNote || last.Reference != note.CreditNo || last.Credit != 500 {
+		t.Fatalf("unexpected last entry: %+v", last)
+	}
+}
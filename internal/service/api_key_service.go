@@ -0,0 +1,181 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+const (
+	apiKeyScheme = "tk"
+	// apiKeyTouchInterval 最近使用时间的最小写入间隔，避免每个请求都写库
+	apiKeyTouchInterval = time.Minute
+)
+
+var ErrInvalidAPIKey = errors.New("无效的 API 密钥")
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// CreateAPIKeyResponse 创建 API 密钥的结果，Key 为明文密钥，只返回这一次
+type CreateAPIKeyResponse struct {
+	Key    string        `json:"key" example:"tk_1a2b3c4d_9f86d081884c7d659a2feaa0c55ad015"`
+	APIKey *model.APIKey `json:"apiKey"`
+}
+
+// Create 为用户创建 API 密钥，权限范围不能超出用户自身权限
+func (s *APIKeyService) Create(userID uint, name string, permissions, allowedIPs []string, expiresAt *time.Time) (*CreateAPIKeyResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if len(permissions) == 0 {
+		return nil, errors.New("请指定密钥权限范围")
+	}
+	granted := user.EffectivePermissions()
+	for _, p := range permissions {
+		if !utils.HasPermission(granted, p) {
+			return nil, errors.New("权限超出当前用户范围: " + p)
+		}
+	}
+
+	for _, ip := range allowedIPs {
+		if parseIPRule(ip) == nil {
+			return nil, errors.New("无效的 IP 或 CIDR: " + ip)
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("过期时间必须晚于当前时间")
+	}
+
+	prefix, err := utils.GenerateRandomToken(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+	rawKey := apiKeyScheme + "_" + prefix + "_" + secret
+
+	key := &model.APIKey{
+		UserID:      userID,
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     utils.HashToken(rawKey),
+		Permissions: permissions,
+		AllowedIPs:  allowedIPs,
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyResponse{Key: rawKey, APIKey: key}, nil
+}
+
+func (s *APIKeyService) List(page, pageSize int, userID uint) ([]model.APIKey, int64, error) {
+	return s.apiKeyRepo.List(page, pageSize, userID)
+}
+
+func (s *APIKeyService) GetByID(id uint) (*model.APIKey, error) {
+	return s.apiKeyRepo.FindByID(id)
+}
+
+func (s *APIKeyService) Revoke(id uint) error {
+	return s.apiKeyRepo.Revoke(id, time.Now())
+}
+
+// Authenticate 校验明文 API 密钥：哈希、吊销状态、过期时间与来源 IP
+func (s *APIKeyService) Authenticate(rawKey, ip string) (*model.APIKey, error) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.FindByPrefix(parts[1])
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(rawKey))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, errors.New("API 密钥已吊销")
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, errors.New("API 密钥已过期")
+	}
+	if !ipAllowed(key.AllowedIPs, ip) {
+		return nil, errors.New("来源 IP 不在允许范围内")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval || key.LastUsedIP != ip {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID, ip, now); err != nil {
+			zap.L().Warn("failed to record api key usage", zap.Uint("apiKeyID", key.ID), zap.Error(err))
+		}
+	}
+
+	return key, nil
+}
+
+func ipAllowed(rules []string, ip string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, rule := range rules {
+		if network := parseIPRule(rule); network != nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPRule 解析单个 IP 或 CIDR，单个 IP 视为掩码全 1 的网段
+func parseIPRule(rule string) *net.IPNet {
+	if strings.Contains(rule, "/") {
+		_, network, err := net.ParseCIDR(rule)
+		if err != nil {
+			return nil
+		}
+		return network
+	}
+
+	addr := net.ParseIP(rule)
+	if addr == nil {
+		return nil
+	}
+	bits := 8 * net.IPv6len
+	if v4 := addr.To4(); v4 != nil {
+		addr = v4
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: addr, Mask: net.CIDRMask(bits, bits)}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+// auditIgnoredFields 比较更新前后差异时忽略的字段
+var auditIgnoredFields = map[string]bool{
+	"updatedAt":  true,
+	"tenantName": true,
+}
+
+type AuditService struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditService(auditLogRepo repository.AuditLogRepository) *AuditService {
+	return &AuditService{auditLogRepo: auditLogRepo}
+}
+
+// recordAudit 在事务中记录一次数据变更，与数据变更一同提交或回滚，审计写入失败时整个操作失败。
+// before 为 nil 表示创建，after 为 nil 表示删除；更新时只保存发生变化的字段，没有变化则不记录。
+// 接口发起的变更以当前请求的用户为操作人，后台任务以 system 为操作人
+func recordAudit(tx *repository.Tx, actor Actor, entityType string, entityID uint, action string, before, after interface{}) error {
+	entry := &model.AuditLog{
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+		APIKeyID:   actor.APIKeyID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+	}
+	changed, err := fillAuditChanges(entry, before, after)
+	if err != nil || !changed {
+		return err
+	}
+	return tx.AuditLogs.Create(entry)
+}
+
+// fillAuditChanges 将变更前后的字段写入 entry，更新时只保留发生变化的字段；没有变化时返回 false
+func fillAuditChanges(entry *model.AuditLog, before, after interface{}) (bool, error) {
+	beforeFields, err := toFieldMap(before)
+	if err != nil {
+		return false, err
+	}
+	afterFields, err := toFieldMap(after)
+	if err != nil {
+		return false, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if auditIgnoredFields[key] || reflect.DeepEqual(value, afterFields[key]) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+		if len(beforeFields) == 0 && len(afterFields) == 0 {
+			return false, nil
+		}
+	}
+
+	if entry.Before, err = marshalFields(beforeFields); err != nil {
+		return false, err
+	}
+	if entry.After, err = marshalFields(afterFields); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *AuditService) List(page, pageSize int, entityType string, entityID, actorID uint, action, requestID string, from, to *time.Time) ([]model.AuditLog, int64, error) {
+	return s.auditLogRepo.List(page, pageSize, entityType, entityID, actorID, action, requestID, from, to)
+}
+
+func (s *AuditService) History(entityType string, entityID uint) ([]model.AuditLog, error) {
+	return s.auditLogRepo.ListByEntity(entityType, entityID)
+}
+
+// toFieldMap 按 JSON 序列化结果把实体转换为字段表，便于逐字段比较
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func marshalFields(fields map[string]interface{}) (model.JSON, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
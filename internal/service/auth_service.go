@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
 	"yuxialuozi_graduation_design_backend/internal/config"
@@ -12,46 +13,347 @@ import (
 	"yuxialuozi_graduation_design_backend/pkg/utils"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("无效的刷新令牌")
+	ErrLoginLocked         = errors.New("登录失败次数过多，请稍后再试")
+	ErrNoSession           = errors.New("API 密钥认证的请求没有可退出的会话")
+)
+
 type AuthService struct {
-	userRepo *repository.UserRepository
-	config   *config.Config
+	userRepo         repository.UserRepository
+	tokenRepo        repository.TokenRepository
+	loginHistoryRepo repository.LoginHistoryRepository
+	keyService       *KeyService
+	config           *config.Config
 }
 
-func NewAuthService(userRepo *repository.UserRepository, config *config.Config) *AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	loginHistoryRepo repository.LoginHistoryRepository,
+	keyService *KeyService,
+	config *config.Config,
+) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		config:   config,
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		loginHistoryRepo: loginHistoryRepo,
+		keyService:       keyService,
+		config:           config,
 	}
 }
 
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // LoginResponse represents the response for login API.
 // @Description LoginResponse represents the response for login API with JWT token and user info.
 type LoginResponse struct {
-	Token string           `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User  *model.UserResponse `json:"user"`
+	Token        string              `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string              `json:"refreshToken" example:"9f86d081884c7d659a2feaa0c55ad015..."`
+	ExpiresIn    int64               `json:"expiresIn" example:"900"`
+	User         *model.UserResponse `json:"user"`
+	// MFARequired 为 true 时 Token 为空，需携带 MFAToken 调用 /auth/mfa/verify 完成登录
+	MFARequired bool   `json:"mfaRequired" example:"false"`
+	MFAToken    string `json:"mfaToken,omitempty"`
+	// MFASetupRequired 为 true 时表示当前角色被要求启用两步验证，完成绑定前只能访问 /auth 下的接口
+	MFASetupRequired bool `json:"mfaSetupRequired" example:"false"`
 }
 
 func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
+	ipWindow, _ := time.ParseDuration(s.config.Login.IPWindow)
+	ipFailures, err := s.loginHistoryRepo.CountFailuresByIPSince(req.IP, time.Now().Add(-ipWindow))
+	if err != nil {
+		return nil, err
+	}
+	if s.config.Login.IPMaxAttempts > 0 && ipFailures >= int64(s.config.Login.IPMaxAttempts) {
+		s.recordLogin(nil, req, false, "IP 已限流")
+		return nil, ErrLoginLocked
+	}
+
 	user, err := s.userRepo.FindByUsername(req.Username)
 	if err != nil {
+		s.recordLogin(nil, req, false, "用户不存在")
 		return nil, errors.New("用户名或密码错误")
 	}
 
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		s.recordLogin(user, req, false, "账号已锁定")
+		return nil, ErrLoginLocked
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		s.recordLogin(user, req, false, "密码错误")
+		if err := s.registerFailure(user); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("用户名或密码错误")
 	}
 
-	expire, _ := time.ParseDuration(s.config.JWT.Expire)
-	token, err := utils.GenerateToken(user.ID, user.Username, user.Role, s.config.JWT.Secret, expire)
+	if user.Status == model.UserStatusDisabled {
+		s.recordLogin(user, req, false, "账号已禁用")
+		return nil, errors.New("账号已被禁用")
+	}
+
+	if user.FailedAttempts > 0 || user.LockedUntil != nil {
+		user.FailedAttempts = 0
+		user.LockedUntil = nil
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	if user.MFAEnabled {
+		pendingExpire, _ := time.ParseDuration(s.config.MFA.PendingExpire)
+		key, err := s.keyService.SigningKey()
+		if err != nil {
+			return nil, errors.New("生成 token 失败")
+		}
+		mfaToken, err := utils.GenerateMFAToken(user.ID, user.Username, key, pendingExpire)
+		if err != nil {
+			return nil, errors.New("生成 token 失败")
+		}
+		return &LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	return s.completeLogin(user, req)
+}
+
+// VerifyMFA 登录第二步：校验两步验证码或恢复码后签发令牌
+func (s *AuthService) VerifyMFA(mfaToken, code string, req *LoginRequest) (*LoginResponse, error) {
+	claims, err := utils.ParseToken(mfaToken, s.keyService.VerificationKey)
+	if err != nil || claims.Purpose != utils.PurposeMFA {
+		return nil, errors.New("两步验证已过期，请重新登录")
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || !user.MFAEnabled {
+		return nil, errors.New("两步验证已过期，请重新登录")
+	}
+	req.Username = user.Username
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		s.recordLogin(user, req, false, "账号已锁定")
+		return nil, ErrLoginLocked
+	}
+
+	if !verifyMFACode(user, code) {
+		s.recordLogin(user, req, false, "两步验证码错误")
+		if err := s.registerFailure(user); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	user.FailedAttempts = 0
+	user.LockedUntil = nil
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(user, req)
+}
+
+func (s *AuthService) completeLogin(user *model.User, req *LoginRequest) (*LoginResponse, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, errors.New("生成 token 失败")
+	}
+
+	result, err := s.issueTokens(user, familyID, req.IP, req.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordLogin(user, req, true, "")
+	return result, nil
+}
+
+// maxBackoffShift 指数退避的最大倍数为 2^maxBackoffShift，避免位移溢出
+const maxBackoffShift = 10
+
+// registerFailure 在数据库中原子地累加连续失败次数：未达上限时按指数退避短暂锁定，
+// 达到上限后锁定 lockout_duration；上一次锁定到期后再失败时重新计数
+func (s *AuthService) registerFailure(user *model.User) error {
+	now := time.Now()
+	attempts, err := s.userRepo.IncrementFailedAttempts(user.ID, s.config.Login.MaxAttempts, now)
+	if err != nil {
+		return err
+	}
+	user.FailedAttempts = attempts
+
+	lockout, _ := time.ParseDuration(s.config.Login.LockoutDuration)
+	var wait time.Duration
+	if s.config.Login.MaxAttempts > 0 && attempts >= s.config.Login.MaxAttempts {
+		wait = lockout
+		zap.L().Warn("account locked after repeated login failures",
+			zap.Uint("userId", user.ID),
+			zap.Int("failedAttempts", attempts),
+		)
+	} else {
+		base, _ := time.ParseDuration(s.config.Login.BackoffBase)
+		wait = base << min(attempts-1, maxBackoffShift)
+		if lockout > 0 && wait > lockout {
+			wait = lockout
+		}
+	}
+
+	if wait <= 0 {
+		return nil
+	}
+	lockedUntil := now.Add(wait)
+	user.LockedUntil = &lockedUntil
+	return s.userRepo.LockUntil(user.ID, lockedUntil)
+}
+
+func (s *AuthService) recordLogin(user *model.User, req *LoginRequest, success bool, reason string) {
+	history := &model.LoginHistory{
+		Username:  req.Username,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		Success:   success,
+		Reason:    reason,
+	}
+	if user != nil {
+		history.UserID = &user.ID
+	}
+
+	if err := s.loginHistoryRepo.Create(history); err != nil {
+		zap.L().Error("failed to record login history", zap.Error(err))
+	}
+}
+
+func (s *AuthService) ListLoginHistory(page, pageSize int, userID uint, username, ip string, success *bool) ([]model.LoginHistory, int64, error) {
+	return s.loginHistoryRepo.List(page, pageSize, userID, username, ip, success)
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌，并轮换刷新令牌。
+// 已轮换或已撤销的刷新令牌再次使用时，撤销其所在 family 的全部会话。
+// 令牌以条件更新原子地标记为已使用，并发使用同一令牌时只有一个请求成功，其余按重用处理。
+func (s *AuthService) Refresh(refreshToken, ip, userAgent string) (*LoginResponse, error) {
+	token, err := s.tokenRepo.FindRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil || token.RevokedAt != nil {
+		return nil, s.refreshTokenReused(token, ip)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil || user.Status == model.UserStatusDisabled {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	claimed, err := s.tokenRepo.ClaimRefreshToken(token.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, s.refreshTokenReused(token, ip)
+	}
+
+	return s.issueTokens(user, token.FamilyID, ip, userAgent)
+}
+
+// refreshTokenReused 记录刷新令牌重用并撤销其所在 family，撤销成功时返回 ErrInvalidRefreshToken
+func (s *AuthService) refreshTokenReused(token *model.RefreshToken, ip string) error {
+	zap.L().Warn("refresh token reuse detected",
+		zap.Uint("userId", token.UserID),
+		zap.String("familyId", token.FamilyID),
+		zap.String("ip", ip),
+	)
+	if err := s.revokeFamily(token.FamilyID); err != nil {
+		return err
+	}
+	return ErrInvalidRefreshToken
+}
+
+// Logout 注销当前会话：访问令牌加入黑名单，并撤销对应的刷新令牌 family。
+// 没有访问令牌 ID（如 API 密钥认证）时返回 ErrNoSession
+func (s *AuthService) Logout(userID uint, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return ErrNoSession
+	}
+	if err := s.tokenRepo.RevokeAccessToken(tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+
+	token, err := s.tokenRepo.FindRefreshTokenByAccessJTI(tokenID)
+	if err != nil {
+		return nil
+	}
+	return s.revokeFamily(token.FamilyID)
+}
+
+// LogoutAll 注销用户的全部会话
+func (s *AuthService) LogoutAll(userID uint) error {
+	tokens, err := s.tokenRepo.FindActiveRefreshTokensByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.revokeTokens(tokens)
+}
+
+// PurgeExpiredTokens 清理已过期的黑名单记录与刷新令牌，由后台任务定期执行
+func (s *AuthService) PurgeExpiredTokens() error {
+	return s.tokenRepo.DeleteExpired()
+}
+
+func (s *AuthService) GetCurrentUser(userID uint) (*model.User, error) {
+	return s.userRepo.FindByID(userID)
+}
+
+func (s *AuthService) issueTokens(user *model.User, familyID, ip, userAgent string) (*LoginResponse, error) {
+	accessExpire, _ := time.ParseDuration(s.config.JWT.Expire)
+	refreshExpire, _ := time.ParseDuration(s.config.JWT.RefreshExpire)
+
+	tokenID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, errors.New("生成 token 失败")
+	}
+
+	key, err := s.keyService.SigningKey()
+	if err != nil {
+		return nil, errors.New("生成 token 失败")
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Username, user.Role, tokenID, key, accessExpire)
+	if err != nil {
+		return nil, errors.New("生成 token 失败")
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
 	if err != nil {
 		return nil, errors.New("生成 token 失败")
 	}
 
+	now := time.Now()
+	if err := s.tokenRepo.CreateRefreshToken(&model.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       utils.HashToken(refreshToken),
+		FamilyID:        familyID,
+		AccessJTI:       tokenID,
+		AccessExpiresAt: now.Add(accessExpire),
+		ExpiresAt:       now.Add(refreshExpire),
+		IP:              ip,
+		UserAgent:       userAgent,
+	}); err != nil {
+		return nil, err
+	}
+
 	// Convert model.User to model.UserResponse for Swagger
 	userResponse := &model.UserResponse{
 		ID:          user.ID,
@@ -60,38 +362,45 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 		Avatar:      user.Avatar,
 		Role:        user.Role,
 		Permissions: []string(user.Permissions),
+		Status:      user.Status,
+		MFAEnabled:  user.MFAEnabled,
+		TenantID:    user.TenantID,
 		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
 	}
 
 	return &LoginResponse{
-		Token: token,
-		User:  userResponse,
+		Token:            accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(accessExpire.Seconds()),
+		User:             userResponse,
+		MFASetupRequired: s.config.MFA.IsRequired(user.Role) && !user.MFAEnabled,
 	}, nil
 }
 
-func (s *AuthService) GetCurrentUser(userID uint) (*model.User, error) {
-	return s.userRepo.FindByID(userID)
-}
-
-func (s *AuthService) CreateDefaultAdmin() error {
-	_, err := s.userRepo.FindByUsername("admin")
-	if err == nil {
-		return nil
-	}
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+func (s *AuthService) revokeFamily(familyID string) error {
+	tokens, err := s.tokenRepo.FindActiveRefreshTokensByFamily(familyID)
 	if err != nil {
 		return err
 	}
+	return s.revokeTokens(tokens)
+}
 
-	admin := &model.User{
-		Username:    "admin",
-		Password:    string(hashedPassword),
-		Nickname:    "管理员",
-		Role:        "admin",
-		Permissions: []string{"*"},
-	}
+// revokeTokens 撤销刷新令牌，并将其最近签发且未过期的访问令牌加入黑名单
+func (s *AuthService) revokeTokens(tokens []model.RefreshToken) error {
+	now := time.Now()
+	for i := range tokens {
+		token := &tokens[i]
+		if token.AccessJTI != "" && token.AccessExpiresAt.After(now) {
+			if err := s.tokenRepo.RevokeAccessToken(token.AccessJTI, token.UserID, token.AccessExpiresAt); err != nil {
+				return err
+			}
+		}
 
-	return s.userRepo.Create(admin)
-}
\ No newline at end of file
+		token.RevokedAt = &now
+		if err := s.tokenRepo.UpdateRefreshToken(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
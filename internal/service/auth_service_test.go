@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+func newTestAuthService(t *testing.T) (*AuthService, *UserService) {
+	t.Helper()
+	cfg := testConfig()
+	repos := newTestRepositories()
+
+	keyService := NewKeyService(repos.SigningKeys, cfg)
+	authService := NewAuthService(repos.Users, repos.Tokens, repos.LoginHistories, keyService, cfg)
+	userService := NewUserService(repos.Users, repos.Tenants)
+
+	if err := userService.Create(&model.User{Username: "alice", Role: "admin"}, "secret123"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return authService, userService
+}
+
+func TestLogin(t *testing.T) {
+	authService, _ := newTestAuthService(t)
+
+	resp, err := authService.Login(&LoginRequest{Username: "alice", Password: "secret123", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if resp.Token == "" || resp.RefreshToken == "" {
+		t.Fatalf("expected access and refresh tokens, got %+v", resp)
+	}
+	if resp.User == nil || resp.User.Username != "alice" {
+		t.Fatalf("unexpected user in response: %+v", resp.User)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	authService, _ := newTestAuthService(t)
+
+	if _, err := authService.Login(&LoginRequest{Username: "alice", Password: "wrong", IP: "127.0.0.1"}); err == nil {
+		t.Fatal("expected login with wrong password to fail")
+	}
+	if _, err := authService.Login(&LoginRequest{Username: "nobody", Password: "secret123", IP: "127.0.0.1"}); err == nil {
+		t.Fatal("expected login with unknown user to fail")
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	authService, _ := newTestAuthService(t)
+
+	for i := 0; i < 3; i++ {
+		authService.Login(&LoginRequest{Username: "alice", Password: "wrong", IP: "127.0.0.1"})
+	}
+
+	if _, err := authService.Login(&LoginRequest{Username: "alice", Password: "secret123", IP: "127.0.0.1"}); err == nil {
+		t.Fatal("expected locked account to reject correct password")
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	authService, _ := newTestAuthService(t)
+
+	login, err := authService.Login(&LoginRequest{Username: "alice", Password: "secret123", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	rotated, err := authService.Refresh(login.RefreshToken, "127.0.0.1", "")
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	if _, err := authService.Refresh(login.RefreshToken, "127.0.0.1", ""); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected ErrInvalidRefreshToken on reuse, got %v", err)
+	}
+	if _, err := authService.Refresh(rotated.RefreshToken, "127.0.0.1", ""); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("reuse must revoke the rotated token of the same family, got %v", err)
+	}
+}
+
+func TestConcurrentRefreshSucceedsOnce(t *testing.T) {
+	authService, _ := newTestAuthService(t)
+
+	login, err := authService.Login(&LoginRequest{Username: "alice", Password: "secret123", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := authService.Refresh(login.RefreshToken, "127.0.0.1", ""); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one refresh to succeed, got %d", succeeded)
+	}
+}
+
+func TestLoginFailuresCountedConcurrently(t *testing.T) {
+	cfg := testConfig()
+	cfg.Login.MaxAttempts = 50
+	repos := newTestRepositories()
+	authService := NewAuthService(repos.Users, repos.Tokens, repos.LoginHistories, NewKeyService(repos.SigningKeys, cfg), cfg)
+	if err := NewUserService(repos.Users, repos.Tenants).Create(&model.User{Username: "alice", Role: "admin"}, "secret123"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authService.Login(&LoginRequest{Username: "alice", Password: "wrong", IP: "127.0.0.1"})
+		}()
+	}
+	wg.Wait()
+
+	user, _ := repos.Users.FindByUsername("alice")
+	if user.FailedAttempts != 10 {
+		t.Fatalf("expected 10 failed attempts, got %d", user.FailedAttempts)
+	}
+}
+
+func TestLoginFailuresResetAfterLockoutAndBackoffCapped(t *testing.T) {
+	cfg := testConfig()
+	cfg.Login.BackoffBase = "1s"
+	repos := newTestRepositories()
+	authService := NewAuthService(repos.Users, repos.Tokens, repos.LoginHistories, NewKeyService(repos.SigningKeys, cfg), cfg)
+	if err := NewUserService(repos.Users, repos.Tenants).Create(&model.User{Username: "alice", Role: "admin"}, "secret123"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	// 锁定到期后再次失败从 1 重新计数，只按退避基数短暂锁定
+	user, _ := repos.Users.FindByUsername("alice")
+	expired := time.Now().Add(-time.Minute)
+	user.FailedAttempts, user.LockedUntil = cfg.Login.MaxAttempts, &expired
+	repos.Users.Update(user)
+	authService.Login(&LoginRequest{Username: "alice", Password: "wrong", IP: "127.0.0.1"})
+	user, _ = repos.Users.FindByUsername("alice")
+	if user.FailedAttempts != 1 || user.LockedUntil == nil || time.Until(*user.LockedUntil) > 2*time.Second {
+		t.Fatalf("expected the counter to restart after lockout expiry: attempts=%d lockedUntil=%v", user.FailedAttempts, user.LockedUntil)
+	}
+
+	// 退避时长不超过 lockout_duration，失败次数很大时也不溢出
+	cfg.Login.MaxAttempts = 0
+	user.FailedAttempts, user.LockedUntil = 70, nil
+	repos.Users.Update(user)
+	authService.Login(&LoginRequest{Username: "alice", Password: "wrong", IP: "127.0.0.1"})
+	user, _ = repos.Users.FindByUsername("alice")
+	if user.LockedUntil == nil {
+		t.Fatal("expected a backoff lock")
+	}
+	if wait := time.Until(*user.LockedUntil); wait <= 0 || wait > 15*time.Minute {
+		t.Fatalf("expected backoff capped at the lockout duration, got %v", wait)
+	}
+}
+
+func TestConcurrentKeyRotationAcrossInstances(t *testing.T) {
+	cfg := testConfig()
+	cfg.JWT.Algorithm = utils.AlgEdDSA
+	cfg.JWT.RotationInterval = "720h"
+	repos := newTestRepositories()
+
+	// 两个副本共享同一组密钥，同时发现没有生效密钥时只应生成一个
+	instances := []*KeyService{NewKeyService(repos.SigningKeys, cfg), NewKeyService(repos.SigningKeys, cfg)}
+	var wg sync.WaitGroup
+	for _, instance := range instances {
+		wg.Add(1)
+		go func(s *KeyService) {
+			defer wg.Done()
+			if _, err := s.SigningKey(); err != nil {
+				t.Errorf("signing key: %v", err)
+			}
+		}(instance)
+	}
+	wg.Wait()
+
+	keys, _ := repos.SigningKeys.List()
+	if len(keys) != 1 {
+		t.Fatalf("expected a single active key across instances, got %d", len(keys))
+	}
+
+	rotated, err := instances[0].Rotate()
+	if err != nil || rotated.KID == keys[0].KID {
+		t.Fatalf("manual rotation must create a new key: %+v, %v", rotated, err)
+	}
+	keys, _ = repos.SigningKeys.List()
+	active := 0
+	for _, k := range keys {
+		if k.RetiredAt == nil {
+			active++
+		}
+	}
+	if len(keys) != 2 || active != 1 {
+		t.Fatalf("expected the old key retired after rotation: %d keys, %d active", len(keys), active)
+	}
+}
+
+func TestLogoutWithoutSession(t *testing.T) {
+	authService, _ := newTestAuthService(t)
+
+	if err := authService.Logout(1, "", time.Now().Add(time.Hour)); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("expected ErrNoSession for a request without token id, got %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+var (
+	ErrInvalidBillingPeriod = errors.New("账期格式错误，应为 YYYY-MM")
+	ErrInvalidBilling       = errors.New("计费条款无效")
+)
+
+// 账单明细的处理结果
+const (
+	BillingItemCreated = "created"
+	BillingItemExists  = "exists"
+	BillingItemPending = "pending"
+)
+
+// BillingItem 账单生成的一笔租金，对应合同中一间房间的一个计费周期。
+// ServiceStart、ServiceEnd 为计费周期中处于租期内的日期（含两端），不足整月的部分按天折算
+type BillingItem struct {
+	ContractID   uint      `json:"contractId"`
+	ContractNo   string    `json:"contractNo"`
+	TenantID     uint      `json:"tenantId"`
+	TenantName   string    `json:"tenantName"`
+	RoomNo       string    `json:"roomNo"`
+	Period       string    `json:"period"`
+	ServiceStart time.Time `json:"serviceStart"`
+	ServiceEnd   time.Time `json:"serviceEnd"`
+	MonthlyRent  float64   `json:"monthlyRent"`
+	Prorated     bool      `json:"prorated"`
+	Amount       float64   `json:"amount"`
+	DueDate      time.Time `json:"dueDate"`
+	// Result 为 created（本次生成）、exists（此前已生成）或 pending（预览时将要生成）
+	Result string `json:"result"`
+	FeeID  *uint  `json:"feeId,omitempty"`
+}
+
+// BillingRun 一次账单生成的结果。Created、Pending 分别为本次生成和预览时将要生成的笔数，
+// Skipped 为此前已生成而跳过的笔数，Amount 为本次生成（预览时为将要生成）的租金合计
+type BillingRun struct {
+	Period  string        `json:"period"`
+	DryRun  bool          `json:"dryRun"`
+	Created int           `json:"created"`
+	Pending int           `json:"pending"`
+	Skipped int           `json:"skipped"`
+	Amount  float64       `json:"amount"`
+	Items   []BillingItem `json:"items"`
+}
+
+type BillingService struct {
+	contractRepo repository.ContractRepository
+	feeRepo      repository.FeeRepository
+	uow          repository.UnitOfWork
+	config       *config.Config
+}
+
+func NewBillingService(contractRepo repository.ContractRepository, feeRepo repository.FeeRepository, uow repository.UnitOfWork, cfg *config.Config) *BillingService {
+	return &BillingService{
+		contractRepo: contractRepo,
+		feeRepo:      feeRepo,
+		uow:          uow,
+		config:       cfg,
+	}
+}
+
+// Run 为账期 period（YYYY-MM）生成租金：收租月份落在该月的每个合同计费周期、每间房间生成一笔 rent 费用。
+// 已生成的费用（含回收站中的）不会重复生成，可重复执行；dryRun 时只计算不保存。生成的租金以 actor 记录审计
+func (s *BillingService) Run(period string, dryRun bool, actor Actor) (*BillingRun, error) {
+	month, err := time.ParseInLocation("2006-01", period, time.Local)
+	if err != nil {
+		return nil, ErrInvalidBillingPeriod
+	}
+
+	// 季度计费的合同最早可在周期开始前三个月内覆盖本月账单
+	from := month.AddDate(0, -3, 0)
+	to := month.AddDate(0, 1, -1)
+	contracts, err := s.contractRepo.FindOverlapping(from, to, model.BillableContractStatuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &BillingRun{Period: period, DryRun: dryRun, Items: []BillingItem{}}
+	for i := range contracts {
+		for _, item := range billingItems(&contracts[i], month) {
+			if err := s.bill(&contracts[i], &item, dryRun, actor); err != nil {
+				return nil, err
+			}
+			switch item.Result {
+			case BillingItemExists:
+				run.Skipped++
+			case BillingItemCreated:
+				run.Created++
+			case BillingItemPending:
+				run.Pending++
+			}
+			if item.Result != BillingItemExists {
+				run.Amount = roundMoney(run.Amount + item.Amount)
+			}
+			run.Items = append(run.Items, item)
+		}
+	}
+	return run, nil
+}
+
+// bill 保存一笔租金并设置处理结果，新生成的租金用租户的账户余额抵扣
+func (s *BillingService) bill(contract *model.Contract, item *BillingItem, dryRun bool, actor Actor) error {
+	if dryRun {
+		exists, err := s.feeRepo.ExistsContractRent(contract.ID, item.RoomNo, item.Period)
+		if err != nil {
+			return err
+		}
+		item.Result = BillingItemPending
+		if exists {
+			item.Result = BillingItemExists
+		}
+		return nil
+	}
+
+	fee := &model.Fee{
+		TenantID:   contract.TenantID,
+		ContractID: &contract.ID,
+		RoomNo:     item.RoomNo,
+		FeeType:    "rent",
+		Amount:     item.Amount,
+		Period:     item.Period,
+		DueDate:    item.DueDate,
+		Status:     "unpaid",
+	}
+	return s.uow.Do(func(tx *repository.Tx) error {
+		created, err := tx.Fees.CreateIfAbsent(fee)
+		if err != nil {
+			return err
+		}
+		item.Result = BillingItemExists
+		if !created {
+			return nil
+		}
+		item.Result = BillingItemCreated
+		item.FeeID = &fee.ID
+		if err := recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionCreate, nil, fee); err != nil {
+			return err
+		}
+		return applyCreditToFee(tx, fee, actor)
+	})
+}
+
+// RunDue 供后台任务调用：生成当月租金，距下月不足 billing.lead_days 天时同时生成下月租金，返回生成的笔数
+func (s *BillingService) RunDue(now time.Time) (int, error) {
+	periods := []string{now.Format("2006-01")}
+	if next := now.AddDate(0, 0, s.config.Billing.LeadDays).Format("2006-01"); next != periods[0] {
+		periods = append(periods, next)
+	}
+
+	created := 0
+	for _, period := range periods {
+		run, err := s.Run(period, false, systemActor)
+		if err != nil {
+			return created, err
+		}
+		created += run.Created
+		if run.Created > 0 {
+			zap.L().Info("rent fees generated", zap.String("period", period),
+				zap.Int("created", run.Created), zap.Float64("amount", run.Amount))
+		}
+	}
+	return created, nil
+}
+
+// billingCycle 计费周期，Start、End 为周期首尾两天
+type billingCycle struct {
+	Label      string
+	Start, End time.Time
+}
+
+// cycleContaining 返回 day 所在的计费周期，季度按自然季度划分
+func cycleContaining(day time.Time, cycle string) billingCycle {
+	if cycle == model.BillingQuarterly {
+		quarter := (int(day.Month()) - 1) / 3
+		start := time.Date(day.Year(), time.Month(quarter*3+1), 1, 0, 0, 0, 0, day.Location())
+		return billingCycle{
+			Label: fmt.Sprintf("%d-Q%d", day.Year(), quarter+1),
+			Start: start,
+			End:   start.AddDate(0, 3, -1),
+		}
+	}
+	start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	return billingCycle{Label: start.Format("2006-01"), Start: start, End: start.AddDate(0, 1, -1)}
+}
+
+// billingItems 计算合同在账期 month 应收的租金。预付的计费周期在租期开始的月份收取，
+// 后付的计费周期在租期结束后的下一个月收取；只保留收租月份为 month 的周期。
+// 租期不含到期日当天，续签合同从原合同到期日起租，不会重复计租；提前终止的合同计租到终止当天
+func billingItems(contract *model.Contract, month time.Time) []BillingItem {
+	if contract.Status == model.ContractStatusTerminated && contract.TerminatedAt == nil {
+		return nil
+	}
+	leaseStart := civilDate(contract.StartDate)
+	leaseEnd := contract.LeaseEnd().AddDate(0, 0, -1)
+
+	var items []BillingItem
+	seen := map[string]bool{}
+	for _, day := range []time.Time{month, month.AddDate(0, -1, 0)} {
+		cycle := cycleContaining(day, contract.Billing.Cycle)
+		if seen[cycle.Label] {
+			continue
+		}
+		seen[cycle.Label] = true
+
+		start, end := laterOf(cycle.Start, leaseStart), earlierOf(cycle.End, leaseEnd)
+		if start.After(end) {
+			continue
+		}
+
+		billedIn := start
+		if contract.Billing.Mode == model.BillingInArrears {
+			billedIn = end.AddDate(0, 0, 1-end.Day()).AddDate(0, 1, 0)
+		}
+		if billedIn.Year() != month.Year() || billedIn.Month() != month.Month() {
+			continue
+		}
+
+		dueDay := contract.Billing.DueDay
+		if dueDay < 1 {
+			dueDay = 1
+		}
+		dueDate := time.Date(month.Year(), month.Month(), dueDay, 0, 0, 0, 0, month.Location())
+		if dueDate.Before(start) {
+			// 月中起租时首期租金不早于起租日
+			dueDate = start
+		}
+		prorated := !start.Equal(cycle.Start) || !end.Equal(cycle.End)
+
+		for _, room := range contract.Rooms {
+			items = append(items, BillingItem{
+				ContractID:   contract.ID,
+				ContractNo:   contract.ContractNo,
+				TenantID:     contract.TenantID,
+				TenantName:   contract.TenantName,
+				RoomNo:       room.RoomNo,
+				Period:       cycle.Label,
+				ServiceStart: start,
+				ServiceEnd:   end,
+				MonthlyRent:  room.MonthlyRent,
+				Prorated:     prorated,
+				Amount:       proratedRent(room.MonthlyRent, start, end),
+				DueDate:      dueDate,
+			})
+		}
+	}
+	return items
+}
+
+// proratedRent 计算 start 到 end（含两端）的租金：整月按月租金，不足整月的按当月天数折算
+func proratedRent(monthlyRent float64, start, end time.Time) float64 {
+	var total float64
+	for monthStart := start.AddDate(0, 0, 1-start.Day()); !monthStart.After(end); monthStart = monthStart.AddDate(0, 1, 0) {
+		monthEnd := monthStart.AddDate(0, 1, -1)
+		from, to := laterOf(monthStart, start), earlierOf(monthEnd, end)
+		days := math.Round(to.Sub(from).Hours()/24) + 1
+		total += monthlyRent * days / float64(monthEnd.Day())
+	}
+	return roundMoney(total)
+}
+
+// validateBilling 校验计费条款并补齐默认值：按月、每月 1 日、预付
+func validateBilling(b *model.ContractBilling) error {
+	if b.Cycle == "" {
+		b.Cycle = model.BillingMonthly
+	}
+	if b.DueDay == 0 {
+		b.DueDay = 1
+	}
+	if b.Mode == "" {
+		b.Mode = model.BillingInAdvance
+	}
+
+	if b.Cycle != model.BillingMonthly && b.Cycle != model.BillingQuarterly {
+		return fmt.Errorf("%w：不支持的计费周期 %s", ErrInvalidBilling, b.Cycle)
+	}
+	if b.DueDay < 1 || b.DueDay > 28 {
+		return fmt.Errorf("%w：缴费日须在 1 到 28 之间", ErrInvalidBilling)
+	}
+	if b.Mode != model.BillingInAdvance && b.Mode != model.BillingInArrears {
+		return fmt.Errorf("%w：不支持的收租方式 %s", ErrInvalidBilling, b.Mode)
+	}
+	return nil
+}
+
+// civilDate 取 t 在其自身时区的日期，转换为本地时区的零点，避免不同时区的日期比较错位
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlierOf(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
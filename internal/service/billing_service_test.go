@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+)
+
+func newTestBillingService(repos *storage.Repositories) *BillingService {
+	return NewBillingService(repos.Contracts, repos.Fees, repos.UnitOfWork, testConfig())
+}
+
+func TestBillingRunMonthlyAdvance(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	billingService := newTestBillingService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	a101 := mustCreateRoom(t, repos, "A101")
+	a102 := mustCreateRoom(t, repos, "A102")
+
+	contract := newLease(tenant.ID, date(2026, 10, 15), date(2027, 10, 15))
+	contract.Billing = model.ContractBilling{DueDay: 5}
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: a101.ID}, {RoomID: a102.ID, MonthlyRent: 2000}})
+
+	run, err := billingService.Run("2026-10", false, testActor)
+	if err != nil {
+		t.Fatalf("billing run: %v", err)
+	}
+	if run.Created != 2 || run.Amount != 2741.93 {
+		t.Fatalf("expected two prorated fees totalling 2741.93, got %+v", run)
+	}
+	first := run.Items[0]
+	if first.Period != "2026-10" || !first.Prorated || first.Amount != 1645.16 || !first.DueDate.Equal(first.ServiceStart) {
+		t.Fatalf("unexpected first item: %+v", first)
+	}
+
+	again, err := billingService.Run("2026-10", false, testActor)
+	if err != nil || again.Created != 0 || again.Skipped != 2 {
+		t.Fatalf("billing run must be idempotent, got %+v, %v", again, err)
+	}
+
+	preview, err := billingService.Run("2026-11", true, testActor)
+	if err != nil || preview.Pending != 2 || preview.Created != 0 || preview.Amount != 5000 {
+		t.Fatalf("unexpected preview: %+v, %v", preview, err)
+	}
+	if preview.Items[0].Prorated || preview.Items[0].DueDate.Day() != 5 {
+		t.Fatalf("full month must not be prorated and is due on the 5th: %+v", preview.Items[0])
+	}
+	if _, total, _ := repos.Fees.List(1, 100, 0, "", "rent", "", ""); total != 2 {
+		t.Fatalf("dry run must not create fees, got %d fees", total)
+	}
+
+	last, err := billingService.Run("2027-10", false, testActor)
+	if err != nil || last.Created != 2 || last.Items[0].Amount != 1354.84 {
+		t.Fatalf("last month must be prorated to the day before the end date, got %+v, %v", last, err)
+	}
+	if after, _ := billingService.Run("2027-11", false, testActor); len(after.Items) != 0 {
+		t.Fatalf("nothing is due after the lease ends, got %+v", after.Items)
+	}
+}
+
+func TestBillingRunQuarterlyArrears(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	billingService := newTestBillingService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	contract := newLease(tenant.ID, date(2026, 8, 10), date(2027, 8, 10))
+	contract.Billing = model.ContractBilling{Cycle: model.BillingQuarterly, DueDay: 10, Mode: model.BillingInArrears}
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: room.ID}})
+
+	// 未生效即作废的合同不计租
+	voided := newLease(tenant.ID, date(2026, 1, 1), date(2027, 1, 1))
+	if err := contractService.Create(voided, []model.ContractRoom{{RoomID: mustCreateRoom(t, repos, "A102").ID}}, testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+	if err := contractService.Transition(voided, model.ContractStatusTerminated, "作废", testActor); err != nil {
+		t.Fatalf("void contract: %v", err)
+	}
+
+	if run, _ := billingService.Run("2026-09", false, testActor); len(run.Items) != 0 {
+		t.Fatalf("arrears must not be billed before the quarter ends, got %+v", run.Items)
+	}
+
+	run, err := billingService.Run("2026-10", false, testActor)
+	if err != nil || len(run.Items) != 1 {
+		t.Fatalf("expected one fee for the quarterly contract, got %+v, %v", run, err)
+	}
+	item := run.Items[0]
+	if item.Period != "2026-Q3" || item.Amount != 5129.03 || item.DueDate.Month() != 10 || item.DueDate.Day() != 10 {
+		t.Fatalf("unexpected quarterly item: %+v", item)
+	}
+
+	fee, err := repos.Fees.FindByID(*item.FeeID)
+	if err != nil || fee.ContractID == nil || *fee.ContractID != contract.ID || fee.FeeType != "rent" || fee.Status != "unpaid" {
+		t.Fatalf("unexpected generated fee: %+v, %v", fee, err)
+	}
+}
+
+func TestBillingRunTerminatedContract(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	billingService := newTestBillingService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	contract := newLease(tenant.ID, date(2026, 1, 1), date(2027, 1, 1))
+	contract.Billing = model.ContractBilling{Mode: model.BillingInArrears}
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: room.ID}})
+	if err := contractService.Transition(contract, model.ContractStatusTerminated, "提前退租", testActor); err != nil {
+		t.Fatalf("terminate contract: %v", err)
+	}
+	if contract.TerminatedAt == nil {
+		t.Fatal("termination of an active contract must record the termination time")
+	}
+
+	// 固定终止时间为 9 月 20 日
+	terminatedAt := time.Date(2026, 9, 20, 15, 0, 0, 0, time.Local)
+	contract.TerminatedAt = &terminatedAt
+	if err := repos.Contracts.Update(contract); err != nil {
+		t.Fatalf("update contract: %v", err)
+	}
+
+	// 后付的最后一期按天计租到终止当天，在终止后的下一个月收取
+	run, err := billingService.Run("2026-10", false, testActor)
+	if err != nil || len(run.Items) != 1 {
+		t.Fatalf("expected the final cycle of the terminated contract, got %+v, %v", run, err)
+	}
+	item := run.Items[0]
+	if item.Period != "2026-09" || !item.Prorated || item.Amount != 2000 || !item.ServiceEnd.Equal(*date(2026, 9, 20)) {
+		t.Fatalf("unexpected final item: %+v", item)
+	}
+	if after, _ := billingService.Run("2026-11", false, testActor); len(after.Items) != 0 {
+		t.Fatalf("nothing is due after the termination, got %+v", after.Items)
+	}
+}
+
+func TestValidateBilling(t *testing.T) {
+	billing := model.ContractBilling{}
+	if err := validateBilling(&billing); err != nil || billing.Cycle != model.BillingMonthly || billing.DueDay != 1 || billing.Mode != model.BillingInAdvance {
+		t.Fatalf("expected defaults, got %+v, %v", billing, err)
+	}
+	if err := validateBilling(&model.ContractBilling{DueDay: 31}); err == nil {
+		t.Fatal("due day after the 28th must be rejected")
+	}
+}
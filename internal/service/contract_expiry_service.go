@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+// systemActor 后台任务变更合同状态时记录的操作人
+var systemActor = Actor{Name: "system"}
+
+// ExpiryResult 一次到期检查中变更状态的合同数与新生成的提醒数
+type ExpiryResult struct {
+	Expiring  int `json:"expiring"`
+	Expired   int `json:"expired"`
+	Reminders int `json:"reminders"`
+}
+
+type ContractExpiryService struct {
+	contractRepo     repository.ContractRepository
+	notificationRepo repository.NotificationRepository
+	contractService  *ContractService
+	config           *config.Config
+}
+
+func NewContractExpiryService(
+	contractRepo repository.ContractRepository,
+	notificationRepo repository.NotificationRepository,
+	contractService *ContractService,
+	config *config.Config,
+) *ContractExpiryService {
+	return &ContractExpiryService{
+		contractRepo:     contractRepo,
+		notificationRepo: notificationRepo,
+		contractService:  contractService,
+		config:           config,
+	}
+}
+
+// Run 检查占用房间的合同：到达到期日的变为 expired 并释放房间，距到期不足
+// contract.expiring_days 天的变为 expiring，到达提醒节点时生成续租提醒。
+// 重复执行不会重复变更或提醒；单个合同失败只记录日志，下次执行时重试
+func (s *ContractExpiryService) Run(now time.Time) (*ExpiryResult, error) {
+	today := startOfDay(now)
+	horizon := s.config.Contract.ExpiringDays
+	for _, days := range s.config.Contract.ReminderDays {
+		if days > horizon {
+			horizon = days
+		}
+	}
+
+	contracts, err := s.contractRepo.FindEndingBefore(today.AddDate(0, 0, horizon+1), model.OccupyingContractStatuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExpiryResult{}
+	for i := range contracts {
+		if err := s.check(&contracts[i], today, result); err != nil {
+			zap.L().Warn("contract expiry check failed",
+				zap.Uint("contract_id", contracts[i].ID),
+				zap.String("contract_no", contracts[i].ContractNo),
+				zap.Error(err))
+		}
+	}
+	return result, nil
+}
+
+// check 处理单个合同。租期不含到期日当天（见 Contract.LeaseEnd），到期日当天即变为 expired，与计租一致
+func (s *ContractExpiryService) check(contract *model.Contract, today time.Time, result *ExpiryResult) error {
+	if contract.LeaseEndedOn(today) {
+		if err := s.contractService.Transition(contract, model.ContractStatusExpired, "合同已过到期日", systemActor); err != nil {
+			return err
+		}
+		result.Expired++
+		return nil
+	}
+
+	daysLeft := daysUntil(today, contract.EndDate)
+	if contract.Status == model.ContractStatusActive && daysLeft <= s.config.Contract.ExpiringDays {
+		reason := fmt.Sprintf("距到期日 %d 天", daysLeft)
+		if err := s.contractService.Transition(contract, model.ContractStatusExpiring, reason, systemActor); err != nil {
+			return err
+		}
+		result.Expiring++
+	}
+
+	created, err := s.remind(contract, daysLeft)
+	if err != nil {
+		return err
+	}
+	if created {
+		result.Reminders++
+	}
+	return nil
+}
+
+// remind 生成已到达的最近一个提醒节点的提醒。合同在提醒节点之后才创建或到期日被修改时，
+// 只补发最近的节点，不会一次生成多条
+func (s *ContractExpiryService) remind(contract *model.Contract, daysLeft int) (bool, error) {
+	offset := -1
+	for _, days := range s.config.Contract.ReminderDays {
+		if days >= daysLeft && (offset < 0 || days < offset) {
+			offset = days
+		}
+	}
+	if offset < 0 {
+		return false, nil
+	}
+
+	endDate := contract.EndDate.Format("2006-01-02")
+	return s.notificationRepo.CreateIfAbsent(&model.Notification{
+		Type:       model.NotificationTypeContractExpiry,
+		DedupKey:   fmt.Sprintf("%s:%d:%s:%d", model.NotificationTypeContractExpiry, contract.ID, endDate, offset),
+		TenantID:   &contract.TenantID,
+		ContractID: &contract.ID,
+		Title:      fmt.Sprintf("合同 %s 距到期还有 %d 天", contract.ContractNo, daysLeft),
+		Content:    fmt.Sprintf("租户 %s 的合同 %s 将于 %s 到期，请及时跟进续租。", contract.TenantName, contract.ContractNo, endDate),
+	})
+}
+
+// startOfDay 返回 t 所在日期的零点
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// daysUntil 返回从 today 到 date 所在日期相差的自然日，已过去时为负数
+func daysUntil(today, date time.Time) int {
+	day := startOfDay(date.In(today.Location()))
+	return int(math.Round(day.Sub(today).Hours() / 24))
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestContractExpiryRun(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, roomService := newTestContractService(repos)
+	expiryService := NewContractExpiryService(repos.Contracts, repos.Notifications, contractService, testConfig())
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	a101 := mustCreateRoom(t, repos, "A101")
+	a102 := mustCreateRoom(t, repos, "A102")
+	a103 := mustCreateRoom(t, repos, "A103")
+
+	// 今天为 2026-10-17：分别剩余 80 天、20 天、已过期 2 天
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+	longLease := newLease(tenant.ID, date(2026, 1, 1), date(2027, 1, 5))
+	mustCreateActive(t, contractService, longLease, []model.ContractRoom{{RoomID: a101.ID}})
+	soonLease := newLease(tenant.ID, date(2026, 1, 1), date(2026, 11, 6))
+	mustCreateActive(t, contractService, soonLease, []model.ContractRoom{{RoomID: a102.ID}})
+	pastLease := newLease(tenant.ID, date(2025, 10, 15), date(2026, 10, 15))
+	mustCreateActive(t, contractService, pastLease, []model.ContractRoom{{RoomID: a103.ID}})
+
+	result, err := expiryService.Run(now)
+	if err != nil {
+		t.Fatalf("run expiry check: %v", err)
+	}
+	if result.Expiring != 1 || result.Expired != 1 || result.Reminders != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	statuses := map[uint]string{
+		longLease.ID: model.ContractStatusActive,
+		soonLease.ID: model.ContractStatusExpiring,
+		pastLease.ID: model.ContractStatusExpired,
+	}
+	for id, want := range statuses {
+		if got, _ := contractService.GetByID(id); got.Status != want {
+			t.Fatalf("contract %d: expected %s, got %s", id, want, got.Status)
+		}
+	}
+	if room, _ := roomService.GetByID(a102.ID); room.Status != "occupied" {
+		t.Fatalf("expiring contract must keep its room, got %s", room.Status)
+	}
+	if room, _ := roomService.GetByID(a103.ID); room.Status != "vacant" {
+		t.Fatalf("expired contract must release its room, got %s", room.Status)
+	}
+
+	history, _ := contractService.ListTransitions(pastLease.ID)
+	if last := history[len(history)-1]; last.ToStatus != model.ContractStatusExpired || last.ActorName != "system" {
+		t.Fatalf("unexpected expiry transition: %+v", last)
+	}
+
+	// 重复执行不重复变更或提醒
+	if result, err := expiryService.Run(now.Add(time.Hour)); err != nil || *result != (ExpiryResult{}) {
+		t.Fatalf("second run must be a no-op, got %+v, %v", result, err)
+	}
+
+	// 长租合同剩余 60 天时生成下一个节点的提醒
+	result, err = expiryService.Run(time.Date(2026, 11, 6, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("run expiry check: %v", err)
+	}
+	if result.Reminders != 1 {
+		t.Fatalf("expected the 60-day reminder, got %+v", result)
+	}
+
+	notifications, total, _ := repos.Notifications.List(1, 10, model.NotificationTypeContractExpiry, true)
+	if total != 3 || notifications[0].ContractID == nil || *notifications[0].ContractID != longLease.ID {
+		t.Fatalf("unexpected notifications: %+v", notifications)
+	}
+}
+
+func TestContractListExpiring(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	a101 := mustCreateRoom(t, repos, "A101")
+	a102 := mustCreateRoom(t, repos, "A102")
+
+	now := time.Date(2026, 10, 17, 18, 0, 0, 0, time.Local)
+	soon := newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 16))
+	mustCreateActive(t, contractService, soon, []model.ContractRoom{{RoomID: a101.ID}})
+	later := newLease(tenant.ID, date(2026, 1, 1), date(2027, 3, 1))
+	mustCreateActive(t, contractService, later, []model.ContractRoom{{RoomID: a102.ID}})
+
+	draft := newLease(tenant.ID, date(2026, 1, 1), date(2026, 11, 1))
+	if err := contractService.Create(draft, nil, testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+
+	contracts, err := contractService.ListExpiring(60, now)
+	if err != nil {
+		t.Fatalf("list expiring: %v", err)
+	}
+	if len(contracts) != 1 || contracts[0].ID != soon.ID || contracts[0].DaysLeft != 60 {
+		t.Fatalf("unexpected expiring contracts: %+v", contracts)
+	}
+}
+
+func TestContractExpiresOnEndDate(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	expiryService := NewContractExpiryService(repos.Contracts, repos.Notifications, contractService, testConfig())
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	a101 := mustCreateRoom(t, repos, "A101")
+
+	lease := newLease(tenant.ID, date(2025, 10, 17), date(2026, 10, 17))
+	mustCreateActive(t, contractService, lease, []model.ContractRoom{{RoomID: a101.ID}})
+
+	// 到期日前一天是租期的最后一天，合同仍有效
+	if _, err := expiryService.Run(time.Date(2026, 10, 16, 23, 0, 0, 0, time.Local)); err != nil {
+		t.Fatalf("run expiry check: %v", err)
+	}
+	if got, _ := contractService.GetByID(lease.ID); got.Status != model.ContractStatusExpiring {
+		t.Fatalf("expected expiring on the last lease day, got %s", got.Status)
+	}
+
+	// 到期日当天不再计租，到期检查同时将合同标记为 expired
+	result, err := expiryService.Run(time.Date(2026, 10, 17, 0, 30, 0, 0, time.Local))
+	if err != nil || result.Expired != 1 {
+		t.Fatalf("expected the contract to expire on its end date, got %+v, %v", result, err)
+	}
+	got, _ := contractService.GetByID(lease.ID)
+	if got.Status != model.ContractStatusExpired {
+		t.Fatalf("expected expired on the end date, got %s", got.Status)
+	}
+	for _, item := range billingItems(got, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)) {
+		if !item.ServiceEnd.Before(got.LeaseEnd()) {
+			t.Fatalf("billing must stop before the end date, got service end %v", item.ServiceEnd)
+		}
+	}
+}
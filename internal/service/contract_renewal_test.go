@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestContractRenewal(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, roomService := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	contract := newLease(tenant.ID, date(2025, 1, 1), date(2026, 1, 1))
+	contract.Escalation = model.ContractEscalation{Type: model.EscalationPercent, Value: 5}
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: room.ID}})
+
+	successor, err := contractService.Renew(contract, RenewalOptions{}, testActor)
+	if err != nil {
+		t.Fatalf("renew contract: %v", err)
+	}
+	if successor.Status != model.ContractStatusDraft || successor.PredecessorID == nil || *successor.PredecessorID != contract.ID {
+		t.Fatalf("unexpected successor: %+v", successor)
+	}
+	if !successor.StartDate.Equal(*date(2026, 1, 1)) || !successor.EndDate.Equal(*date(2027, 1, 1)) {
+		t.Fatalf("successor must continue the previous term, got %s - %s", successor.StartDate, successor.EndDate)
+	}
+	if len(successor.Rooms) != 1 || successor.Rooms[0].MonthlyRent != 3150 || successor.Amount != 37800 {
+		t.Fatalf("expected rent escalated by 5%%, got rooms %+v amount %.2f", successor.Rooms, successor.Amount)
+	}
+	if successor.Escalation.Type != model.EscalationPercent || successor.Escalation.Timing != model.EscalationOnRenewal {
+		t.Fatalf("successor must inherit the escalation clause, got %+v", successor.Escalation)
+	}
+
+	if _, err := contractService.Renew(contract, RenewalOptions{}, testActor); !errors.Is(err, ErrContractRenewed) {
+		t.Fatalf("expected ErrContractRenewed for a second renewal, got %v", err)
+	}
+	if got, _ := contractService.GetByID(contract.ID); got.Status != model.ContractStatusActive {
+		t.Fatalf("predecessor must stay active until the renewal takes effect, got %s", got.Status)
+	}
+
+	if err := activate(contractService, successor); err != nil {
+		t.Fatalf("activate successor: %v", err)
+	}
+	predecessor, _ := contractService.GetByID(contract.ID)
+	if predecessor.Status != model.ContractStatusRenewed {
+		t.Fatalf("predecessor must become renewed, got %s", predecessor.Status)
+	}
+	if r, _ := roomService.GetByID(room.ID); r.Status != "occupied" || r.TenantID == nil || *r.TenantID != tenant.ID {
+		t.Fatalf("room must stay occupied across the renewal: %+v", r)
+	}
+
+	if len(predecessor.RenewalChain) != 2 || predecessor.RenewalChain[0].ID != contract.ID || predecessor.RenewalChain[1].ID != successor.ID {
+		t.Fatalf("unexpected renewal chain: %+v", predecessor.RenewalChain)
+	}
+	if predecessor.RenewalChain[1].MonthlyRent != 3150 {
+		t.Fatalf("chain must show the successor rent, got %+v", predecessor.RenewalChain[1])
+	}
+
+	adjustments, err := contractService.ListRentAdjustments(successor.ID)
+	if err != nil || len(adjustments) != 1 || adjustments[0].OldRent != 3000 || adjustments[0].NewRent != 3150 {
+		t.Fatalf("unexpected rent adjustments: %+v, %v", adjustments, err)
+	}
+
+	if _, err := contractService.Renew(predecessor, RenewalOptions{}, testActor); !errors.Is(err, ErrInvalidContractTransition) {
+		t.Fatalf("renewed contract must not be renewed again, got %v", err)
+	}
+}
+
+func TestContractRenewalCPI(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	contract := newLease(tenant.ID, date(2025, 1, 1), date(2026, 1, 1))
+	contract.Escalation = model.ContractEscalation{Type: model.EscalationCPI, Value: 0.5}
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: room.ID}})
+
+	if _, err := contractService.Renew(contract, RenewalOptions{}, testActor); !errors.Is(err, ErrCPIIndexMissing) {
+		t.Fatalf("expected ErrCPIIndexMissing, got %v", err)
+	}
+
+	if _, err := contractService.SaveCPIIndex(2025, 2); err != nil {
+		t.Fatalf("save cpi index: %v", err)
+	}
+	amount := 40000.0
+	successor, err := contractService.Renew(contract, RenewalOptions{
+		EndDate: date(2026, 7, 1),
+		Amount:  &amount,
+		Status:  model.ContractStatusPendingApproval,
+	}, testActor)
+	if err != nil {
+		t.Fatalf("renew contract: %v", err)
+	}
+	if successor.Rooms[0].MonthlyRent != 3075 || successor.Amount != 40000 || successor.Status != model.ContractStatusPendingApproval {
+		t.Fatalf("unexpected successor: rooms %+v amount %.2f status %s", successor.Rooms, successor.Amount, successor.Status)
+	}
+
+	if _, err := contractService.Renew(successor, RenewalOptions{EndDate: date(2025, 12, 1)}, testActor); !errors.Is(err, ErrInvalidContractTransition) {
+		t.Fatalf("pending contract must not be renewed, got %v", err)
+	}
+}
+
+func TestContractAnniversaryEscalation(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	contract := newLease(tenant.ID, date(2024, 3, 1), date(2027, 3, 1))
+	contract.Escalation = model.ContractEscalation{Type: model.EscalationAmount, Value: 100, Timing: model.EscalationOnAnniversary}
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: room.ID}})
+
+	count, err := contractService.ApplyAnniversaryEscalations(*date(2026, 4, 1))
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 contract escalated, got %d, %v", count, err)
+	}
+	got, _ := contractService.GetByID(contract.ID)
+	if got.Rooms[0].MonthlyRent != 3200 || got.Escalation.AppliedYears != 2 {
+		t.Fatalf("expected two anniversaries applied, got rent %.2f applied %d", got.Rooms[0].MonthlyRent, got.Escalation.AppliedYears)
+	}
+
+	if count, err := contractService.ApplyAnniversaryEscalations(*date(2026, 5, 1)); err != nil || count != 0 {
+		t.Fatalf("escalation must not repeat, got %d, %v", count, err)
+	}
+
+	adjustments, _ := contractService.ListRentAdjustments(contract.ID)
+	if len(adjustments) != 2 || !adjustments[0].EffectiveDate.Equal(*date(2025, 3, 1)) || adjustments[1].NewRent != 3200 {
+		t.Fatalf("unexpected rent adjustments: %+v", adjustments)
+	}
+}
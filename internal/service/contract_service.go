@@ -1,48 +1,738 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"go.uber.org/zap"
+
 	"yuxialuozi_graduation_design_backend/internal/model"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 )
 
+var (
+	ErrRoomNotFound          = errors.New("房间不存在")
+	ErrContractRoomDuplicate = errors.New("合同中存在重复的房间")
+	ErrContractNoRooms       = errors.New("合同未指定租赁房间，无法生效")
+	ErrRoomLeaseOverlap      = errors.New("房间在该租期内已有生效合同")
+	ErrContractActive        = errors.New("生效中的合同无法删除，请先结束合同")
+	ErrContractPeriod        = errors.New("合同结束日期须晚于开始日期")
+	ErrContractRenewed       = errors.New("合同已有未终止的续签合同")
+
+	ErrInvalidContractTransition = errors.New("合同当前状态不允许该操作")
+	ErrContractStatusReadOnly    = fmt.Errorf("%w，合同状态需通过 submit、activate、terminate、renew 接口变更", ErrInvalidContractTransition)
+)
+
+// ContractTransitionError 非法的合同状态变更，Allowed 为当前状态允许变更到的状态
+type ContractTransitionError struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Allowed []string `json:"allowed"`
+}
+
+func (e *ContractTransitionError) Error() string {
+	if e.From == "" {
+		return fmt.Sprintf("合同不能以 %s 状态创建", e.To)
+	}
+	return fmt.Sprintf("合同状态不能从 %s 变更为 %s", e.From, e.To)
+}
+
+func (e *ContractTransitionError) Unwrap() error {
+	return ErrInvalidContractTransition
+}
+
+// ExpiringContract 即将到期的合同，DaysLeft 为距到期日的自然日数
+type ExpiringContract struct {
+	model.Contract
+	DaysLeft int `json:"daysLeft"`
+}
+
+// Actor 执行操作的用户，记录在合同状态变更历史与服务层写入的审计日志中。
+// IP、RequestID、APIKeyID 取自发起操作的请求，后台任务为空
+type Actor struct {
+	ID        uint
+	Name      string
+	IP        string
+	RequestID string
+	APIKeyID  *uint
+}
+
+// RenewalOptions 续签参数，为空的字段沿用前序合同：开始日期默认为前序合同到期日，
+// 租期默认与前序合同相同，总额默认按递增后的月租金乘以租期月数计算
+type RenewalOptions struct {
+	StartDate  *time.Time
+	EndDate    *time.Time
+	Amount     *float64
+	Escalation *model.ContractEscalation
+	Status     string
+	Reason     string
+}
+
 type ContractService struct {
-	contractRepo *repository.ContractRepository
-	tenantRepo   *repository.TenantRepository
+	contractRepo repository.ContractRepository
+	tenantRepo   repository.TenantRepository
+	cpiRepo      repository.CPIIndexRepository
+	roomService  *RoomService
+	uow          repository.UnitOfWork
 }
 
-func NewContractService(contractRepo *repository.ContractRepository, tenantRepo *repository.TenantRepository) *ContractService {
+func NewContractService(
+	contractRepo repository.ContractRepository,
+	tenantRepo repository.TenantRepository,
+	cpiRepo repository.CPIIndexRepository,
+	roomService *RoomService,
+	uow repository.UnitOfWork,
+) *ContractService {
 	return &ContractService{
 		contractRepo: contractRepo,
 		tenantRepo:   tenantRepo,
+		cpiRepo:      cpiRepo,
+		roomService:  roomService,
+		uow:          uow,
 	}
 }
 
-func (s *ContractService) Create(contract *model.Contract) error {
+// Create 以草稿或待审批状态创建合同，保存租赁房间并记录初始状态与审计
+func (s *ContractService) Create(contract *model.Contract, rooms []model.ContractRoom, actor Actor) error {
+	if contract.Status == "" {
+		contract.Status = model.ContractStatusDraft
+	}
+	if !model.CanTransitionContract("", contract.Status) {
+		return newContractTransitionError("", contract.Status)
+	}
+	if err := validateEscalation(&contract.Escalation); err != nil {
+		return err
+	}
+	contract.Escalation.AppliedYears = 0
+	if err := validateBilling(&contract.Billing); err != nil {
+		return err
+	}
 	if contract.ContractNo == "" {
 		contract.ContractNo = s.generateContractNo()
 	}
-	return s.contractRepo.Create(contract)
+
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if err := tx.Contracts.Create(contract); err != nil {
+			return err
+		}
+		if err := s.saveRooms(tx, contract, rooms); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityContract, contract.ID, model.AuditActionCreate, nil, contract); err != nil {
+			return err
+		}
+		return recordTransition(tx, contract.ID, "", contract.Status, "创建合同", actor)
+	})
 }
 
+// GetByID 返回合同详情，存在续签关系时填充续签链
 func (s *ContractService) GetByID(id uint) (*model.Contract, error) {
-	return s.contractRepo.FindByID(id)
+	contract, err := s.contractRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := s.renewalChain(contract)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) > 1 {
+		contract.RenewalChain = chain
+	}
+	return contract, nil
 }
 
-func (s *ContractService) Update(contract *model.Contract) error {
-	return s.contractRepo.Update(contract)
+// renewalChain 沿前序合同向前、沿未终止的续签合同向后查找，返回从最早到最新的续签链
+func (s *ContractService) renewalChain(contract *model.Contract) ([]model.ContractChainItem, error) {
+	chain := []model.ContractChainItem{chainItem(contract)}
+	seen := map[uint]bool{contract.ID: true}
+
+	for id := contract.PredecessorID; id != nil && !seen[*id]; {
+		predecessor, err := s.contractRepo.FindByID(*id)
+		if err != nil {
+			// 前序合同已移入回收站
+			break
+		}
+		seen[predecessor.ID] = true
+		chain = append([]model.ContractChainItem{chainItem(predecessor)}, chain...)
+		id = predecessor.PredecessorID
+	}
+
+	for current := contract.ID; ; {
+		successors, err := s.contractRepo.FindSuccessors(current)
+		if err != nil {
+			return nil, err
+		}
+		next := latestSuccessor(successors)
+		if next == nil || seen[next.ID] {
+			break
+		}
+		seen[next.ID] = true
+		chain = append(chain, chainItem(next))
+		current = next.ID
+	}
+	return chain, nil
 }
 
-func (s *ContractService) Delete(id uint) error {
-	return s.contractRepo.Delete(id)
+// latestSuccessor 优先返回未终止的续签合同，全部终止时返回最新的一份
+func latestSuccessor(successors []model.Contract) *model.Contract {
+	for i := len(successors) - 1; i >= 0; i-- {
+		if successors[i].Status != model.ContractStatusTerminated {
+			return &successors[i]
+		}
+	}
+	if len(successors) > 0 {
+		return &successors[len(successors)-1]
+	}
+	return nil
+}
+
+func chainItem(c *model.Contract) model.ContractChainItem {
+	return model.ContractChainItem{
+		ID:          c.ID,
+		ContractNo:  c.ContractNo,
+		StartDate:   c.StartDate,
+		EndDate:     c.EndDate,
+		Status:      c.Status,
+		MonthlyRent: roundMoney(c.MonthlyRent()),
+	}
+}
+
+// Update 保存合同信息，rooms 为 nil 时保持原有租赁房间；状态不能通过 Update 修改。
+// 生效中的合同调整房间时，新增房间被占用，移除的房间被释放。修改前后的合同记入审计
+func (s *ContractService) Update(contract *model.Contract, rooms []model.ContractRoom, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Contracts.FindByIDForUpdate(contract.ID)
+		if err != nil {
+			return err
+		}
+		if contract.Status != current.Status {
+			return ErrContractStatusReadOnly
+		}
+		if err := validateEscalation(&contract.Escalation); err != nil {
+			return err
+		}
+		contract.Escalation.AppliedYears = current.Escalation.AppliedYears
+		if err := validateBilling(&contract.Billing); err != nil {
+			return err
+		}
+		contract.PredecessorID = current.PredecessorID
+
+		if rooms != nil {
+			if err := s.saveRooms(tx, contract, rooms); err != nil {
+				return err
+			}
+		} else {
+			contract.Rooms = current.Rooms
+		}
+
+		if err := tx.Contracts.Update(contract); err != nil {
+			return err
+		}
+		if err := s.syncRooms(tx, current, contract); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityContract, contract.ID, model.AuditActionUpdate, current, contract)
+	})
+}
+
+// Transition 按生命周期变更合同状态并记录操作人、原因与审计。
+// contract 为调用方读取的合同，版本已变化时返回 ErrVersionConflict；成功后 contract 更新为最新数据。
+func (s *ContractService) Transition(contract *model.Contract, to, reason string, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Contracts.FindByIDForUpdate(contract.ID)
+		if err != nil {
+			return err
+		}
+		if current.Version != contract.Version {
+			return ErrVersionConflict
+		}
+		if !model.CanTransitionContract(current.Status, to) {
+			return newContractTransitionError(current.Status, to)
+		}
+
+		updated := *current
+		updated.Status = to
+		if to == model.ContractStatusTerminated && model.ContractOccupiesRooms(current.Status) {
+			now := time.Now()
+			updated.TerminatedAt = &now
+		}
+		if err := tx.Contracts.Update(&updated); err != nil {
+			return err
+		}
+		// 续签合同生效时前序合同变为 renewed，先释放其房间再由续签合同占用
+		if to == model.ContractStatusActive && updated.PredecessorID != nil {
+			if err := s.completeRenewal(tx, &updated, actor); err != nil {
+				return err
+			}
+		}
+		if err := s.syncRooms(tx, current, &updated); err != nil {
+			return err
+		}
+		if err := recordTransition(tx, updated.ID, current.Status, to, reason, actor); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityContract, updated.ID, model.AuditActionTransition, current, &updated); err != nil {
+			return err
+		}
+
+		*contract = updated
+		return nil
+	})
+}
+
+// completeRenewal 将续签合同的前序合同变更为 renewed；前序合同已不存在或已处于终态时忽略
+func (s *ContractService) completeRenewal(tx *repository.Tx, successor *model.Contract, actor Actor) error {
+	predecessor, err := tx.Contracts.FindByIDForUpdate(*successor.PredecessorID)
+	if err != nil || !model.CanTransitionContract(predecessor.Status, model.ContractStatusRenewed) {
+		return nil
+	}
+
+	renewed := *predecessor
+	renewed.Status = model.ContractStatusRenewed
+	if err := tx.Contracts.Update(&renewed); err != nil {
+		return err
+	}
+	if err := s.syncRooms(tx, predecessor, &renewed); err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("续签合同 %s 生效", successor.ContractNo)
+	if err := recordTransition(tx, renewed.ID, predecessor.Status, renewed.Status, reason, actor); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, model.AuditEntityContract, renewed.ID, model.AuditActionTransition, predecessor, &renewed)
+}
+
+// Renew 为合同创建续签合同。前序合同须处于可续签状态（active、expiring、expired）且没有未终止的续签合同；
+// 前序合同约定了租金递增时，续签合同的各房间月租金按其条款在续签开始日递增。
+// 续签合同以草稿或待审批状态创建，生效时前序合同才变为 renewed。
+func (s *ContractService) Renew(predecessor *model.Contract, opts RenewalOptions, actor Actor) (*model.Contract, error) {
+	var successor *model.Contract
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Contracts.FindByIDForUpdate(predecessor.ID)
+		if err != nil {
+			return err
+		}
+		if current.Version != predecessor.Version {
+			return ErrVersionConflict
+		}
+		if !model.CanTransitionContract(current.Status, model.ContractStatusRenewed) {
+			return newContractTransitionError(current.Status, model.ContractStatusRenewed)
+		}
+
+		successors, err := tx.Contracts.FindSuccessors(current.ID)
+		if err != nil {
+			return err
+		}
+		for _, c := range successors {
+			if c.Status != model.ContractStatusTerminated {
+				return fmt.Errorf("%w（%s）", ErrContractRenewed, c.ContractNo)
+			}
+		}
+
+		successor, err = s.buildRenewal(current, opts)
+		if err != nil {
+			return err
+		}
+		if err := tx.Contracts.Create(successor); err != nil {
+			return err
+		}
+		rooms := successor.Rooms
+		if err := s.saveRooms(tx, successor, rooms); err != nil {
+			return err
+		}
+		if err := recordRentAdjustments(tx, successor.ID, current.Rooms, rooms, successor.StartDate, "续签租金递增"); err != nil {
+			return err
+		}
+
+		reason := opts.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("续签合同 %s", current.ContractNo)
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityContract, successor.ID, model.AuditActionCreate, nil, successor); err != nil {
+			return err
+		}
+		return recordTransition(tx, successor.ID, "", successor.Status, reason, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return successor, nil
+}
+
+// buildRenewal 按前序合同与续签参数生成续签合同，租赁房间的月租金已按前序合同的条款递增
+func (s *ContractService) buildRenewal(current *model.Contract, opts RenewalOptions) (*model.Contract, error) {
+	start := current.EndDate
+	if opts.StartDate != nil {
+		start = *opts.StartDate
+	}
+	end := start.Add(current.EndDate.Sub(current.StartDate))
+	if months := monthsBetween(current.StartDate, current.EndDate); months > 0 {
+		end = start.AddDate(0, months, 0)
+	}
+	if opts.EndDate != nil {
+		end = *opts.EndDate
+	}
+	if !end.After(start) {
+		return nil, ErrContractPeriod
+	}
+
+	status := opts.Status
+	if status == "" {
+		status = model.ContractStatusDraft
+	}
+	if !model.CanTransitionContract("", status) {
+		return nil, newContractTransitionError("", status)
+	}
+
+	escalation := current.Escalation
+	if opts.Escalation != nil {
+		escalation = *opts.Escalation
+	}
+	escalation.AppliedYears = 0
+	if err := validateEscalation(&escalation); err != nil {
+		return nil, err
+	}
+
+	rooms := current.Rooms
+	if current.Escalation.Enabled() {
+		var err error
+		if rooms, err = escalateRooms(s.cpiRepo, current.Rooms, current.Escalation, start); err != nil {
+			return nil, err
+		}
+	}
+
+	successor := &model.Contract{
+		TenantID:      current.TenantID,
+		ContractNo:    s.generateContractNo(),
+		StartDate:     start,
+		EndDate:       end,
+		Status:        status,
+		Rooms:         rooms,
+		Escalation:    escalation,
+		Billing:       current.Billing,
+		PredecessorID: &current.ID,
+	}
+	if opts.Amount != nil {
+		successor.Amount = *opts.Amount
+	} else if months := monthsBetween(start, end); months > 0 {
+		successor.Amount = roundMoney(successor.MonthlyRent() * float64(months))
+	} else {
+		successor.Amount = current.Amount
+	}
+	return successor, nil
+}
+
+// ApplyAnniversaryEscalations 为约定周年递增的在租合同补齐已到达的周年日租金递增，返回调整的合同数。
+// 已执行的次数记录在 Escalation.AppliedYears 中，重复执行不会重复递增；单个合同失败只记录日志
+func (s *ContractService) ApplyAnniversaryEscalations(now time.Time) (int, error) {
+	contracts, err := s.contractRepo.FindByEscalationTiming(model.EscalationOnAnniversary, model.OccupyingContractStatuses...)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range contracts {
+		if anniversariesDue(&contracts[i], now) <= contracts[i].Escalation.AppliedYears {
+			continue
+		}
+		if err := s.applyAnniversaries(contracts[i].ID, now); err != nil {
+			zap.L().Warn("rent escalation failed",
+				zap.Uint("contract_id", contracts[i].ID),
+				zap.String("contract_no", contracts[i].ContractNo),
+				zap.Error(err))
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *ContractService) applyAnniversaries(id uint, now time.Time) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		contract, err := tx.Contracts.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		due := anniversariesDue(contract, now)
+		if due <= contract.Escalation.AppliedYears {
+			return nil
+		}
+
+		rooms := contract.Rooms
+		for year := contract.Escalation.AppliedYears + 1; year <= due; year++ {
+			at := contract.StartDate.AddDate(year, 0, 0)
+			escalated, err := escalateRooms(s.cpiRepo, rooms, contract.Escalation, at)
+			if err != nil {
+				return err
+			}
+			if err := recordRentAdjustments(tx, contract.ID, rooms, escalated, at, fmt.Sprintf("第 %d 个周年日租金递增", year)); err != nil {
+				return err
+			}
+			rooms = escalated
+		}
+
+		if err := tx.Contracts.ReplaceRooms(contract.ID, rooms); err != nil {
+			return err
+		}
+		contract.Rooms = rooms
+		contract.Escalation.AppliedYears = due
+		return tx.Contracts.Update(contract)
+	})
+}
+
+// ListRentAdjustments 返回合同的租金调整记录
+func (s *ContractService) ListRentAdjustments(id uint) ([]model.RentAdjustment, error) {
+	return s.contractRepo.FindRentAdjustments(id)
+}
+
+// ListCPIIndices 返回本地维护的 CPI 年度涨幅，按年份升序
+func (s *ContractService) ListCPIIndices() ([]model.CPIIndex, error) {
+	return s.cpiRepo.List()
+}
+
+// SaveCPIIndex 新增或覆盖指定年份的 CPI 涨幅
+func (s *ContractService) SaveCPIIndex(year int, rate float64) (*model.CPIIndex, error) {
+	index := &model.CPIIndex{Year: year, Rate: rate}
+	if err := s.cpiRepo.Save(index); err != nil {
+		return nil, err
+	}
+	return s.cpiRepo.FindByYear(year)
+}
+
+func (s *ContractService) GetCPIIndex(year int) (*model.CPIIndex, error) {
+	return s.cpiRepo.FindByYear(year)
+}
+
+func (s *ContractService) DeleteCPIIndex(year int) error {
+	return s.cpiRepo.Delete(year)
+}
+
+// ListTransitions 返回合同的状态变更历史
+func (s *ContractService) ListTransitions(id uint) ([]model.ContractTransition, error) {
+	return s.contractRepo.FindTransitions(id)
+}
+
+// Delete 删除合同并记录审计，生效中的合同须先结束以释放房间
+func (s *ContractService) Delete(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		contract, err := tx.Contracts.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if model.ContractOccupiesRooms(contract.Status) {
+			return ErrContractActive
+		}
+		if err := tx.Contracts.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityContract, id, model.AuditActionDelete, contract, nil)
+	})
+}
+
+func newContractTransitionError(from, to string) *ContractTransitionError {
+	allowed := model.ContractTransitions[from]
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return &ContractTransitionError{From: from, To: to, Allowed: allowed}
+}
+
+func recordTransition(tx *repository.Tx, contractID uint, from, to, reason string, actor Actor) error {
+	return tx.Contracts.CreateTransition(&model.ContractTransition{
+		ContractID: contractID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+	})
+}
+
+// syncRooms 根据合同变更前后的状态与房间同步房间占用：
+// 离开生效状态或移除的房间被释放，处于生效状态时占用全部租赁房间
+func (s *ContractService) syncRooms(tx *repository.Tx, before, after *model.Contract) error {
+	occupying := model.ContractOccupiesRooms(after.Status)
+	if model.ContractOccupiesRooms(before.Status) {
+		for _, room := range before.Rooms {
+			if occupying && after.TenantID == before.TenantID && after.HasRoom(room.RoomID) {
+				continue
+			}
+			if err := s.releaseRoom(tx, before, room.RoomID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if occupying {
+		return s.occupyRooms(tx, after)
+	}
+	return nil
+}
+
+// saveRooms 校验并保存合同的租赁房间，未约定月租金的房间按房间当前月租金计
+func (s *ContractService) saveRooms(tx *repository.Tx, contract *model.Contract, rooms []model.ContractRoom) error {
+	seen := make(map[uint]bool, len(rooms))
+	for i := range rooms {
+		if seen[rooms[i].RoomID] {
+			return ErrContractRoomDuplicate
+		}
+		seen[rooms[i].RoomID] = true
+
+		room, err := tx.Rooms.FindByID(rooms[i].RoomID)
+		if err != nil {
+			return fmt.Errorf("%w（ID %d）", ErrRoomNotFound, rooms[i].RoomID)
+		}
+		if rooms[i].MonthlyRent == 0 {
+			rooms[i].MonthlyRent = room.MonthlyRent
+		}
+		rooms[i].RoomNo = room.RoomNo
+	}
+
+	if err := tx.Contracts.ReplaceRooms(contract.ID, rooms); err != nil {
+		return err
+	}
+	contract.Rooms = rooms
+	return nil
+}
+
+// occupyRooms 按房间 ID 顺序锁定房间行，确认租期不与该房间的其他生效合同重叠后将房间分配给合同租户。
+// 锁定房间行使同一房间的并发生效串行执行，重叠检查不会同时通过。
+func (s *ContractService) occupyRooms(tx *repository.Tx, contract *model.Contract) error {
+	if len(contract.Rooms) == 0 {
+		return ErrContractNoRooms
+	}
+
+	roomIDs := make([]uint, 0, len(contract.Rooms))
+	for _, room := range contract.Rooms {
+		roomIDs = append(roomIDs, room.RoomID)
+	}
+	sort.Slice(roomIDs, func(i, j int) bool { return roomIDs[i] < roomIDs[j] })
+
+	for _, roomID := range roomIDs {
+		room, err := tx.Rooms.FindByIDForUpdate(roomID)
+		if err != nil {
+			return fmt.Errorf("%w（ID %d）", ErrRoomNotFound, roomID)
+		}
+
+		leases, err := tx.Contracts.FindByRoom(roomID, model.OccupyingContractStatuses...)
+		if err != nil {
+			return err
+		}
+		for i := range leases {
+			if leases[i].ID != contract.ID && leases[i].Overlaps(contract) {
+				return fmt.Errorf("%w（房间 %s，合同 %s）", ErrRoomLeaseOverlap, room.RoomNo, leases[i].ContractNo)
+			}
+		}
+
+		if err := s.roomService.assignTenant(tx, roomID, contract.TenantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseRoom 合同结束后通过 RoomService 释放房间。房间已分配给其他租户，
+// 或仍被其他生效合同租赁时保持不变
+func (s *ContractService) releaseRoom(tx *repository.Tx, contract *model.Contract, roomID uint) error {
+	room, err := tx.Rooms.FindByIDForUpdate(roomID)
+	if err != nil {
+		// 房间已删除，无需释放
+		return nil
+	}
+	if room.TenantID == nil || *room.TenantID != contract.TenantID {
+		return nil
+	}
+
+	leases, err := tx.Contracts.FindByRoom(roomID, model.OccupyingContractStatuses...)
+	if err != nil {
+		return err
+	}
+	for _, lease := range leases {
+		if lease.ID != contract.ID {
+			return nil
+		}
+	}
+
+	return s.roomService.releaseTenant(tx, roomID)
 }
 
 func (s *ContractService) List(page, pageSize int, keyword, status string, startDateFrom, startDateTo *time.Time) ([]model.Contract, int64, error) {
 	return s.contractRepo.List(page, pageSize, keyword, status, startDateFrom, startDateTo)
 }
 
+// ListExpiring 返回 within 天内到期、仍占用房间的合同，按到期日升序；
+// 已过到期日但尚未被后台任务处理的合同同样返回，DaysLeft 为负数
+func (s *ContractService) ListExpiring(within int, now time.Time) ([]ExpiringContract, error) {
+	today := startOfDay(now)
+	contracts, err := s.contractRepo.FindEndingBefore(today.AddDate(0, 0, within+1), model.OccupyingContractStatuses...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ExpiringContract, 0, len(contracts))
+	for _, contract := range contracts {
+		result = append(result, ExpiringContract{Contract: contract, DaysLeft: daysUntil(today, contract.EndDate)})
+	}
+	return result, nil
+}
+
+func (s *ContractService) ListByTenant(tenantID uint) ([]model.Contract, error) {
+	return s.contractRepo.FindByTenantID(tenantID)
+}
+
 func (s *ContractService) generateContractNo() string {
 	return fmt.Sprintf("HT%s%04d", time.Now().Format("20060102"), time.Now().UnixNano()%10000)
 }
+
+func (s *ContractService) ListTrash(page, pageSize int) ([]model.Contract, int64, error) {
+	return s.contractRepo.ListTrashed(page, pageSize)
+}
+
+func (s *ContractService) GetTrashedByID(id uint) (*model.Contract, error) {
+	return s.contractRepo.FindTrashedByID(id)
+}
+
+// Restore 从回收站恢复合同并返回包含续签链的合同，所属租户已删除时返回 ErrTenantDeleted
+func (s *ContractService) Restore(id uint, actor Actor) (*model.Contract, error) {
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		before, err := tx.Contracts.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Tenants.FindByID(before.TenantID); err != nil {
+			return ErrTenantDeleted
+		}
+
+		if err := tx.Contracts.Restore(id); err != nil {
+			return err
+		}
+		contract, err := tx.Contracts.FindByID(id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityContract, id, model.AuditActionRestore, before, contract)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Purge 永久删除回收站中的合同并记录审计
+func (s *ContractService) Purge(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		contract, err := tx.Contracts.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Contracts.Purge(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityContract, id, model.AuditActionPurge, contract, nil)
+	})
+}
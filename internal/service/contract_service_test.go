@@ -0,0 +1,269 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+)
+
+var testActor = Actor{ID: 1, Name: "admin"}
+
+func newTestContractService(repos *storage.Repositories) (*ContractService, *RoomService) {
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	return NewContractService(repos.Contracts, repos.Tenants, repos.CPIIndices, roomService, repos.UnitOfWork), roomService
+}
+
+func newLease(tenantID uint, start, end *time.Time) *model.Contract {
+	return &model.Contract{TenantID: tenantID, StartDate: *start, EndDate: *end, Amount: 36000}
+}
+
+// mustCreateActive 创建合同并经审批流程生效
+func mustCreateActive(t *testing.T, s *ContractService, contract *model.Contract, rooms []model.ContractRoom) {
+	t.Helper()
+	if err := s.Create(contract, rooms, testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+	if err := activate(s, contract); err != nil {
+		t.Fatalf("activate contract: %v", err)
+	}
+}
+
+func activate(s *ContractService, contract *model.Contract) error {
+	if err := s.Transition(contract, model.ContractStatusPendingApproval, "", testActor); err != nil {
+		return err
+	}
+	return s.Transition(contract, model.ContractStatusActive, "审批通过", testActor)
+}
+
+func TestContractActivationOccupiesRooms(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, roomService := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	a101 := mustCreateRoom(t, repos, "A101")
+	a102 := mustCreateRoom(t, repos, "A102")
+
+	contract := newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 31))
+	rooms := []model.ContractRoom{{RoomID: a101.ID}, {RoomID: a102.ID, MonthlyRent: 2500}}
+	if err := contractService.Create(contract, rooms, testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+
+	got, _ := contractService.GetByID(contract.ID)
+	if got.Status != model.ContractStatusDraft {
+		t.Fatalf("expected draft status, got %s", got.Status)
+	}
+	if len(got.Rooms) != 2 || got.Rooms[0].RoomNo != "A101" || got.Rooms[0].MonthlyRent != 3000 || got.Rooms[1].MonthlyRent != 2500 {
+		t.Fatalf("unexpected contract rooms: %+v", got.Rooms)
+	}
+	if room, _ := roomService.GetByID(a101.ID); room.Status != "vacant" {
+		t.Fatalf("draft contract must not occupy rooms, got %s", room.Status)
+	}
+
+	if err := activate(contractService, got); err != nil {
+		t.Fatalf("activate contract: %v", err)
+	}
+	for _, id := range []uint{a101.ID, a102.ID} {
+		room, _ := roomService.GetByID(id)
+		if room.Status != "occupied" || room.TenantID == nil || *room.TenantID != tenant.ID {
+			t.Fatalf("room %s not occupied after activation: %+v", room.RoomNo, room)
+		}
+	}
+
+	if err := contractService.Delete(got.ID, testActor); !errors.Is(err, ErrContractActive) {
+		t.Fatalf("expected ErrContractActive, got %v", err)
+	}
+	if err := roomService.Delete(a101.ID, testActor); !errors.Is(err, ErrRoomUnderLease) {
+		t.Fatalf("expected ErrRoomUnderLease, got %v", err)
+	}
+
+	if err := contractService.Transition(got, model.ContractStatusTerminated, "提前退租", testActor); err != nil {
+		t.Fatalf("terminate contract: %v", err)
+	}
+	for _, id := range []uint{a101.ID, a102.ID} {
+		room, _ := roomService.GetByID(id)
+		if room.Status != "vacant" || room.TenantID != nil {
+			t.Fatalf("room %s not released after termination: %+v", room.RoomNo, room)
+		}
+	}
+
+	if err := contractService.Delete(got.ID, testActor); err != nil {
+		t.Fatalf("delete contract: %v", err)
+	}
+	if restored, err := contractService.Restore(got.ID, testActor); err != nil || restored.Status != model.ContractStatusTerminated {
+		t.Fatalf("restore contract: %+v, %v", restored, err)
+	}
+
+	// 新建、状态变更、删除与恢复的审计都在合同服务的事务中写入，被拒绝的删除不留下记录
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityContract, got.ID)
+	var actions []string
+	for _, a := range audits {
+		actions = append(actions, a.Action)
+	}
+	n := len(actions)
+	if n < 4 || actions[0] != model.AuditActionCreate || actions[n-3] != model.AuditActionTransition ||
+		actions[n-2] != model.AuditActionDelete || actions[n-1] != model.AuditActionRestore {
+		t.Fatalf("unexpected contract audit trail: %v", actions)
+	}
+	for _, action := range actions[1 : n-2] {
+		if action != model.AuditActionTransition {
+			t.Fatalf("unexpected contract audit trail: %v", actions)
+		}
+	}
+}
+
+func TestContractRemovingRoomReleasesIt(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, roomService := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	a101 := mustCreateRoom(t, repos, "A101")
+	a102 := mustCreateRoom(t, repos, "A102")
+
+	contract := newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 31))
+	mustCreateActive(t, contractService, contract, []model.ContractRoom{{RoomID: a101.ID}, {RoomID: a102.ID}})
+
+	if err := contractService.Update(contract, []model.ContractRoom{{RoomID: a101.ID}}, testActor); err != nil {
+		t.Fatalf("remove room: %v", err)
+	}
+	if room, _ := roomService.GetByID(a101.ID); room.Status != "occupied" {
+		t.Fatalf("kept room must stay occupied, got %s", room.Status)
+	}
+	if room, _ := roomService.GetByID(a102.ID); room.Status != "vacant" {
+		t.Fatalf("removed room must be released, got %s", room.Status)
+	}
+}
+
+func TestContractLeaseOverlap(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, roomService := newTestContractService(repos)
+	first := mustCreateTenant(t, repos, "租户甲")
+	second := mustCreateTenant(t, repos, "租户乙")
+	room := mustCreateRoom(t, repos, "A101")
+	leased := func() []model.ContractRoom { return []model.ContractRoom{{RoomID: room.ID}} }
+
+	current := newLease(first.ID, date(2026, 1, 1), date(2027, 1, 1))
+	mustCreateActive(t, contractService, current, leased())
+
+	overlapping := newLease(first.ID, date(2026, 6, 1), date(2027, 6, 1))
+	if err := contractService.Create(overlapping, leased(), testActor); err != nil {
+		t.Fatalf("draft contracts may overlap: %v", err)
+	}
+	if err := activate(contractService, overlapping); !errors.Is(err, ErrRoomLeaseOverlap) {
+		t.Fatalf("expected ErrRoomLeaseOverlap, got %v", err)
+	}
+
+	// 失败的生效整体回滚
+	if got, _ := contractService.GetByID(overlapping.ID); got.Status != model.ContractStatusPendingApproval {
+		t.Fatalf("failed activation must be rolled back, got %s", got.Status)
+	}
+
+	// 紧接上一份合同的续租不算重叠
+	successor := newLease(first.ID, date(2027, 1, 1), date(2028, 1, 1))
+	mustCreateActive(t, contractService, successor, leased())
+
+	// 前一份合同续签后房间仍被续租合同占用
+	if err := contractService.Transition(current, model.ContractStatusRenewed, "", testActor); err != nil {
+		t.Fatalf("renew contract: %v", err)
+	}
+	if got, _ := roomService.GetByID(room.ID); got.Status != "occupied" || *got.TenantID != first.ID {
+		t.Fatalf("room must remain occupied by successor contract: %+v", got)
+	}
+
+	other := newLease(second.ID, date(2030, 1, 1), date(2031, 1, 1))
+	if err := contractService.Create(other, leased(), testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+	if err := activate(contractService, other); !errors.Is(err, ErrRoomOccupied) {
+		t.Fatalf("expected ErrRoomOccupied for another tenant, got %v", err)
+	}
+}
+
+func TestContractActivationRequiresRooms(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	if err := contractService.Create(newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 31)), []model.ContractRoom{{RoomID: 42}}, testActor); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("expected ErrRoomNotFound, got %v", err)
+	}
+
+	contract := newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 31))
+	if err := contractService.Create(contract, nil, testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+	if err := activate(contractService, contract); !errors.Is(err, ErrContractNoRooms) {
+		t.Fatalf("expected ErrContractNoRooms, got %v", err)
+	}
+}
+
+func TestContractTransitions(t *testing.T) {
+	repos := newTestRepositories()
+	contractService, _ := newTestContractService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	invalid := newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 31))
+	invalid.Status = model.ContractStatusActive
+	if err := contractService.Create(invalid, nil, testActor); !errors.Is(err, ErrInvalidContractTransition) {
+		t.Fatalf("creating an active contract must be rejected, got %v", err)
+	}
+
+	contract := newLease(tenant.ID, date(2026, 1, 1), date(2026, 12, 31))
+	if err := contractService.Create(contract, []model.ContractRoom{{RoomID: room.ID}}, testActor); err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+
+	var transitionErr *ContractTransitionError
+	err := contractService.Transition(contract, model.ContractStatusActive, "", testActor)
+	if !errors.As(err, &transitionErr) || !errors.Is(err, ErrInvalidContractTransition) {
+		t.Fatalf("draft must not activate directly, got %v", err)
+	}
+	if transitionErr.From != model.ContractStatusDraft || len(transitionErr.Allowed) == 0 {
+		t.Fatalf("unexpected transition error: %+v", transitionErr)
+	}
+
+	contract.Status = model.ContractStatusActive
+	if err := contractService.Update(contract, nil, testActor); !errors.Is(err, ErrContractStatusReadOnly) {
+		t.Fatalf("status must not change through Update, got %v", err)
+	}
+	contract.Status = model.ContractStatusDraft
+
+	stale := *contract
+	if err := activate(contractService, contract); err != nil {
+		t.Fatalf("activate contract: %v", err)
+	}
+	if err := contractService.Transition(&stale, model.ContractStatusTerminated, "过期数据", testActor); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict for stale contract, got %v", err)
+	}
+
+	if err := contractService.Transition(contract, model.ContractStatusTerminated, "提前退租", Actor{ID: 2, Name: "operator"}); err != nil {
+		t.Fatalf("terminate contract: %v", err)
+	}
+	if err := contractService.Transition(contract, model.ContractStatusActive, "", testActor); !errors.Is(err, ErrInvalidContractTransition) {
+		t.Fatalf("terminated is a final state, got %v", err)
+	}
+
+	history, err := contractService.ListTransitions(contract.ID)
+	if err != nil {
+		t.Fatalf("list transitions: %v", err)
+	}
+	want := []struct{ from, to string }{
+		{"", model.ContractStatusDraft},
+		{model.ContractStatusDraft, model.ContractStatusPendingApproval},
+		{model.ContractStatusPendingApproval, model.ContractStatusActive},
+		{model.ContractStatusActive, model.ContractStatusTerminated},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("expected %d transitions, got %+v", len(want), history)
+	}
+	for i, w := range want {
+		if history[i].FromStatus != w.from || history[i].ToStatus != w.to {
+			t.Fatalf("transition %d: expected %s -> %s, got %s -> %s", i, w.from, w.to, history[i].FromStatus, history[i].ToStatus)
+		}
+	}
+	if last := history[3]; last.Reason != "提前退租" || last.ActorID != 2 || last.ActorName != "operator" {
+		t.Fatalf("unexpected actor or reason: %+v", last)
+	}
+}
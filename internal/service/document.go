@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/pkg/pdf"
+)
+
+// 单据版心左右边距
+const (
+	docLeft  = 50.0
+	docRight = pdf.PageWidth - 50
+)
+
+var feeTypeNames = map[string]string{
+	"rent":        "租金",
+	"water":       "水费",
+	"electricity": "电费",
+	"property":    "物业费",
+	"other":       "其他费用",
+	"late_fee":    "滞纳金",
+}
+
+// feeTypeName 费用类型的中文名称，未知类型原样返回
+func feeTypeName(feeType string) string {
+	if name, ok := feeTypeNames[feeType]; ok {
+		return name
+	}
+	return feeType
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// documentNo 单据编号，格式为 系列-年度-六位序号，如 INV-2026-000001
+func documentNo(series string, year, seq int) string {
+	return fmt.Sprintf("%s-%d-%06d", series, year, seq)
+}
+
+// drawDocumentHeader 绘制出租方抬头与单据标题，返回标题下方可继续绘制的纵坐标
+func drawDocumentHeader(doc *pdf.Document, company config.CompanyConfig, title string) float64 {
+	y := 60.0
+	doc.Text(pdf.PageWidth/2, y, 16, pdf.AlignCenter, company.Name)
+
+	var details []string
+	if company.TaxID != "" {
+		details = append(details, "纳税人识别号："+company.TaxID)
+	}
+	if company.Address != "" {
+		details = append(details, "地址："+company.Address)
+	}
+	if company.Phone != "" {
+		details = append(details, "电话："+company.Phone)
+	}
+	if company.BankName != "" || company.BankAccount != "" {
+		details = append(details, strings.TrimSpace("开户行及账号："+company.BankName+" "+company.BankAccount))
+	}
+	for _, line := range details {
+		y += 14
+		doc.Text(pdf.PageWidth/2, y, 9, pdf.AlignCenter, line)
+	}
+
+	y += 34
+	doc.Text(pdf.PageWidth/2, y, 20, pdf.AlignCenter, title)
+	y += 10
+	doc.Line(docLeft, y, docRight, y, 1)
+	return y + 20
+}
@@ -0,0 +1,6 @@
+package service
+
+import "yuxialuozi_graduation_design_backend/internal/repository"
+
+// ErrVersionConflict 更新时记录已被他人修改（乐观锁版本号不一致）
+var ErrVersionConflict = repository.ErrVersionConflict
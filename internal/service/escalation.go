@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+var (
+	ErrInvalidEscalation = errors.New("租金递增条款无效")
+	ErrCPIIndexMissing   = errors.New("缺少计算租金递增所需的 CPI 涨幅数据")
+)
+
+// validateEscalation 校验递增条款并补齐默认值
+func validateEscalation(e *model.ContractEscalation) error {
+	if e.Type == "" {
+		e.Type = model.EscalationNone
+	}
+	if e.Timing == "" {
+		e.Timing = model.EscalationOnRenewal
+	}
+
+	switch e.Type {
+	case model.EscalationNone, model.EscalationCPI:
+	case model.EscalationPercent, model.EscalationAmount:
+		if e.Value < 0 {
+			return fmt.Errorf("%w：递增值不能为负数", ErrInvalidEscalation)
+		}
+	default:
+		return fmt.Errorf("%w：不支持的递增方式 %s", ErrInvalidEscalation, e.Type)
+	}
+	if e.Timing != model.EscalationOnRenewal && e.Timing != model.EscalationOnAnniversary {
+		return fmt.Errorf("%w：不支持的递增时机 %s", ErrInvalidEscalation, e.Timing)
+	}
+	return nil
+}
+
+// escalateRooms 按递增条款计算 at 时点递增后的各房间月租金，返回新的房间列表，不修改 rooms。
+// cpi 方式使用 at 上一年的 CPI 涨幅，缺少该年数据时返回 ErrCPIIndexMissing
+func escalateRooms(cpiRepo repository.CPIIndexRepository, rooms []model.ContractRoom, e model.ContractEscalation, at time.Time) ([]model.ContractRoom, error) {
+	var rate float64
+	switch e.Type {
+	case model.EscalationPercent:
+		rate = e.Value
+	case model.EscalationCPI:
+		index, err := cpiRepo.FindByYear(at.Year() - 1)
+		if err != nil {
+			return nil, fmt.Errorf("%w（%d 年）", ErrCPIIndexMissing, at.Year()-1)
+		}
+		rate = index.Rate + e.Value
+	}
+
+	escalated := make([]model.ContractRoom, len(rooms))
+	for i, room := range rooms {
+		escalated[i] = model.ContractRoom{RoomID: room.RoomID, RoomNo: room.RoomNo, MonthlyRent: room.MonthlyRent}
+		switch e.Type {
+		case model.EscalationPercent, model.EscalationCPI:
+			escalated[i].MonthlyRent = roundMoney(room.MonthlyRent * (1 + rate/100))
+		case model.EscalationAmount:
+			escalated[i].MonthlyRent = roundMoney(room.MonthlyRent + e.Value)
+		}
+	}
+	return escalated, nil
+}
+
+// recordRentAdjustments 为租金发生变化的房间记录调整记录，before 与 after 按房间 ID 对应
+func recordRentAdjustments(tx *repository.Tx, contractID uint, before, after []model.ContractRoom, at time.Time, reason string) error {
+	oldRents := make(map[uint]float64, len(before))
+	for _, room := range before {
+		oldRents[room.RoomID] = room.MonthlyRent
+	}
+
+	for _, room := range after {
+		old, ok := oldRents[room.RoomID]
+		if !ok || old == room.MonthlyRent {
+			continue
+		}
+		if err := tx.Contracts.CreateRentAdjustment(&model.RentAdjustment{
+			ContractID:    contractID,
+			RoomID:        room.RoomID,
+			EffectiveDate: at,
+			OldRent:       old,
+			NewRent:       room.MonthlyRent,
+			Reason:        reason,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roundMoney 金额四舍五入到分
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// monthsBetween 返回 start 到 end 之间的整月数
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	if start.AddDate(0, months, 0).After(end) {
+		months--
+	}
+	return months
+}
+
+// anniversariesDue 返回 now 之前（含当天）已到达且早于合同到期日的周年日个数
+func anniversariesDue(contract *model.Contract, now time.Time) int {
+	years := 0
+	for {
+		at := contract.StartDate.AddDate(years+1, 0, 0)
+		if at.After(now) || !at.Before(contract.EndDate) {
+			return years
+		}
+		years++
+	}
+}
@@ -1,56 +1,324 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"yuxialuozi_graduation_design_backend/internal/model"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 )
 
+var (
+	ErrFeeAlreadyPaid     = errors.New("该费用已缴纳")
+	ErrFeeStatusDerived   = errors.New("paid、partially_paid 状态由收款记录决定，请通过缴费或收款登记；waived 状态请通过减免接口设置")
+	ErrFeeAmountBelowPaid = errors.New("费用金额不能低于已收金额")
+	ErrFeeHasPayments     = errors.New("费用已有收款记录，请先冲正收款")
+	ErrFeeNotPenalty      = errors.New("只能减免滞纳金")
+	ErrFeeWaived          = errors.New("该滞纳金已减免")
+	ErrFeeTenantLocked    = errors.New("费用已有收款、余额抵扣、发票、贷项或滞纳金，不能改为其他租户")
+)
+
+// OverdueFee 逾期费用，DaysPastDue 为已过到期日的自然日数，LateFee 为已生成的滞纳金
+type OverdueFee struct {
+	model.Fee
+	DaysPastDue int        `json:"daysPastDue"`
+	LateFee     *model.Fee `json:"lateFee"`
+}
+
 type FeeService struct {
-	feeRepo    *repository.FeeRepository
-	tenantRepo *repository.TenantRepository
+	feeRepo    repository.FeeRepository
+	tenantRepo repository.TenantRepository
+	uow        repository.UnitOfWork
 }
 
-func NewFeeService(feeRepo *repository.FeeRepository, tenantRepo *repository.TenantRepository) *FeeService {
+func NewFeeService(feeRepo repository.FeeRepository, tenantRepo repository.TenantRepository, uow repository.UnitOfWork) *FeeService {
 	return &FeeService{
 		feeRepo:    feeRepo,
 		tenantRepo: tenantRepo,
+		uow:        uow,
 	}
 }
 
-func (s *FeeService) Create(fee *model.Fee) error {
-	return s.feeRepo.Create(fee)
+// Create 新建费用，已收金额只能通过收款登记或账户余额抵扣产生；租户账户有余额时自动抵扣。
+// 新建与抵扣依次记入审计日志
+func (s *FeeService) Create(fee *model.Fee, actor Actor) error {
+	if fee.Status == "paid" || fee.Status == "partially_paid" || fee.Status == "waived" {
+		return ErrFeeStatusDerived
+	}
+	fee.PaidAmount = 0
+	fee.PaidDate = nil
+	fee.PenaltyOfID = nil
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		if err := tx.Fees.Create(fee); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionCreate, nil, fee); err != nil {
+			return err
+		}
+		return applyCreditToFee(tx, fee, actor)
+	})
+	if err != nil {
+		return err
+	}
+	fee.Balance = fee.Outstanding()
+	return nil
 }
 
 func (s *FeeService) GetByID(id uint) (*model.Fee, error) {
 	return s.feeRepo.FindByID(id)
 }
 
-func (s *FeeService) Update(fee *model.Fee) error {
-	return s.feeRepo.Update(fee)
+// Update 更新费用。金额不能低于已收金额；已有收款的费用按调整后的金额重新计算缴纳状态，
+// 没有收款的费用不能直接改为 paid 或 partially_paid。已减免的滞纳金改为其他状态时清除减免记录。
+// 已有收款或关联记录的费用不能改为其他租户，以免收款、余额与对账单留在原租户名下；
+// 已开入未作废发票的费用不能修改金额、租户、类型与账期，以免与发票明细不符。
+// 锁定租户与费用后在事务中保存并记录审计
+func (s *FeeService) Update(fee *model.Fee, actor Actor) error {
+	if fee.Amount < fee.PaidAmount {
+		return ErrFeeAmountBelowPaid
+	}
+	if fee.Status == "waived" && fee.WaivedAt == nil {
+		return ErrFeeStatusDerived
+	}
+	if fee.Status != "waived" {
+		fee.WaivedAt, fee.WaivedBy, fee.WaiveReason = nil, "", ""
+	}
+	if fee.PaidAmount > 0 {
+		paidAt := time.Now()
+		if fee.PaidDate != nil {
+			paidAt = *fee.PaidDate
+		}
+		settleFee(fee, paidAt)
+	} else if fee.Status == "paid" || fee.Status == "partially_paid" {
+		return ErrFeeStatusDerived
+	}
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		current, err := lockFee(tx, fee.ID)
+		if err != nil {
+			return err
+		}
+		if fee.Amount != current.Amount || fee.TenantID != current.TenantID || fee.FeeType != current.FeeType || fee.Period != current.Period {
+			if err := checkFeeNotInvoiced(tx, fee.ID); err != nil {
+				return err
+			}
+		}
+		if fee.TenantID != current.TenantID {
+			referenced, err := tx.Fees.HasReferences(fee.ID)
+			if err != nil {
+				return err
+			}
+			if current.PaidAmount > 0 || referenced {
+				return ErrFeeTenantLocked
+			}
+		}
+		if err := tx.Fees.Update(fee); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionUpdate, current, fee)
+	})
+	if err != nil {
+		return err
+	}
+	fee.Balance = fee.Outstanding()
+	return nil
+}
+
+// Delete 删除费用并记录审计，已收取部分或全部金额的费用须先冲正收款，已开入发票的费用须先作废发票
+func (s *FeeService) Delete(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		fee, err := lockFee(tx, id)
+		if err != nil {
+			return err
+		}
+		if fee.PaidAmount > 0 {
+			return ErrFeeHasPayments
+		}
+		if err := checkFeeNotInvoiced(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Fees.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityFee, id, model.AuditActionDelete, fee, nil)
+	})
 }
 
-func (s *FeeService) Delete(id uint) error {
-	return s.feeRepo.Delete(id)
+// checkFeeNotInvoiced 费用在未作废的发票（含草稿）上时返回 ErrFeeInvoiced
+func checkFeeNotInvoiced(tx *repository.Tx, id uint) error {
+	invoiced, err := tx.Invoices.FindInvoicedFees([]uint{id})
+	if err != nil {
+		return err
+	}
+	if len(invoiced) > 0 {
+		return fmt.Errorf("%w，请先作废发票", ErrFeeInvoiced)
+	}
+	return nil
+}
+
+// lockFee 先锁定费用所属租户再锁定费用，与收款登记、余额抵扣的加锁顺序一致
+func lockFee(tx *repository.Tx, id uint) (*model.Fee, error) {
+	fee, err := tx.Fees.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Tenants.FindByIDForUpdate(fee.TenantID); err != nil {
+		return nil, err
+	}
+	return tx.Fees.FindByIDForUpdate(id)
 }
 
 func (s *FeeService) List(page, pageSize int, tenantID uint, roomNo, feeType, status, period string) ([]model.Fee, int64, error) {
 	return s.feeRepo.List(page, pageSize, tenantID, roomNo, feeType, status, period)
 }
 
-func (s *FeeService) Pay(id uint, paidDate *time.Time) error {
-	fee, err := s.feeRepo.FindByID(id)
+// Pay 为单笔费用登记收款：payment.Amount 为 0 时收取全部未收金额，未指定收款方式时按现金登记。
+// 锁定费用行后再登记，已结清的费用返回 ErrFeeAlreadyPaid
+func (s *FeeService) Pay(id uint, payment *model.Payment, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		fee, err := lockFee(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if fee.Status == "paid" || fee.Outstanding() <= 0 {
+			return ErrFeeAlreadyPaid
+		}
+		if fee.Status == "waived" {
+			return ErrFeeWaived
+		}
+
+		if payment.Amount == 0 {
+			payment.Amount = fee.Outstanding()
+		}
+		if payment.Method == "" {
+			payment.Method = model.PaymentMethodCash
+		}
+		payment.TenantID = fee.TenantID
+		payment.Allocations = []model.PaymentAllocation{{FeeID: fee.ID, Amount: payment.Amount}}
+		return recordPayment(tx, payment, actor)
+	})
+}
+
+// ListOverdue 分页获取逾期费用及其逾期天数与滞纳金，逾期最久的在前
+func (s *FeeService) ListOverdue(page, pageSize int, tenantID uint, feeType string, now time.Time) ([]OverdueFee, int64, error) {
+	fees, total, err := s.feeRepo.ListOverdue(page, pageSize, tenantID, feeType)
 	if err != nil {
-		return err
+		return nil, 0, err
+	}
+
+	ids := make([]uint, 0, len(fees))
+	for _, fee := range fees {
+		ids = append(ids, fee.ID)
+	}
+	penalties, err := s.feeRepo.FindPenalties(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	lateFees := make(map[uint]*model.Fee, len(penalties))
+	for i := range penalties {
+		if !penalties[i].DeletedAt.Valid {
+			lateFees[*penalties[i].PenaltyOfID] = &penalties[i]
+		}
+	}
+
+	today := startOfDay(now)
+	result := make([]OverdueFee, 0, len(fees))
+	for _, fee := range fees {
+		result = append(result, OverdueFee{Fee: fee, DaysPastDue: -daysUntil(today, fee.DueDate), LateFee: lateFees[fee.ID]})
+	}
+	return result, total, nil
+}
+
+// Waive 减免滞纳金并记录审计，减免后不再计入欠费也不再累计；已收取部分金额的滞纳金须先冲正收款
+func (s *FeeService) Waive(id uint, reason string, actor Actor) (*model.Fee, error) {
+	var fee *model.Fee
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		var err error
+		fee, err = tx.Fees.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fee.PenaltyOfID == nil:
+			return ErrFeeNotPenalty
+		case fee.Status == "waived":
+			return ErrFeeWaived
+		case fee.Status == "paid":
+			return ErrFeeAlreadyPaid
+		case fee.PaidAmount > 0:
+			return ErrFeeHasPayments
+		}
+
+		before := *fee
+		now := time.Now()
+		fee.Status = "waived"
+		fee.WaivedAt = &now
+		fee.WaivedBy = actor.Name
+		fee.WaiveReason = reason
+		if err := tx.Fees.Update(fee); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionWaive, &before, fee)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return s.feeRepo.FindByID(id)
+}
+
+func (s *FeeService) ListTrash(page, pageSize int) ([]model.Fee, int64, error) {
+	return s.feeRepo.ListTrashed(page, pageSize)
+}
 
-	now := time.Now()
-	if paidDate == nil {
-		paidDate = &now
+func (s *FeeService) GetTrashedByID(id uint) (*model.Fee, error) {
+	return s.feeRepo.FindTrashedByID(id)
+}
+
+// Restore 从回收站恢复费用并返回恢复后的费用，所属租户已删除时返回 ErrTenantDeleted
+func (s *FeeService) Restore(id uint, actor Actor) (*model.Fee, error) {
+	var fee *model.Fee
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		before, err := tx.Fees.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Tenants.FindByID(before.TenantID); err != nil {
+			return ErrTenantDeleted
+		}
+
+		if err := tx.Fees.Restore(id); err != nil {
+			return err
+		}
+		if fee, err = tx.Fees.FindByID(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityFee, id, model.AuditActionRestore, before, fee)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return fee, nil
+}
 
-	fee.PaidDate = paidDate
-	fee.Status = "paid"
-	return s.feeRepo.Update(fee)
+// Purge 永久删除回收站中的费用，有收款记录（包括已冲正的收款）的费用需保留
+func (s *FeeService) Purge(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		fee, err := tx.Fees.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+		referenced, err := tx.Fees.HasReferences(id)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return ErrFeeHasPayments
+		}
+		if err := tx.Fees.Purge(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityFee, id, model.AuditActionPurge, fee, nil)
+	})
 }
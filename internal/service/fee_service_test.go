@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+func TestPayFee(t *testing.T) {
+	repos := newTestRepositories()
+	feeService := NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	fee := &model.Fee{TenantID: tenant.ID, FeeType: "rent", Amount: 3000, Period: "2026-10"}
+	if err := feeService.Create(fee, testActor); err != nil {
+		t.Fatalf("create fee: %v", err)
+	}
+	if fee.Status != "unpaid" {
+		t.Fatalf("expected default status unpaid, got %s", fee.Status)
+	}
+
+	paidDate := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	if err := feeService.Pay(fee.ID, &model.Payment{ReceivedAt: paidDate}, testActor); err != nil {
+		t.Fatalf("pay fee: %v", err)
+	}
+
+	got, err := feeService.GetByID(fee.ID)
+	if err != nil {
+		t.Fatalf("get fee: %v", err)
+	}
+	if got.Status != "paid" || got.PaidDate == nil || !got.PaidDate.Equal(paidDate) {
+		t.Fatalf("fee not paid: status=%s paidDate=%v", got.Status, got.PaidDate)
+	}
+
+	if err := feeService.Pay(fee.ID, &model.Payment{}, testActor); !errors.Is(err, ErrFeeAlreadyPaid) {
+		t.Fatalf("expected ErrFeeAlreadyPaid, got %v", err)
+	}
+}
+
+func TestUpdateFeeVersionConflict(t *testing.T) {
+	repos := newTestRepositories()
+	feeService := NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	fee := &model.Fee{TenantID: tenant.ID, FeeType: "water", Amount: 80}
+	if err := feeService.Create(fee, testActor); err != nil {
+		t.Fatalf("create fee: %v", err)
+	}
+
+	stale, _ := feeService.GetByID(fee.ID)
+	if err := feeService.Pay(fee.ID, &model.Payment{}, testActor); err != nil {
+		t.Fatalf("pay fee: %v", err)
+	}
+
+	stale.Amount = 100
+	if err := feeService.Update(stale, testActor); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestUpdateAndDeleteFeeAudited(t *testing.T) {
+	repos := newTestRepositories()
+	feeService := NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	fee := &model.Fee{TenantID: tenant.ID, FeeType: "water", Amount: 80}
+	if err := feeService.Create(fee, testActor); err != nil {
+		t.Fatalf("create fee: %v", err)
+	}
+	fee.Amount = 95
+	if err := feeService.Update(fee, testActor); err != nil {
+		t.Fatalf("update fee: %v", err)
+	}
+	if err := feeService.Delete(fee.ID, testActor); err != nil {
+		t.Fatalf("delete fee: %v", err)
+	}
+	if restored, err := feeService.Restore(fee.ID, testActor); err != nil || restored.Amount != 95 {
+		t.Fatalf("restore fee: %+v, %v", restored, err)
+	}
+	if err := feeService.Delete(fee.ID, testActor); err != nil {
+		t.Fatalf("delete fee again: %v", err)
+	}
+	if err := feeService.Purge(fee.ID, testActor); err != nil {
+		t.Fatalf("purge fee: %v", err)
+	}
+
+	// 修改、删除、恢复与永久删除的审计随事务写入
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityFee, fee.ID)
+	want := []string{
+		model.AuditActionCreate, model.AuditActionUpdate, model.AuditActionDelete,
+		model.AuditActionRestore, model.AuditActionDelete, model.AuditActionPurge,
+	}
+	if len(audits) != len(want) {
+		t.Fatalf("unexpected fee audit trail: %+v", audits)
+	}
+	for i, a := range audits {
+		if a.Action != want[i] {
+			t.Fatalf("audit %d: expected %s, got %+v", i, want[i], a)
+		}
+	}
+	if audits[1].ActorName != testActor.Name || !bytes.Contains(audits[1].After, []byte(`"amount":95`)) {
+		t.Fatalf("unexpected update audit: %+v", audits[1])
+	}
+}
+
+func TestUpdateFeeTenantWithPayments(t *testing.T) {
+	repos := newTestRepositories()
+	feeService := NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	other := mustCreateTenant(t, repos, "租户乙")
+
+	paid := &model.Fee{TenantID: tenant.ID, FeeType: "rent", Amount: 3000}
+	unpaid := &model.Fee{TenantID: tenant.ID, FeeType: "water", Amount: 80}
+	for _, fee := range []*model.Fee{paid, unpaid} {
+		if err := feeService.Create(fee, testActor); err != nil {
+			t.Fatalf("create fee: %v", err)
+		}
+	}
+	if err := feeService.Pay(paid.ID, &model.Payment{Amount: 1000}, testActor); err != nil {
+		t.Fatalf("pay fee: %v", err)
+	}
+
+	moved, _ := feeService.GetByID(paid.ID)
+	moved.TenantID = other.ID
+	if err := feeService.Update(moved, testActor); !errors.Is(err, ErrFeeTenantLocked) {
+		t.Fatalf("expected ErrFeeTenantLocked, got %v", err)
+	}
+
+	// 没有收款与关联记录的费用可以改为其他租户
+	unpaid.TenantID = other.ID
+	if err := feeService.Update(unpaid, testActor); err != nil {
+		t.Fatalf("move unpaid fee: %v", err)
+	}
+	if got, _ := feeService.GetByID(unpaid.ID); got.TenantID != other.ID {
+		t.Fatalf("expected fee to move to the other tenant: %+v", got)
+	}
+}
+
+func TestInvoicedFeeLocked(t *testing.T) {
+	repos := newTestRepositories()
+	feeService := NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+	invoiceService := newTestInvoiceService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreatePeriodFee(t, repos, tenant.ID, "rent", "2026-10", 1090)
+
+	invoice := &model.Invoice{TenantID: tenant.ID, Period: "2026-10"}
+	if err := invoiceService.Create(invoice, nil, testActor); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+	if _, err := invoiceService.Issue(invoice.ID, testActor); err != nil {
+		t.Fatalf("issue invoice: %v", err)
+	}
+
+	edited, _ := feeService.GetByID(rent.ID)
+	edited.Amount = 1200
+	if err := feeService.Update(edited, testActor); !errors.Is(err, ErrFeeInvoiced) {
+		t.Fatalf("expected ErrFeeInvoiced for amount change, got %v", err)
+	}
+	if err := feeService.Delete(rent.ID, testActor); !errors.Is(err, ErrFeeInvoiced) {
+		t.Fatalf("expected ErrFeeInvoiced for delete, got %v", err)
+	}
+
+	// 不影响发票明细的字段仍可修改
+	edited, _ = feeService.GetByID(rent.ID)
+	edited.DueDate = *date(2026, 10, 10)
+	if err := feeService.Update(edited, testActor); err != nil {
+		t.Fatalf("update due date: %v", err)
+	}
+
+	// 作废发票后可以修改与删除
+	if _, err := invoiceService.Void(invoice.ID, "开错金额", testActor); err != nil {
+		t.Fatalf("void invoice: %v", err)
+	}
+	edited, _ = feeService.GetByID(rent.ID)
+	edited.Amount = 1200
+	if err := feeService.Update(edited, testActor); err != nil {
+		t.Fatalf("update fee of a voided invoice: %v", err)
+	}
+	if err := feeService.Delete(rent.ID, testActor); err != nil {
+		t.Fatalf("delete fee of a voided invoice: %v", err)
+	}
+}
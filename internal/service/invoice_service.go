@@ -0,0 +1,541 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/pkg/pdf"
+)
+
+var (
+	ErrInvalidInvoice      = errors.New("发票信息无效")
+	ErrInvoiceEmpty        = errors.New("该租户在该账期没有可开票的费用")
+	ErrFeeInvoiced         = errors.New("费用已开具发票")
+	ErrInvoiceNotDraft     = errors.New("只有草稿发票可以开具或删除")
+	ErrInvoiceNotIssued    = errors.New("只有已开具的发票可以作废或冲红")
+	ErrInvoiceCredited     = errors.New("发票已开具红字发票，请先作废红字发票")
+	ErrCreditExceedsAmount = errors.New("冲红金额超过发票可冲减金额")
+	ErrInvoiceCreditUsed   = errors.New("红字发票计入账户的余额已被抵扣或退款，不能作废")
+)
+
+// 发票编号系列
+const (
+	invoiceSeries    = "INV"
+	creditNoteSeries = "CN"
+)
+
+type InvoiceService struct {
+	invoiceRepo repository.InvoiceRepository
+	feeRepo     repository.FeeRepository
+	uow         repository.UnitOfWork
+	config      *config.Config
+}
+
+func NewInvoiceService(invoiceRepo repository.InvoiceRepository, feeRepo repository.FeeRepository, uow repository.UnitOfWork, cfg *config.Config) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepo: invoiceRepo,
+		feeRepo:     feeRepo,
+		uow:         uow,
+		config:      cfg,
+	}
+}
+
+// Create 为租户创建草稿发票。feeIDs 为空时汇总该租户在 period 账期内尚未开票的全部费用；
+// 已减免、金额为 0 的费用不开票，每笔费用只能出现在一张未作废的发票中
+func (s *InvoiceService) Create(invoice *model.Invoice, feeIDs []uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if _, err := tx.Tenants.FindByID(invoice.TenantID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w：租户不存在", ErrInvalidInvoice)
+			}
+			return err
+		}
+
+		explicit := len(feeIDs) > 0
+		if !explicit {
+			if invoice.Period == "" {
+				return fmt.Errorf("%w：未指定费用时必须指定账期", ErrInvalidInvoice)
+			}
+			fees, err := tx.Fees.FindByTenantPeriod(invoice.TenantID, invoice.Period)
+			if err != nil {
+				return err
+			}
+			for _, f := range fees {
+				feeIDs = append(feeIDs, f.ID)
+			}
+		}
+		feeIDs = append([]uint(nil), feeIDs...)
+		sort.Slice(feeIDs, func(i, j int) bool { return feeIDs[i] < feeIDs[j] })
+
+		// 先按 ID 顺序锁定费用，再检查是否已开票，避免同一费用被并发开入两张发票
+		fees := make([]*model.Fee, 0, len(feeIDs))
+		for i, id := range feeIDs {
+			if i > 0 && feeIDs[i-1] == id {
+				return fmt.Errorf("%w：费用 %d 重复", ErrInvalidInvoice, id)
+			}
+			fee, err := tx.Fees.FindByIDForUpdate(id)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w：费用 %d 不存在", ErrInvalidInvoice, id)
+			}
+			if err != nil {
+				return err
+			}
+			if fee.TenantID != invoice.TenantID {
+				return fmt.Errorf("%w：费用 %d 不属于该租户", ErrInvalidInvoice, id)
+			}
+			if fee.Status == "waived" || fee.Amount <= 0 {
+				if explicit {
+					return fmt.Errorf("%w：费用 %d 已减免或金额为 0", ErrInvalidInvoice, id)
+				}
+				continue
+			}
+			fees = append(fees, fee)
+		}
+
+		ids := make([]uint, len(fees))
+		for i, f := range fees {
+			ids[i] = f.ID
+		}
+		invoiced, err := tx.Invoices.FindInvoicedFees(ids)
+		if err != nil {
+			return err
+		}
+		if explicit && len(invoiced) > 0 {
+			return fmt.Errorf("%w：费用 %v", ErrFeeInvoiced, invoiced)
+		}
+		skip := map[uint]bool{}
+		for _, id := range invoiced {
+			skip[id] = true
+		}
+
+		invoice.Lines = nil
+		for _, f := range fees {
+			if skip[f.ID] {
+				continue
+			}
+			invoice.Lines = append(invoice.Lines, s.feeLine(f))
+		}
+		if len(invoice.Lines) == 0 {
+			return ErrInvoiceEmpty
+		}
+
+		invoice.ID = 0
+		invoice.InvoiceNo = nil
+		invoice.Kind = model.InvoiceKindInvoice
+		invoice.OriginalID = nil
+		invoice.Status = model.InvoiceStatusDraft
+		invoice.CreditedAmount = 0
+		sumLines(invoice)
+		if err := tx.Invoices.Create(invoice); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityInvoice, invoice.ID, model.AuditActionCreate, nil, invoice)
+	})
+}
+
+// feeLine 按费用类型的税率将含税的费用金额拆分为不含税金额与税额
+func (s *InvoiceService) feeLine(fee *model.Fee) model.InvoiceLine {
+	rate := s.config.Invoice.TaxRate(fee.FeeType)
+	total := roundMoney(fee.Amount)
+	amount := roundMoney(total / (1 + rate))
+	feeID := fee.ID
+	return model.InvoiceLine{
+		FeeID:       &feeID,
+		FeeType:     fee.FeeType,
+		RoomNo:      fee.RoomNo,
+		Period:      fee.Period,
+		Description: feeTypeName(fee.FeeType),
+		Amount:      amount,
+		TaxRate:     rate,
+		TaxAmount:   roundMoney(total - amount),
+		Total:       total,
+	}
+}
+
+func sumLines(invoice *model.Invoice) {
+	invoice.Subtotal, invoice.TaxAmount, invoice.Total = 0, 0, 0
+	for _, l := range invoice.Lines {
+		invoice.Subtotal = roundMoney(invoice.Subtotal + l.Amount)
+		invoice.TaxAmount = roundMoney(invoice.TaxAmount + l.TaxAmount)
+		invoice.Total = roundMoney(invoice.Total + l.Total)
+	}
+}
+
+func (s *InvoiceService) GetByID(id uint) (*model.Invoice, error) {
+	return s.invoiceRepo.FindByID(id)
+}
+
+func (s *InvoiceService) List(page, pageSize int, tenantID uint, kind, status, period string) ([]model.Invoice, int64, error) {
+	return s.invoiceRepo.List(page, pageSize, tenantID, kind, status, period)
+}
+
+// Delete 删除草稿发票，草稿没有占用编号，删除后其中的费用可重新开票
+func (s *InvoiceService) Delete(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		invoice, err := tx.Invoices.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != model.InvoiceStatusDraft {
+			return ErrInvoiceNotDraft
+		}
+		if err := tx.Invoices.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityInvoice, id, model.AuditActionDelete, invoice, nil)
+	})
+}
+
+// Issue 开具草稿发票。编号在同一事务内按开具年度递增分配，事务失败时编号随之回滚，
+// 因此已开具的发票编号连续无空号
+func (s *InvoiceService) Issue(id uint, actor Actor) (*model.Invoice, error) {
+	var invoice *model.Invoice
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		var err error
+		invoice, err = tx.Invoices.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != model.InvoiceStatusDraft {
+			return ErrInvoiceNotDraft
+		}
+
+		before := *invoice
+		now := time.Now()
+		no, err := nextInvoiceNo(tx, invoiceSeries, now)
+		if err != nil {
+			return err
+		}
+		invoice.InvoiceNo = &no
+		invoice.Status = model.InvoiceStatusIssued
+		invoice.IssuedAt = &now
+		invoice.IssuedBy = actor.Name
+		if err := tx.Invoices.Update(invoice); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityInvoice, id, model.AuditActionIssue, &before, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func nextInvoiceNo(tx *repository.Tx, series string, now time.Time) (string, error) {
+	seq, err := tx.Invoices.NextNumber(series, now.Year())
+	if err != nil {
+		return "", err
+	}
+	return documentNo(series, now.Year(), seq), nil
+}
+
+// Void 作废已开具的发票或红字发票，作废后保留编号。已被红字发票冲减的发票须先作废红字发票；
+// 作废红字发票时恢复原发票的可冲减金额，并作废其开具的账户贷项，贷项已被抵扣或退款时不能作废
+func (s *InvoiceService) Void(id uint, reason string, actor Actor) (*model.Invoice, error) {
+	var invoice *model.Invoice
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		var err error
+		invoice, err = tx.Invoices.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if invoice.Kind == model.InvoiceKindCreditNote {
+			if err := lockAccount(tx, invoice.TenantID, ErrInvalidInvoice); err != nil {
+				return err
+			}
+		}
+		if invoice, err = tx.Invoices.FindByIDForUpdate(id); err != nil {
+			return err
+		}
+		if invoice.Status != model.InvoiceStatusIssued {
+			return ErrInvoiceNotIssued
+		}
+		if invoice.CreditedAmount > 0 {
+			return ErrInvoiceCredited
+		}
+		before := *invoice
+
+		if invoice.Kind == model.InvoiceKindCreditNote && invoice.OriginalID != nil {
+			original, err := tx.Invoices.FindByIDForUpdate(*invoice.OriginalID)
+			if err != nil {
+				return err
+			}
+			original.CreditedAmount = roundMoney(original.CreditedAmount + invoice.Total)
+			if err := tx.Invoices.Update(original); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if invoice.Kind == model.InvoiceKindCreditNote {
+			if err := voidInvoiceCredits(tx, invoice, actor, now); err != nil {
+				return err
+			}
+		}
+		invoice.Status = model.InvoiceStatusVoid
+		invoice.VoidedAt = &now
+		invoice.VoidedBy = actor.Name
+		invoice.VoidReason = reason
+		if err := tx.Invoices.Update(invoice); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityInvoice, id, model.AuditActionVoid, &before, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// CreditLine 红字发票中对原发票某一明细的冲减金额（含税，正数）
+type CreditLine struct {
+	LineID uint
+	Amount float64
+}
+
+// CreditNote 为已开具的发票开具红字发票，红字发票开具即生效并分配 CN 系列编号。
+// lines 为空时冲减全部剩余金额；每行冲减金额不能超过该明细扣除未作废红字发票后的剩余金额。
+// 红字发票同时为其中的费用开具账户贷项，使发票与租户对账单保持一致
+func (s *InvoiceService) CreditNote(id uint, reason string, lines []CreditLine, actor Actor) (*model.Invoice, error) {
+	var note *model.Invoice
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		// 先锁定租户行再锁定发票，与贷项开具的加锁顺序一致
+		original, err := tx.Invoices.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if err := lockAccount(tx, original.TenantID, ErrInvalidInvoice); err != nil {
+			return err
+		}
+		if original, err = tx.Invoices.FindByIDForUpdate(id); err != nil {
+			return err
+		}
+		if original.Kind != model.InvoiceKindInvoice || original.Status != model.InvoiceStatusIssued {
+			return ErrInvoiceNotIssued
+		}
+
+		now := time.Now()
+		if note, err = issueCreditInvoice(tx, original, reason, lines, actor, now); err != nil {
+			return err
+		}
+		return creditInvoiceFees(tx, note, actor, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.invoiceRepo.FindByID(note.ID)
+}
+
+// issueCreditInvoice 为已锁定的原发票开具红字发票并更新原发票的已冲减金额，红字发票的开具与原发票的冲减记入审计。
+// 调用方须已锁定租户行与原发票
+func issueCreditInvoice(tx *repository.Tx, original *model.Invoice, reason string, lines []CreditLine, actor Actor, now time.Time) (*model.Invoice, error) {
+	before := *original
+	notes, err := tx.Invoices.FindCreditNotes(original.ID)
+	if err != nil {
+		return nil, err
+	}
+	credited := map[uint]float64{}
+	for _, n := range notes {
+		if n.Status == model.InvoiceStatusVoid {
+			continue
+		}
+		for _, l := range n.Lines {
+			if l.OriginalLineID != nil {
+				credited[*l.OriginalLineID] = roundMoney(credited[*l.OriginalLineID] - l.Total)
+			}
+		}
+	}
+
+	originalLines := map[uint]model.InvoiceLine{}
+	for _, l := range original.Lines {
+		originalLines[l.ID] = l
+	}
+	if len(lines) == 0 {
+		for _, l := range original.Lines {
+			if remaining := roundMoney(l.Total - credited[l.ID]); remaining > 0 {
+				lines = append(lines, CreditLine{LineID: l.ID, Amount: remaining})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("%w：发票已全部冲红", ErrCreditExceedsAmount)
+		}
+	}
+
+	note := &model.Invoice{
+		Kind:       model.InvoiceKindCreditNote,
+		TenantID:   original.TenantID,
+		Period:     original.Period,
+		OriginalID: &original.ID,
+		Status:     model.InvoiceStatusIssued,
+		Remark:     reason,
+		IssuedAt:   &now,
+		IssuedBy:   actor.Name,
+	}
+	seen := map[uint]bool{}
+	for _, c := range lines {
+		line, ok := originalLines[c.LineID]
+		if !ok {
+			return nil, fmt.Errorf("%w：明细 %d 不属于该发票", ErrInvalidInvoice, c.LineID)
+		}
+		if seen[c.LineID] {
+			return nil, fmt.Errorf("%w：明细 %d 重复", ErrInvalidInvoice, c.LineID)
+		}
+		seen[c.LineID] = true
+		amount := roundMoney(c.Amount)
+		if amount <= 0 {
+			return nil, fmt.Errorf("%w：冲红金额须大于 0", ErrInvalidInvoice)
+		}
+		if remaining := roundMoney(line.Total - credited[line.ID]); amount > remaining {
+			return nil, fmt.Errorf("%w：明细 %d 可冲减金额为 %.2f", ErrCreditExceedsAmount, line.ID, remaining)
+		}
+
+		net := roundMoney(amount / (1 + line.TaxRate))
+		lineID := line.ID
+		note.Lines = append(note.Lines, model.InvoiceLine{
+			FeeID:          line.FeeID,
+			FeeType:        line.FeeType,
+			RoomNo:         line.RoomNo,
+			Period:         line.Period,
+			OriginalLineID: &lineID,
+			Description:    line.Description,
+			Amount:         -net,
+			TaxRate:        line.TaxRate,
+			TaxAmount:      -roundMoney(amount - net),
+			Total:          -amount,
+		})
+	}
+	sumLines(note)
+
+	no, err := nextInvoiceNo(tx, creditNoteSeries, now)
+	if err != nil {
+		return nil, err
+	}
+	note.InvoiceNo = &no
+	if err := tx.Invoices.Create(note); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(tx, actor, model.AuditEntityInvoice, note.ID, model.AuditActionCreate, nil, note); err != nil {
+		return nil, err
+	}
+
+	original.CreditedAmount = roundMoney(original.CreditedAmount - note.Total)
+	if err := tx.Invoices.Update(original); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(tx, actor, model.AuditEntityInvoice, original.ID, model.AuditActionCredit, &before, original); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// RenderPDF 生成发票的 PDF，草稿与已作废的发票在标题中注明
+func (s *InvoiceService) RenderPDF(id uint) (*model.Invoice, []byte, error) {
+	invoice, err := s.invoiceRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var original *model.Invoice
+	if invoice.OriginalID != nil {
+		if original, err = s.invoiceRepo.FindByID(*invoice.OriginalID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return invoice, renderInvoice(s.config.Company, invoice, original), nil
+}
+
+func renderInvoice(company config.CompanyConfig, invoice, original *model.Invoice) []byte {
+	title := "发  票"
+	if invoice.Kind == model.InvoiceKindCreditNote {
+		title = "红 字 发 票"
+	}
+	switch invoice.Status {
+	case model.InvoiceStatusDraft:
+		title += "（草稿）"
+	case model.InvoiceStatusVoid:
+		title += "（已作废）"
+	}
+
+	doc := pdf.New()
+	y := drawDocumentHeader(doc, company, title)
+
+	no := "—"
+	if invoice.InvoiceNo != nil {
+		no = *invoice.InvoiceNo
+	}
+	issued := "—"
+	if invoice.IssuedAt != nil {
+		issued = invoice.IssuedAt.Format("2006-01-02")
+	}
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, "发票号码："+no)
+	doc.Text(docRight, y, 10, pdf.AlignRight, "开票日期："+issued)
+	y += 18
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("购买方："+invoice.TenantName, 10, 300))
+	if invoice.Period != "" {
+		doc.Text(docRight, y, 10, pdf.AlignRight, "账期："+invoice.Period)
+	}
+	if original != nil && original.InvoiceNo != nil {
+		y += 18
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, "原发票号码："+*original.InvoiceNo)
+	}
+	y += 24
+
+	header := func() {
+		doc.Line(docLeft, y-13, docRight, y-13, 0.5)
+		doc.Text(docLeft, y, 9, pdf.AlignLeft, "项目")
+		doc.Text(225, y, 9, pdf.AlignLeft, "房间")
+		doc.Text(280, y, 9, pdf.AlignLeft, "账期")
+		doc.Text(400, y, 9, pdf.AlignRight, "金额")
+		doc.Text(445, y, 9, pdf.AlignRight, "税率")
+		doc.Text(495, y, 9, pdf.AlignRight, "税额")
+		doc.Text(docRight, y, 9, pdf.AlignRight, "价税合计")
+		doc.Line(docLeft, y+6, docRight, y+6, 0.5)
+		y += 22
+	}
+	header()
+	for _, l := range invoice.Lines {
+		if y > pdf.PageHeight-100 {
+			doc.AddPage()
+			y = 70
+			header()
+		}
+		doc.Text(docLeft, y, 9, pdf.AlignLeft, pdf.Truncate(l.Description, 9, 170))
+		doc.Text(225, y, 9, pdf.AlignLeft, pdf.Truncate(l.RoomNo, 9, 50))
+		doc.Text(280, y, 9, pdf.AlignLeft, pdf.Truncate(l.Period, 9, 60))
+		doc.Text(400, y, 9, pdf.AlignRight, formatMoney(l.Amount))
+		doc.Text(445, y, 9, pdf.AlignRight, fmt.Sprintf("%g%%", roundMoney(l.TaxRate*100)))
+		doc.Text(495, y, 9, pdf.AlignRight, formatMoney(l.TaxAmount))
+		doc.Text(docRight, y, 9, pdf.AlignRight, formatMoney(l.Total))
+		y += 18
+	}
+	doc.Line(docLeft, y-10, docRight, y-10, 0.5)
+	y += 4
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, "合计")
+	doc.Text(400, y, 10, pdf.AlignRight, formatMoney(invoice.Subtotal))
+	doc.Text(495, y, 10, pdf.AlignRight, formatMoney(invoice.TaxAmount))
+	doc.Text(docRight, y, 10, pdf.AlignRight, formatMoney(invoice.Total))
+	y += 30
+
+	if invoice.CreditedAmount > 0 {
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, "已冲红金额："+formatMoney(invoice.CreditedAmount))
+		y += 18
+	}
+	if invoice.Remark != "" {
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("备注："+invoice.Remark, 10, docRight-docLeft))
+		y += 18
+	}
+	if invoice.Status == model.InvoiceStatusVoid {
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("作废原因："+invoice.VoidReason, 10, docRight-docLeft))
+		y += 18
+	}
+	doc.Text(docRight, y+12, 10, pdf.AlignRight, "开票人："+invoice.IssuedBy)
+	return doc.Bytes()
+}
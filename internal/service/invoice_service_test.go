@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+)
+
+func newTestInvoiceService(repos *storage.Repositories) *InvoiceService {
+	cfg := testConfig()
+	cfg.Company = config.CompanyConfig{Name: "测试物业", TaxID: "91110000000000000X"}
+	cfg.Invoice = config.InvoiceConfig{TaxRates: map[string]float64{"rent": 0.09}}
+	return NewInvoiceService(repos.Invoices, repos.Fees, repos.UnitOfWork, cfg)
+}
+
+func mustCreatePeriodFee(t *testing.T, repos *storage.Repositories, tenantID uint, feeType, period string, amount float64) *model.Fee {
+	t.Helper()
+	fee := &model.Fee{TenantID: tenantID, FeeType: feeType, Period: period, RoomNo: "A101", Amount: amount, DueDate: *date(2026, 10, 5)}
+	if err := repos.Fees.Create(fee); err != nil {
+		t.Fatalf("create fee: %v", err)
+	}
+	return fee
+}
+
+func expectedNo(series string, seq int) string {
+	return fmt.Sprintf("%s-%d-%06d", series, time.Now().Year(), seq)
+}
+
+func TestInvoiceLifecycle(t *testing.T) {
+	repos := newTestRepositories()
+	invoiceService := newTestInvoiceService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	other := mustCreateTenant(t, repos, "租户乙")
+	rent := mustCreatePeriodFee(t, repos, tenant.ID, "rent", "2026-10", 1090)
+	water := mustCreatePeriodFee(t, repos, tenant.ID, "water", "2026-10", 100)
+	november := mustCreatePeriodFee(t, repos, tenant.ID, "rent", "2026-11", 2180)
+	foreign := mustCreatePeriodFee(t, repos, other.ID, "rent", "2026-10", 1090)
+
+	draft := &model.Invoice{TenantID: tenant.ID, Period: "2026-10"}
+	if err := invoiceService.Create(draft, nil, testActor); err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+	if len(draft.Lines) != 2 || draft.Subtotal != 1100 || draft.TaxAmount != 90 || draft.Total != 1190 {
+		t.Fatalf("unexpected invoice totals: %+v", draft)
+	}
+	if draft.Status != model.InvoiceStatusDraft || draft.InvoiceNo != nil {
+		t.Fatalf("new invoice must be an unnumbered draft: %+v", draft)
+	}
+
+	if err := invoiceService.Create(&model.Invoice{TenantID: tenant.ID, Period: "2026-10"}, nil, testActor); !errors.Is(err, ErrInvoiceEmpty) {
+		t.Fatalf("expected ErrInvoiceEmpty, got %v", err)
+	}
+	if err := invoiceService.Create(&model.Invoice{TenantID: tenant.ID}, []uint{rent.ID}, testActor); !errors.Is(err, ErrFeeInvoiced) {
+		t.Fatalf("expected ErrFeeInvoiced, got %v", err)
+	}
+	if err := invoiceService.Create(&model.Invoice{TenantID: tenant.ID}, []uint{foreign.ID}, testActor); !errors.Is(err, ErrInvalidInvoice) {
+		t.Fatalf("expected ErrInvalidInvoice for another tenant's fee, got %v", err)
+	}
+
+	// 删除草稿不占用编号
+	if err := invoiceService.Delete(draft.ID, testActor); err != nil {
+		t.Fatalf("delete draft: %v", err)
+	}
+	first := &model.Invoice{TenantID: tenant.ID, Period: "2026-10"}
+	if err := invoiceService.Create(first, nil, testActor); err != nil {
+		t.Fatalf("recreate invoice: %v", err)
+	}
+	issued, err := invoiceService.Issue(first.ID, testActor)
+	if err != nil || *issued.InvoiceNo != expectedNo("INV", 1) || issued.IssuedBy != testActor.Name {
+		t.Fatalf("unexpected issued invoice: %+v, %v", issued, err)
+	}
+	if _, err := invoiceService.Issue(first.ID, testActor); !errors.Is(err, ErrInvoiceNotDraft) {
+		t.Fatalf("expected ErrInvoiceNotDraft, got %v", err)
+	}
+	if err := invoiceService.Delete(first.ID, testActor); !errors.Is(err, ErrInvoiceNotDraft) {
+		t.Fatalf("issued invoice must not be deleted: %v", err)
+	}
+
+	// 作废的发票保留编号，其中的费用可重新开票
+	second := &model.Invoice{TenantID: tenant.ID}
+	if err := invoiceService.Create(second, []uint{november.ID}, testActor); err != nil {
+		t.Fatalf("create second invoice: %v", err)
+	}
+	invoiceService.Issue(second.ID, testActor)
+	voided, err := invoiceService.Void(second.ID, "开错抬头", testActor)
+	if err != nil || voided.Status != model.InvoiceStatusVoid || *voided.InvoiceNo != expectedNo("INV", 2) {
+		t.Fatalf("unexpected voided invoice: %+v, %v", voided, err)
+	}
+	third := &model.Invoice{TenantID: tenant.ID}
+	if err := invoiceService.Create(third, []uint{november.ID}, testActor); err != nil {
+		t.Fatalf("fee of a voided invoice must be invoiceable again: %v", err)
+	}
+	if issued, _ := invoiceService.Issue(third.ID, testActor); *issued.InvoiceNo != expectedNo("INV", 3) {
+		t.Fatalf("expected gap-free numbering, got %s", *issued.InvoiceNo)
+	}
+
+	// 费用均已结清，红字发票开具的贷项留在账户余额中
+	_, feeService := newTestPaymentServices(repos)
+	for _, f := range []*model.Fee{rent, water, november} {
+		if err := feeService.Pay(f.ID, &model.Payment{Amount: f.Amount}, testActor); err != nil {
+			t.Fatalf("pay fee: %v", err)
+		}
+	}
+
+	rentLine := first.Lines[0]
+	note, err := invoiceService.CreditNote(first.ID, "租金优惠", []CreditLine{{LineID: rentLine.ID, Amount: 545}}, testActor)
+	if err != nil {
+		t.Fatalf("create credit note: %v", err)
+	}
+	if *note.InvoiceNo != expectedNo("CN", 1) || note.Total != -545 || note.Subtotal != -500 || note.TaxAmount != -45 {
+		t.Fatalf("unexpected credit note: %+v", note)
+	}
+	if _, err := invoiceService.CreditNote(first.ID, "超额", []CreditLine{{LineID: rentLine.ID, Amount: 600}}, testActor); !errors.Is(err, ErrCreditExceedsAmount) {
+		t.Fatalf("expected ErrCreditExceedsAmount, got %v", err)
+	}
+	if _, err := invoiceService.Void(first.ID, "作废", testActor); !errors.Is(err, ErrInvoiceCredited) {
+		t.Fatalf("expected ErrInvoiceCredited, got %v", err)
+	}
+
+	rest, err := invoiceService.CreditNote(first.ID, "退租", nil, testActor)
+	if err != nil || rest.Total != -645 || len(rest.Lines) != 2 {
+		t.Fatalf("unexpected full credit note: %+v, %v", rest, err)
+	}
+	if _, err := invoiceService.CreditNote(first.ID, "重复", nil, testActor); !errors.Is(err, ErrCreditExceedsAmount) {
+		t.Fatalf("expected fully credited invoice to be rejected, got %v", err)
+	}
+	if _, err := invoiceService.Void(rest.ID, "退租取消", testActor); err != nil {
+		t.Fatalf("void credit note: %v", err)
+	}
+	if inv, _ := invoiceService.GetByID(first.ID); inv.CreditedAmount != 545 {
+		t.Fatalf("voiding a credit note must restore the credited amount, got %v", inv.CreditedAmount)
+	}
+
+	// 发票的新建、开具、冲红与红字发票的作废都在事务中记入审计，被拒绝的操作不留下记录
+	wantAudits := map[uint][]string{
+		draft.ID: {model.AuditActionCreate, model.AuditActionDelete},
+		first.ID: {model.AuditActionCreate, model.AuditActionIssue, model.AuditActionCredit, model.AuditActionCredit},
+		note.ID:  {model.AuditActionCreate},
+		rest.ID:  {model.AuditActionCreate, model.AuditActionVoid},
+	}
+	for id, want := range wantAudits {
+		audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityInvoice, id)
+		if len(audits) != len(want) {
+			t.Fatalf("invoice %d: unexpected audit trail %+v", id, audits)
+		}
+		for i, a := range audits {
+			if a.Action != want[i] || a.ActorName != testActor.Name {
+				t.Fatalf("invoice %d audit %d: expected %s, got %+v", id, i, want[i], a)
+			}
+		}
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 545 {
+		t.Fatalf("voiding a credit note must void its account credit, got balance %v", balance)
+	}
+
+	_, data, err := invoiceService.RenderPDF(note.ID)
+	if err != nil || !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("unexpected pdf: %v", err)
+	}
+}
+
+func TestInvoiceNumberingConcurrent(t *testing.T) {
+	repos := newTestRepositories()
+	invoiceService := newTestInvoiceService(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	const n = 20
+	ids := make([]uint, n)
+	for i := range ids {
+		fee := mustCreatePeriodFee(t, repos, tenant.ID, "rent", fmt.Sprintf("P%02d", i), 1000)
+		invoice := &model.Invoice{TenantID: tenant.ID}
+		if err := invoiceService.Create(invoice, []uint{fee.ID}, testActor); err != nil {
+			t.Fatalf("create invoice: %v", err)
+		}
+		ids[i] = invoice.ID
+	}
+
+	numbers := make([]string, n)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if invoice, err := invoiceService.Issue(ids[i], testActor); err == nil {
+				numbers[i] = *invoice.InvoiceNo
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Strings(numbers)
+	for i, no := range numbers {
+		if no != expectedNo("INV", i+1) {
+			t.Fatalf("expected gap-free unique numbers, got %v", numbers)
+		}
+	}
+}
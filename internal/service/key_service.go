@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+const (
+	keyCacheTTL       = time.Minute
+	keyReloadThrottle = 5 * time.Second
+)
+
+var (
+	ErrUnknownKey          = errors.New("unknown signing key")
+	ErrRotationUnsupported = errors.New("HS256 模式不支持密钥轮换")
+)
+
+// KeyService 管理 JWT 签名密钥。HS256 使用配置中的共享密钥；
+// RS256 / EdDSA 的密钥存储在数据库中，按 rotation_interval 自动轮换，
+// 多副本共享同一组密钥，退役密钥在 rotation_overlap 内仍可验签。
+type KeyService struct {
+	keyRepo repository.SigningKeyRepository
+	config  *config.Config
+
+	rotateMu sync.Mutex
+	mu       sync.RWMutex
+	active   *utils.SigningKey
+	activeAt time.Time
+	keys     map[string]*utils.SigningKey
+	loadedAt time.Time
+}
+
+func NewKeyService(keyRepo repository.SigningKeyRepository, config *config.Config) *KeyService {
+	return &KeyService{
+		keyRepo: keyRepo,
+		config:  config,
+	}
+}
+
+func (s *KeyService) symmetric() bool {
+	return s.config.JWT.Algorithm == "" || s.config.JWT.Algorithm == utils.AlgHS256
+}
+
+// SigningKey 返回当前用于签发令牌的密钥，到期时自动轮换
+func (s *KeyService) SigningKey() (*utils.SigningKey, error) {
+	if s.symmetric() {
+		return utils.NewHMACKey(s.config.JWT.Secret), nil
+	}
+
+	if err := s.ensureLoaded(keyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	if s.rotationDue() {
+		s.rotateMu.Lock()
+		// 等锁期间可能已由其他请求完成轮换；其他副本的轮换由 keyRepo.Rotate 识别
+		if s.rotationDue() {
+			if _, err := s.rotate(s.rotationDueBefore()); err != nil {
+				s.rotateMu.Unlock()
+				return nil, err
+			}
+		}
+		s.rotateMu.Unlock()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active, nil
+}
+
+func (s *KeyService) rotationDue() bool {
+	interval, _ := time.ParseDuration(s.config.JWT.RotationInterval)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active == nil || (interval > 0 && time.Since(s.activeAt) > interval)
+}
+
+// rotationDueBefore 自动轮换的条件：没有生效密钥时任何生效密钥都可直接使用，
+// 否则只有创建时间早于 rotation_interval 的生效密钥需要轮换
+func (s *KeyService) rotationDueBefore() time.Time {
+	interval, _ := time.ParseDuration(s.config.JWT.RotationInterval)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.active == nil || interval <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-interval)
+}
+
+// VerificationKey 按 kid 查找验签密钥，供 utils.ParseToken 使用
+func (s *KeyService) VerificationKey(kid string) (*utils.SigningKey, error) {
+	if s.symmetric() {
+		return utils.NewHMACKey(s.config.JWT.Secret), nil
+	}
+
+	if err := s.ensureLoaded(keyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	s.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	// 其他副本可能刚完成轮换，限频重新加载一次
+	if err := s.ensureLoaded(keyReloadThrottle); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	key, ok = s.keys[kid]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Rotate 生成新的签名密钥并退役旧密钥
+func (s *KeyService) Rotate() (*model.SigningKey, error) {
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+	return s.rotate(time.Now())
+}
+
+// rotate 生成新密钥并在数据库中没有创建于 dueBefore 之后的生效密钥时写入。
+// rotateMu 只串行化本进程，多副本之间由 keyRepo.Rotate 的咨询锁串行化；
+// 其他副本已抢先轮换时不再写入，重新加载并返回当前生效的密钥
+func (s *KeyService) rotate(dueBefore time.Time) (*model.SigningKey, error) {
+	if s.symmetric() {
+		return nil, ErrRotationUnsupported
+	}
+
+	privatePEM, publicPEM, err := utils.GenerateKeyPair(s.config.JWT.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := utils.GenerateRandomToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &model.SigningKey{
+		KID:        kid,
+		Algorithm:  s.config.JWT.Algorithm,
+		PrivateKey: privatePEM,
+		PublicKey:  publicPEM,
+	}
+	rotated, err := s.keyRepo.Rotate(key, time.Now().Add(s.overlap()), dueBefore)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureLoaded(0); err != nil {
+		return nil, err
+	}
+	if !rotated {
+		zap.L().Info("jwt signing key already rotated by another instance")
+		keys, err := s.keyRepo.FindValid()
+		if err != nil {
+			return nil, err
+		}
+		for i := range keys {
+			if keys[i].RetiredAt == nil {
+				return &keys[i], nil
+			}
+		}
+		return nil, ErrUnknownKey
+	}
+
+	zap.L().Info("jwt signing key rotated", zap.String("kid", kid), zap.String("algorithm", key.Algorithm))
+	return key, nil
+}
+
+// JWKS 返回当前可用于验签的公钥集合
+func (s *KeyService) JWKS() (*utils.JWKS, error) {
+	jwks := &utils.JWKS{Keys: []utils.JWK{}}
+	if s.symmetric() {
+		return jwks, nil
+	}
+
+	if err := s.ensureLoaded(keyCacheTTL); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range s.keys {
+		if jwk, ok := utils.PublicJWK(key); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks, nil
+}
+
+func (s *KeyService) List() ([]model.SigningKey, error) {
+	return s.keyRepo.List()
+}
+
+// overlap 退役密钥的验签宽限期，至少覆盖一个访问令牌有效期
+func (s *KeyService) overlap() time.Duration {
+	overlap, _ := time.ParseDuration(s.config.JWT.RotationOverlap)
+	expire, _ := time.ParseDuration(s.config.JWT.Expire)
+	if overlap < expire {
+		return expire
+	}
+	return overlap
+}
+
+// ensureLoaded 缓存早于 maxAge 时从数据库重新加载密钥
+func (s *KeyService) ensureLoaded(maxAge time.Duration) error {
+	s.mu.RLock()
+	age := time.Since(s.loadedAt)
+	s.mu.RUnlock()
+
+	if age < maxAge {
+		return nil
+	}
+
+	records, err := s.keyRepo.FindValid()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*utils.SigningKey, len(records))
+	var active *utils.SigningKey
+	var activeAt time.Time
+	for _, record := range records {
+		privatePEM := ""
+		if record.RetiredAt == nil {
+			privatePEM = record.PrivateKey
+		}
+		key, err := utils.ParseKeyPair(record.KID, record.Algorithm, privatePEM, record.PublicKey)
+		if err != nil {
+			zap.L().Error("failed to parse signing key", zap.String("kid", record.KID), zap.Error(err))
+			continue
+		}
+		keys[record.KID] = key
+
+		// 记录按创建时间倒序，第一把未退役且算法匹配的即为当前签名密钥
+		if active == nil && record.RetiredAt == nil && record.Algorithm == s.config.JWT.Algorithm {
+			active, activeAt = key, record.CreatedAt
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.active = active
+	s.activeAt = activeAt
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,68 +9,163 @@ import (
 	"yuxialuozi_graduation_design_backend/internal/repository"
 )
 
+var ErrMaintenanceClosed = errors.New("工单已完成或已取消")
+
 type MaintenanceService struct {
-	maintenanceRepo *repository.MaintenanceRepository
-	tenantRepo      *repository.TenantRepository
+	maintenanceRepo repository.MaintenanceRepository
+	tenantRepo      repository.TenantRepository
+	uow             repository.UnitOfWork
 }
 
-func NewMaintenanceService(maintenanceRepo *repository.MaintenanceRepository, tenantRepo *repository.TenantRepository) *MaintenanceService {
+func NewMaintenanceService(maintenanceRepo repository.MaintenanceRepository, tenantRepo repository.TenantRepository, uow repository.UnitOfWork) *MaintenanceService {
 	return &MaintenanceService{
 		maintenanceRepo: maintenanceRepo,
 		tenantRepo:      tenantRepo,
+		uow:             uow,
 	}
 }
 
-func (s *MaintenanceService) Create(maintenance *model.Maintenance) error {
+// Create 新建工单，未指定工单号时自动生成；租户在门户提交的工单同样经此记录审计
+func (s *MaintenanceService) Create(maintenance *model.Maintenance, actor Actor) error {
 	if maintenance.TicketNo == "" {
 		maintenance.TicketNo = s.generateTicketNo()
 	}
-	return s.maintenanceRepo.Create(maintenance)
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if err := tx.Maintenances.Create(maintenance); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, maintenance.ID, model.AuditActionCreate, nil, maintenance)
+	})
 }
 
 func (s *MaintenanceService) GetByID(id uint) (*model.Maintenance, error) {
 	return s.maintenanceRepo.FindByID(id)
 }
 
-func (s *MaintenanceService) Update(maintenance *model.Maintenance) error {
-	return s.maintenanceRepo.Update(maintenance)
+// Update 锁定工单后保存修改，版本不一致时返回 ErrVersionConflict
+func (s *MaintenanceService) Update(maintenance *model.Maintenance, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Maintenances.FindByIDForUpdate(maintenance.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Maintenances.Update(maintenance); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, maintenance.ID, model.AuditActionUpdate, current, maintenance)
+	})
 }
 
-func (s *MaintenanceService) Delete(id uint) error {
-	return s.maintenanceRepo.Delete(id)
+func (s *MaintenanceService) Delete(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		maintenance, err := tx.Maintenances.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Maintenances.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, id, model.AuditActionDelete, maintenance, nil)
+	})
 }
 
-func (s *MaintenanceService) List(page, pageSize int, keyword, maintenanceType, status, priority string) ([]model.Maintenance, int64, error) {
-	return s.maintenanceRepo.List(page, pageSize, keyword, maintenanceType, status, priority)
+func (s *MaintenanceService) List(page, pageSize int, tenantID uint, keyword, maintenanceType, status, priority string) ([]model.Maintenance, int64, error) {
+	return s.maintenanceRepo.List(page, pageSize, tenantID, keyword, maintenanceType, status, priority)
 }
 
-func (s *MaintenanceService) Assign(id uint, assignee string) error {
-	maintenance, err := s.maintenanceRepo.FindByID(id)
-	if err != nil {
-		return err
-	}
+// Assign 指派维修人员并将工单置为处理中
+func (s *MaintenanceService) Assign(id uint, assignee string, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		maintenance, err := tx.Maintenances.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
 
-	maintenance.Assignee = assignee
-	maintenance.Status = "processing"
-	return s.maintenanceRepo.Update(maintenance)
+		before := *maintenance
+		maintenance.Assignee = assignee
+		maintenance.Status = "processing"
+		if err := tx.Maintenances.Update(maintenance); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, id, model.AuditActionAssign, &before, maintenance)
+	})
 }
 
-func (s *MaintenanceService) Complete(id uint, completedAt *time.Time) error {
-	maintenance, err := s.maintenanceRepo.FindByID(id)
-	if err != nil {
-		return err
-	}
+// Complete 锁定工单行后完成工单，已完成或已取消的工单不能再次完成
+func (s *MaintenanceService) Complete(id uint, completedAt *time.Time, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		maintenance, err := tx.Maintenances.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
 
-	now := time.Now()
-	if completedAt == nil {
-		completedAt = &now
-	}
+		if maintenance.Status == "completed" || maintenance.Status == "cancelled" {
+			return ErrMaintenanceClosed
+		}
+
+		now := time.Now()
+		if completedAt == nil {
+			completedAt = &now
+		}
 
-	maintenance.CompletedAt = completedAt
-	maintenance.Status = "completed"
-	return s.maintenanceRepo.Update(maintenance)
+		before := *maintenance
+		maintenance.CompletedAt = completedAt
+		maintenance.Status = "completed"
+		if err := tx.Maintenances.Update(maintenance); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, id, model.AuditActionComplete, &before, maintenance)
+	})
 }
 
 func (s *MaintenanceService) generateTicketNo() string {
 	return fmt.Sprintf("WX%s%04d", time.Now().Format("20060102"), time.Now().UnixNano()%10000)
 }
+
+func (s *MaintenanceService) ListTrash(page, pageSize int) ([]model.Maintenance, int64, error) {
+	return s.maintenanceRepo.ListTrashed(page, pageSize)
+}
+
+func (s *MaintenanceService) GetTrashedByID(id uint) (*model.Maintenance, error) {
+	return s.maintenanceRepo.FindTrashedByID(id)
+}
+
+// Restore 从回收站恢复工单并返回恢复后的工单，所属租户已删除时返回 ErrTenantDeleted
+func (s *MaintenanceService) Restore(id uint, actor Actor) (*model.Maintenance, error) {
+	var maintenance *model.Maintenance
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		before, err := tx.Maintenances.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Tenants.FindByID(before.TenantID); err != nil {
+			return ErrTenantDeleted
+		}
+
+		if err := tx.Maintenances.Restore(id); err != nil {
+			return err
+		}
+		if maintenance, err = tx.Maintenances.FindByID(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, id, model.AuditActionRestore, before, maintenance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return maintenance, nil
+}
+
+func (s *MaintenanceService) Purge(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		maintenance, err := tx.Maintenances.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Maintenances.Purge(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityMaintenance, id, model.AuditActionPurge, maintenance, nil)
+	})
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestMaintenanceMutationsAudited(t *testing.T) {
+	repos := newTestRepositories()
+	maintenanceService := NewMaintenanceService(repos.Maintenances, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	maintenance := &model.Maintenance{TenantID: tenant.ID, RoomNo: "A101", Type: "plumbing", Description: "漏水", Priority: "high", Status: "pending"}
+	if err := maintenanceService.Create(maintenance, testActor); err != nil {
+		t.Fatalf("create maintenance: %v", err)
+	}
+	if maintenance.TicketNo == "" {
+		t.Fatal("expected a generated ticket number")
+	}
+	if err := maintenanceService.Assign(maintenance.ID, "张师傅", testActor); err != nil {
+		t.Fatalf("assign maintenance: %v", err)
+	}
+	if err := maintenanceService.Complete(maintenance.ID, nil, testActor); err != nil {
+		t.Fatalf("complete maintenance: %v", err)
+	}
+	if err := maintenanceService.Complete(maintenance.ID, nil, testActor); !errors.Is(err, ErrMaintenanceClosed) {
+		t.Fatalf("expected ErrMaintenanceClosed, got %v", err)
+	}
+	if err := maintenanceService.Delete(maintenance.ID, testActor); err != nil {
+		t.Fatalf("delete maintenance: %v", err)
+	}
+	restored, err := maintenanceService.Restore(maintenance.ID, testActor)
+	if err != nil || restored.Status != "completed" || restored.Assignee != "张师傅" {
+		t.Fatalf("restore maintenance: %+v, %v", restored, err)
+	}
+	if err := maintenanceService.Delete(maintenance.ID, testActor); err != nil {
+		t.Fatalf("delete maintenance again: %v", err)
+	}
+	if err := maintenanceService.Purge(maintenance.ID, testActor); err != nil {
+		t.Fatalf("purge maintenance: %v", err)
+	}
+
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityMaintenance, maintenance.ID)
+	want := []string{
+		model.AuditActionCreate, model.AuditActionAssign, model.AuditActionComplete, model.AuditActionDelete,
+		model.AuditActionRestore, model.AuditActionDelete, model.AuditActionPurge,
+	}
+	if len(audits) != len(want) {
+		t.Fatalf("unexpected maintenance audit trail: %+v", audits)
+	}
+	for i, a := range audits {
+		if a.Action != want[i] || a.ActorName != testActor.Name {
+			t.Fatalf("audit %d: expected %s by %s, got %+v", i, want[i], testActor.Name, a)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+const recoveryCodeCount = 10
+
+var ErrInvalidMFACode = errors.New("验证码错误")
+
+type MFAService struct {
+	userRepo repository.UserRepository
+	config   *config.Config
+}
+
+func NewMFAService(userRepo repository.UserRepository, config *config.Config) *MFAService {
+	return &MFAService{
+		userRepo: userRepo,
+		config:   config,
+	}
+}
+
+type MFASetupResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"uri" example:"otpauth://totp/..."`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Setup 生成待确认的 TOTP 密钥，需调用 Enable 提交验证码后才生效
+func (s *MFAService) Setup(userID uint) (*MFASetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.New("已启用两步验证")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFAPendingSecret = secret
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &MFASetupResponse{
+		Secret: secret,
+		URI:    utils.TOTPURI(s.config.MFA.Issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 校验绑定中的验证码并启用两步验证，返回仅展示一次的恢复码
+func (s *MFAService) Enable(userID uint, code string) (*RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.New("已启用两步验证")
+	}
+	if user.MFAPendingSecret == "" {
+		return nil, errors.New("请先获取两步验证密钥")
+	}
+
+	step, ok := utils.ValidateTOTP(user.MFAPendingSecret, code, time.Now(), 1)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFAEnabled = true
+	user.MFASecret = user.MFAPendingSecret
+	user.MFAPendingSecret = ""
+	user.MFALastStep = step
+	user.MFARecoveryCodes = hashes
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable 关闭两步验证，需同时校验密码和验证码；策略要求启用的角色不允许关闭
+func (s *MFAService) Disable(userID uint, password, code string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return errors.New("未启用两步验证")
+	}
+	if s.config.MFA.IsRequired(user.Role) {
+		return errors.New("当前角色必须启用两步验证")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return errors.New("密码错误")
+	}
+	if !verifyMFACode(user, code) {
+		return ErrInvalidMFACode
+	}
+
+	clearMFA(user)
+	return s.userRepo.Update(user)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部失效
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) (*RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MFAEnabled {
+		return nil, errors.New("未启用两步验证")
+	}
+	if !verifyMFACode(user, code) {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFARecoveryCodes = hashes
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Reset 管理员为丢失设备的用户重置两步验证
+func (s *MFAService) Reset(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	clearMFA(user)
+	return s.userRepo.Update(user)
+}
+
+// verifyMFACode 校验 TOTP 验证码或恢复码。校验通过时会更新 user 的
+// MFALastStep 或移除已使用的恢复码，调用方需负责保存。
+func verifyMFACode(user *model.User, code string) bool {
+	code = strings.TrimSpace(code)
+
+	if step, ok := utils.ValidateTOTP(user.MFASecret, code, time.Now(), 1); ok {
+		if step <= user.MFALastStep {
+			return false
+		}
+		user.MFALastStep = step
+		return true
+	}
+
+	hash := utils.HashToken(strings.ToLower(code))
+	for i, h := range user.MFARecoveryCodes {
+		if h == hash {
+			user.MFARecoveryCodes = append(user.MFARecoveryCodes[:i:i], user.MFARecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func clearMFA(user *model.User) {
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	user.MFAPendingSecret = ""
+	user.MFARecoveryCodes = nil
+	user.MFALastStep = 0
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.GenerateRandomToken(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
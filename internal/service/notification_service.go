@@ -0,0 +1,31 @@
+package service
+
+import (
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+func (s *NotificationService) List(page, pageSize int, notificationType string, unreadOnly bool) ([]model.Notification, int64, error) {
+	return s.notificationRepo.List(page, pageSize, notificationType, unreadOnly)
+}
+
+// MarkRead 标记通知为已读并返回最新数据，重复标记保留首次阅读时间
+func (s *NotificationService) MarkRead(id uint) (*model.Notification, error) {
+	if _, err := s.notificationRepo.FindByID(id); err != nil {
+		return nil, err
+	}
+	if err := s.notificationRepo.MarkRead(id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.notificationRepo.FindByID(id)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+// OverdueResult 一次逾期检查中新标记逾期的费用数，以及新生成、金额增加的滞纳金笔数
+type OverdueResult struct {
+	Overdue          int `json:"overdue"`
+	PenaltiesCreated int `json:"penaltiesCreated"`
+	PenaltiesAccrued int `json:"penaltiesAccrued"`
+}
+
+type OverdueService struct {
+	feeRepo repository.FeeRepository
+	uow     repository.UnitOfWork
+	config  *config.Config
+}
+
+func NewOverdueService(feeRepo repository.FeeRepository, uow repository.UnitOfWork, cfg *config.Config) *OverdueService {
+	return &OverdueService{
+		feeRepo: feeRepo,
+		uow:     uow,
+		config:  cfg,
+	}
+}
+
+// Run 将超过到期日 overdue.grace_days 天仍未结清的费用标记为 overdue，并按费用类型的滞纳金规则
+// 为逾期费用生成或累计滞纳金。每笔逾期费用只有一笔滞纳金，重复执行时金额只增不减；
+// 各项变更以 system 为操作人记录审计。单笔费用失败只记录日志，下次执行时重试
+func (s *OverdueService) Run(now time.Time) (*OverdueResult, error) {
+	today := startOfDay(now)
+	grace := s.config.Overdue.GraceDays
+
+	due, err := s.feeRepo.FindDueBefore(today.AddDate(0, 0, -grace), "unpaid", "partially_paid")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OverdueResult{}
+	for i := range due {
+		if err := s.markOverdue(due[i].ID, result); err != nil {
+			zap.L().Warn("mark fee overdue failed", zap.Uint("fee_id", due[i].ID), zap.Error(err))
+		}
+	}
+
+	overdue, err := s.feeRepo.FindDueBefore(today, "overdue")
+	if err != nil {
+		return nil, err
+	}
+	for i := range overdue {
+		rule, ok := s.config.Overdue.LateFees[overdue[i].FeeType]
+		if !ok || overdue[i].PenaltyOfID != nil {
+			continue
+		}
+		if err := s.accrue(overdue[i].TenantID, overdue[i].ID, rule, today, result); err != nil {
+			zap.L().Warn("late fee accrual failed", zap.Uint("fee_id", overdue[i].ID), zap.Error(err))
+		}
+	}
+	return result, nil
+}
+
+// markOverdue 锁定费用并在其仍未结清时标记为 overdue，期间已缴清或已被其他副本标记的费用跳过
+func (s *OverdueService) markOverdue(feeID uint, result *OverdueResult) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		fee, err := tx.Fees.FindByIDForUpdate(feeID)
+		if err != nil {
+			return err
+		}
+		if fee.Status != "unpaid" && fee.Status != "partially_paid" {
+			return nil
+		}
+
+		before := *fee
+		fee.Status = "overdue"
+		if err := tx.Fees.Update(fee); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, systemActor, model.AuditEntityFee, fee.ID, model.AuditActionOverdue, &before, fee); err != nil {
+			return err
+		}
+		result.Overdue++
+		return nil
+	})
+}
+
+// accrue 锁定逾期费用后生成或更新其滞纳金，锁定保证多副本同时执行时只生成一笔。
+// 已减免或已删除的滞纳金不再累计；新生成或累计增加的滞纳金用租户的账户余额抵扣，
+// 因此先锁定租户再锁定费用，与开具贷项的加锁顺序一致
+func (s *OverdueService) accrue(tenantID, feeID uint, rule config.LateFeeRule, today time.Time, result *OverdueResult) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if _, err := tx.Tenants.FindByIDForUpdate(tenantID); err != nil {
+			return err
+		}
+		fee, err := tx.Fees.FindByIDForUpdate(feeID)
+		if err != nil {
+			return err
+		}
+		if fee.Status != "overdue" {
+			return nil
+		}
+
+		amount := lateFeeAmount(fee, rule, -daysUntil(today, fee.DueDate)-s.config.Overdue.GraceDays)
+		if amount <= 0 {
+			return nil
+		}
+
+		penalties, err := tx.Fees.FindPenalties([]uint{fee.ID})
+		if err != nil {
+			return err
+		}
+		if len(penalties) == 0 {
+			penalty := &model.Fee{
+				TenantID:    fee.TenantID,
+				RoomNo:      fee.RoomNo,
+				ContractID:  fee.ContractID,
+				FeeType:     model.FeeTypeLateFee,
+				Amount:      amount,
+				Period:      fee.Period,
+				DueDate:     today,
+				Status:      "unpaid",
+				PenaltyOfID: &fee.ID,
+			}
+			if err := tx.Fees.Create(penalty); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, systemActor, model.AuditEntityFee, penalty.ID, model.AuditActionCreate, nil, penalty); err != nil {
+				return err
+			}
+			if err := applyCreditToFee(tx, penalty, systemActor); err != nil {
+				return err
+			}
+			result.PenaltiesCreated++
+			return nil
+		}
+
+		penalty := penalties[0]
+		if penalty.DeletedAt.Valid || penalty.Status == "waived" || amount <= penalty.Amount {
+			return nil
+		}
+		before := penalty
+		penalty.Amount = amount
+		settleFee(&penalty, today)
+		if err := tx.Fees.Update(&penalty); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, systemActor, model.AuditEntityFee, penalty.ID, model.AuditActionAccrue, &before, &penalty); err != nil {
+			return err
+		}
+		if err := applyCreditToFee(tx, &penalty, systemActor); err != nil {
+			return err
+		}
+		result.PenaltiesAccrued++
+		return nil
+	})
+}
+
+// lateFeeAmount 按规则计算逾期费用的滞纳金：固定金额加上超过宽限期的天数乘以未收金额与日费率，
+// 再按金额上限和原费用金额的比例上限封顶
+func lateFeeAmount(fee *model.Fee, rule config.LateFeeRule, days int) float64 {
+	if days <= 0 {
+		return 0
+	}
+	amount := rule.FlatAmount + fee.Outstanding()*rule.DailyRate*float64(days)
+	if rule.MaxAmount > 0 {
+		amount = min(amount, rule.MaxAmount)
+	}
+	if rule.MaxRate > 0 {
+		amount = min(amount, fee.Amount*rule.MaxRate)
+	}
+	return roundMoney(amount)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestOverdueLateFees(t *testing.T) {
+	repos := newTestRepositories()
+	_, feeService := newTestPaymentServices(repos)
+	cfg := testConfig()
+	cfg.Overdue = config.OverdueConfig{
+		GraceDays: 5,
+		LateFees: map[string]config.LateFeeRule{
+			"rent": {DailyRate: 0.001, FlatAmount: 20, MaxRate: 0.1},
+		},
+	}
+	overdueService := NewOverdueService(repos.Fees, repos.UnitOfWork, cfg)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 10, 1))
+	water := mustCreateFee(t, feeService, tenant.ID, "water", 80, date(2026, 10, 1))
+
+	result, err := overdueService.Run(*date(2026, 10, 6))
+	if err != nil || result.Overdue != 0 {
+		t.Fatalf("fees within the grace period must not be overdue: %+v, %v", result, err)
+	}
+
+	result, _ = overdueService.Run(*date(2026, 10, 7))
+	if result.Overdue != 2 || result.PenaltiesCreated != 1 {
+		t.Fatalf("unexpected first overdue run: %+v", result)
+	}
+	result, _ = overdueService.Run(*date(2026, 10, 7))
+	if result.Overdue != 0 || result.PenaltiesCreated != 0 || result.PenaltiesAccrued != 0 {
+		t.Fatalf("overdue run must be idempotent: %+v", result)
+	}
+
+	penalties, _ := repos.Fees.FindPenalties([]uint{rent.ID, water.ID})
+	if len(penalties) != 1 {
+		t.Fatalf("expected one late fee for the rent only, got %+v", penalties)
+	}
+	penalty := penalties[0]
+	if penalty.FeeType != model.FeeTypeLateFee || penalty.Amount != 23 || penalty.TenantID != tenant.ID {
+		t.Fatalf("unexpected late fee: %+v", penalty)
+	}
+
+	if err := feeService.Pay(rent.ID, &model.Payment{Amount: 1000, ReceivedAt: *date(2026, 10, 8)}, testActor); err != nil {
+		t.Fatalf("partial payment: %v", err)
+	}
+	if f, _ := feeService.GetByID(rent.ID); f.Status != "overdue" || f.Balance != 2000 {
+		t.Fatalf("partially paid fee must stay overdue: %+v", f)
+	}
+
+	result, _ = overdueService.Run(*date(2026, 10, 11))
+	if result.PenaltiesAccrued != 1 {
+		t.Fatalf("expected the late fee to accrue: %+v", result)
+	}
+	if f, _ := feeService.GetByID(penalty.ID); f.Amount != 30 {
+		t.Fatalf("expected 20 + 2000 * 0.1%% * 5 = 30, got %v", f.Amount)
+	}
+
+	overdue, total, err := feeService.ListOverdue(1, 10, tenant.ID, "", *date(2026, 10, 11))
+	if err != nil || total != 2 {
+		t.Fatalf("unexpected overdue list: %+v, %v", overdue, err)
+	}
+	if overdue[0].DaysPastDue != 10 || overdue[0].LateFee == nil || overdue[0].LateFee.ID != penalty.ID {
+		t.Fatalf("unexpected overdue rent: %+v", overdue[0])
+	}
+
+	overdueService.Run(*date(2027, 6, 1))
+	if f, _ := feeService.GetByID(penalty.ID); f.Amount != 300 {
+		t.Fatalf("late fee must be capped at 10%% of the rent, got %v", f.Amount)
+	}
+
+	if _, err := feeService.Waive(rent.ID, "误操作", testActor); !errors.Is(err, ErrFeeNotPenalty) {
+		t.Fatalf("expected ErrFeeNotPenalty, got %v", err)
+	}
+	waived, err := feeService.Waive(penalty.ID, "租户困难，经批准减免", testActor)
+	if err != nil || waived.Status != "waived" || waived.WaivedBy != testActor.Name || waived.WaivedAt == nil {
+		t.Fatalf("unexpected waived fee: %+v, %v", waived, err)
+	}
+	if _, err := feeService.Waive(penalty.ID, "重复减免", testActor); !errors.Is(err, ErrFeeWaived) {
+		t.Fatalf("expected ErrFeeWaived, got %v", err)
+	}
+	if err := feeService.Pay(penalty.ID, &model.Payment{}, testActor); !errors.Is(err, ErrFeeWaived) {
+		t.Fatalf("expected ErrFeeWaived for payment, got %v", err)
+	}
+
+	result, _ = overdueService.Run(*date(2027, 7, 1))
+	if result.PenaltiesCreated != 0 || result.PenaltiesAccrued != 0 {
+		t.Fatalf("waived late fee must not accrue or be recreated: %+v", result)
+	}
+
+	// 逾期标记与滞纳金的生成、累计以 system 记录审计，费用的新建与减免记录操作人
+	type audited struct{ action, actor string }
+	wantAudits := map[uint][]audited{
+		water.ID: {{model.AuditActionCreate, testActor.Name}, {model.AuditActionOverdue, systemActor.Name}},
+		penalty.ID: {
+			{model.AuditActionCreate, systemActor.Name},
+			{model.AuditActionAccrue, systemActor.Name},
+			{model.AuditActionOverdue, systemActor.Name},
+			{model.AuditActionAccrue, systemActor.Name},
+			{model.AuditActionWaive, testActor.Name},
+		},
+	}
+	for id, want := range wantAudits {
+		audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityFee, id)
+		if len(audits) != len(want) {
+			t.Fatalf("fee %d: expected audits %v, got %+v", id, want, audits)
+		}
+		for i, a := range audits {
+			if a.Action != want[i].action || a.ActorName != want[i].actor {
+				t.Fatalf("fee %d: unexpected audit %d: %+v", id, i, a)
+			}
+		}
+	}
+}
+
+func TestOverdueAccrualAppliesCredit(t *testing.T) {
+	repos := newTestRepositories()
+	paymentService, feeService := newTestPaymentServices(repos)
+	cfg := testConfig()
+	cfg.Overdue = config.OverdueConfig{
+		GraceDays: 5,
+		LateFees:  map[string]config.LateFeeRule{"rent": {DailyRate: 0.001, FlatAmount: 20, MaxRate: 0.1}},
+	}
+	overdueService := NewOverdueService(repos.Fees, repos.UnitOfWork, cfg)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 10, 1))
+
+	overdueService.Run(*date(2026, 10, 7))
+	penalties, _ := repos.Fees.FindPenalties([]uint{rent.ID})
+	if len(penalties) != 1 || penalties[0].Amount != 23 {
+		t.Fatalf("unexpected late fee: %+v", penalties)
+	}
+	penalty := penalties[0]
+
+	// 只缴清滞纳金，多缴的部分转入账户余额
+	payment := &model.Payment{
+		TenantID:    tenant.ID,
+		Amount:      600,
+		Method:      model.PaymentMethodCash,
+		Allocations: []model.PaymentAllocation{{FeeID: penalty.ID, Amount: 23}},
+	}
+	if err := paymentService.Create(payment, testActor); err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+
+	// 累计增加的滞纳金同样用账户余额抵扣
+	overdueService.Run(*date(2026, 10, 11))
+	f, _ := feeService.GetByID(penalty.ID)
+	if f.Amount != 35 || f.PaidAmount != 35 || f.Status != "paid" {
+		t.Fatalf("expected the accrued late fee to be settled from the credit: %+v", f)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 565 {
+		t.Fatalf("expected credit balance 565, got %v", balance)
+	}
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityFee, penalty.ID)
+	if last := audits[len(audits)-1]; last.Action != model.AuditActionCredit || last.ActorName != systemActor.Name {
+		t.Fatalf("expected the credit application to be audited: %+v", last)
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+var (
+	ErrInvalidPayment        = errors.New("收款信息无效")
+	ErrPaymentExceedsBalance = errors.New("收款金额超过费用未收金额")
+	ErrPaymentReversed       = errors.New("该收款已冲正")
+	ErrPaymentRefunded       = errors.New("该收款已有退款，不能冲正")
+	ErrPaymentCreditUsed     = errors.New("该收款转入账户的余额已被使用，不能冲正")
+)
+
+type PaymentService struct {
+	paymentRepo repository.PaymentRepository
+	uow         repository.UnitOfWork
+}
+
+func NewPaymentService(paymentRepo repository.PaymentRepository, uow repository.UnitOfWork) *PaymentService {
+	return &PaymentService{
+		paymentRepo: paymentRepo,
+		uow:         uow,
+	}
+}
+
+// Create 登记一笔收款并分配到费用。未指定分配明细时按到期日从早到晚依次冲抵该租户未结清的费用；
+// 未分配的部分转入租户账户余额。各费用按已收金额更新为 partially_paid 或 paid
+func (s *PaymentService) Create(payment *model.Payment, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		return recordPayment(tx, payment, actor)
+	})
+}
+
+func (s *PaymentService) GetByID(id uint) (*model.Payment, error) {
+	return s.paymentRepo.FindByID(id)
+}
+
+func (s *PaymentService) List(page, pageSize int, tenantID uint, method, status string, from, to *time.Time) ([]model.Payment, int64, error) {
+	return s.paymentRepo.List(page, pageSize, tenantID, method, status, from, to)
+}
+
+// ListByFee 分配到该费用的全部收款，包括已冲正的收款
+func (s *PaymentService) ListByFee(feeID uint) ([]model.Payment, error) {
+	return s.paymentRepo.FindByFee(feeID)
+}
+
+// Reverse 冲正收款：退回分配到各费用的金额并重新计算费用状态，转入账户余额的部分一并扣回，
+// 收款保留为 reversed 状态，收据随之作废。每笔费用的变更记录审计；
+// 已有退款的收款，以及转入的余额已被抵扣或退还的收款不能冲正
+func (s *PaymentService) Reverse(id uint, reason string, actor Actor) (*model.Payment, error) {
+	var payment *model.Payment
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Payments.FindByID(id)
+		if err != nil {
+			return err
+		}
+		// 先锁定租户再锁定收款与费用，与余额抵扣、退款的加锁顺序一致
+		if _, err := tx.Tenants.FindByIDForUpdate(current.TenantID); err != nil {
+			return err
+		}
+		payment, err = tx.Payments.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if payment.Status == model.PaymentStatusReversed {
+			return ErrPaymentReversed
+		}
+		before := *payment
+		refunded, err := tx.Accounts.SumRefundsByPayment(payment.ID)
+		if err != nil {
+			return err
+		}
+		if refunded > 0 {
+			return ErrPaymentRefunded
+		}
+		if payment.Credited > 0 {
+			balance, err := tx.Accounts.CreditBalance(payment.TenantID)
+			if err != nil {
+				return err
+			}
+			if roundMoney(balance) < payment.Credited {
+				return ErrPaymentCreditUsed
+			}
+		}
+
+		for _, a := range payment.Allocations {
+			fee, err := tx.Fees.FindByIDForUpdate(a.FeeID)
+			if err != nil {
+				return err
+			}
+			before := *fee
+			fee.PaidAmount = roundMoney(fee.PaidAmount - a.Amount)
+			settleFee(fee, payment.ReceivedAt)
+			if err := tx.Fees.Update(fee); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionReverse, &before, fee); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		payment.Status = model.PaymentStatusReversed
+		payment.ReversedAt = &now
+		payment.ReversedBy = actor.Name
+		payment.ReversalReason = reason
+		if err := tx.Payments.Update(payment); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityPayment, payment.ID, model.AuditActionReverse, &before, payment); err != nil {
+			return err
+		}
+		return voidReceipt(tx, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// recordPayment 在事务中校验并保存收款，同时更新所分配费用的已收金额与状态、记录费用与收款的审计并开具收据，
+// 未分配的部分记为 Credited 转入账户余额。先锁定租户再按 ID 顺序锁定费用，与余额抵扣的加锁顺序一致
+func recordPayment(tx *repository.Tx, payment *model.Payment, actor Actor) error {
+	payment.Amount = roundMoney(payment.Amount)
+	if payment.Amount <= 0 {
+		return fmt.Errorf("%w：收款金额须大于 0", ErrInvalidPayment)
+	}
+	if !model.IsPaymentMethod(payment.Method) {
+		return fmt.Errorf("%w：不支持的收款方式 %s", ErrInvalidPayment, payment.Method)
+	}
+	if _, err := tx.Tenants.FindByIDForUpdate(payment.TenantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w：租户不存在", ErrInvalidPayment)
+		}
+		return err
+	}
+	if payment.ReceivedAt.IsZero() {
+		payment.ReceivedAt = time.Now()
+	}
+
+	if len(payment.Allocations) == 0 {
+		allocations, err := autoAllocate(tx, payment)
+		if err != nil {
+			return err
+		}
+		payment.Allocations = allocations
+	}
+
+	allocations := payment.Allocations
+	sort.SliceStable(allocations, func(i, j int) bool { return allocations[i].FeeID < allocations[j].FeeID })
+	var total float64
+	for i := range allocations {
+		a := &allocations[i]
+		a.Amount = roundMoney(a.Amount)
+		if a.Amount <= 0 {
+			return fmt.Errorf("%w：分配金额须大于 0", ErrInvalidPayment)
+		}
+		if i > 0 && allocations[i-1].FeeID == a.FeeID {
+			return fmt.Errorf("%w：费用 %d 重复分配", ErrInvalidPayment, a.FeeID)
+		}
+
+		fee, err := tx.Fees.FindByIDForUpdate(a.FeeID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w：费用 %d 不存在", ErrInvalidPayment, a.FeeID)
+		}
+		if err != nil {
+			return err
+		}
+		if fee.TenantID != payment.TenantID {
+			return fmt.Errorf("%w：费用 %d 不属于该租户", ErrInvalidPayment, a.FeeID)
+		}
+		if fee.Status == "waived" {
+			return fmt.Errorf("%w：费用 %d 已减免", ErrInvalidPayment, a.FeeID)
+		}
+		if a.Amount > fee.Outstanding() {
+			return fmt.Errorf("%w：费用 %d 未收金额为 %.2f", ErrPaymentExceedsBalance, fee.ID, fee.Outstanding())
+		}
+
+		before := *fee
+		fee.PaidAmount = roundMoney(fee.PaidAmount + a.Amount)
+		settleFee(fee, payment.ReceivedAt)
+		if err := tx.Fees.Update(fee); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, model.AuditEntityFee, fee.ID, model.AuditActionPay, &before, fee); err != nil {
+			return err
+		}
+		total = roundMoney(total + a.Amount)
+	}
+	if total > payment.Amount {
+		return fmt.Errorf("%w：分配金额合计 %.2f 超过收款金额 %.2f", ErrInvalidPayment, total, payment.Amount)
+	}
+
+	payment.Credited = roundMoney(payment.Amount - total)
+	payment.Status = model.PaymentStatusCompleted
+	payment.OperatorID = actor.ID
+	payment.OperatorName = actor.Name
+	if err := tx.Payments.Create(payment); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, actor, model.AuditEntityPayment, payment.ID, model.AuditActionCreate, nil, payment); err != nil {
+		return err
+	}
+	return issueReceipt(tx, payment, actor)
+}
+
+// autoAllocate 按到期日从早到晚将收款分配到租户未结清的费用，超出未收金额合计的部分不分配
+func autoAllocate(tx *repository.Tx, payment *model.Payment) ([]model.PaymentAllocation, error) {
+	fees, err := tx.Fees.FindOutstanding(payment.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := payment.Amount
+	var allocations []model.PaymentAllocation
+	for _, fee := range fees {
+		if remaining <= 0 {
+			break
+		}
+		amount := min(remaining, fee.Outstanding())
+		allocations = append(allocations, model.PaymentAllocation{FeeID: fee.ID, Amount: amount})
+		remaining = roundMoney(remaining - amount)
+	}
+	return allocations, nil
+}
+
+// settleFee 按已收金额更新费用状态：结清为 paid 并记录结清日期；未结清时已标记逾期的保持 overdue，
+// 否则部分收取为 partially_paid，尚未收取为 unpaid
+func settleFee(fee *model.Fee, receivedAt time.Time) {
+	switch {
+	case fee.PaidAmount >= fee.Amount && fee.Amount > 0:
+		fee.Status = "paid"
+		paidDate := receivedAt
+		fee.PaidDate = &paidDate
+	case fee.Status == "overdue":
+		fee.PaidDate = nil
+	case fee.PaidAmount > 0:
+		fee.Status = "partially_paid"
+		fee.PaidDate = nil
+	default:
+		fee.Status = "unpaid"
+		fee.PaidDate = nil
+	}
+	fee.Balance = fee.Outstanding()
+}
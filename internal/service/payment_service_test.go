@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+)
+
+func mustCreateFee(t *testing.T, feeService *FeeService, tenantID uint, feeType string, amount float64, dueDate *time.Time) *model.Fee {
+	t.Helper()
+	fee := &model.Fee{TenantID: tenantID, FeeType: feeType, Amount: amount}
+	if dueDate != nil {
+		fee.DueDate = *dueDate
+	}
+	if err := feeService.Create(fee, testActor); err != nil {
+		t.Fatalf("create fee: %v", err)
+	}
+	return fee
+}
+
+func newTestPaymentServices(repos *storage.Repositories) (*PaymentService, *FeeService) {
+	return NewPaymentService(repos.Payments, repos.UnitOfWork), NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+}
+
+func TestPaymentAllocationAndReversal(t *testing.T) {
+	repos := newTestRepositories()
+	paymentService, feeService := newTestPaymentServices(repos)
+	reportService := NewReportService(repos.Fees, repos.Rooms, repos.Maintenances, repos.Tenants, repos.Contracts)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, nil)
+	water := mustCreateFee(t, feeService, tenant.ID, "water", 120.5, nil)
+
+	if err := feeService.Pay(rent.ID, &model.Payment{Amount: 1000, ReceivedAt: *date(2026, 10, 3)}, testActor); err != nil {
+		t.Fatalf("partial payment: %v", err)
+	}
+	got, _ := feeService.GetByID(rent.ID)
+	if got.Status != "partially_paid" || got.PaidAmount != 1000 || got.Balance != 2000 || got.PaidDate != nil {
+		t.Fatalf("unexpected fee after partial payment: %+v", got)
+	}
+
+	payment := &model.Payment{
+		TenantID:   tenant.ID,
+		Amount:     2120.5,
+		Method:     model.PaymentMethodBankTransfer,
+		Reference:  "BT-001",
+		ReceivedAt: *date(2026, 10, 8),
+		Allocations: []model.PaymentAllocation{
+			{FeeID: water.ID, Amount: 120.5},
+			{FeeID: rent.ID, Amount: 2000},
+		},
+	}
+	if err := paymentService.Create(payment, testActor); err != nil {
+		t.Fatalf("record payment: %v", err)
+	}
+	if payment.OperatorName != testActor.Name || payment.Status != model.PaymentStatusCompleted {
+		t.Fatalf("unexpected payment: %+v", payment)
+	}
+	got, _ = feeService.GetByID(rent.ID)
+	if got.Status != "paid" || got.Balance != 0 || got.PaidDate == nil || !got.PaidDate.Equal(*date(2026, 10, 8)) {
+		t.Fatalf("rent must be settled by the second payment: %+v", got)
+	}
+
+	report, _ := reportService.GetIncomeReport(*date(2026, 10, 1), *date(2026, 10, 31), "month")
+	if report.Total != 3120.5 {
+		t.Fatalf("expected income 3120.5, got %v", report.Total)
+	}
+
+	if _, err := paymentService.Reverse(payment.ID, "银行退票", testActor); err != nil {
+		t.Fatalf("reverse payment: %v", err)
+	}
+	if _, err := paymentService.Reverse(payment.ID, "重复冲正", testActor); !errors.Is(err, ErrPaymentReversed) {
+		t.Fatalf("expected ErrPaymentReversed, got %v", err)
+	}
+	got, _ = feeService.GetByID(rent.ID)
+	if got.Status != "partially_paid" || got.PaidAmount != 1000 || got.PaidDate != nil {
+		t.Fatalf("reversal must restore the partial balance: %+v", got)
+	}
+	if w, _ := feeService.GetByID(water.ID); w.Status != "unpaid" || w.PaidAmount != 0 {
+		t.Fatalf("reversal must restore the water fee: %+v", w)
+	}
+
+	report, _ = reportService.GetIncomeReport(*date(2026, 10, 1), *date(2026, 10, 31), "month")
+	if report.Total != 1000 {
+		t.Fatalf("reversed payment must not count as income, got %v", report.Total)
+	}
+
+	history, err := paymentService.ListByFee(rent.ID)
+	if err != nil || len(history) != 2 || history[1].Status != model.PaymentStatusReversed {
+		t.Fatalf("unexpected fee payment history: %+v, %v", history, err)
+	}
+
+	// 费用的新建、每次分配与冲正都在费用上留下审计记录
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityFee, rent.ID)
+	var actions []string
+	for _, a := range audits {
+		if a.ActorName != testActor.Name {
+			t.Fatalf("unexpected audit actor: %+v", a)
+		}
+		actions = append(actions, a.Action)
+	}
+	if len(actions) != 4 || actions[0] != model.AuditActionCreate || actions[1] != model.AuditActionPay ||
+		actions[2] != model.AuditActionPay || actions[3] != model.AuditActionReverse {
+		t.Fatalf("unexpected fee audit trail: %v", actions)
+	}
+
+	// 收款的登记与冲正同样在事务中记录审计
+	paymentAudits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityPayment, payment.ID)
+	if len(paymentAudits) != 2 || paymentAudits[0].Action != model.AuditActionCreate || paymentAudits[1].Action != model.AuditActionReverse {
+		t.Fatalf("unexpected payment audit trail: %+v", paymentAudits)
+	}
+
+	if err := feeService.Delete(rent.ID, testActor); !errors.Is(err, ErrFeeHasPayments) {
+		t.Fatalf("expected ErrFeeHasPayments, got %v", err)
+	}
+}
+
+func TestPaymentAutoAllocation(t *testing.T) {
+	repos := newTestRepositories()
+	paymentService, feeService := newTestPaymentServices(repos)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	later := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 11, 1))
+	earlier := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 10, 1))
+
+	payment := &model.Payment{TenantID: tenant.ID, Amount: 4000, Method: model.PaymentMethodCash}
+	if err := paymentService.Create(payment, testActor); err != nil {
+		t.Fatalf("record payment: %v", err)
+	}
+	if len(payment.Allocations) != 2 {
+		t.Fatalf("expected the payment to cover two fees, got %+v", payment.Allocations)
+	}
+	if f, _ := feeService.GetByID(earlier.ID); f.Status != "paid" {
+		t.Fatalf("earliest fee must be settled first: %+v", f)
+	}
+	if f, _ := feeService.GetByID(later.ID); f.Status != "partially_paid" || f.Balance != 2000 {
+		t.Fatalf("later fee must receive the remainder: %+v", f)
+	}
+
+	// 超出未结清费用的部分转入账户余额
+	over := &model.Payment{TenantID: tenant.ID, Amount: 2500, Method: model.PaymentMethodCash}
+	if err := paymentService.Create(over, testActor); err != nil {
+		t.Fatalf("record overpayment: %v", err)
+	}
+	if len(over.Allocations) != 1 || over.Credited != 500 {
+		t.Fatalf("expected 2000 allocated and 500 credited, got %+v", over)
+	}
+	if f, _ := feeService.GetByID(later.ID); f.Status != "paid" {
+		t.Fatalf("later fee must be settled: %+v", f)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 500 {
+		t.Fatalf("expected credit balance 500, got %v", balance)
+	}
+
+	tooMuch := &model.Payment{
+		TenantID:    tenant.ID,
+		Amount:      300,
+		Method:      model.PaymentMethodCash,
+		Allocations: []model.PaymentAllocation{{FeeID: mustCreateFee(t, feeService, tenant.ID, "water", 80, nil).ID, Amount: 400}},
+	}
+	if err := paymentService.Create(tooMuch, testActor); !errors.Is(err, ErrPaymentExceedsBalance) {
+		t.Fatalf("expected ErrPaymentExceedsBalance, got %v", err)
+	}
+
+	// 新费用已抵扣转入的余额，收款不能再冲正；剩余余额可原路退回
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 420 {
+		t.Fatalf("expected the water fee to be settled from the credit, got balance %v", balance)
+	}
+	if _, err := paymentService.Reverse(over.ID, "银行退票", testActor); !errors.Is(err, ErrPaymentCreditUsed) {
+		t.Fatalf("expected ErrPaymentCreditUsed, got %v", err)
+	}
+	refund := &model.Refund{TenantID: tenant.ID, PaymentID: &over.ID, Amount: 420, Method: model.PaymentMethodCash, Reason: "退还多缴款"}
+	if err := newTestAccountService(repos).CreateRefund(refund, testActor); err != nil {
+		t.Fatalf("refund overpayment: %v", err)
+	}
+	if balance, _ := repos.Accounts.CreditBalance(tenant.ID); balance != 0 {
+		t.Fatalf("expected empty credit balance after refund, got %v", balance)
+	}
+
+	if err := feeService.Update(&model.Fee{ID: later.ID, TenantID: tenant.ID, Amount: 500, PaidAmount: 1000}, testActor); !errors.Is(err, ErrFeeAmountBelowPaid) {
+		t.Fatalf("expected ErrFeeAmountBelowPaid, got %v", err)
+	}
+}
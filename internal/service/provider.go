@@ -5,11 +5,24 @@ import (
 )
 
 var ProviderSet = wire.NewSet(
+	NewKeyService,
 	NewAuthService,
+	NewUserService,
+	NewMFAService,
+	NewAPIKeyService,
+	NewAuditService,
 	NewTenantService,
 	NewContractService,
+	NewContractExpiryService,
+	NewOverdueService,
 	NewRoomService,
 	NewFeeService,
 	NewMaintenanceService,
 	NewReportService,
+	NewNotificationService,
+	NewBillingService,
+	NewPaymentService,
+	NewInvoiceService,
+	NewReceiptService,
+	NewAccountService,
 )
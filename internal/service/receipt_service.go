@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/pkg/pdf"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+// receiptSeries 收据编号系列
+const receiptSeries = "RC"
+
+var paymentMethodNames = map[string]string{
+	model.PaymentMethodCash:         "现金",
+	model.PaymentMethodBankTransfer: "银行转账",
+	model.PaymentMethodWechat:       "微信",
+	model.PaymentMethodAlipay:       "支付宝",
+	model.PaymentMethodOther:        "其他",
+}
+
+// paymentMethodName 收款方式的中文名称，未知方式原样返回
+func paymentMethodName(method string) string {
+	if name, ok := paymentMethodNames[method]; ok {
+		return name
+	}
+	return method
+}
+
+type ReceiptService struct {
+	receiptRepo repository.ReceiptRepository
+	paymentRepo repository.PaymentRepository
+	config      *config.Config
+}
+
+func NewReceiptService(receiptRepo repository.ReceiptRepository, paymentRepo repository.PaymentRepository, cfg *config.Config) *ReceiptService {
+	return &ReceiptService{
+		receiptRepo: receiptRepo,
+		paymentRepo: paymentRepo,
+		config:      cfg,
+	}
+}
+
+// issueReceipt 在登记收款的事务中开具收据，编号按开具年度连续分配
+func issueReceipt(tx *repository.Tx, payment *model.Payment, actor Actor) error {
+	now := time.Now()
+	seq, err := tx.Receipts.NextNumber(receiptSeries, now.Year())
+	if err != nil {
+		return err
+	}
+	receipt := &model.Receipt{
+		ReceiptNo: documentNo(receiptSeries, now.Year(), seq),
+		PaymentID: payment.ID,
+		TenantID:  payment.TenantID,
+		Amount:    payment.Amount,
+		Status:    model.ReceiptStatusValid,
+		IssuedAt:  now,
+		IssuedBy:  actor.Name,
+	}
+	if err := tx.Receipts.Create(receipt); err != nil {
+		return err
+	}
+	payment.Receipt = receipt
+	return nil
+}
+
+// voidReceipt 在冲正收款的事务中作废其收据，作废原因与冲正原因一致
+func voidReceipt(tx *repository.Tx, payment *model.Payment) error {
+	receipt, err := tx.Receipts.FindByPayment(payment.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	receipt.Status = model.ReceiptStatusVoid
+	receipt.VoidedAt = payment.ReversedAt
+	receipt.VoidedBy = payment.ReversedBy
+	receipt.VoidReason = payment.ReversalReason
+	if err := tx.Receipts.Update(receipt); err != nil {
+		return err
+	}
+	payment.Receipt = receipt
+	return nil
+}
+
+func (s *ReceiptService) GetByID(id uint) (*model.Receipt, error) {
+	return s.receiptRepo.FindByID(id)
+}
+
+func (s *ReceiptService) GetByPayment(paymentID uint) (*model.Receipt, error) {
+	return s.receiptRepo.FindByPayment(paymentID)
+}
+
+func (s *ReceiptService) List(page, pageSize int, tenantID uint, status, receiptNo string) ([]model.Receipt, int64, error) {
+	return s.receiptRepo.List(page, pageSize, tenantID, status, receiptNo)
+}
+
+// RenderPDF 生成收据的 PDF 并累加打印次数，第二次起的打印在标题中注明补打，已作废的收据注明作废
+func (s *ReceiptService) RenderPDF(id uint) (*model.Receipt, []byte, error) {
+	receipt, err := s.receiptRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	payment, err := s.paymentRepo.FindByID(receipt.PaymentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := renderReceipt(s.config.Company, receipt, payment)
+	now := time.Now()
+	if err := s.receiptRepo.RecordPrint(receipt.ID, now); err != nil {
+		return nil, nil, err
+	}
+	receipt.PrintCount++
+	receipt.LastPrintedAt = &now
+	return receipt, data, nil
+}
+
+func renderReceipt(company config.CompanyConfig, receipt *model.Receipt, payment *model.Payment) []byte {
+	title := "收 款 收 据"
+	switch {
+	case receipt.Status == model.ReceiptStatusVoid:
+		title += "（已作废）"
+	case receipt.PrintCount > 0:
+		title += "（补打）"
+	}
+
+	doc := pdf.New()
+	y := drawDocumentHeader(doc, company, title)
+
+	method := paymentMethodName(payment.Method)
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, "收据编号："+receipt.ReceiptNo)
+	doc.Text(docRight, y, 10, pdf.AlignRight, "收款日期："+payment.ReceivedAt.Format("2006-01-02"))
+	y += 18
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("交款人："+receipt.TenantName, 10, 300))
+	doc.Text(docRight, y, 10, pdf.AlignRight, "收款方式："+method)
+	if payment.Reference != "" {
+		y += 18
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("交易流水号："+payment.Reference, 10, docRight-docLeft))
+	}
+	y += 24
+
+	doc.Line(docLeft, y-13, docRight, y-13, 0.5)
+	doc.Text(docLeft, y, 9, pdf.AlignLeft, "收费项目")
+	doc.Text(220, y, 9, pdf.AlignLeft, "房间")
+	doc.Text(320, y, 9, pdf.AlignLeft, "账期")
+	doc.Text(docRight, y, 9, pdf.AlignRight, "金额（元）")
+	doc.Line(docLeft, y+6, docRight, y+6, 0.5)
+	y += 22
+	for _, a := range payment.Allocations {
+		if y > pdf.PageHeight-120 {
+			doc.AddPage()
+			y = 70
+		}
+		doc.Text(docLeft, y, 9, pdf.AlignLeft, feeTypeName(a.FeeType))
+		doc.Text(220, y, 9, pdf.AlignLeft, pdf.Truncate(a.RoomNo, 9, 90))
+		doc.Text(320, y, 9, pdf.AlignLeft, pdf.Truncate(a.Period, 9, 100))
+		doc.Text(docRight, y, 9, pdf.AlignRight, formatMoney(a.Amount))
+		y += 18
+	}
+	if payment.Credited > 0 {
+		doc.Text(docLeft, y, 9, pdf.AlignLeft, "预存（转入账户余额）")
+		doc.Text(docRight, y, 9, pdf.AlignRight, formatMoney(payment.Credited))
+		y += 18
+	}
+	doc.Line(docLeft, y-10, docRight, y-10, 0.5)
+	y += 6
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, "合计（小写）：￥"+formatMoney(receipt.Amount))
+	y += 18
+	doc.Text(docLeft, y, 10, pdf.AlignLeft, "合计（大写）：人民币"+utils.AmountInWords(receipt.Amount))
+	y += 24
+
+	if payment.Remark != "" {
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("备注："+payment.Remark, 10, docRight-docLeft))
+		y += 18
+	}
+	if receipt.Status == model.ReceiptStatusVoid {
+		doc.Text(docLeft, y, 10, pdf.AlignLeft, pdf.Truncate("作废原因："+receipt.VoidReason, 10, docRight-docLeft))
+		y += 18
+	}
+	operator := payment.OperatorName
+	if operator == "" {
+		operator = "—"
+	}
+	doc.Text(docLeft, y+12, 10, pdf.AlignLeft, "开具日期："+receipt.IssuedAt.Format("2006-01-02"))
+	doc.Text(docRight, y+12, 10, pdf.AlignRight, "收款人："+operator)
+	return doc.Bytes()
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/pkg/utils"
+)
+
+func TestPaymentReceipts(t *testing.T) {
+	repos := newTestRepositories()
+	paymentService, feeService := newTestPaymentServices(repos)
+	receiptService := NewReceiptService(repos.Receipts, repos.Payments, testConfig())
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	rent := mustCreateFee(t, feeService, tenant.ID, "rent", 3000, date(2026, 10, 5))
+	water := mustCreateFee(t, feeService, tenant.ID, "water", 120.5, date(2026, 10, 5))
+
+	first := &model.Payment{Amount: 1000, ReceivedAt: *date(2026, 10, 3)}
+	if err := feeService.Pay(rent.ID, first, testActor); err != nil {
+		t.Fatalf("pay fee: %v", err)
+	}
+	if first.Receipt == nil || first.Receipt.ReceiptNo != expectedNo("RC", 1) || first.Receipt.IssuedBy != testActor.Name {
+		t.Fatalf("unexpected receipt: %+v", first.Receipt)
+	}
+
+	// 登记失败的收款不占用收据编号
+	if err := paymentService.Create(&model.Payment{TenantID: tenant.ID, Amount: 9999, Method: "cash",
+		Allocations: []model.PaymentAllocation{{FeeID: water.ID, Amount: 9999}}}, testActor); !errors.Is(err, ErrPaymentExceedsBalance) {
+		t.Fatalf("expected ErrPaymentExceedsBalance, got %v", err)
+	}
+	second := &model.Payment{TenantID: tenant.ID, Amount: 2120.5, Method: "wechat"}
+	if err := paymentService.Create(second, testActor); err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	receipt, err := receiptService.GetByPayment(second.ID)
+	if err != nil || receipt.ReceiptNo != expectedNo("RC", 2) || receipt.Amount != 2120.5 || receipt.TenantName != "租户甲" {
+		t.Fatalf("unexpected receipt: %+v, %v", receipt, err)
+	}
+	if words := utils.AmountInWords(receipt.Amount); words != "贰仟壹佰贰拾元伍角" {
+		t.Fatalf("unexpected amount in words: %s", words)
+	}
+	if p, _ := paymentService.GetByID(second.ID); p.Receipt == nil || p.Receipt.ID != receipt.ID {
+		t.Fatalf("payment must carry its receipt: %+v", p.Receipt)
+	}
+
+	// 第二次起的打印标注“补打”（UCS-2 编码为 88656253）
+	reprint := []byte("88656253")
+	_, data, err := receiptService.RenderPDF(receipt.ID)
+	if err != nil || !bytes.HasPrefix(data, []byte("%PDF-")) || bytes.Contains(data, reprint) {
+		t.Fatalf("unexpected first print: %v", err)
+	}
+	printed, data, _ := receiptService.RenderPDF(receipt.ID)
+	if printed.PrintCount != 2 || !bytes.Contains(data, reprint) {
+		t.Fatalf("second print must be marked as a reprint: %+v", printed)
+	}
+
+	if _, err := paymentService.Reverse(second.ID, "银行退票", testActor); err != nil {
+		t.Fatalf("reverse payment: %v", err)
+	}
+	voided, _ := receiptService.GetByID(receipt.ID)
+	if voided.Status != model.ReceiptStatusVoid || voided.VoidReason != "银行退票" || voided.VoidedBy != testActor.Name || voided.PrintCount != 2 {
+		t.Fatalf("reversal must void the receipt: %+v", voided)
+	}
+	if f, _ := feeService.GetByID(water.ID); f.Status == "paid" {
+		t.Fatalf("reversal must reopen the water fee: %+v", f)
+	}
+}
@@ -3,23 +3,24 @@ package service
 import (
 	"time"
 
+	"yuxialuozi_graduation_design_backend/internal/model"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 )
 
 type ReportService struct {
-	feeRepo         *repository.FeeRepository
-	roomRepo        *repository.RoomRepository
-	maintenanceRepo *repository.MaintenanceRepository
-	tenantRepo      *repository.TenantRepository
-	contractRepo    *repository.ContractRepository
+	feeRepo         repository.FeeRepository
+	roomRepo        repository.RoomRepository
+	maintenanceRepo repository.MaintenanceRepository
+	tenantRepo      repository.TenantRepository
+	contractRepo    repository.ContractRepository
 }
 
 func NewReportService(
-	feeRepo *repository.FeeRepository,
-	roomRepo *repository.RoomRepository,
-	maintenanceRepo *repository.MaintenanceRepository,
-	tenantRepo *repository.TenantRepository,
-	contractRepo *repository.ContractRepository,
+	feeRepo repository.FeeRepository,
+	roomRepo repository.RoomRepository,
+	maintenanceRepo repository.MaintenanceRepository,
+	tenantRepo repository.TenantRepository,
+	contractRepo repository.ContractRepository,
 ) *ReportService {
 	return &ReportService{
 		feeRepo:         feeRepo,
@@ -152,7 +153,7 @@ func (s *ReportService) GetDashboardData() (*DashboardData, error) {
 		return nil, err
 	}
 
-	activeContracts, err := s.contractRepo.CountByStatus("active")
+	activeContracts, err := s.contractRepo.CountByStatus(model.OccupyingContractStatuses...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestIncomeReport(t *testing.T) {
+	repos := newTestRepositories()
+	feeService := NewFeeService(repos.Fees, repos.Tenants, repos.UnitOfWork)
+	reportService := NewReportService(repos.Fees, repos.Rooms, repos.Maintenances, repos.Tenants, repos.Contracts)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	fees := []struct {
+		feeType string
+		amount  float64
+		paid    *time.Time
+	}{
+		{"rent", 3000, date(2026, 9, 3)},
+		{"rent", 3000, date(2026, 10, 3)},
+		{"water", 120.5, date(2026, 10, 8)},
+		{"electricity", 200, nil},         // 未缴不计入收入
+		{"rent", 3000, date(2026, 11, 3)}, // 超出统计区间
+	}
+	for _, f := range fees {
+		fee := &model.Fee{TenantID: tenant.ID, FeeType: f.feeType, Amount: f.amount}
+		if err := feeService.Create(fee, testActor); err != nil {
+			t.Fatalf("create fee: %v", err)
+		}
+		if f.paid != nil {
+			if err := feeService.Pay(fee.ID, &model.Payment{ReceivedAt: *f.paid}, testActor); err != nil {
+				t.Fatalf("pay fee: %v", err)
+			}
+		}
+	}
+
+	report, err := reportService.GetIncomeReport(*date(2026, 9, 1), *date(2026, 10, 31), "month")
+	if err != nil {
+		t.Fatalf("income report: %v", err)
+	}
+
+	if report.Total != 6120.5 {
+		t.Fatalf("expected total 6120.5, got %v", report.Total)
+	}
+	if len(report.ByMonth) != 2 ||
+		report.ByMonth[0].Month != "2026-09" || report.ByMonth[0].Amount != 3000 ||
+		report.ByMonth[1].Month != "2026-10" || report.ByMonth[1].Amount != 3120.5 {
+		t.Fatalf("unexpected income by month: %+v", report.ByMonth)
+	}
+	if len(report.ByType) != 2 ||
+		report.ByType[0].FeeType != "rent" || report.ByType[0].Amount != 6000 ||
+		report.ByType[1].FeeType != "water" || report.ByType[1].Amount != 120.5 {
+		t.Fatalf("unexpected income by type: %+v", report.ByType)
+	}
+}
+
+func TestOccupancyReport(t *testing.T) {
+	repos := newTestRepositories()
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	reportService := NewReportService(repos.Fees, repos.Rooms, repos.Maintenances, repos.Tenants, repos.Contracts)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	for _, roomNo := range []string{"A101", "A102", "A103", "A104"} {
+		room := mustCreateRoom(t, repos, roomNo)
+		if roomNo == "A101" {
+			if err := roomService.AssignTenant(room.ID, tenant.ID, testActor); err != nil {
+				t.Fatalf("assign tenant: %v", err)
+			}
+		}
+	}
+
+	report, err := reportService.GetOccupancyReport(time.Time{}, time.Now())
+	if err != nil {
+		t.Fatalf("occupancy report: %v", err)
+	}
+	if report.TotalRooms != 4 || report.OccupiedRooms != 1 || report.VacantRooms != 3 {
+		t.Fatalf("unexpected occupancy report: %+v", report)
+	}
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	return &t
+}
@@ -2,74 +2,209 @@ package service
 
 import (
 	"errors"
+	"fmt"
 
 	"yuxialuozi_graduation_design_backend/internal/model"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 )
 
+var (
+	ErrRoomOccupied   = errors.New("房间已被占用")
+	ErrTenantNotFound = errors.New("租户不存在")
+	ErrRoomUnderLease = errors.New("房间仍有生效合同，无法删除")
+	ErrRoomReferenced = errors.New("仍有合同关联该房间，无法永久删除")
+)
+
 type RoomService struct {
-	roomRepo   *repository.RoomRepository
-	tenantRepo *repository.TenantRepository
+	roomRepo   repository.RoomRepository
+	tenantRepo repository.TenantRepository
+	uow        repository.UnitOfWork
 }
 
-func NewRoomService(roomRepo *repository.RoomRepository, tenantRepo *repository.TenantRepository) *RoomService {
+func NewRoomService(roomRepo repository.RoomRepository, tenantRepo repository.TenantRepository, uow repository.UnitOfWork) *RoomService {
 	return &RoomService{
 		roomRepo:   roomRepo,
 		tenantRepo: tenantRepo,
+		uow:        uow,
 	}
 }
 
-func (s *RoomService) Create(room *model.Room) error {
-	return s.roomRepo.Create(room)
+// Create 新建房间并在同一事务中记录审计
+func (s *RoomService) Create(room *model.Room, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if err := tx.Rooms.Create(room); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRoom, room.ID, model.AuditActionCreate, nil, room)
+	})
 }
 
 func (s *RoomService) GetByID(id uint) (*model.Room, error) {
 	return s.roomRepo.FindByID(id)
 }
 
-func (s *RoomService) Update(room *model.Room) error {
-	return s.roomRepo.Update(room)
+// Update 锁定房间后保存修改，版本不一致时返回 ErrVersionConflict，审计与修改一同提交
+func (s *RoomService) Update(room *model.Room, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Rooms.FindByIDForUpdate(room.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Rooms.Update(room); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRoom, room.ID, model.AuditActionUpdate, current, room)
+	})
 }
 
-func (s *RoomService) Delete(id uint) error {
-	return s.roomRepo.Delete(id)
+// Delete 删除房间并记录审计，房间仍被生效合同租赁时返回 ErrRoomUnderLease
+func (s *RoomService) Delete(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		room, err := tx.Rooms.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		leases, err := tx.Contracts.FindByRoom(id, model.OccupyingContractStatuses...)
+		if err != nil {
+			return err
+		}
+		if len(leases) > 0 {
+			return fmt.Errorf("%w（合同 %s）", ErrRoomUnderLease, leases[0].ContractNo)
+		}
+
+		if err := tx.Rooms.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRoom, id, model.AuditActionDelete, room, nil)
+	})
 }
 
 func (s *RoomService) List(page, pageSize int, keyword, building, status string) ([]model.Room, int64, error) {
 	return s.roomRepo.List(page, pageSize, keyword, building, status)
 }
 
-func (s *RoomService) AssignTenant(roomID uint, tenantID uint) error {
-	room, err := s.roomRepo.FindByID(roomID)
+func (s *RoomService) ListByTenant(tenantID uint) ([]model.Room, error) {
+	return s.roomRepo.FindByTenantID(tenantID)
+}
+
+// AssignTenant 在事务中锁定房间行后检查占用状态，并对租户加共享锁防止其同时被删除；
+// 分配前后的房间记入审计
+func (s *RoomService) AssignTenant(roomID uint, tenantID uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		before, err := tx.Rooms.FindByIDForUpdate(roomID)
+		if err != nil {
+			return err
+		}
+		if err := s.assignTenant(tx, roomID, tenantID); err != nil {
+			return err
+		}
+		after, err := tx.Rooms.FindByID(roomID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRoom, roomID, model.AuditActionAssign, before, after)
+	})
+}
+
+// assignTenant 在调用方的事务中将房间分配给租户，合同生效时也通过它占用房间
+func (s *RoomService) assignTenant(tx *repository.Tx, roomID uint, tenantID uint) error {
+	room, err := tx.Rooms.FindByIDForUpdate(roomID)
 	if err != nil {
 		return err
 	}
 
-	if room.Status == "occupied" && room.TenantID != nil && *room.TenantID != tenantID {
-		return errors.New("房间已被占用")
+	if room.Status == "occupied" && room.TenantID != nil {
+		if *room.TenantID != tenantID {
+			return ErrRoomOccupied
+		}
+		return nil
 	}
 
-	_, err = s.tenantRepo.FindByID(tenantID)
-	if err != nil {
-		return errors.New("租户不存在")
+	if _, err := tx.Tenants.FindByIDForShare(tenantID); err != nil {
+		return ErrTenantNotFound
 	}
 
 	room.TenantID = &tenantID
 	room.Status = "occupied"
-	return s.roomRepo.Update(room)
+	return tx.Rooms.Update(room)
 }
 
 func (s *RoomService) ReleaseTenant(roomID uint) error {
-	room, err := s.roomRepo.FindByID(roomID)
+	return s.uow.Do(func(tx *repository.Tx) error {
+		return s.releaseTenant(tx, roomID)
+	})
+}
+
+func (s *RoomService) releaseTenant(tx *repository.Tx, roomID uint) error {
+	room, err := tx.Rooms.FindByIDForUpdate(roomID)
 	if err != nil {
 		return err
 	}
 
 	room.TenantID = nil
 	room.Status = "vacant"
-	return s.roomRepo.Update(room)
+	return tx.Rooms.Update(room)
 }
 
 func (s *RoomService) GetBuildings() ([]string, error) {
 	return s.roomRepo.GetBuildings()
 }
+
+func (s *RoomService) ListTrash(page, pageSize int) ([]model.Room, int64, error) {
+	return s.roomRepo.ListTrashed(page, pageSize)
+}
+
+func (s *RoomService) GetTrashedByID(id uint) (*model.Room, error) {
+	return s.roomRepo.FindTrashedByID(id)
+}
+
+// Restore 从回收站恢复房间并返回恢复后的房间，所属租户已删除时返回 ErrTenantDeleted
+func (s *RoomService) Restore(id uint, actor Actor) (*model.Room, error) {
+	var room *model.Room
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		before, err := tx.Rooms.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+
+		if before.TenantID != nil {
+			if _, err := tx.Tenants.FindByID(*before.TenantID); err != nil {
+				return ErrTenantDeleted
+			}
+		}
+
+		if err := tx.Rooms.Restore(id); err != nil {
+			return err
+		}
+		if room, err = tx.Rooms.FindByID(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRoom, id, model.AuditActionRestore, before, room)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// Purge 永久删除回收站中的房间，仍有合同关联时返回 ErrRoomReferenced
+func (s *RoomService) Purge(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		room, err := tx.Rooms.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+		referenced, err := tx.Rooms.HasReferences(id)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return ErrRoomReferenced
+		}
+		if err := tx.Rooms.Purge(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityRoom, id, model.AuditActionPurge, room, nil)
+	})
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestAssignTenant(t *testing.T) {
+	repos := newTestRepositories()
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+	room := mustCreateRoom(t, repos, "A101")
+
+	if err := roomService.AssignTenant(room.ID, tenant.ID, testActor); err != nil {
+		t.Fatalf("assign tenant: %v", err)
+	}
+
+	got, err := roomService.GetByID(room.ID)
+	if err != nil {
+		t.Fatalf("get room: %v", err)
+	}
+	if got.Status != "occupied" || got.TenantID == nil || *got.TenantID != tenant.ID {
+		t.Fatalf("room not assigned: status=%s tenantId=%v", got.Status, got.TenantID)
+	}
+	if got.TenantName != "租户甲" {
+		t.Fatalf("expected tenant name to be loaded, got %q", got.TenantName)
+	}
+	if got.Version != room.Version+1 {
+		t.Fatalf("expected version %d, got %d", room.Version+1, got.Version)
+	}
+
+	// 同一租户重复分配是幂等的
+	if err := roomService.AssignTenant(room.ID, tenant.ID, testActor); err != nil {
+		t.Fatalf("reassign same tenant: %v", err)
+	}
+}
+
+func TestAssignTenantOccupied(t *testing.T) {
+	repos := newTestRepositories()
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	first := mustCreateTenant(t, repos, "租户甲")
+	second := mustCreateTenant(t, repos, "租户乙")
+	room := mustCreateRoom(t, repos, "A101")
+
+	if err := roomService.AssignTenant(room.ID, first.ID, testActor); err != nil {
+		t.Fatalf("assign tenant: %v", err)
+	}
+	if err := roomService.AssignTenant(room.ID, second.ID, testActor); !errors.Is(err, ErrRoomOccupied) {
+		t.Fatalf("expected ErrRoomOccupied, got %v", err)
+	}
+
+	if err := roomService.ReleaseTenant(room.ID); err != nil {
+		t.Fatalf("release tenant: %v", err)
+	}
+	if err := roomService.AssignTenant(room.ID, second.ID, testActor); err != nil {
+		t.Fatalf("assign after release: %v", err)
+	}
+}
+
+func TestAssignTenantNotFound(t *testing.T) {
+	repos := newTestRepositories()
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	room := mustCreateRoom(t, repos, "A101")
+
+	if err := roomService.AssignTenant(room.ID, 999, testActor); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+
+	got, _ := roomService.GetByID(room.ID)
+	if got.Status != "vacant" || got.TenantID != nil {
+		t.Fatalf("failed assignment must not modify room: %+v", got)
+	}
+}
+
+func TestAssignTenantConcurrent(t *testing.T) {
+	repos := newTestRepositories()
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	room := mustCreateRoom(t, repos, "A101")
+
+	const n = 10
+	tenantIDs := make([]uint, n)
+	for i := range tenantIDs {
+		tenantIDs[i] = mustCreateTenant(t, repos, fmt.Sprintf("租户%d", i)).ID
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = roomService.AssignTenant(room.ID, tenantIDs[i], testActor)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRoomOccupied):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one successful assignment, got %d", succeeded)
+	}
+}
+
+func TestRoomMutationsAudited(t *testing.T) {
+	repos := newTestRepositories()
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	tenant := mustCreateTenant(t, repos, "租户甲")
+
+	room := &model.Room{RoomNo: "A101", Building: "A", MonthlyRent: 3000, Status: "vacant"}
+	if err := roomService.Create(room, testActor); err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	room.MonthlyRent = 3200
+	if err := roomService.Update(room, testActor); err != nil {
+		t.Fatalf("update room: %v", err)
+	}
+	if err := roomService.AssignTenant(room.ID, tenant.ID, testActor); err != nil {
+		t.Fatalf("assign tenant: %v", err)
+	}
+	if err := roomService.Delete(room.ID, testActor); err != nil {
+		t.Fatalf("delete room: %v", err)
+	}
+	restored, err := roomService.Restore(room.ID, testActor)
+	if err != nil || restored.TenantID == nil || *restored.TenantID != tenant.ID {
+		t.Fatalf("restore room: %+v, %v", restored, err)
+	}
+	if err := roomService.Delete(room.ID, testActor); err != nil {
+		t.Fatalf("delete room again: %v", err)
+	}
+	if err := roomService.Purge(room.ID, testActor); err != nil {
+		t.Fatalf("purge room: %v", err)
+	}
+
+	// 每次变更的审计都与变更在同一事务内写入
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityRoom, room.ID)
+	want := []string{
+		model.AuditActionCreate, model.AuditActionUpdate, model.AuditActionAssign,
+		model.AuditActionDelete, model.AuditActionRestore, model.AuditActionDelete, model.AuditActionPurge,
+	}
+	if len(audits) != len(want) {
+		t.Fatalf("unexpected room audit trail: %+v", audits)
+	}
+	for i, a := range audits {
+		if a.Action != want[i] || a.ActorName != testActor.Name {
+			t.Fatalf("audit %d: expected %s by %s, got %+v", i, want[i], testActor.Name, a)
+		}
+	}
+	if !bytes.Contains(audits[1].Before, []byte(`"monthlyRent":3000`)) || !bytes.Contains(audits[1].After, []byte(`"monthlyRent":3200`)) {
+		t.Fatalf("update audit must record the locked row and the change: %+v", audits[1])
+	}
+}
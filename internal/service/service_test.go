@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository/memory"
+	"yuxialuozi_graduation_design_backend/internal/storage"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server:   config.ServerConfig{Mode: "test"},
+		Database: config.DatabaseConfig{Driver: storage.DriverMemory},
+		JWT: config.JWTConfig{
+			Algorithm:     "HS256",
+			Secret:        "test-secret",
+			Expire:        "15m",
+			RefreshExpire: "168h",
+		},
+		Login: config.LoginConfig{
+			MaxAttempts:     3,
+			LockoutDuration: "15m",
+			IPMaxAttempts:   100,
+			IPWindow:        "15m",
+		},
+		MFA:      config.MFAConfig{Issuer: "test", PendingExpire: "5m"},
+		Contract: config.ContractConfig{ExpiringDays: 30, ReminderDays: []int{90, 60, 30}},
+	}
+}
+
+func newTestRepositories() *storage.Repositories {
+	return storage.NewMemoryRepositories(memory.NewStore())
+}
+
+func mustCreateTenant(t *testing.T, repos *storage.Repositories, name string) *model.Tenant {
+	t.Helper()
+	tenant := &model.Tenant{Name: name}
+	if err := repos.Tenants.Create(tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	return tenant
+}
+
+func mustCreateRoom(t *testing.T, repos *storage.Repositories, roomNo string) *model.Room {
+	t.Helper()
+	room := &model.Room{RoomNo: roomNo, Building: "A", MonthlyRent: 3000}
+	if err := repos.Rooms.Create(room); err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	return room
+}
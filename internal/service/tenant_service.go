@@ -1,32 +1,109 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+
 	"yuxialuozi_graduation_design_backend/internal/model"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 )
 
+var (
+	ErrTenantInUse      = errors.New("租户仍有生效合同、在租房间或未缴费用，无法删除")
+	ErrTenantReferenced = errors.New("仍有合同、房间、费用、工单或账号关联该租户，无法永久删除")
+	ErrTenantDeleted    = errors.New("所属租户已删除，请先恢复租户")
+)
+
 type TenantService struct {
-	tenantRepo *repository.TenantRepository
+	tenantRepo   repository.TenantRepository
+	contractRepo repository.ContractRepository
+	roomRepo     repository.RoomRepository
+	feeRepo      repository.FeeRepository
+	uow          repository.UnitOfWork
 }
 
-func NewTenantService(tenantRepo *repository.TenantRepository) *TenantService {
-	return &TenantService{tenantRepo: tenantRepo}
+func NewTenantService(
+	tenantRepo repository.TenantRepository,
+	contractRepo repository.ContractRepository,
+	roomRepo repository.RoomRepository,
+	feeRepo repository.FeeRepository,
+	uow repository.UnitOfWork,
+) *TenantService {
+	return &TenantService{
+		tenantRepo:   tenantRepo,
+		contractRepo: contractRepo,
+		roomRepo:     roomRepo,
+		feeRepo:      feeRepo,
+		uow:          uow,
+	}
 }
 
-func (s *TenantService) Create(tenant *model.Tenant) error {
-	return s.tenantRepo.Create(tenant)
+// Create 新建租户并在同一事务中记录审计
+func (s *TenantService) Create(tenant *model.Tenant, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		if err := tx.Tenants.Create(tenant); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityTenant, tenant.ID, model.AuditActionCreate, nil, tenant)
+	})
 }
 
 func (s *TenantService) GetByID(id uint) (*model.Tenant, error) {
 	return s.tenantRepo.FindByID(id)
 }
 
-func (s *TenantService) Update(tenant *model.Tenant) error {
-	return s.tenantRepo.Update(tenant)
+// Update 锁定租户后保存修改，版本不一致时返回 ErrVersionConflict；审计记录锁定时的数据与修改后的数据
+func (s *TenantService) Update(tenant *model.Tenant, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		current, err := tx.Tenants.FindByIDForUpdate(tenant.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Tenants.Update(tenant); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityTenant, tenant.ID, model.AuditActionUpdate, current, tenant)
+	})
 }
 
-func (s *TenantService) Delete(id uint) error {
-	return s.tenantRepo.Delete(id)
+// Delete 将租户移入回收站，仍有生效合同、在租房间、未缴费用或账户余额时拒绝删除。
+// 租户行加排他锁，与分配房间时的共享锁互斥，避免检查通过后又被分配房间
+func (s *TenantService) Delete(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		activeContracts, err := tx.Contracts.CountByTenant(id, model.OccupyingContractStatuses...)
+		if err != nil {
+			return err
+		}
+		occupiedRooms, err := tx.Rooms.CountByTenant(id)
+		if err != nil {
+			return err
+		}
+		unpaidFees, err := tx.Fees.CountByTenant(id, "unpaid", "partially_paid", "overdue")
+		if err != nil {
+			return err
+		}
+
+		if activeContracts > 0 || occupiedRooms > 0 || unpaidFees > 0 {
+			return fmt.Errorf("%w（生效合同 %d 份，在租房间 %d 间，未缴费用 %d 笔）", ErrTenantInUse, activeContracts, occupiedRooms, unpaidFees)
+		}
+		credit, err := tx.Accounts.CreditBalance(id)
+		if err != nil {
+			return err
+		}
+		if credit > 0 {
+			return fmt.Errorf("%w（账户余额 %.2f 元，请先退款）", ErrTenantInUse, credit)
+		}
+
+		if err := tx.Tenants.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityTenant, id, model.AuditActionDelete, tenant, nil)
+	})
 }
 
 func (s *TenantService) List(page, pageSize int, keyword, status string) ([]model.Tenant, int64, error) {
@@ -36,3 +113,54 @@ func (s *TenantService) List(page, pageSize int, keyword, status string) ([]mode
 func (s *TenantService) GetAll() ([]model.Tenant, error) {
 	return s.tenantRepo.FindAll()
 }
+
+func (s *TenantService) ListTrash(page, pageSize int) ([]model.Tenant, int64, error) {
+	return s.tenantRepo.ListTrashed(page, pageSize)
+}
+
+func (s *TenantService) GetTrashedByID(id uint) (*model.Tenant, error) {
+	return s.tenantRepo.FindTrashedByID(id)
+}
+
+// Restore 从回收站恢复租户，返回恢复后的租户
+func (s *TenantService) Restore(id uint, actor Actor) (*model.Tenant, error) {
+	var tenant *model.Tenant
+	err := s.uow.Do(func(tx *repository.Tx) error {
+		before, err := tx.Tenants.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Tenants.Restore(id); err != nil {
+			return err
+		}
+		if tenant, err = tx.Tenants.FindByID(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityTenant, id, model.AuditActionRestore, before, tenant)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// Purge 永久删除回收站中的租户，仍被其他记录引用时拒绝
+func (s *TenantService) Purge(id uint, actor Actor) error {
+	return s.uow.Do(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindTrashedByID(id)
+		if err != nil {
+			return err
+		}
+		referenced, err := tx.Tenants.HasReferences(id)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return ErrTenantReferenced
+		}
+		if err := tx.Tenants.Purge(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, model.AuditEntityTenant, id, model.AuditActionPurge, tenant, nil)
+	})
+}
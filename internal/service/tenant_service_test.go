@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+)
+
+func TestTenantMutationsAudited(t *testing.T) {
+	repos := newTestRepositories()
+	tenantService := NewTenantService(repos.Tenants, repos.Contracts, repos.Rooms, repos.Fees, repos.UnitOfWork)
+
+	tenant := &model.Tenant{Name: "租户甲", Phone: "13800000000", Status: "active"}
+	if err := tenantService.Create(tenant, testActor); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	tenant.Phone = "13900000000"
+	if err := tenantService.Update(tenant, testActor); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+
+	// 拒绝的删除不留下审计
+	room := mustCreateRoom(t, repos, "A101")
+	roomService := NewRoomService(repos.Rooms, repos.Tenants, repos.UnitOfWork)
+	if err := roomService.AssignTenant(room.ID, tenant.ID, testActor); err != nil {
+		t.Fatalf("assign tenant: %v", err)
+	}
+	if err := tenantService.Delete(tenant.ID, testActor); !errors.Is(err, ErrTenantInUse) {
+		t.Fatalf("expected ErrTenantInUse, got %v", err)
+	}
+	if err := roomService.Purge(room.ID, testActor); err == nil {
+		t.Fatal("expected purge of a room outside the trash to fail")
+	}
+	if err := roomService.Delete(room.ID, testActor); err != nil {
+		t.Fatalf("delete room: %v", err)
+	}
+	if err := roomService.Purge(room.ID, testActor); err != nil {
+		t.Fatalf("purge room: %v", err)
+	}
+
+	if err := tenantService.Delete(tenant.ID, testActor); err != nil {
+		t.Fatalf("delete tenant: %v", err)
+	}
+	restored, err := tenantService.Restore(tenant.ID, testActor)
+	if err != nil || restored.Phone != "13900000000" {
+		t.Fatalf("restore tenant: %+v, %v", restored, err)
+	}
+	if err := tenantService.Delete(tenant.ID, testActor); err != nil {
+		t.Fatalf("delete tenant again: %v", err)
+	}
+	if err := tenantService.Purge(tenant.ID, testActor); err != nil {
+		t.Fatalf("purge tenant: %v", err)
+	}
+
+	audits, _ := repos.AuditLogs.ListByEntity(model.AuditEntityTenant, tenant.ID)
+	want := []string{
+		model.AuditActionCreate, model.AuditActionUpdate, model.AuditActionDelete,
+		model.AuditActionRestore, model.AuditActionDelete, model.AuditActionPurge,
+	}
+	if len(audits) != len(want) {
+		t.Fatalf("unexpected tenant audit trail: %+v", audits)
+	}
+	for i, a := range audits {
+		if a.Action != want[i] || a.ActorName != testActor.Name {
+			t.Fatalf("audit %d: expected %s by %s, got %+v", i, want[i], testActor.Name, a)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"yuxialuozi_graduation_design_backend/internal/model"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+)
+
+type UserService struct {
+	userRepo   repository.UserRepository
+	tenantRepo repository.TenantRepository
+}
+
+func NewUserService(userRepo repository.UserRepository, tenantRepo repository.TenantRepository) *UserService {
+	return &UserService{
+		userRepo:   userRepo,
+		tenantRepo: tenantRepo,
+	}
+}
+
+func (s *UserService) Create(user *model.User, password string) error {
+	if _, err := s.userRepo.FindByUsername(user.Username); err == nil {
+		return errors.New("用户名已存在")
+	}
+
+	if user.Role == "" {
+		user.Role = model.RoleUser
+	}
+	if err := s.validateRole(user); err != nil {
+		return err
+	}
+	if user.Status == "" {
+		user.Status = model.UserStatusActive
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+
+	return s.userRepo.Create(user)
+}
+
+func (s *UserService) GetByID(id uint) (*model.User, error) {
+	return s.userRepo.FindByID(id)
+}
+
+func (s *UserService) Update(user *model.User) error {
+	if err := s.validateRole(user); err != nil {
+		return err
+	}
+	return s.userRepo.Update(user)
+}
+
+func (s *UserService) Delete(operatorID, id uint) error {
+	if operatorID == id {
+		return errors.New("不能删除当前登录用户")
+	}
+	return s.userRepo.Delete(id)
+}
+
+func (s *UserService) List(page, pageSize int, keyword, role, status string) ([]model.User, int64, error) {
+	return s.userRepo.List(page, pageSize, keyword, role, status)
+}
+
+// SetStatus 启用或禁用账号，禁用后该账号无法登录，已签发的 token 也会被 Authorize 拒绝
+func (s *UserService) SetStatus(operatorID, id uint, status string) error {
+	if operatorID == id && status == model.UserStatusDisabled {
+		return errors.New("不能禁用当前登录用户")
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	user.Status = status
+	return s.userRepo.Update(user)
+}
+
+// Unlock 解除账号因登录失败产生的锁定
+func (s *UserService) Unlock(id uint) error {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	user.FailedAttempts = 0
+	user.LockedUntil = nil
+	return s.userRepo.Update(user)
+}
+
+// ChangePassword 用户修改自己的密码，需校验旧密码
+func (s *UserService) ChangePassword(userID uint, oldPassword, newPassword string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return errors.New("原密码错误")
+	}
+
+	hashedPassword, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	return s.userRepo.Update(user)
+}
+
+// validateRole 校验角色合法；tenant 角色必须关联存在的租户，其他角色不关联租户
+func (s *UserService) validateRole(user *model.User) error {
+	if _, ok := model.RolePermissions[user.Role]; !ok {
+		return errors.New("无效的角色")
+	}
+
+	if user.Role != model.RoleTenant {
+		user.TenantID = nil
+		return nil
+	}
+
+	if user.TenantID == nil {
+		return errors.New("租户账号必须关联租户")
+	}
+	if _, err := s.tenantRepo.FindByID(*user.TenantID); err != nil {
+		return errors.New("租户不存在")
+	}
+	return nil
+}
+
+// ResetPassword 管理员重置指定用户的密码，同时解除登录锁定
+func (s *UserService) ResetPassword(username, newPassword string) error {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	hashedPassword, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.FailedAttempts = 0
+	user.LockedUntil = nil
+	return s.userRepo.Update(user)
+}
+
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
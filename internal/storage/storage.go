@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"yuxialuozi_graduation_design_backend/internal/config"
+	"yuxialuozi_graduation_design_backend/internal/database"
+	"yuxialuozi_graduation_design_backend/internal/repository"
+	"yuxialuozi_graduation_design_backend/internal/repository/memory"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+var ProviderSet = wire.NewSet(NewRepositories, RepositorySet)
+
+// RepositorySet 将 Repositories 的字段作为各仓储接口的提供者
+var RepositorySet = wire.NewSet(
+	wire.FieldsOf(new(*Repositories),
+		"Users", "Tokens", "LoginHistories", "SigningKeys", "APIKeys", "AuditLogs",
+		"Tenants", "Contracts", "CPIIndices", "Rooms", "Fees", "Payments", "Invoices", "Receipts", "Accounts", "Maintenances", "Notifications", "UnitOfWork",
+	),
+)
+
+// Repositories 按 database.driver 选择的一整套仓储实现
+type Repositories struct {
+	Users          repository.UserRepository
+	Tokens         repository.TokenRepository
+	LoginHistories repository.LoginHistoryRepository
+	SigningKeys    repository.SigningKeyRepository
+	APIKeys        repository.APIKeyRepository
+	AuditLogs      repository.AuditLogRepository
+	Tenants        repository.TenantRepository
+	Contracts      repository.ContractRepository
+	CPIIndices     repository.CPIIndexRepository
+	Rooms          repository.RoomRepository
+	Fees           repository.FeeRepository
+	Payments       repository.PaymentRepository
+	Invoices       repository.InvoiceRepository
+	Receipts       repository.ReceiptRepository
+	Accounts       repository.AccountRepository
+	Maintenances   repository.MaintenanceRepository
+	Notifications  repository.NotificationRepository
+	UnitOfWork     repository.UnitOfWork
+}
+
+func NewRepositories(cfg *config.Config) (*Repositories, error) {
+	switch cfg.Database.Driver {
+	case DriverMemory:
+		zap.L().Warn("using in-memory storage, data will be lost on restart")
+		return NewMemoryRepositories(memory.NewStore()), nil
+	case "", DriverPostgres:
+		db, err := database.NewDatabase(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewPostgresRepositories(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Database.Driver)
+	}
+}
+
+func NewPostgresRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:          repository.NewUserRepository(db),
+		Tokens:         repository.NewTokenRepository(db),
+		LoginHistories: repository.NewLoginHistoryRepository(db),
+		SigningKeys:    repository.NewSigningKeyRepository(db),
+		APIKeys:        repository.NewAPIKeyRepository(db),
+		AuditLogs:      repository.NewAuditLogRepository(db),
+		Tenants:        repository.NewTenantRepository(db),
+		Contracts:      repository.NewContractRepository(db),
+		CPIIndices:     repository.NewCPIIndexRepository(db),
+		Rooms:          repository.NewRoomRepository(db),
+		Fees:           repository.NewFeeRepository(db),
+		Payments:       repository.NewPaymentRepository(db),
+		Invoices:       repository.NewInvoiceRepository(db),
+		Receipts:       repository.NewReceiptRepository(db),
+		Accounts:       repository.NewAccountRepository(db),
+		Maintenances:   repository.NewMaintenanceRepository(db),
+		Notifications:  repository.NewNotificationRepository(db),
+		UnitOfWork:     repository.NewUnitOfWork(db),
+	}
+}
+
+// NewMemoryRepositories 基于同一个 Store 创建全部内存仓储，测试可直接传入预置数据的 Store
+func NewMemoryRepositories(store *memory.Store) *Repositories {
+	return &Repositories{
+		Users:          memory.NewUserRepository(store),
+		Tokens:         memory.NewTokenRepository(store),
+		LoginHistories: memory.NewLoginHistoryRepository(store),
+		SigningKeys:    memory.NewSigningKeyRepository(store),
+		APIKeys:        memory.NewAPIKeyRepository(store),
+		AuditLogs:      memory.NewAuditLogRepository(store),
+		Tenants:        memory.NewTenantRepository(store),
+		Contracts:      memory.NewContractRepository(store),
+		CPIIndices:     memory.NewCPIIndexRepository(store),
+		Rooms:          memory.NewRoomRepository(store),
+		Fees:           memory.NewFeeRepository(store),
+		Payments:       memory.NewPaymentRepository(store),
+		Invoices:       memory.NewInvoiceRepository(store),
+		Receipts:       memory.NewReceiptRepository(store),
+		Accounts:       memory.NewAccountRepository(store),
+		Maintenances:   memory.NewMaintenanceRepository(store),
+		Notifications:  memory.NewNotificationRepository(store),
+		UnitOfWork:     memory.NewUnitOfWork(store),
+	}
+}
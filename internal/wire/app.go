@@ -0,0 +1,24 @@
+package wire
+
+import (
+	"yuxialuozi_graduation_design_backend/internal/router"
+	"yuxialuozi_graduation_design_backend/internal/scheduler"
+)
+
+// App HTTP 服务与后台任务
+type App struct {
+	Router    *router.Router
+	Scheduler *scheduler.Scheduler
+}
+
+func NewApp(router *router.Router, scheduler *scheduler.Scheduler) *App {
+	return &App{Router: router, Scheduler: scheduler}
+}
+
+// Run 启动后台任务并运行 HTTP 服务，服务退出时停止后台任务
+func (a *App) Run() error {
+	a.Scheduler.Start()
+	defer a.Scheduler.Stop()
+
+	return a.Router.Run()
+}
@@ -6,22 +6,48 @@ package wire
 import (
 	"github.com/google/wire"
 
+	"yuxialuozi_graduation_design_backend/internal/cli"
 	"yuxialuozi_graduation_design_backend/internal/config"
 	"yuxialuozi_graduation_design_backend/internal/database"
 	"yuxialuozi_graduation_design_backend/internal/handler"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 	"yuxialuozi_graduation_design_backend/internal/router"
+	"yuxialuozi_graduation_design_backend/internal/scheduler"
 	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/internal/storage"
 )
 
-func InitializeApp() (*router.Router, func(), error) {
+func InitializeApp() (*App, func(), error) {
 	wire.Build(
 		config.ProviderSet,
-		database.ProviderSet,
-		repository.ProviderSet,
+		storage.ProviderSet,
+		service.ProviderSet,
+		handler.ProviderSet,
+		router.ProviderSet,
+		scheduler.ProviderSet,
+		NewApp,
+	)
+	return nil, nil, nil
+}
+
+// InitializeAppWithRepositories 使用给定的配置与仓储组装应用，测试中传入内存仓储
+func InitializeAppWithRepositories(cfg *config.Config, repos *storage.Repositories) *router.Router {
+	wire.Build(
+		storage.RepositorySet,
 		service.ProviderSet,
 		handler.ProviderSet,
 		router.ProviderSet,
 	)
+	return nil
+}
+
+func InitializeCLI() (*cli.CLI, func(), error) {
+	wire.Build(
+		config.ProviderSet,
+		database.ConnectionSet,
+		repository.ProviderSet,
+		service.ProviderSet,
+		cli.ProviderSet,
+	)
 	return nil, nil, nil
 }
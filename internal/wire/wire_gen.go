@@ -7,46 +7,174 @@
 package wire
 
 import (
+	"yuxialuozi_graduation_design_backend/internal/cli"
 	"yuxialuozi_graduation_design_backend/internal/config"
 	"yuxialuozi_graduation_design_backend/internal/database"
 	"yuxialuozi_graduation_design_backend/internal/handler"
 	"yuxialuozi_graduation_design_backend/internal/repository"
 	"yuxialuozi_graduation_design_backend/internal/router"
+	"yuxialuozi_graduation_design_backend/internal/scheduler"
 	"yuxialuozi_graduation_design_backend/internal/service"
+	"yuxialuozi_graduation_design_backend/internal/storage"
 )
 
-func InitializeApp() (*router.Router, func(), error) {
+func InitializeApp() (*App, func(), error) {
 	configConfig, err := config.NewConfig()
 	if err != nil {
 		return nil, nil, err
 	}
-	db, err := database.NewDatabase(configConfig)
+	repositories, err := storage.NewRepositories(configConfig)
 	if err != nil {
 		return nil, nil, err
 	}
-	userRepository := repository.NewUserRepository(db)
-	authService := service.NewAuthService(userRepository, configConfig)
+	userRepository := repositories.Users
+	tokenRepository := repositories.Tokens
+	loginHistoryRepository := repositories.LoginHistories
+	signingKeyRepository := repositories.SigningKeys
+	keyService := service.NewKeyService(signingKeyRepository, configConfig)
+	authService := service.NewAuthService(userRepository, tokenRepository, loginHistoryRepository, keyService, configConfig)
 	authHandler := handler.NewAuthHandler(authService)
-	tenantRepository := repository.NewTenantRepository(db)
-	tenantService := service.NewTenantService(tenantRepository)
+	tenantRepository := repositories.Tenants
+	userService := service.NewUserService(userRepository, tenantRepository)
+	userHandler := handler.NewUserHandler(userService)
+	mfaService := service.NewMFAService(userRepository, configConfig)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	keyHandler := handler.NewKeyHandler(keyService)
+	apiKeyRepository := repositories.APIKeys
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	auditLogRepository := repositories.AuditLogs
+	auditService := service.NewAuditService(auditLogRepository)
+	auditHandler := handler.NewAuditHandler(auditService)
+	contractRepository := repositories.Contracts
+	roomRepository := repositories.Rooms
+	feeRepository := repositories.Fees
+	paymentRepository := repositories.Payments
+	unitOfWork := repositories.UnitOfWork
+	tenantService := service.NewTenantService(tenantRepository, contractRepository, roomRepository, feeRepository, unitOfWork)
 	tenantHandler := handler.NewTenantHandler(tenantService)
-	contractRepository := repository.NewContractRepository(db)
-	contractService := service.NewContractService(contractRepository, tenantRepository)
+	roomService := service.NewRoomService(roomRepository, tenantRepository, unitOfWork)
+	cpiIndexRepository := repositories.CPIIndices
+	contractService := service.NewContractService(contractRepository, tenantRepository, cpiIndexRepository, roomService, unitOfWork)
 	contractHandler := handler.NewContractHandler(contractService)
-	roomRepository := repository.NewRoomRepository(db)
-	roomService := service.NewRoomService(roomRepository, tenantRepository)
 	roomHandler := handler.NewRoomHandler(roomService)
-	feeRepository := repository.NewFeeRepository(db)
-	feeService := service.NewFeeService(feeRepository, tenantRepository)
+	feeService := service.NewFeeService(feeRepository, tenantRepository, unitOfWork)
+	feeHandler := handler.NewFeeHandler(feeService)
+	maintenanceRepository := repositories.Maintenances
+	maintenanceService := service.NewMaintenanceService(maintenanceRepository, tenantRepository, unitOfWork)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceService)
+	reportService := service.NewReportService(feeRepository, roomRepository, maintenanceRepository, tenantRepository, contractRepository)
+	reportHandler := handler.NewReportHandler(reportService)
+	accountRepository := repositories.Accounts
+	accountService := service.NewAccountService(accountRepository, tenantRepository, feeRepository, paymentRepository, unitOfWork)
+	paymentService := service.NewPaymentService(paymentRepository, unitOfWork)
+	portalHandler := handler.NewPortalHandler(tenantService, feeService, paymentService, contractService, roomService, maintenanceService, accountService)
+	notificationRepository := repositories.Notifications
+	notificationService := service.NewNotificationService(notificationRepository)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	billingService := service.NewBillingService(contractRepository, feeRepository, unitOfWork, configConfig)
+	billingHandler := handler.NewBillingHandler(billingService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	invoiceRepository := repositories.Invoices
+	invoiceService := service.NewInvoiceService(invoiceRepository, feeRepository, unitOfWork, configConfig)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+	receiptRepository := repositories.Receipts
+	receiptService := service.NewReceiptService(receiptRepository, paymentRepository, configConfig)
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	routerRouter := router.NewRouter(configConfig, userRepository, tokenRepository, keyService, apiKeyService, authHandler, userHandler, mfaHandler, keyHandler, apiKeyHandler, tenantHandler, contractHandler, roomHandler, feeHandler, maintenanceHandler, reportHandler, portalHandler, auditHandler, notificationHandler, billingHandler, paymentHandler, invoiceHandler, receiptHandler, accountHandler)
+
+	contractExpiryService := service.NewContractExpiryService(contractRepository, notificationRepository, contractService, configConfig)
+	overdueService := service.NewOverdueService(feeRepository, unitOfWork, configConfig)
+	schedulerScheduler := scheduler.NewScheduler(configConfig, authService, contractExpiryService, contractService, billingService, overdueService)
+	app := NewApp(routerRouter, schedulerScheduler)
+
+	cleanup := func() {}
+
+	return app, cleanup, nil
+}
+
+func InitializeAppWithRepositories(cfg *config.Config, repositories *storage.Repositories) *router.Router {
+	userRepository := repositories.Users
+	tokenRepository := repositories.Tokens
+	loginHistoryRepository := repositories.LoginHistories
+	signingKeyRepository := repositories.SigningKeys
+	keyService := service.NewKeyService(signingKeyRepository, cfg)
+	authService := service.NewAuthService(userRepository, tokenRepository, loginHistoryRepository, keyService, cfg)
+	authHandler := handler.NewAuthHandler(authService)
+	tenantRepository := repositories.Tenants
+	userService := service.NewUserService(userRepository, tenantRepository)
+	userHandler := handler.NewUserHandler(userService)
+	mfaService := service.NewMFAService(userRepository, cfg)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	keyHandler := handler.NewKeyHandler(keyService)
+	apiKeyRepository := repositories.APIKeys
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	auditLogRepository := repositories.AuditLogs
+	auditService := service.NewAuditService(auditLogRepository)
+	auditHandler := handler.NewAuditHandler(auditService)
+	contractRepository := repositories.Contracts
+	roomRepository := repositories.Rooms
+	feeRepository := repositories.Fees
+	paymentRepository := repositories.Payments
+	unitOfWork := repositories.UnitOfWork
+	tenantService := service.NewTenantService(tenantRepository, contractRepository, roomRepository, feeRepository, unitOfWork)
+	tenantHandler := handler.NewTenantHandler(tenantService)
+	roomService := service.NewRoomService(roomRepository, tenantRepository, unitOfWork)
+	cpiIndexRepository := repositories.CPIIndices
+	contractService := service.NewContractService(contractRepository, tenantRepository, cpiIndexRepository, roomService, unitOfWork)
+	contractHandler := handler.NewContractHandler(contractService)
+	roomHandler := handler.NewRoomHandler(roomService)
+	feeService := service.NewFeeService(feeRepository, tenantRepository, unitOfWork)
 	feeHandler := handler.NewFeeHandler(feeService)
-	maintenanceRepository := repository.NewMaintenanceRepository(db)
-	maintenanceService := service.NewMaintenanceService(maintenanceRepository, tenantRepository)
+	maintenanceRepository := repositories.Maintenances
+	maintenanceService := service.NewMaintenanceService(maintenanceRepository, tenantRepository, unitOfWork)
 	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceService)
 	reportService := service.NewReportService(feeRepository, roomRepository, maintenanceRepository, tenantRepository, contractRepository)
 	reportHandler := handler.NewReportHandler(reportService)
-	routerRouter := router.NewRouter(configConfig, authHandler, tenantHandler, contractHandler, roomHandler, feeHandler, maintenanceHandler, reportHandler)
+	accountRepository := repositories.Accounts
+	accountService := service.NewAccountService(accountRepository, tenantRepository, feeRepository, paymentRepository, unitOfWork)
+	paymentService := service.NewPaymentService(paymentRepository, unitOfWork)
+	portalHandler := handler.NewPortalHandler(tenantService, feeService, paymentService, contractService, roomService, maintenanceService, accountService)
+	notificationRepository := repositories.Notifications
+	notificationService := service.NewNotificationService(notificationRepository)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	billingService := service.NewBillingService(contractRepository, feeRepository, unitOfWork, cfg)
+	billingHandler := handler.NewBillingHandler(billingService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	invoiceRepository := repositories.Invoices
+	invoiceService := service.NewInvoiceService(invoiceRepository, feeRepository, unitOfWork, cfg)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+	receiptRepository := repositories.Receipts
+	receiptService := service.NewReceiptService(receiptRepository, paymentRepository, cfg)
+	receiptHandler := handler.NewReceiptHandler(receiptService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	routerRouter := router.NewRouter(cfg, userRepository, tokenRepository, keyService, apiKeyService, authHandler, userHandler, mfaHandler, keyHandler, apiKeyHandler, tenantHandler, contractHandler, roomHandler, feeHandler, maintenanceHandler, reportHandler, portalHandler, auditHandler, notificationHandler, billingHandler, paymentHandler, invoiceHandler, receiptHandler, accountHandler)
+
+	return routerRouter
+}
+
+func InitializeCLI() (*cli.CLI, func(), error) {
+	configConfig, err := config.NewConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := database.Open(configConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	userRepository := repository.NewUserRepository(db)
+	tenantRepository := repository.NewTenantRepository(db)
+	userService := service.NewUserService(userRepository, tenantRepository)
+	contractRepository := repository.NewContractRepository(db)
+	roomRepository := repository.NewRoomRepository(db)
+	feeRepository := repository.NewFeeRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
+	tenantService := service.NewTenantService(tenantRepository, contractRepository, roomRepository, feeRepository, unitOfWork)
+	cliCLI := cli.NewCLI(db, userService, tenantService)
 
 	cleanup := func() {}
 
-	return routerRouter, cleanup, nil
+	return cliCLI, cleanup, nil
 }
@@ -6,6 +6,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// 业务错误码，用于同一 HTTP 状态下需要客户端区分处理的错误
+const (
+	// CodeInvalidStateTransition 当前状态不允许该操作，data 中返回当前状态与允许的目标状态
+	CodeInvalidStateTransition = 40901
+)
+
 type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
@@ -44,6 +50,14 @@ func ErrorWithHTTPStatus(c *gin.Context, httpStatus int, code int, message strin
 	})
 }
 
+func ErrorWithData(c *gin.Context, httpStatus int, code int, message string, data interface{}) {
+	c.JSON(httpStatus, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+}
+
 func BadRequest(c *gin.Context, message string) {
 	ErrorWithHTTPStatus(c, http.StatusBadRequest, 400, message)
 }
@@ -60,6 +74,28 @@ func NotFound(c *gin.Context, message string) {
 	ErrorWithHTTPStatus(c, http.StatusNotFound, 404, message)
 }
 
+func Conflict(c *gin.Context, message string) {
+	ErrorWithHTTPStatus(c, http.StatusConflict, 409, message)
+}
+
+// ConflictWithData 返回 409，并附带服务端当前数据供客户端合并
+func ConflictWithData(c *gin.Context, message string, data interface{}) {
+	ErrorWithData(c, http.StatusConflict, 409, message, data)
+}
+
+// PreconditionFailed 返回 412，并附带服务端当前数据
+func PreconditionFailed(c *gin.Context, message string, data interface{}) {
+	ErrorWithData(c, http.StatusPreconditionFailed, 412, message, data)
+}
+
+func PreconditionRequired(c *gin.Context, message string) {
+	ErrorWithHTTPStatus(c, http.StatusPreconditionRequired, 428, message)
+}
+
+func TooManyRequests(c *gin.Context, message string) {
+	ErrorWithHTTPStatus(c, http.StatusTooManyRequests, 429, message)
+}
+
 func InternalError(c *gin.Context, message string) {
 	ErrorWithHTTPStatus(c, http.StatusInternalServerError, 500, message)
 }
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SigningKey 签名/验签密钥。HS256 时 SignKey 与 VerifyKey 为同一共享密钥；
+// 非对称算法时 SignKey 为私钥（仅验签的密钥可为空），VerifyKey 为公钥。
+type SigningKey struct {
+	KID       string
+	Method    jwt.SigningMethod
+	SignKey   interface{}
+	VerifyKey interface{}
+}
+
+func NewHMACKey(secret string) *SigningKey {
+	return &SigningKey{
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   []byte(secret),
+		VerifyKey: []byte(secret),
+	}
+}
+
+// GenerateKeyPair 生成指定算法的密钥对，返回 PKCS#8 私钥与 PKIX 公钥的 PEM 编码
+func GenerateKeyPair(alg string) (privatePEM, publicPEM string, err error) {
+	var privateKey, publicKey interface{}
+	switch alg {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return "", "", err
+		}
+		privateKey, publicKey = key, &key.PublicKey
+	case AlgEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		privateKey, publicKey = priv, pub
+	default:
+		return "", "", errors.New("unsupported algorithm: " + alg)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return privatePEM, publicPEM, nil
+}
+
+// ParseKeyPair 解析 PEM 编码的密钥对，privatePEM 为空时得到仅可验签的密钥
+func ParseKeyPair(kid, alg, privatePEM, publicPEM string) (*SigningKey, error) {
+	key := &SigningKey{KID: kid}
+	switch alg {
+	case AlgRS256:
+		key.Method = jwt.SigningMethodRS256
+	case AlgEdDSA:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("unsupported algorithm: " + alg)
+	}
+
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("invalid public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key.VerifyKey = publicKey
+
+	if privatePEM != "" {
+		block, _ := pem.Decode([]byte(privatePEM))
+		if block == nil {
+			return nil, errors.New("invalid private key")
+		}
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.SignKey = privateKey
+	}
+
+	return key, nil
+}
+
+// JWK RFC 7517 公钥表示
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK 导出公钥的 JWK，对称密钥不可导出
+func PublicJWK(key *SigningKey) (JWK, bool) {
+	enc := base64.RawURLEncoding
+	switch pub := key.VerifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: key.KID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			N:   enc.EncodeToString(pub.N.Bytes()),
+			E:   enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: key.KID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			Crv: "Ed25519",
+			X:   enc.EncodeToString(pub),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
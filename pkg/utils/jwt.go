@@ -7,32 +7,64 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// PurposeMFA 标记两步验证过程中签发的临时令牌，不能用于访问业务接口
+const PurposeMFA = "mfa"
+
 type Claims struct {
 	UserID   uint   `json:"userId"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Purpose  string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, username, role, secret string, expire time.Duration) (string, error) {
+// KeyLookup 根据 token 头部的 kid 查找验签密钥
+type KeyLookup func(kid string) (*SigningKey, error)
+
+func GenerateToken(userID uint, username, role, tokenID string, key *SigningKey, expire time.Duration) (string, error) {
 	claims := Claims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return signClaims(claims, key)
 }
 
-func ParseToken(tokenString, secret string) (*Claims, error) {
+// GenerateMFAToken 生成密码校验通过、等待两步验证的临时令牌
+func GenerateMFAToken(userID uint, username string, key *SigningKey, expire time.Duration) (string, error) {
+	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		Purpose:  PurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signClaims(claims, key)
+}
+
+func ParseToken(tokenString string, lookup KeyLookup) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
+		kid, _ := token.Header["kid"].(string)
+		key, err := lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		// 签名算法必须与密钥一致，防止算法混淆攻击
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.VerifyKey, nil
 	})
 
 	if err != nil {
@@ -45,3 +77,11 @@ func ParseToken(tokenString, secret string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+func signClaims(claims Claims, key *SigningKey) (string, error) {
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.KID != "" {
+		token.Header["kid"] = key.KID
+	}
+	return token.SignedString(key.SignKey)
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+var (
+	cnDigits     = []string{"零", "壹", "贰", "叁", "肆", "伍", "陆", "柒", "捌", "玖"}
+	cnPlaces     = []string{"仟", "佰", "拾", ""}
+	cnGroupUnits = []string{"", "万", "亿", "万亿"}
+)
+
+// AmountInWords 将金额转为人民币大写，如 3120.5 转为 叁仟壹佰贰拾元伍角，100 转为 壹佰元整；
+// 金额按分四舍五入，负数以“负”开头
+func AmountInWords(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	var b strings.Builder
+	if amount < 0 && cents > 0 {
+		b.WriteString("负")
+	}
+
+	yuan, jiao, fen := cents/100, cents/10%10, cents%10
+	if yuan > 0 || cents == 0 {
+		b.WriteString(integerInWords(yuan))
+		b.WriteString("元")
+	}
+	if jiao == 0 && fen == 0 {
+		b.WriteString("整")
+		return b.String()
+	}
+	if jiao > 0 {
+		b.WriteString(cnDigits[jiao] + "角")
+	} else if yuan > 0 {
+		b.WriteString("零")
+	}
+	if fen > 0 {
+		b.WriteString(cnDigits[fen] + "分")
+	}
+	return b.String()
+}
+
+// integerInWords 按万、亿分节转换整数部分，节内与节间连续的零只读一个“零”
+func integerInWords(n int64) string {
+	if n == 0 {
+		return cnDigits[0]
+	}
+	var groups []int64
+	for ; n > 0; n /= 10000 {
+		groups = append(groups, n%10000)
+	}
+
+	var b strings.Builder
+	zero := false
+	for i := len(groups) - 1; i >= 0; i-- {
+		g := groups[i]
+		if g == 0 {
+			zero = true
+			continue
+		}
+		if b.Len() > 0 && (zero || g < 1000) {
+			b.WriteString(cnDigits[0])
+		}
+		b.WriteString(groupInWords(g))
+		b.WriteString(cnGroupUnits[i])
+		zero = false
+	}
+	return b.String()
+}
+
+func groupInWords(g int64) string {
+	digits := []int64{g / 1000, g / 100 % 10, g / 10 % 10, g % 10}
+	var b strings.Builder
+	started, zero := false, false
+	for i, d := range digits {
+		if d == 0 {
+			zero = started
+			continue
+		}
+		if zero {
+			b.WriteString(cnDigits[0])
+			zero = false
+		}
+		b.WriteString(cnDigits[d] + cnPlaces[i])
+		started = true
+	}
+	return b.String()
+}
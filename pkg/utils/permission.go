@@ -0,0 +1,33 @@
+package utils
+
+import "strings"
+
+// HasPermission 判断已授予的权限中是否包含 required。
+// 支持通配：* 匹配全部，tenant:* 匹配 tenant 下所有操作，*:read 匹配所有资源的 read 操作。
+func HasPermission(granted []string, required string) bool {
+	for _, p := range granted {
+		if matchPermission(p, required) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPermission(pattern, required string) bool {
+	if pattern == "*" || pattern == required {
+		return true
+	}
+
+	patternParts := strings.Split(pattern, ":")
+	requiredParts := strings.Split(required, ":")
+	if len(patternParts) != len(requiredParts) {
+		return false
+	}
+
+	for i := range patternParts {
+		if patternParts[i] != "*" && patternParts[i] != requiredParts[i] {
+			return false
+		}
+	}
+	return true
+}